@@ -0,0 +1,224 @@
+// Package acl enforces access control lists that map routes to the roles they require. The lists are loaded from a
+// config file, so the authorization rules can be audited and changed without touching handlers.
+//
+//	{
+//		"default": "deny",
+//		"rules": [
+//			{"path": "/health", "public": true},
+//			{"route": "users.delete", "roles": ["admin"]},
+//			{"path": "/admin/**", "methods": ["GET"], "roles": ["admin", "support"]},
+//			{"path": "/admin/**", "roles": ["admin"]},
+//			{"path": "/**", "roles": ["user"]}
+//		]
+//	}
+package acl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/authz"
+)
+
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// Rule grants access to the routes with the name, or with a path matching the pattern. Patterns are matched against
+// the path of the route, e.g. "/users/{id}", "*" matches a segment and a trailing "/**" everything below the path.
+// Rules without methods apply to every method. Principals need one of the roles, public routes need none.
+type Rule struct {
+	Route   string   `json:"route,omitempty"`
+	Path    string   `json:"path,omitempty"`
+	Methods []string `json:"methods,omitempty"`
+	Roles   []string `json:"roles,omitempty"`
+	Public  bool     `json:"public,omitempty"`
+}
+
+// ACL is a list of rules, the first rule that applies to a route decides. Routes without a rule are handled by the
+// default, which is Deny unless it's set to Allow.
+type ACL struct {
+	Default string `json:"default,omitempty"`
+	Rules   []Rule `json:"rules"`
+}
+
+// RoleHolder is a principal that knows its roles, see authz.WithPrincipal.
+type RoleHolder interface {
+	Roles() []string
+}
+
+// Load reads the ACL from a JSON file.
+func Load(file string) (*ACL, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes the ACL from JSON and validates its rules.
+func Parse(data []byte) (*ACL, error) {
+	var acl ACL
+	if err := json.Unmarshal(data, &acl); err != nil {
+		return nil, fmt.Errorf("acl: %w", err)
+	}
+	if err := acl.Validate(); err != nil {
+		return nil, err
+	}
+	return &acl, nil
+}
+
+// Validate checks that every rule has either a route name or a valid path, and roles or is public.
+func (a *ACL) Validate() error {
+	if a.Default != "" && a.Default != Allow && a.Default != Deny {
+		return fmt.Errorf("acl: invalid default %q, expected %q or %q", a.Default, Allow, Deny)
+	}
+	var errs []error
+	for i, rule := range a.Rules {
+		switch {
+		case (rule.Route == "") == (rule.Path == ""):
+			errs = append(errs, fmt.Errorf("acl: rule %d needs either a route or a path", i))
+		case rule.Path != "" && !strings.HasPrefix(rule.Path, "/"):
+			errs = append(errs, fmt.Errorf("acl: rule %d: path %q must start with a slash", i, rule.Path))
+		case rule.Public && len(rule.Roles) > 0:
+			errs = append(errs, fmt.Errorf("acl: rule %d is public and has roles", i))
+		case !rule.Public && len(rule.Roles) == 0:
+			errs = append(errs, fmt.Errorf("acl: rule %d needs roles or to be public", i))
+		}
+		if _, err := path.Match(strings.TrimSuffix(rule.Path, "/**"), ""); err != nil {
+			errs = append(errs, fmt.Errorf("acl: rule %d: invalid path %q: %w", i, rule.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Middleware enforces the ACL for the matched route. Requests without a principal get a 401 response and principals
+// without one of the roles a 403, both through the error handler of the router. The roles are those of a RoleHolder
+// principal, so the middleware has to run after the authentication middleware. Unmatched requests pass through.
+func (a *ACL) Middleware() router.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			route := router.MatchedRoute(r)
+			if route == nil {
+				next(w, r)
+				return
+			}
+			i := a.ruleFor(route, r.Method)
+			if i < 0 {
+				if a.Default == Allow {
+					next(w, r)
+				} else {
+					router.Error(w, r, http.StatusForbidden, authz.ErrForbidden)
+				}
+				return
+			}
+			rule := a.Rules[i]
+			if rule.Public {
+				next(w, r)
+				return
+			}
+
+			principal := authz.Principal(r)
+			if principal == nil {
+				router.Error(w, r, http.StatusUnauthorized, authz.ErrUnauthenticated)
+				return
+			}
+			holder, _ := principal.(RoleHolder)
+			if holder == nil || !slices.ContainsFunc(holder.Roles(), func(role string) bool {
+				return slices.Contains(rule.Roles, role)
+			}) {
+				router.Error(w, r, http.StatusForbidden, authz.ErrForbidden)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// Access is the access to a route, as decided by the ACL. Rule is the index of the rule that applies, or -1 when the
+// default applies. Method is empty when the access applies to every method of the route.
+type Access struct {
+	Route  string   `json:"route"`
+	Name   string   `json:"name,omitempty"`
+	Method string   `json:"method,omitempty"`
+	Rule   int      `json:"rule"`
+	Public bool     `json:"public,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+	Denied bool     `json:"denied,omitempty"`
+}
+
+// Audit returns the access to every route of the router, so the effect of the rules can be reviewed, e.g. in a test
+// that fails when a route is public by accident. The routes have to be set up. Routes without a method, like mounts,
+// get an access per method of the rules that apply differently, and one without a method for the other methods. HEAD
+// requests have the access of GET, GET routes get an access for HEAD as well when a rule names HEAD.
+func (a *ACL) Audit(r *router.Router) []Access {
+	var accesses []Access
+	for _, route := range r.Routes() {
+		methods := []string{route.Method}
+		if route.Method == "" {
+			methods = a.methods(route)
+		} else if route.Method == http.MethodGet && a.ruleFor(route, http.MethodHead) != a.ruleFor(route, http.MethodGet) {
+			methods = append(methods, http.MethodHead)
+		}
+		for _, method := range methods {
+			access := Access{Route: route.FullPattern(), Name: route.GetName(), Method: method, Rule: a.ruleFor(route, method)}
+			if access.Rule >= 0 {
+				access.Public, access.Roles = a.Rules[access.Rule].Public, a.Rules[access.Rule].Roles
+			} else if a.Default == Allow {
+				access.Public = true
+			} else {
+				access.Denied = true
+			}
+			accesses = append(accesses, access)
+		}
+	}
+	return accesses
+}
+
+// methods returns the methods of the rules that decide differently for the route than the other methods, followed by
+// an empty method for the other methods
+func (a *ACL) methods(route *router.Route) []string {
+	other := a.ruleFor(route, "")
+	var methods []string
+	for _, rule := range a.Rules {
+		for _, method := range rule.Methods {
+			if !slices.Contains(methods, method) && a.ruleFor(route, method) != other {
+				methods = append(methods, method)
+			}
+		}
+	}
+	slices.Sort(methods)
+	return append(methods, "")
+}
+
+// ruleFor returns the index of the first rule that applies to the route and method, or -1. HEAD requests get the
+// rules for GET as well, like the GET routes they are served by. An empty method only gets rules without methods.
+func (a *ACL) ruleFor(route *router.Route, method string) int {
+	routePath := route.Path()
+	for i, rule := range a.Rules {
+		if len(rule.Methods) > 0 && !slices.Contains(rule.Methods, method) &&
+			!(method == http.MethodHead && slices.Contains(rule.Methods, http.MethodGet)) {
+			continue
+		}
+		if rule.Route != "" && rule.Route == route.GetName() || rule.Path != "" && matchPath(rule.Path, routePath) {
+			return i
+		}
+	}
+	return -1
+}
+
+func matchPath(pattern string, routePath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return routePath == prefix || strings.HasPrefix(routePath, prefix+"/")
+	}
+	matched, _ := path.Match(pattern, routePath)
+	return matched
+}
@@ -0,0 +1,198 @@
+package acl_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/acl"
+	"github.com/gogo-framework/router/authz"
+)
+
+const config = `{
+	"rules": [
+		{"path": "/health", "public": true},
+		{"route": "users.delete", "roles": ["admin"]},
+		{"path": "/admin/**", "methods": ["GET"], "roles": ["admin", "support"]},
+		{"path": "/admin/**", "roles": ["admin"]},
+		{"path": "/users/*", "roles": ["user"]}
+	]
+}`
+
+type user struct {
+	roles []string
+}
+
+func (u *user) Roles() []string {
+	return u.roles
+}
+
+// authenticate sets a principal with the roles of the X-Roles header
+func authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if roles, ok := r.Header["X-Roles"]; ok {
+			r = authz.WithPrincipal(r, &user{roles: roles})
+		}
+		next(w, r)
+	}
+}
+
+func TestACL(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "acl.json")
+	os.WriteFile(file, []byte(config), 0o600)
+	list, err := acl.Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(authenticate, list.Middleware())
+
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/health", handler)
+	r.GET("/users/{id}", handler)
+	r.DELETE("/users/{id}", handler).Name("users.delete")
+	r.Group("/admin", func(r *router.Router) {
+		r.GET("/settings", handler)
+		r.PUT("/settings", handler)
+	})
+	r.GET("/reports", handler)
+
+	// Define test cases
+	tests := []struct {
+		method       string
+		path         string
+		roles        []string
+		expectedCode int
+	}{
+		{http.MethodGet, "/health/", nil, http.StatusOK},
+		{http.MethodGet, "/users/1/", nil, http.StatusUnauthorized},
+		{http.MethodGet, "/users/1/", []string{"guest"}, http.StatusForbidden},
+		{http.MethodGet, "/users/1/", []string{"guest", "user"}, http.StatusOK},
+		{http.MethodDelete, "/users/1/", []string{"user"}, http.StatusForbidden},
+		{http.MethodDelete, "/users/1/", []string{"admin"}, http.StatusOK},
+		{http.MethodGet, "/admin/settings/", []string{"support"}, http.StatusOK},
+		{http.MethodHead, "/admin/settings/", nil, http.StatusUnauthorized},
+		{http.MethodHead, "/admin/settings/", []string{"support"}, http.StatusOK},
+		{http.MethodPut, "/admin/settings/", []string{"support"}, http.StatusForbidden},
+		{http.MethodPut, "/admin/settings/", []string{"admin"}, http.StatusOK},
+		{http.MethodGet, "/reports/", []string{"admin"}, http.StatusForbidden},
+		{http.MethodGet, "/missing/", nil, http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.roles != nil {
+			req.Header["X-Roles"] = tc.roles
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tc.expectedCode {
+			t.Errorf("%s %s with %v: expected status code %d, got %d", tc.method, tc.path, tc.roles, tc.expectedCode, rr.Code)
+		}
+	}
+
+	// The audit lists the rule of every route
+	audit := map[string]acl.Access{}
+	for _, access := range list.Audit(r) {
+		audit[access.Route+" "+access.Method] = access
+	}
+	expected := map[string]int{
+		"GET /health/{$} GET":           0,
+		"GET /users/{id}/{$} GET":       4,
+		"DELETE /users/{id}/{$} DELETE": 1,
+		"GET /admin/settings/{$} GET":   2,
+		"PUT /admin/settings/{$} PUT":   3,
+		"GET /reports/{$} GET":          -1,
+	}
+	for route, rule := range expected {
+		if access := audit[route]; access.Rule != rule {
+			t.Errorf("%s: expected rule %d, got %+v", route, rule, access)
+		}
+	}
+	if !audit["GET /health/{$} GET"].Public || !audit["GET /reports/{$} GET"].Denied {
+		t.Errorf("Expected the health route to be public and the reports route to be denied")
+	}
+}
+
+func TestACLMethods(t *testing.T) {
+	list, err := acl.Parse([]byte(`{
+		"rules": [
+			{"path": "/reports/**", "methods": ["GET"], "roles": ["admin"]},
+			{"path": "/**", "public": true}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(authenticate, list.Middleware())
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/reports/{id}", handler)
+	r.Mount("/reports/archive/files", http.HandlerFunc(handler))
+
+	// Define test cases
+	tests := []struct {
+		method       string
+		path         string
+		expectedCode int
+	}{
+		{http.MethodGet, "/reports/1/", http.StatusUnauthorized},
+		{http.MethodHead, "/reports/1/", http.StatusUnauthorized},
+		{http.MethodGet, "/reports/archive/files/q1.pdf", http.StatusUnauthorized},
+		{http.MethodHead, "/reports/archive/files/q1.pdf", http.StatusUnauthorized},
+		{http.MethodPost, "/reports/archive/files/q1.pdf", http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, nil))
+
+		if rr.Code != tc.expectedCode {
+			t.Errorf("%s %s: expected status code %d, got %d", tc.method, tc.path, tc.expectedCode, rr.Code)
+		}
+	}
+
+	// The audit of the mount shows the rule of GET and the rule of the other methods
+	var mount []acl.Access
+	for _, access := range list.Audit(r) {
+		if access.Route == "/reports/archive/files/" {
+			mount = append(mount, access)
+		}
+	}
+	if len(mount) != 2 || mount[0].Method != http.MethodGet || mount[0].Rule != 0 || mount[1].Method != "" || !mount[1].Public {
+		t.Errorf("Expected the GET rule and a public access for the other methods of the mount, got %+v", mount)
+	}
+}
+
+func TestACLValidate(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		config string
+		valid  bool
+	}{
+		{`{"rules": [{"path": "/users/**", "roles": ["user"]}]}`, true},
+		{`{"default": "allow", "rules": []}`, true},
+		{`{"default": "maybe", "rules": []}`, false},
+		{`{"rules": [{"roles": ["user"]}]}`, false},
+		{`{"rules": [{"route": "users", "path": "/users", "roles": ["user"]}]}`, false},
+		{`{"rules": [{"path": "users", "roles": ["user"]}]}`, false},
+		{`{"rules": [{"path": "/users/[", "roles": ["user"]}]}`, false},
+		{`{"rules": [{"path": "/users"}]}`, false},
+		{`{"rules": [{"path": "/users", "public": true, "roles": ["user"]}]}`, false},
+		{`{"rules": [`, false},
+	}
+
+	for _, tc := range tests {
+		if _, err := acl.Parse([]byte(tc.config)); (err == nil) != tc.valid {
+			t.Errorf("%s: expected valid %v, got %v", tc.config, tc.valid, err)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package router
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrMaintenance is the error passed to the error handler for requests refused in maintenance mode.
+var ErrMaintenance = errors.New("router: down for maintenance")
+
+type AdminConfig struct {
+	// Token is the bearer token required in the Authorization header of admin requests
+	Token string
+	// Authorize decides if a request may use the admin API, it's used instead of Token when set
+	Authorize func(r *http.Request) bool
+	// Endpoints are extra endpoints of the admin API, e.g. {"/slo": tracker.Report}. Patterns are GET endpoints
+	// unless they start with a method, like "PUT /chaos"
+	Endpoints map[string]http.HandlerFunc
+}
+
+// adminState is the runtime state that can be changed through the admin API
+type adminState struct {
+	maintenance atomic.Bool
+	flags       sync.Map // string -> bool
+	logLevel    slog.LevelVar
+}
+
+// SetMaintenance enables or disables maintenance mode, in which all routes except the admin API respond with 503.
+func (r *Router) SetMaintenance(enabled bool) {
+	r.admin.maintenance.Store(enabled)
+}
+
+// SetFlag enables or disables a feature flag.
+func (r *Router) SetFlag(name string, enabled bool) {
+	r.admin.flags.Store(name, enabled)
+}
+
+// Flag reports whether the feature flag is enabled, unknown flags are disabled.
+func (r *Router) Flag(name string) bool {
+	enabled, _ := r.admin.flags.Load(name)
+	return enabled == true
+}
+
+// FlagEnabled reports whether the feature flag is enabled on the router that matched the request.
+func FlagEnabled(req *http.Request, name string) bool {
+	r := routerFromRequest(req)
+	return r != nil && r.Flag(name)
+}
+
+// LogLevel is the level that can be changed through the admin API, use it as the level of the application's logger,
+// e.g. slog.HandlerOptions{Level: r.LogLevel()}.
+func (r *Router) LogLevel() *slog.LevelVar {
+	return &r.admin.logLevel
+}
+
+// EnableAdmin registers the admin API below the prefix, to list routes with their in-flight requests and the
+// connections of the server, explain route matching, toggle maintenance mode, flip feature flags and change the log
+// level at runtime. Requests have to be authorized by the config, it panics when the config has no way to authorize
+// requests.
+func (r *Router) EnableAdmin(prefix string, config AdminConfig) *RouteGroup {
+	if config.Token == "" && config.Authorize == nil {
+		panic("router: the admin API needs a Token or Authorize function")
+	}
+	authorize := config.Authorize
+	if authorize == nil {
+		authorize = func(req *http.Request) bool {
+			token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+			return ok && subtle.ConstantTimeCompare([]byte(token), []byte(config.Token)) == 1
+		}
+	}
+
+	return r.Group(prefix, func(admin *Router) {
+		admin.Use(func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) {
+				if !authorize(req) {
+					Error(w, req, http.StatusUnauthorized, nil)
+					return
+				}
+				next(w, req)
+			}
+		})
+
+		admin.GET("/routes", adminEndpoint((*Router).adminRoutes))
+		admin.GET("/in-flight", adminEndpoint((*Router).adminInFlight))
+		admin.GET("/connections", adminEndpoint((*Router).adminConnections))
+		admin.GET("/match", adminEndpoint((*Router).adminMatch))
+		admin.GET("/profile", adminEndpoint((*Router).adminProfile))
+		admin.GET("/maintenance", adminEndpoint((*Router).adminMaintenance))
+		admin.PUT("/maintenance", adminEndpoint((*Router).adminMaintenance))
+		admin.GET("/flags", adminEndpoint((*Router).adminFlags))
+		admin.PUT("/flags/{name}", adminEndpoint((*Router).adminFlags))
+		admin.GET("/log-level", adminEndpoint((*Router).adminLogLevel))
+		admin.PUT("/log-level", adminEndpoint((*Router).adminLogLevel))
+		for pattern, endpoint := range config.Endpoints {
+			method, path, ok := strings.Cut(pattern, " ")
+			if !ok {
+				method, path = http.MethodGet, pattern
+			}
+			admin.RegisterRoute(method, strings.TrimSpace(path), endpoint)
+		}
+	}).Set("admin", true)
+}
+
+// adminEndpoint calls the endpoint on the router that matched the request, so the admin API of a cloned router
+// shows the state of the clone
+func adminEndpoint(endpoint func(r *Router, w http.ResponseWriter, req *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		endpoint(routerFromRequest(req), w, req)
+	}
+}
+
+type adminRoute struct {
+	Method   string `json:"method,omitempty"`
+	Path     string `json:"path"`
+	Name     string `json:"name,omitempty"`
+	Team     string `json:"team,omitempty"`
+	InFlight int64  `json:"in_flight"`
+	Source   string `json:"source,omitempty"`
+}
+
+func (r *Router) adminRoutes(w http.ResponseWriter, req *http.Request) {
+	routes := []adminRoute{}
+	for _, route := range r.Routes() {
+		routes = append(routes, adminRoute{
+			Method:   route.Method,
+			Path:     route.Path(),
+			Name:     route.name,
+			Team:     route.GetTeam(),
+			InFlight: route.inFlight.Load(),
+			Source:   route.Source(),
+		})
+	}
+	writeAdminJSON(w, routes)
+}
+
+func (r *Router) adminInFlight(w http.ResponseWriter, req *http.Request) {
+	var total int64
+	routes := map[string]int64{}
+	for _, route := range r.Routes() {
+		if n := route.inFlight.Load(); n > 0 {
+			routes[route.FullPattern()] = n
+			total += n
+		}
+	}
+	writeAdminJSON(w, map[string]any{"total": total, "routes": routes})
+}
+
+func (r *Router) adminMatch(w http.ResponseWriter, req *http.Request) {
+	method := req.URL.Query().Get("method")
+	if method == "" {
+		method = http.MethodGet
+	}
+	path := req.URL.Query().Get("path")
+	if !strings.HasPrefix(path, "/") {
+		Error(w, req, http.StatusBadRequest, errors.New("the path parameter has to start with a slash"))
+		return
+	}
+	// The host defaults to the host the admin API was requested at
+	host := req.URL.Query().Get("host")
+	if host == "" {
+		host = req.Host
+	}
+	explanation, err := r.Explain(method, host, path)
+	if err != nil {
+		Error(w, req, http.StatusBadRequest, err)
+		return
+	}
+	writeAdminJSON(w, explanation)
+}
+
+func (r *Router) adminProfile(w http.ResponseWriter, req *http.Request) {
+	profiles := map[string][]MiddlewareTiming{}
+	for _, route := range r.Routes() {
+		if profile := route.MiddlewareProfile(); profile != nil {
+			profiles[route.FullPattern()] = profile
+		}
+	}
+	writeAdminJSON(w, profiles)
+}
+
+type adminToggle struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (r *Router) adminMaintenance(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPut {
+		var body adminToggle
+		if !readAdminJSON(w, req, &body) {
+			return
+		}
+		r.SetMaintenance(body.Enabled)
+	}
+	writeAdminJSON(w, adminToggle{Enabled: r.admin.maintenance.Load()})
+}
+
+func (r *Router) adminFlags(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPut {
+		var body adminToggle
+		if !readAdminJSON(w, req, &body) {
+			return
+		}
+		r.SetFlag(req.PathValue("name"), body.Enabled)
+	}
+	flags := map[string]bool{}
+	r.admin.flags.Range(func(name, enabled any) bool {
+		flags[name.(string)] = enabled.(bool)
+		return true
+	})
+	writeAdminJSON(w, flags)
+}
+
+func (r *Router) adminLogLevel(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Level slog.Level `json:"level"`
+	}
+	if req.Method == http.MethodPut {
+		if !readAdminJSON(w, req, &body) {
+			return
+		}
+		r.admin.logLevel.Set(body.Level)
+	}
+	body.Level = r.admin.logLevel.Level()
+	writeAdminJSON(w, body)
+}
+
+func readAdminJSON(w http.ResponseWriter, req *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<10)).Decode(v); err != nil {
+		Error(w, req, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+func writeAdminJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(v)
+}
+
+// adminHandler counts the in-flight requests of the route and refuses requests in maintenance mode
+func (r *Router) adminHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	_, isAdmin := route.Get("admin")
+	return func(w http.ResponseWriter, req *http.Request) {
+		if !isAdmin && r.admin.maintenance.Load() {
+			w.Header().Set("Retry-After", "60")
+			r.writeError(w, req, http.StatusServiceUnavailable, ErrMaintenance)
+			return
+		}
+		route.inFlight.Add(1)
+		defer route.inFlight.Add(-1)
+		handler(w, req)
+	}
+}
@@ -0,0 +1,99 @@
+package router_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestAdmin(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.EnableAdmin("/__router", router.AdminConfig{Token: "secret"})
+
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		if router.FlagEnabled(r, "new-users") {
+			w.Write([]byte("new"))
+			return
+		}
+		w.Write([]byte("old"))
+	}).Name("users.list")
+
+	do := func(method string, path string, token string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Define test cases, requests are executed in order
+	tests := []struct {
+		method     string
+		path       string
+		token      string
+		body       string
+		statusCode int
+		response   string
+	}{
+		{http.MethodGet, "/__router/routes/", "", "", http.StatusUnauthorized, ""},
+		{http.MethodGet, "/__router/routes/", "wrong", "", http.StatusUnauthorized, ""},
+		{http.MethodGet, "/users/", "", "", http.StatusOK, "old"},
+		{http.MethodPut, "/__router/flags/new-users/", "secret", `{"enabled": true}`, http.StatusOK, `{"new-users":true}`},
+		{http.MethodGet, "/users/", "", "", http.StatusOK, "new"},
+		{http.MethodPut, "/__router/maintenance/", "secret", `{"enabled": true}`, http.StatusOK, `{"enabled":true}`},
+		{http.MethodGet, "/users/", "", "", http.StatusServiceUnavailable, ""},
+		{http.MethodGet, "/__router/maintenance/", "secret", "", http.StatusOK, `{"enabled":true}`},
+		{http.MethodPut, "/__router/maintenance/", "secret", `{"enabled": false}`, http.StatusOK, `{"enabled":false}`},
+		{http.MethodGet, "/users/", "", "", http.StatusOK, "new"},
+		{http.MethodPut, "/__router/log-level/", "secret", `{"level": "DEBUG"}`, http.StatusOK, `{"level":"DEBUG"}`},
+		{http.MethodPut, "/__router/log-level/", "secret", `{"level": "LOUD"}`, http.StatusBadRequest, ""},
+		{http.MethodGet, "/__router/in-flight/", "secret", "", http.StatusOK, `{"routes":{"GET /__router/in-flight/{$}":1},"total":1}`},
+		{http.MethodGet, "/__router/connections/", "secret", "", http.StatusOK, `{"new":0,"active":0,"idle":0,"accepted":0,"hijacked":0,"closed":0}`},
+	}
+
+	for _, tc := range tests {
+		rr := do(tc.method, tc.path, tc.token, tc.body)
+		if rr.Code != tc.statusCode {
+			t.Errorf("%s %s: expected status code %d, got %d", tc.method, tc.path, tc.statusCode, rr.Code)
+		}
+		if tc.response != "" && strings.TrimSpace(rr.Body.String()) != tc.response {
+			t.Errorf("%s %s: expected body %q, got %q", tc.method, tc.path, tc.response, rr.Body.String())
+		}
+	}
+
+	if r.LogLevel().Level() != slog.LevelDebug {
+		t.Errorf("Expected the log level to be changed to debug, got %s", r.LogLevel().Level())
+	}
+
+	var routes []struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+		Name   string `json:"name"`
+	}
+	if err := json.Unmarshal(do(http.MethodGet, "/__router/routes/", "secret", "").Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) < 2 || routes[1].Path != "/__router/routes" || routes[0].Path != "/users" || routes[0].Name != "users.list" {
+		t.Errorf("Unexpected routes %+v", routes)
+	}
+}
+
+func TestAdminRequiresAuthentication(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected EnableAdmin to panic without authentication")
+		}
+	}()
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.EnableAdmin("/__router", router.AdminConfig{})
+}
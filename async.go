@@ -0,0 +1,130 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// asyncWork counts the running work of Async. Unlike a sync.WaitGroup, work can start while Drain waits.
+type asyncWork struct {
+	mutex   sync.Mutex
+	running int
+	// idle is closed when the running work has finished
+	idle chan struct{}
+}
+
+func (a *asyncWork) add() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.running == 0 {
+		a.idle = make(chan struct{})
+	}
+	a.running++
+}
+
+func (a *asyncWork) done() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.running--
+	if a.running == 0 {
+		close(a.idle)
+	}
+}
+
+// wait returns a channel that is closed once the running work has finished
+func (a *asyncWork) wait() <-chan struct{} {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.running == 0 {
+		idle := make(chan struct{})
+		close(idle)
+		return idle
+	}
+	return a.idle
+}
+
+// Async runs work in the background once the handler of the request has returned, for the common pattern of
+// responding with 202 Accepted and doing the work afterwards. The context of the work has the values of the request
+// context, but isn't canceled when the request ends. Errors and panics of the work are logged, and panics are passed
+// to the panic reporter of the router. Router.Drain waits for the work on shutdown. Work passed outside of a
+// request of the router starts right away.
+//
+//	r.POST("/reports", func(w http.ResponseWriter, r *http.Request) {
+//		month := r.FormValue("month")
+//		router.Async(r, func(ctx context.Context) error {
+//			return reports.Generate(ctx, month)
+//		})
+//		w.WriteHeader(http.StatusAccepted)
+//	})
+func Async(r *http.Request, work func(ctx context.Context) error) {
+	owner := routerFromRequest(r)
+	if owner == nil {
+		owner = &Router{}
+	}
+	owner.async.add()
+	detached := r.WithContext(context.WithoutCancel(r.Context()))
+	job := func() {
+		owner.runAsync(detached, work)
+	}
+
+	c, _ := r.Context().Value(requestContextKey).(*requestContext)
+	if c != nil {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		if !c.done {
+			c.jobs = append(c.jobs, job)
+			return
+		}
+	}
+	go job()
+}
+
+// runAsync runs the work of Async, the request has the detached context
+func (r *Router) runAsync(req *http.Request, work func(ctx context.Context) error) {
+	defer r.async.done()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err := &PanicError{Value: recovered, Stack: stackTrace(3, false)}
+			if r.panicReporter != nil {
+				r.panicReporter(req, err)
+			}
+			r.Logger().Error("router: async work panicked", "method", req.Method, "path", req.URL.Path, "error", err)
+		}
+	}()
+	if err := work(req.Context()); err != nil {
+		r.Logger().Error("router: async work failed", "method", req.Method, "path", req.URL.Path, "error", err)
+	}
+}
+
+// startJobs starts the work passed to Async during the request, work passed later starts right away
+func (c *requestContext) startJobs() {
+	c.mutex.Lock()
+	jobs := c.jobs
+	c.jobs = nil
+	c.done = true
+	c.mutex.Unlock()
+	for _, job := range jobs {
+		go job()
+	}
+}
+
+// Drain waits until the work passed to Async has finished and the queued match events were delivered to the OnMatch
+// listeners, e.g. after the server was shut down, so neither is cut off. It returns the error of the context when the
+// context ends first.
+//
+//	server.Shutdown(ctx)
+//	r.Drain(ctx)
+func (r *Router) Drain(ctx context.Context) error {
+	// Requests of a swapped in router start their work on that router
+	for _, owner := range []*Router{r, r.Active()} {
+		for _, work := range []*asyncWork{&owner.async, &owner.matchEvents.pending} {
+			select {
+			case <-work.wait():
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+type asyncContextKey struct{}
+
+func TestAsync(t *testing.T) {
+	var logs bytes.Buffer
+	var mutex sync.Mutex
+	reported := make(chan *router.PanicError, 1)
+	release := make(chan struct{})
+
+	// Create a new router instance
+	r := router.NewRouter(
+		router.WithMux(http.NewServeMux()),
+		router.WithLogger(slog.New(slog.NewTextHandler(&syncWriter{mutex: &mutex, w: &logs}, nil))),
+		router.WithPanicReporter(func(r *http.Request, err *router.PanicError) {
+			reported <- err
+		}),
+	)
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			next(w, r.WithContext(context.WithValue(r.Context(), asyncContextKey{}, "acme")))
+		}
+	})
+
+	var handlerReturned bool
+	results := make(chan string, 1)
+	r.POST("/reports", func(w http.ResponseWriter, r *http.Request) {
+		router.Async(r, func(ctx context.Context) error {
+			<-release
+			results <- fmt.Sprintf("%v %v %v", handlerReturned, ctx.Value(asyncContextKey{}), ctx.Err())
+			return nil
+		})
+		w.WriteHeader(http.StatusAccepted)
+		handlerReturned = true
+	})
+	r.POST("/failing", func(w http.ResponseWriter, r *http.Request) {
+		router.Async(r, func(ctx context.Context) error {
+			return errors.New("report generation failed")
+		})
+		router.Async(r, func(ctx context.Context) error {
+			panic("report generation panicked")
+		})
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/reports/", nil).WithContext(ctx))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d", http.StatusAccepted, rr.Code)
+	}
+
+	// The work is still running, so draining times out
+	cancel()
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelTimeout()
+	if err := r.Drain(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected draining to time out, got %v", err)
+	}
+
+	// The work runs after the handler, with the values of the request but without its cancellation
+	close(release)
+	if result := <-results; result != "true acme <nil>" {
+		t.Errorf("Expected the work to run after the handler with the request values, got %q", result)
+	}
+	if err := r.Drain(context.Background()); err != nil {
+		t.Errorf("Expected the work to be drained, got %v", err)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/failing/", nil))
+	if err := r.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if panicErr := <-reported; panicErr.Value != "report generation panicked" {
+		t.Errorf("Expected the panic to be reported, got %v", panicErr.Value)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, expected := range []string{"report generation failed", "report generation panicked"} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("Expected %q to be logged, got %q", expected, logs.String())
+		}
+	}
+}
+
+// syncWriter guards a writer that is written from several goroutines
+type syncWriter struct {
+	mutex *sync.Mutex
+	w     *bytes.Buffer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.w.Write(p)
+}
@@ -0,0 +1,28 @@
+// Package auth holds the identity of the logged in user of a request, it's set by login flows like the oidc package.
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// Identity is a logged in user.
+type Identity struct {
+	Subject string         `json:"sub"`
+	Email   string         `json:"email,omitempty"`
+	Name    string         `json:"name,omitempty"`
+	Claims  map[string]any `json:"claims,omitempty"`
+}
+
+type identityContextKey struct{}
+
+// WithUser returns the request with the logged in user.
+func WithUser(r *http.Request, identity *Identity) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), identityContextKey{}, identity))
+}
+
+// User returns the logged in user of the request, or nil when the user isn't logged in.
+func User(r *http.Request) *Identity {
+	identity, _ := r.Context().Value(identityContextKey{}).(*Identity)
+	return identity
+}
@@ -0,0 +1,305 @@
+// Package introspect authenticates requests with opaque bearer tokens, which are verified by the introspection
+// endpoint of the authorization server (RFC 7662). The scopes of the token become the permissions that authz checks
+// against Route.Require.
+package introspect
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/authz"
+)
+
+var (
+	// ErrInactiveToken is passed to the error handler when the authorization server doesn't know the token, or it was
+	// revoked or has expired
+	ErrInactiveToken = errors.New("introspect: inactive token")
+	// ErrIntrospection is passed to the error handler when the introspection endpoint can't be reached or fails
+	ErrIntrospection = errors.New("introspect: introspection failed")
+)
+
+// maxResponseSize is the largest introspection response that is decoded
+const maxResponseSize = 1 << 20
+
+type Config struct {
+	// Endpoint is the URL of the introspection endpoint, this is required
+	Endpoint string
+	// ClientID and ClientSecret authenticate the router at the endpoint with HTTP basic authentication
+	ClientID     string
+	ClientSecret string
+	// Scopes maps a scope to the permissions it grants, scopes that aren't in the map grant the permission with the
+	// name of the scope
+	Scopes map[string][]string
+	// CacheTTL is how long the result of an introspection is reused, defaults to 1 minute. Tokens are never cached
+	// beyond their expiry, so a revoked token can be used for at most the CacheTTL
+	CacheTTL time.Duration
+	// MaxCacheEntries limits the number of cached active tokens, defaults to 10000. The least recently used token is
+	// removed when the cache is full
+	MaxCacheEntries int
+	// MaxInactiveCacheEntries limits the number of cached inactive tokens, defaults to 1000. They have their own
+	// cache, so requests with random tokens don't push out the active ones
+	MaxInactiveCacheEntries int
+	// HTTPClient is used to call the endpoint, defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Token is the principal of requests with an active token. It implements authz.PermissionHolder.
+type Token struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+
+	permissions []string
+}
+
+// Scopes returns the scopes of the token.
+func (t *Token) Scopes() []string {
+	return strings.Fields(t.Scope)
+}
+
+// Permissions returns the permissions the scopes of the token grant.
+func (t *Token) Permissions() []string {
+	return t.permissions
+}
+
+type cacheEntry struct {
+	key     [sha256.Size]byte
+	token   *Token
+	expires time.Time
+}
+
+// tokenCache is a LRU cache of introspection results
+type tokenCache struct {
+	max     int
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List
+}
+
+func newTokenCache(max int) *tokenCache {
+	return &tokenCache{max: max, entries: map[[sha256.Size]byte]*list.Element{}, order: list.New()}
+}
+
+// get returns the entry unless it has expired
+func (c *tokenCache) get(key [sha256.Size]byte, now time.Time) (cacheEntry, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	entry := element.Value.(cacheEntry)
+	if !now.Before(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return cacheEntry{}, false
+	}
+	c.order.MoveToFront(element)
+	return entry, true
+}
+
+// put adds the entry, the least recently used entry is removed when the cache is full
+func (c *tokenCache) put(entry cacheEntry) {
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	if c.order.Len() >= c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(cacheEntry).key)
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+}
+
+// call is an introspection in flight, concurrent requests with the same token wait for it
+type call struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+type Introspector struct {
+	config Config
+
+	mutex    sync.Mutex
+	active   *tokenCache
+	inactive *tokenCache
+	calls    map[[sha256.Size]byte]*call
+}
+
+// New returns an Introspector for the endpoint.
+func New(config Config) (*Introspector, error) {
+	if u, err := url.Parse(config.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("introspect: invalid endpoint %q", config.Endpoint)
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = time.Minute
+	}
+	if config.MaxCacheEntries <= 0 {
+		config.MaxCacheEntries = 10000
+	}
+	if config.MaxInactiveCacheEntries <= 0 {
+		config.MaxInactiveCacheEntries = 1000
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &Introspector{
+		config:   config,
+		active:   newTokenCache(config.MaxCacheEntries),
+		inactive: newTokenCache(config.MaxInactiveCacheEntries),
+		calls:    map[[sha256.Size]byte]*call{},
+	}, nil
+}
+
+// Middleware sets the Token of requests with a bearer token as the principal, for authz.Enforce. Requests without a
+// token pass without a principal, so Enforce can respond with a 401 when the route requires permissions. Inactive
+// tokens get a 401 and failed introspections a 503, both through the error handler of the router.
+func (i *Introspector) Middleware() router.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			raw, ok := bearer(r)
+			if !ok {
+				next(w, r)
+				return
+			}
+			token, err := i.Introspect(r.Context(), raw)
+			switch {
+			case errors.Is(err, ErrInactiveToken):
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				router.Error(w, r, http.StatusUnauthorized, err)
+				return
+			case err != nil:
+				router.Error(w, r, http.StatusServiceUnavailable, err)
+				return
+			}
+			next(w, authz.WithPrincipal(r, token))
+		}
+	}
+}
+
+// Introspect returns the active token, or ErrInactiveToken. Results are cached, inactive tokens as well, so invalid
+// tokens don't reach the authorization server on every request. Concurrent requests with the same token share one
+// introspection.
+func (i *Introspector) Introspect(ctx context.Context, raw string) (*Token, error) {
+	key := sha256.Sum256([]byte(raw))
+	now := time.Now()
+	i.mutex.Lock()
+	entry, ok := i.active.get(key, now)
+	if !ok {
+		entry, ok = i.inactive.get(key, now)
+	}
+	if ok {
+		i.mutex.Unlock()
+		return checkToken(entry.token, now)
+	}
+	c, ok := i.calls[key]
+	if !ok {
+		c = &call{done: make(chan struct{})}
+		i.calls[key] = c
+		// The introspection isn't canceled with the request that started it, as other requests wait for it as well
+		go i.call(context.WithoutCancel(ctx), key, raw, c)
+	}
+	i.mutex.Unlock()
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrIntrospection, ctx.Err())
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return checkToken(c.token, time.Now())
+}
+
+// call introspects the token, caches the result and wakes up the requests waiting for it
+func (i *Introspector) call(ctx context.Context, key [sha256.Size]byte, raw string, c *call) {
+	c.token, c.err = i.introspect(ctx, raw)
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	delete(i.calls, key)
+	close(c.done)
+	if c.err != nil {
+		return
+	}
+	entry := cacheEntry{key: key, token: c.token, expires: time.Now().Add(i.config.CacheTTL)}
+	if exp := time.Unix(c.token.ExpiresAt, 0); c.token.ExpiresAt > 0 && exp.Before(entry.expires) {
+		entry.expires = exp
+	}
+	if c.token.Active {
+		i.active.put(entry)
+	} else {
+		i.inactive.put(entry)
+	}
+}
+
+// checkToken returns ErrInactiveToken when the token isn't active, has expired or isn't valid yet
+func checkToken(token *Token, now time.Time) (*Token, error) {
+	switch {
+	case !token.Active,
+		token.ExpiresAt > 0 && !now.Before(time.Unix(token.ExpiresAt, 0)),
+		token.NotBefore > 0 && now.Before(time.Unix(token.NotBefore, 0)):
+		return nil, ErrInactiveToken
+	}
+	return token, nil
+}
+
+func (i *Introspector) introspect(ctx context.Context, raw string) (*Token, error) {
+	form := url.Values{"token": {raw}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.config.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIntrospection, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.config.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.config.ClientID), url.QueryEscape(i.config.ClientSecret))
+	}
+	res, err := i.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIntrospection, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrIntrospection, res.StatusCode)
+	}
+	token := &Token{}
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(token); err != nil {
+		return nil, fmt.Errorf("%w: invalid response: %w", ErrIntrospection, err)
+	}
+	for _, scope := range token.Scopes() {
+		if permissions, ok := i.config.Scopes[scope]; ok {
+			token.permissions = append(token.permissions, permissions...)
+		} else {
+			token.permissions = append(token.permissions, scope)
+		}
+	}
+	return token, nil
+}
+
+func bearer(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,228 @@
+package introspect_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/auth/introspect"
+	"github.com/gogo-framework/router/authz"
+)
+
+// authorizationServer introspects the tokens in the map, other tokens are inactive
+func authorizationServer(t *testing.T, tokens map[string]map[string]any, calls *atomic.Int64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if id, secret, _ := r.BasicAuth(); id != "api" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		token, ok := tokens[r.PostFormValue("token")]
+		if !ok {
+			token = map[string]any{"active": false}
+		}
+		json.NewEncoder(w).Encode(token)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMiddleware(t *testing.T) {
+	var calls atomic.Int64
+	server := authorizationServer(t, map[string]map[string]any{
+		"reader":  {"active": true, "scope": "users.read", "sub": "alice"},
+		"writer":  {"active": true, "scope": "users.read users.write", "sub": "bob"},
+		"expired": {"active": true, "scope": "users.read", "exp": time.Now().Add(-time.Minute).Unix()},
+		"revoked": {"active": false},
+		"future":  {"active": true, "scope": "users.read", "nbf": time.Now().Add(time.Hour).Unix()},
+	}, &calls)
+	introspector, err := introspect.New(introspect.Config{
+		Endpoint:     server.URL,
+		ClientID:     "api",
+		ClientSecret: "secret",
+		Scopes:       map[string][]string{"users.write": {"users:write", "users:delete"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the introspector: %v", err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(introspector.Middleware(), authz.Enforce(authz.Permissions))
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(authz.Principal(r).(*introspect.Token).Subject))
+	}
+	r.GET("/public", func(w http.ResponseWriter, r *http.Request) {})
+	r.GET("/users", handler).Require("users.read")
+	r.DELETE("/users", handler).Require("users:delete")
+
+	// Define test cases
+	tests := []struct {
+		method       string
+		token        string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{http.MethodGet, "", "/public/", http.StatusOK, ""},
+		{http.MethodGet, "", "/users/", http.StatusUnauthorized, ""},
+		{http.MethodGet, "reader", "/users/", http.StatusOK, "alice"},
+		{http.MethodDelete, "reader", "/users/", http.StatusForbidden, ""},
+		{http.MethodDelete, "writer", "/users/", http.StatusOK, "bob"},
+		{http.MethodGet, "expired", "/users/", http.StatusUnauthorized, ""},
+		{http.MethodGet, "revoked", "/users/", http.StatusUnauthorized, ""},
+		{http.MethodGet, "future", "/users/", http.StatusUnauthorized, ""},
+		{http.MethodGet, "unknown", "/public/", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.expectedCode {
+			t.Errorf("%s %s with %q: expected status %d, got %d", tt.method, tt.path, tt.token, tt.expectedCode, w.Code)
+		}
+		if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+			t.Errorf("%s %s with %q: expected body %q, got %q", tt.method, tt.path, tt.token, tt.expectedBody, w.Body.String())
+		}
+	}
+
+	// The results are cached, the second round doesn't call the server
+	before := calls.Load()
+	for _, token := range []string{"reader", "writer", "revoked"} {
+		req := httptest.NewRequest(http.MethodGet, "/users/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls.Load() != before {
+		t.Errorf("Expected cached introspections, got %d calls", calls.Load()-before)
+	}
+}
+
+func TestIntrospectionFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	introspector, err := introspect.New(introspect.Config{Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create the introspector: %v", err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(introspector.Middleware())
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	if _, err := introspect.New(introspect.Config{Endpoint: "/introspect"}); err == nil {
+		t.Error("Expected an error for a relative endpoint")
+	}
+}
+
+func TestCache(t *testing.T) {
+	var calls atomic.Int64
+	server := authorizationServer(t, map[string]map[string]any{
+		"reader": {"active": true, "scope": "users.read", "sub": "alice"},
+	}, &calls)
+	introspector, err := introspect.New(introspect.Config{
+		Endpoint:                server.URL,
+		ClientID:                "api",
+		ClientSecret:            "secret",
+		MaxCacheEntries:         1,
+		MaxInactiveCacheEntries: 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the introspector: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := introspector.Introspect(ctx, "reader"); err != nil {
+		t.Fatalf("Expected an active token, got %v", err)
+	}
+	// Inactive tokens have their own cache, so they don't push out the active token
+	for i := range 10 {
+		if _, err := introspector.Introspect(ctx, fmt.Sprintf("random-%d", i)); !errors.Is(err, introspect.ErrInactiveToken) {
+			t.Fatalf("Expected ErrInactiveToken, got %v", err)
+		}
+	}
+	before := calls.Load()
+	if _, err := introspector.Introspect(ctx, "reader"); err != nil || calls.Load() != before {
+		t.Errorf("Expected the cached token, got %v and %d calls", err, calls.Load()-before)
+	}
+	if _, err := introspector.Introspect(ctx, "random-9"); !errors.Is(err, introspect.ErrInactiveToken) || calls.Load() != before {
+		t.Errorf("Expected the cached inactive token, got %v and %d calls", err, calls.Load()-before)
+	}
+}
+
+func TestConcurrentIntrospections(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		fmt.Fprint(w, `{"active": true, "sub": "alice"}`)
+	}))
+	defer server.Close()
+	introspector, err := introspect.New(introspect.Config{Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create the introspector: %v", err)
+	}
+
+	// Concurrent requests with the same token share one introspection
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := introspector.Introspect(context.Background(), "token")
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected an active token, got %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 introspection, got %d", calls.Load())
+	}
+}
+
+func TestLargeResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"active": true, "scope": "%s"}`, strings.Repeat("a ", 1<<20))
+	}))
+	defer server.Close()
+	introspector, err := introspect.New(introspect.Config{Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create the introspector: %v", err)
+	}
+	if _, err := introspector.Introspect(context.Background(), "token"); !errors.Is(err, introspect.ErrIntrospection) {
+		t.Errorf("Expected ErrIntrospection, got %v", err)
+	}
+}
@@ -0,0 +1,409 @@
+// Package oidc adds an OpenID Connect login flow to the router, for single sign-on in server rendered applications.
+// It uses the authorization code flow with PKCE, and stores the identity of the user in an encrypted session cookie.
+package oidc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/auth"
+	"github.com/gogo-framework/router/cookies"
+)
+
+var (
+	// ErrInvalidState is passed to the error handler when the callback doesn't belong to a login started by this browser
+	ErrInvalidState = errors.New("oidc: invalid state")
+	// ErrInvalidToken is passed to the error handler when the ID token of the provider can't be trusted
+	ErrInvalidToken = errors.New("oidc: invalid ID token")
+	// ErrCrossOrigin is passed to the error handler when a logout doesn't come from a page of the application
+	ErrCrossOrigin = errors.New("oidc: cross-origin request")
+)
+
+const flowCookieMaxAge = 10 * time.Minute
+
+type Config struct {
+	// Issuer is the URL of the provider, its configuration is discovered at /.well-known/openid-configuration
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the absolute URL of the callback route, e.g. "https://example.com/auth/callback"
+	RedirectURL string
+	// Scopes are requested in addition to "openid", defaults to "profile" and "email"
+	Scopes []string
+	// SessionKey encrypts the session cookie, it has to be 32 random bytes
+	SessionKey []byte
+	// PreviousSessionKeys are only used to read sessions, so the session key can be rotated without logging out users
+	PreviousSessionKeys [][]byte
+	// SessionName is the name of the session cookie, defaults to "session"
+	SessionName string
+	// SessionMaxAge is how long a user stays logged in, defaults to 24 hours
+	SessionMaxAge time.Duration
+	// AfterLogin is where users are sent after logging in when the login didn't have a return_to path, defaults to "/"
+	AfterLogin string
+	// AfterLogout is where users are sent after logging out when the provider doesn't support logging out,
+	// defaults to "/"
+	AfterLogout string
+	// HTTPClient is used to talk to the provider, defaults to http.DefaultClient
+	HTTPClient *http.Client
+	// AllowInsecureIssuer allows an issuer and token endpoint without https, for a local provider during development.
+	// The signature of ID tokens isn't checked, they're trusted because they come from the token endpoint over TLS
+	AllowInsecureIssuer bool
+}
+
+// discovery is the part of the provider configuration that is used
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+type Provider struct {
+	config    Config
+	discovery discovery
+	loginPath string
+	jar       *cookies.Jar
+	// origin is the scheme and host of the RedirectURL, the origin of the pages of the application
+	origin *url.URL
+}
+
+// New discovers the configuration of the provider.
+func New(ctx context.Context, config Config) (*Provider, error) {
+	jar, err := cookies.New(cookies.Config{
+		Keys:     append([][]byte{config.SessionKey}, config.PreviousSessionKeys...),
+		Insecure: !strings.HasPrefix(config.RedirectURL, "https://"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid session key: %w", err)
+	}
+	if config.Scopes == nil {
+		config.Scopes = []string{"profile", "email"}
+	}
+	if config.SessionName == "" {
+		config.SessionName = "session"
+	}
+	if config.SessionMaxAge <= 0 {
+		config.SessionMaxAge = 24 * time.Hour
+	}
+	if config.AfterLogin == "" {
+		config.AfterLogin = "/"
+	}
+	if config.AfterLogout == "" {
+		config.AfterLogout = "/"
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+
+	origin, err := url.Parse(config.RedirectURL)
+	if err != nil || origin.Host == "" {
+		return nil, fmt.Errorf("oidc: invalid redirect URL %q", config.RedirectURL)
+	}
+
+	p := &Provider{config: config, jar: jar, origin: origin}
+	issuer := strings.TrimSuffix(config.Issuer, "/")
+	if !config.AllowInsecureIssuer && !strings.HasPrefix(issuer, "https://") {
+		return nil, fmt.Errorf("oidc: the issuer %q has to use https", config.Issuer)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid issuer: %w", err)
+	}
+	res, err := config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: discovery failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: discovery failed with status %d", res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&p.discovery); err != nil {
+		return nil, fmt.Errorf("oidc: invalid discovery document: %w", err)
+	}
+	if strings.TrimSuffix(p.discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc: the discovery document is for issuer %q", p.discovery.Issuer)
+	}
+	if !config.AllowInsecureIssuer && !strings.HasPrefix(p.discovery.TokenEndpoint, "https://") {
+		return nil, fmt.Errorf("oidc: the token endpoint %q has to use https", p.discovery.TokenEndpoint)
+	}
+	return p, nil
+}
+
+// Mount registers the login, callback and logout routes below the prefix. Links to the login route can pass the
+// path to return to after logging in as return_to query parameter.
+func (p *Provider) Mount(r *router.Router, prefix string) *router.RouteGroup {
+	p.loginPath = strings.TrimSuffix(r.SanitizePath(prefix+"/login"), "{$}")
+	return r.Group(prefix, func(r *router.Router) {
+		r.GET("/login", p.Login)
+		r.GET("/callback", p.Callback)
+		r.POST("/logout", p.Logout)
+	})
+}
+
+// Middleware makes the identity of the session available through auth.User.
+func (p *Provider) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var session session
+		if p.readCookie(r, p.config.SessionName, &session) && time.Now().Before(session.Expires) {
+			r = auth.WithUser(r, &session.Identity)
+		}
+		next(w, r)
+	}
+}
+
+// RequireLogin sends users that aren't logged in to the login route, it has to run after Middleware.
+func (p *Provider) RequireLogin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if auth.User(r) == nil {
+			http.Redirect(w, r, p.loginPath+"?return_to="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// flow is stored in a short lived cookie between the login and the callback
+type flow struct {
+	State    string    `json:"state"`
+	Nonce    string    `json:"nonce"`
+	Verifier string    `json:"verifier"`
+	ReturnTo string    `json:"return_to"`
+	Expires  time.Time `json:"expires"`
+}
+
+type session struct {
+	Identity auth.Identity `json:"identity"`
+	Expires  time.Time     `json:"expires"`
+}
+
+// localPath reports whether the return_to value is a path on this host. Browsers drop tabs and newlines from URLs and
+// treat backslashes like slashes, so values with them are rejected as well, e.g. "/\t/evil.example" becomes
+// "//evil.example".
+func localPath(returnTo string) bool {
+	if strings.ContainsFunc(returnTo, func(r rune) bool { return unicode.IsControl(r) || r == '\\' }) {
+		return false
+	}
+	u, err := url.Parse(returnTo)
+	return err == nil && u.Scheme == "" && u.Host == "" && strings.HasPrefix(returnTo, "/") &&
+		strings.HasPrefix(u.Path, "/") && !strings.HasPrefix(u.Path, "//")
+}
+
+func (p *Provider) Login(w http.ResponseWriter, r *http.Request) {
+	f := flow{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: randomString(),
+		ReturnTo: p.config.AfterLogin,
+		Expires:  time.Now().Add(flowCookieMaxAge),
+	}
+	// Only local paths are allowed, to prevent open redirects
+	if returnTo := r.URL.Query().Get("return_to"); localPath(returnTo) {
+		f.ReturnTo = returnTo
+	}
+	if err := p.writeCookie(w, p.flowCookieName(), f, flowCookieMaxAge); err != nil {
+		router.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(f.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(append([]string{"openid"}, p.config.Scopes...), " ")},
+		"state":                 {f.State},
+		"nonce":                 {f.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	http.Redirect(w, r, p.discovery.AuthorizationEndpoint+"?"+query.Encode(), http.StatusFound)
+}
+
+func (p *Provider) Callback(w http.ResponseWriter, r *http.Request) {
+	var f flow
+	if !p.readCookie(r, p.flowCookieName(), &f) || time.Now().After(f.Expires) ||
+		!hmac.Equal([]byte(f.State), []byte(r.URL.Query().Get("state"))) {
+		router.Error(w, r, http.StatusBadRequest, ErrInvalidState)
+		return
+	}
+	p.deleteCookie(w, p.flowCookieName())
+
+	if providerError := r.URL.Query().Get("error"); providerError != "" {
+		router.Error(w, r, http.StatusUnauthorized, fmt.Errorf("oidc: login failed: %s", providerError))
+		return
+	}
+
+	identity, err := p.exchange(r.Context(), r.URL.Query().Get("code"), f)
+	if err != nil {
+		router.Error(w, r, http.StatusUnauthorized, err)
+		return
+	}
+	err = p.writeCookie(w, p.config.SessionName, session{Identity: *identity, Expires: time.Now().Add(p.config.SessionMaxAge)},
+		p.config.SessionMaxAge)
+	if err != nil {
+		// The identity has too many claims to fit in a cookie
+		router.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	http.Redirect(w, r, f.ReturnTo, http.StatusFound)
+}
+
+// Logout ends the session, and at the provider when it supports it. It only accepts POST requests from pages of the
+// application, so other sites can't log users out with a link or a form.
+func (p *Provider) Logout(w http.ResponseWriter, r *http.Request) {
+	if !p.sameOrigin(r) {
+		router.Error(w, r, http.StatusForbidden, ErrCrossOrigin)
+		return
+	}
+	p.deleteCookie(w, p.config.SessionName)
+	if p.discovery.EndSessionEndpoint == "" {
+		http.Redirect(w, r, p.config.AfterLogout, http.StatusSeeOther)
+		return
+	}
+	query := url.Values{"client_id": {p.config.ClientID}}
+	http.Redirect(w, r, p.discovery.EndSessionEndpoint+"?"+query.Encode(), http.StatusSeeOther)
+}
+
+// sameOrigin reports whether the request comes from a page of the application. Browsers send Sec-Fetch-Site, older
+// browsers the Origin or Referer, which is compared to the origin of the RedirectURL
+func (p *Provider) sameOrigin(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "same-origin"
+	}
+	source := r.Header.Get("Origin")
+	if source == "" || source == "null" {
+		source = r.Header.Get("Referer")
+	}
+	u, err := url.Parse(source)
+	return err == nil && u.Scheme == p.origin.Scheme && u.Host == p.origin.Host
+}
+
+// exchange exchanges the code for tokens and returns the identity of the ID token. The token is received directly
+// from the token endpoint over TLS, which New enforces, and that validates the issuer in place of its signature as
+// allowed by OpenID Connect Core 3.1.3.7.
+func (p *Provider) exchange(ctx context.Context, code string, f flow) (*auth.Identity, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {f.Verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	res, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: token request failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("oidc: token request failed with status %d: %s", res.StatusCode, body)
+	}
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("oidc: invalid token response: %w", err)
+	}
+	return p.verify(tokens.IDToken, f.Nonce)
+}
+
+func (p *Provider) verify(idToken string, nonce string) (*auth.Identity, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	issuer, _ := claims["iss"].(string)
+	if strings.TrimSuffix(issuer, "/") != strings.TrimSuffix(p.discovery.Issuer, "/") {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, issuer)
+	}
+	if !hasAudience(claims["aud"], p.config.ClientID) {
+		return nil, fmt.Errorf("%w: not issued for this client", ErrInvalidToken)
+	}
+	if exp, ok := claims["exp"].(float64); !ok || time.Now().After(time.Unix(int64(exp), 0)) {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if tokenNonce, _ := claims["nonce"].(string); !hmac.Equal([]byte(tokenNonce), []byte(nonce)) {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrInvalidToken)
+	}
+
+	identity := &auth.Identity{Claims: claims}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+	return identity, nil
+}
+
+// hasAudience reports whether the aud claim, a string or an array of strings, contains the client
+func hasAudience(aud any, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []any:
+		return slices.Contains(aud, any(clientID))
+	}
+	return false
+}
+
+func (p *Provider) flowCookieName() string {
+	return p.config.SessionName + "_oidc"
+}
+
+// writeCookie stores the value as encrypted JSON
+func (p *Provider) writeCookie(w http.ResponseWriter, name string, value any, maxAge time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	// Lax, the default of the jar, so the cookies are sent when the provider redirects back to the callback
+	return p.jar.SetEncrypted(w, &http.Cookie{Name: name, Value: string(data), MaxAge: int(maxAge.Seconds())})
+}
+
+func (p *Provider) readCookie(r *http.Request, name string, value any) bool {
+	data, err := p.jar.Encrypted(r, name)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal([]byte(data), value) == nil
+}
+
+func (p *Provider) deleteCookie(w http.ResponseWriter, name string) {
+	p.jar.Delete(w, &http.Cookie{Name: name})
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,280 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/auth"
+	"github.com/gogo-framework/router/auth/oidc"
+)
+
+// fakeProvider is an OpenID Connect provider that logs in every user as "alice"
+type fakeProvider struct {
+	*httptest.Server
+	challenge string
+	nonce     string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	p := &fakeProvider{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"end_session_endpoint":   p.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("code") != "valid-code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		claims, _ := json.Marshal(map[string]any{
+			"iss":   p.URL,
+			"aud":   "client",
+			"sub":   "alice",
+			"email": "alice@example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": p.nonce,
+		})
+		json.NewEncoder(w).Encode(map[string]string{
+			"id_token": "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".signature",
+		})
+	})
+	p.Server = httptest.NewTLSServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func TestLoginFlow(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider, err := oidc.New(context.Background(), oidc.Config{
+		Issuer:       fake.URL,
+		HTTPClient:   fake.Client(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://app.example.com/auth/callback/",
+		SessionKey:   []byte(strings.Repeat("k", 32)),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the provider: %v", err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(provider.Middleware)
+	provider.Mount(r, "/auth")
+	r.GET("/profile", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(auth.User(r).Email))
+	}).Use(provider.RequireLogin)
+
+	// Users that aren't logged in are sent to the login
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/profile/", nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/auth/login/?return_to=%2Fprofile%2F" {
+		t.Fatalf("Expected a redirect to the login, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+
+	// The login redirects to the provider with PKCE
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/login/?return_to=%2Fprofile%2F", nil))
+	location, _ := url.Parse(w.Header().Get("Location"))
+	query := location.Query()
+	if !strings.HasPrefix(location.String(), fake.URL+"/authorize") || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("Expected a redirect to the provider with PKCE, got %s", location)
+	}
+	fake.challenge, fake.nonce = query.Get("code_challenge"), query.Get("nonce")
+	flowCookies := w.Result().Cookies()
+
+	// A callback with another state is rejected
+	req := httptest.NewRequest(http.MethodGet, "/auth/callback/?code=valid-code&state=forged", nil)
+	for _, cookie := range flowCookies {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a forged state, got %d", w.Code)
+	}
+
+	// The callback exchanges the code and starts the session
+	req = httptest.NewRequest(http.MethodGet, "/auth/callback/?code=valid-code&state="+query.Get("state"), nil)
+	for _, cookie := range flowCookies {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/profile/" {
+		t.Fatalf("Expected a redirect to /profile/, got %d %s: %s", w.Code, w.Header().Get("Location"), w.Body.String())
+	}
+	var sessionCookie *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "session" {
+			sessionCookie = cookie
+		}
+	}
+	if sessionCookie == nil || !sessionCookie.HttpOnly {
+		t.Fatal("Expected an HttpOnly session cookie")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/profile/", nil)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "alice@example.com" {
+		t.Errorf("Expected the logged in user, got %d %q", w.Code, w.Body.String())
+	}
+
+	// A tampered session is ignored
+	tampered := *sessionCookie
+	tampered.Value = "A" + sessionCookie.Value[1:]
+	if tampered.Value == sessionCookie.Value {
+		tampered.Value = "B" + sessionCookie.Value[1:]
+	}
+	req = httptest.NewRequest(http.MethodGet, "/profile/", nil)
+	req.AddCookie(&tampered)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusFound {
+		t.Errorf("Expected a tampered session to be redirected to the login, got %d", w.Code)
+	}
+
+	// Sessions of the previous key are still valid after rotating the key
+	rotated, err := oidc.New(context.Background(), oidc.Config{
+		Issuer:              fake.URL,
+		HTTPClient:          fake.Client(),
+		ClientID:            "client",
+		RedirectURL:         "http://app.example.com/auth/callback/",
+		SessionKey:          []byte(strings.Repeat("n", 32)),
+		PreviousSessionKeys: [][]byte{[]byte(strings.Repeat("k", 32))},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the provider: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(sessionCookie)
+	var user *auth.Identity
+	rotated.Middleware(func(w http.ResponseWriter, r *http.Request) { user = auth.User(r) })(httptest.NewRecorder(), req)
+	if user == nil || user.Subject != "alice" {
+		t.Errorf("Expected the session to survive the key rotation, got %+v", user)
+	}
+
+	// Logging out ends the session at the provider, only for requests of pages of the application
+	logoutTests := []struct {
+		header       string
+		value        string
+		expectedCode int
+	}{
+		{"", "", http.StatusForbidden},
+		{"Origin", "http://evil.example.com", http.StatusForbidden},
+		{"Sec-Fetch-Site", "cross-site", http.StatusForbidden},
+		{"Referer", "http://app.example.com/profile/", http.StatusSeeOther},
+		{"Sec-Fetch-Site", "same-origin", http.StatusSeeOther},
+		{"Origin", "http://app.example.com", http.StatusSeeOther},
+	}
+	for _, tt := range logoutTests {
+		req := httptest.NewRequest(http.MethodPost, "/auth/logout/", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.expectedCode {
+			t.Errorf("Logout with %s %q: expected status %d, got %d", tt.header, tt.value, tt.expectedCode, w.Code)
+		}
+	}
+	if !strings.HasPrefix(w.Header().Get("Location"), fake.URL+"/logout") {
+		t.Errorf("Expected a redirect to the provider logout, got %s", w.Header().Get("Location"))
+	}
+}
+
+func TestInsecureIssuer(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	// Define test cases
+	tests := []struct {
+		issuer string
+		allow  bool
+		err    string
+	}{
+		{server.URL, false, "has to use https"},
+		// The discovery fails with the insecure issuer allowed
+		{server.URL, true, "discovery failed"},
+	}
+
+	for _, tt := range tests {
+		_, err := oidc.New(context.Background(), oidc.Config{
+			Issuer:              tt.issuer,
+			ClientID:            "client",
+			RedirectURL:         "http://localhost/auth/callback/",
+			SessionKey:          []byte(strings.Repeat("k", 32)),
+			AllowInsecureIssuer: tt.allow,
+		})
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s with insecure issuers allowed %v: expected an error containing %q, got %v", tt.issuer, tt.allow, tt.err, err)
+		}
+	}
+}
+
+func TestLoginOpenRedirect(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider, err := oidc.New(context.Background(), oidc.Config{
+		Issuer:      fake.URL,
+		HTTPClient:  fake.Client(),
+		ClientID:    "client",
+		RedirectURL: "http://app.example.com/auth/callback/",
+		SessionKey:  []byte(strings.Repeat("k", 32)),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the provider: %v", err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	provider.Mount(r, "/auth")
+
+	// Define test cases
+	tests := []struct {
+		returnTo string
+		expected string
+	}{
+		{"/profile/?tab=1", "/profile/?tab=1"},
+		{"//evil.example.com", "/"},
+		{"/\\evil.example.com", "/"},
+		{"/\t/evil.example.com", "/"},
+		{"/\n/evil.example.com", "/"},
+		{"\\\\evil.example.com", "/"},
+		{"https://evil.example.com/", "/"},
+		{"profile", "/"},
+	}
+
+	for _, tc := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/login/?return_to="+url.QueryEscape(tc.returnTo), nil))
+		location, _ := url.Parse(w.Header().Get("Location"))
+		fake.challenge, fake.nonce = location.Query().Get("code_challenge"), location.Query().Get("nonce")
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/callback/?code=valid-code&state="+location.Query().Get("state"), nil)
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Header().Get("Location") != tc.expected {
+			t.Errorf("%q: expected a redirect to %s, got %s", tc.returnTo, tc.expected, w.Header().Get("Location"))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package authz enforces the permissions routes require with Route.Require, for the principal that was set by the
+// authentication middleware.
+package authz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gogo-framework/router"
+)
+
+var (
+	// ErrUnauthenticated is passed to the error handler when a route requires permissions and there is no principal
+	ErrUnauthenticated = errors.New("authz: unauthenticated")
+	// ErrForbidden is passed to the error handler when the principal doesn't have the required permissions
+	ErrForbidden = errors.New("authz: forbidden")
+)
+
+// PolicyEnforcer decides if the principal may use a route that requires the permissions. An error results in a 500
+// response.
+type PolicyEnforcer interface {
+	Allowed(r *http.Request, principal any, permissions []string) (bool, error)
+}
+
+// PolicyEnforcerFunc allows a function to be used as a PolicyEnforcer.
+type PolicyEnforcerFunc func(r *http.Request, principal any, permissions []string) (bool, error)
+
+func (f PolicyEnforcerFunc) Allowed(r *http.Request, principal any, permissions []string) (bool, error) {
+	return f(r, principal, permissions)
+}
+
+// PermissionHolder is a principal that knows its permissions, it's used by the Permissions enforcer.
+type PermissionHolder interface {
+	Permissions() []string
+}
+
+// Permissions is a PolicyEnforcer that requires a PermissionHolder principal to have all permissions of the route.
+// A permission like "users:*" grants every permission starting with "users:", and "*" grants everything.
+var Permissions PolicyEnforcer = PolicyEnforcerFunc(func(r *http.Request, principal any, required []string) (bool, error) {
+	holder, ok := principal.(PermissionHolder)
+	if !ok {
+		return false, nil
+	}
+	granted := holder.Permissions()
+	for _, permission := range required {
+		if !grants(granted, permission) {
+			return false, nil
+		}
+	}
+	return true, nil
+})
+
+func grants(granted []string, permission string) bool {
+	for _, g := range granted {
+		if g == permission || g == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(g, "*"); ok && strings.HasPrefix(permission, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal returns the request with the authenticated principal, authentication middlewares call it before
+// calling the next handler.
+func WithPrincipal(r *http.Request, principal any) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), principalContextKey{}, principal))
+}
+
+// Principal returns the principal of the request, or nil when it isn't authenticated.
+func Principal(r *http.Request) any {
+	return r.Context().Value(principalContextKey{})
+}
+
+// Enforce checks the permissions of the matched route, including those of its group. Requests without a principal get a 401 response and
+// principals without the permissions a 403, both through the error handler of the router. Routes that don't
+// require permissions are always allowed. It has to run after the authentication middleware.
+func Enforce(enforcer PolicyEnforcer) router.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			route := router.MatchedRoute(r)
+			if route == nil {
+				next(w, r)
+				return
+			}
+			permissions := route.Permissions()
+			if len(permissions) == 0 {
+				next(w, r)
+				return
+			}
+
+			principal := Principal(r)
+			if principal == nil {
+				router.Error(w, r, http.StatusUnauthorized, ErrUnauthenticated)
+				return
+			}
+			allowed, err := enforcer.Allowed(r, principal, permissions)
+			if err != nil {
+				router.Error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			if !allowed {
+				router.Error(w, r, http.StatusForbidden, ErrForbidden)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package authz_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/authz"
+)
+
+type user struct {
+	permissions []string
+}
+
+func (u *user) Permissions() []string {
+	return u.permissions
+}
+
+// authenticate sets a principal with the permissions of the X-Permissions header
+func authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if permissions, ok := r.Header["X-Permissions"]; ok {
+			r = authz.WithPrincipal(r, &user{permissions: permissions})
+		}
+		next(w, r)
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(authenticate, authz.Enforce(authz.Permissions))
+
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/public", handler)
+	r.GET("/users", handler).Require("users:read")
+	r.Group("/admin", func(r *router.Router) {
+		r.GET("/settings", handler)
+		r.PUT("/settings", handler).Require("settings:write")
+		r.DELETE("/settings", handler).Require()
+	}).Require("admin")
+
+	// Define test cases
+	tests := []struct {
+		method       string
+		path         string
+		permissions  []string
+		expectedCode int
+	}{
+		{http.MethodGet, "/public/", nil, http.StatusOK},
+		{http.MethodGet, "/users/", nil, http.StatusUnauthorized},
+		{http.MethodGet, "/users/", []string{"orders:read"}, http.StatusForbidden},
+		{http.MethodGet, "/users/", []string{"users:read"}, http.StatusOK},
+		{http.MethodGet, "/users/", []string{"users:*"}, http.StatusOK},
+		{http.MethodGet, "/users/", []string{"*"}, http.StatusOK},
+		{http.MethodGet, "/admin/settings/", []string{"users:read"}, http.StatusForbidden},
+		{http.MethodGet, "/admin/settings/", []string{"admin"}, http.StatusOK},
+		{http.MethodPut, "/admin/settings/", []string{"admin"}, http.StatusForbidden},
+		{http.MethodPut, "/admin/settings/", []string{"settings:write"}, http.StatusForbidden},
+		{http.MethodPut, "/admin/settings/", []string{"admin", "settings:write"}, http.StatusOK},
+		{http.MethodDelete, "/admin/settings/", []string{"users:read"}, http.StatusForbidden},
+		{http.MethodDelete, "/admin/settings/", []string{"admin"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.permissions != nil {
+			req.Header["X-Permissions"] = tt.permissions
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.expectedCode {
+			t.Errorf("%s %s with %v: expected status %d, got %d", tt.method, tt.path, tt.permissions, tt.expectedCode, w.Code)
+		}
+	}
+}
+
+func TestEnforceError(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	var handled error
+	r.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, status int, err error) {
+		handled = err
+		w.WriteHeader(status)
+	})
+	failing := authz.PolicyEnforcerFunc(func(r *http.Request, principal any, permissions []string) (bool, error) {
+		return false, errors.New("policy store unavailable")
+	})
+	r.Use(authenticate, authz.Enforce(failing))
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {}).Require("users:read")
+
+	req := httptest.NewRequest(http.MethodGet, "/users/", nil)
+	req.Header.Set("X-Permissions", "users:read")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError || handled == nil {
+		t.Errorf("Expected status 500 through the error handler, got %d with %v", w.Code, handled)
+	}
+}
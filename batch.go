@@ -0,0 +1,128 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// maxBatchRequests is the largest number of requests in a batch
+	maxBatchRequests = 100
+	// maxBatchSize is the largest body of a batch request
+	maxBatchSize = 10 << 20
+	// maxBatchedResponseSize is the largest body of the response to a request in a batch
+	maxBatchedResponseSize = 1 << 20
+)
+
+// BatchRequest is a request in a batch. The body is sent as JSON, the headers are added to the headers of the batch
+// request, e.g. to its Authorization header.
+type BatchRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchResponse is the response to a request in a batch. JSON bodies are embedded as they are, other bodies as a
+// string.
+type BatchResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+type batchContextKey struct{}
+
+// Batch registers a POST route that handles a JSON array of requests, so clients can send several requests at once,
+// like the batch APIs of OData or Google. The requests are dispatched through the router one after another, with the
+// middlewares of their routes, and get the headers of the batch request, so they're authenticated like it. The
+// response is an array with the status, headers and body of every request, in the same order. A batch has at most
+// 100 requests and 10MB, the response to a request in it at most 1MB, and batches can't be nested.
+//
+//	r.Batch("/batch")
+//
+//	POST /batch
+//	[{"method": "GET", "path": "/users/1"}, {"method": "POST", "path": "/orders", "body": {"item": 42}}]
+func (r *Router) Batch(path string) *Route {
+	return r.POST(path, func(w http.ResponseWriter, req *http.Request) {
+		if req.Context().Value(batchContextKey{}) != nil {
+			Error(w, req, http.StatusBadRequest, errors.New("batches can't be nested"))
+			return
+		}
+		var requests []BatchRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBatchSize)).Decode(&requests); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				Error(w, req, http.StatusRequestEntityTooLarge, nil)
+				return
+			}
+			Error(w, req, http.StatusBadRequest, fmt.Errorf("invalid batch: %w", err))
+			return
+		}
+		if len(requests) > maxBatchRequests {
+			Error(w, req, http.StatusBadRequest, fmt.Errorf("a batch has at most %d requests", maxBatchRequests))
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), batchContextKey{}, true)
+		responses := make([]BatchResponse, len(requests))
+		for i, batched := range requests {
+			responses[i] = r.serveBatched(ctx, req, batched)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses)
+	})
+}
+
+// serveBatched dispatches a request of the batch through the router
+func (r *Router) serveBatched(ctx context.Context, batch *http.Request, batched BatchRequest) BatchResponse {
+	if batched.Method == "" {
+		batched.Method = http.MethodGet
+	}
+	if !strings.HasPrefix(batched.Path, "/") {
+		return batchError(http.StatusBadRequest, fmt.Sprintf("invalid path %q", batched.Path))
+	}
+	req, err := http.NewRequestWithContext(ctx, batched.Method, batched.Path, bytes.NewReader(batched.Body))
+	if err != nil {
+		return batchError(http.StatusBadRequest, err.Error())
+	}
+	// The requests arrived over the connection of the batch, e.g. for client certificates and secure cookies
+	req.Host = batch.Host
+	req.RemoteAddr = batch.RemoteAddr
+	req.TLS = batch.TLS
+	req.Proto, req.ProtoMajor, req.ProtoMinor = batch.Proto, batch.ProtoMajor, batch.ProtoMinor
+	req.Header = batch.Header.Clone()
+	req.Header.Del("Content-Length")
+	req.Header.Del("Content-Type")
+	if len(batched.Body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range batched.Headers {
+		req.Header.Set(name, value)
+	}
+
+	rec := &responseRecorder{header: http.Header{}, maxBytes: maxBatchedResponseSize}
+	r.ServeHTTP(rec, req)
+	if rec.truncated {
+		return batchError(http.StatusInternalServerError, fmt.Sprintf("the response is larger than %d bytes", maxBatchedResponseSize))
+	}
+	response := BatchResponse{Status: rec.Status(), Headers: make(map[string]string, len(rec.header))}
+	for name, values := range rec.header {
+		response.Headers[name] = strings.Join(values, ", ")
+	}
+	response.Body = rec.body.Bytes()
+	if len(response.Body) > 0 && !json.Valid(response.Body) {
+		response.Body, _ = json.Marshal(rec.body.String())
+	}
+	return response
+}
+
+func batchError(status int, message string) BatchResponse {
+	body, _ := json.Marshal(message)
+	return BatchResponse{Status: status, Body: body}
+}
@@ -0,0 +1,140 @@
+package router_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestBatch(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				router.Error(w, r, http.StatusUnauthorized, nil)
+				return
+			}
+			next(w, r)
+		}
+	})
+	r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"`+r.PathValue("id")+`","locale":"`+r.Header.Get("Accept-Language")+`"}`)
+	})
+	r.POST("/orders", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+	r.GET("/export", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("x", 2<<20))
+	})
+	r.GET("/tls", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(r.TLS != nil)
+	})
+	r.Batch("/batch")
+
+	// Define test cases
+	tests := []struct {
+		name      string
+		auth      string
+		body      string
+		status    int
+		responses string
+	}{
+		{
+			name: "requests",
+			auth: "Bearer token",
+			body: `[
+				{"path": "/users/1/", "headers": {"Accept-Language": "de"}},
+				{"method": "POST", "path": "/orders/", "body": {"item": 42}},
+				{"path": "/missing/"},
+				{"method": "POST", "path": "/batch/", "body": []},
+				{"path": "users"},
+				{"path": "/export/"},
+				{"path": "/tls/"}
+			]`,
+			status: http.StatusOK,
+			responses: `[
+				{"status": 200, "body": {"id": "1", "locale": "de"}},
+				{"status": 201, "body": {"item": 42}},
+				{"status": 404, "body": "404 page not found\n"},
+				{"status": 400, "body": "batches can't be nested\n"},
+				{"status": 400, "body": "invalid path \"users\""},
+				{"status": 500, "body": "the response is larger than 1048576 bytes"},
+				{"status": 200, "body": true}
+			]`,
+		},
+		{
+			name:      "unauthenticated",
+			body:      `[{"path": "/users/1/"}]`,
+			status:    http.StatusUnauthorized,
+			responses: "",
+		},
+		{
+			name:      "invalid",
+			auth:      "Bearer token",
+			body:      `{"path": "/users/1/"}`,
+			status:    http.StatusBadRequest,
+			responses: "",
+		},
+		{
+			name:      "too large",
+			auth:      "Bearer token",
+			body:      "[" + strings.Repeat(`{"path": "/users/1/"},`, 100) + `{"path": "/users/1/"}]`,
+			status:    http.StatusBadRequest,
+			responses: "",
+		},
+		{
+			name:      "too large body",
+			auth:      "Bearer token",
+			body:      `[{"path": "/users/1/", "body": "` + strings.Repeat("x", 10<<20) + `"}]`,
+			status:    http.StatusRequestEntityTooLarge,
+			responses: "",
+		},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/batch/", strings.NewReader(tc.body))
+		req.Header.Set("Authorization", tc.auth)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tc.status {
+			t.Errorf("%s: expected status code %d, got %d %q", tc.name, tc.status, rr.Code, rr.Body.String())
+			continue
+		}
+		if tc.responses == "" {
+			continue
+		}
+		var responses, expected []router.BatchResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &responses); err != nil {
+			t.Fatalf("%s: invalid response %q", tc.name, rr.Body.String())
+		}
+		json.Unmarshal([]byte(tc.responses), &expected)
+		if len(responses) != len(expected) {
+			t.Fatalf("%s: expected %d responses, got %d", tc.name, len(expected), len(responses))
+		}
+		for i := range expected {
+			if responses[i].Status != expected[i].Status || !jsonEqual(responses[i].Body, expected[i].Body) {
+				t.Errorf("%s: expected response %d to be %d %s, got %d %s", tc.name, i, expected[i].Status, expected[i].Body, responses[i].Status, responses[i].Body)
+			}
+		}
+	}
+}
+
+func jsonEqual(a json.RawMessage, b json.RawMessage) bool {
+	var va, vb any
+	json.Unmarshal(a, &va)
+	json.Unmarshal(b, &vb)
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
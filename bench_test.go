@@ -0,0 +1,60 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+// discardWriter is a ResponseWriter that allocates nothing, so the benchmarks only measure the router
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(statusCode int)  {}
+
+func benchmarkRouter(b *testing.B, r *router.Router, path string) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := &discardWriter{header: make(http.Header)}
+	// The first request sets up the routes
+	r.ServeHTTP(w, req)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkServeHTTP(b *testing.B) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	passThrough := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			next(w, r)
+		}
+	}
+
+	b.Run("static", func(b *testing.B) {
+		r := router.NewRouter(router.WithMux(http.NewServeMux()))
+		r.GET("/users", handler)
+		benchmarkRouter(b, r, "/users/")
+	})
+	b.Run("param", func(b *testing.B) {
+		r := router.NewRouter(router.WithMux(http.NewServeMux()))
+		r.GET("/users/{id}", handler)
+		benchmarkRouter(b, r, "/users/1/")
+	})
+	b.Run("middlewares", func(b *testing.B) {
+		r := router.NewRouter(router.WithMux(http.NewServeMux()))
+		r.Use(passThrough, passThrough)
+		r.Group("/api", func(r *router.Router) {
+			r.Use(passThrough)
+			r.GET("/users/{id}", handler).Use(passThrough)
+		})
+		benchmarkRouter(b, r, "/api/users/1/")
+	})
+}
@@ -0,0 +1,86 @@
+// Package bind decodes request bodies, forms and query strings into structs.
+package bind
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gogo-framework/router"
+)
+
+// MaxBodySize is the largest JSON or XML body that is decoded, larger bodies return ErrBodyTooLarge.
+var MaxBodySize int64 = 1 << 20
+
+var (
+	ErrBodyTooLarge         = errors.New("bind: body too large")
+	ErrUnsupportedMediaType = errors.New("bind: unsupported media type")
+)
+
+// FieldError is returned when a form or query value can't be converted to the type of its field.
+type FieldError struct {
+	// Field is the name of the value, e.g. "address.city" or "items.0.amount"
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("bind: invalid value %q for %s: %v", e.Value, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Body decodes the request body into v, using JSON, XML or Form depending on the Content-Type. Other content types
+// return ErrUnsupportedMediaType.
+func Body(r *http.Request, v any) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return JSON(r, v)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return XML(r, v)
+	case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+		return Form(r, v)
+	default:
+		return ErrUnsupportedMediaType
+	}
+}
+
+// JSON decodes the JSON request body into v.
+func JSON(r *http.Request, v any) error {
+	return decodeBody(r, func(body []byte) error {
+		return json.Unmarshal(body, v)
+	})
+}
+
+// XML decodes the XML request body into v, using the xml struct tags.
+func XML(r *http.Request, v any) error {
+	return decodeBody(r, func(body []byte) error {
+		return xml.NewDecoder(bytes.NewReader(body)).Decode(v)
+	})
+}
+
+// decodeBody reads the body into a pooled buffer, the decoders copy what they keep, so it's released afterwards
+func decodeBody(r *http.Request, decode func(body []byte) error) error {
+	if r.Body == nil {
+		return io.EOF
+	}
+	scratch := router.AcquireScratch(r)
+	defer scratch.Release()
+	if _, err := scratch.ReadFrom(io.LimitReader(r.Body, MaxBodySize+1)); err != nil {
+		return err
+	}
+	if int64(scratch.Len()) > MaxBodySize {
+		return ErrBodyTooLarge
+	}
+	return decode(scratch.Bytes())
+}
@@ -0,0 +1,232 @@
+package bind_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/bind"
+)
+
+type Address struct {
+	Street string `form:"street" xml:"street"`
+	City   string `form:"city" xml:"city"`
+}
+
+type Item struct {
+	SKU      string `form:"sku"`
+	Quantity int    `form:"quantity"`
+}
+
+type Order struct {
+	Name      string     `form:"name" xml:"name"`
+	Express   bool       `form:"express"`
+	Tags      []string   `form:"tags"`
+	Address   Address    `form:"address" xml:"address"`
+	Billing   *Address   `form:"billing"`
+	Items     []Item     `form:"items"`
+	Delivery  time.Time  `form:"delivery"`
+	Discount  *float64   `form:"discount"`
+	Internal  string     `form:"-"`
+	CreatedAt *time.Time `form:"created_at"`
+}
+
+func TestForm(t *testing.T) {
+	form := url.Values{
+		"name":             {"Alice"},
+		"express":          {"on"},
+		"tags[]":           {"gift", "fragile"},
+		"address.street":   {"Main Street 1"},
+		"address[city]":    {"Amsterdam"},
+		"items[1][sku]":    {"B-2"},
+		"items[0][sku]":    {"A-1"},
+		"items.0.quantity": {"3"},
+		"delivery":         {"2024-05-01"},
+		"discount":         {""},
+		"Internal":         {"secret"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var order Order
+	if err := bind.Form(req, &order); err != nil {
+		t.Fatalf("Failed to bind the form: %v", err)
+	}
+
+	if order.Name != "Alice" || !order.Express || strings.Join(order.Tags, ",") != "gift,fragile" {
+		t.Errorf("Expected the simple fields to be bound, got %+v", order)
+	}
+	if order.Address != (Address{"Main Street 1", "Amsterdam"}) || order.Billing != nil {
+		t.Errorf("Expected the nested address to be bound, got %+v and %+v", order.Address, order.Billing)
+	}
+	if len(order.Items) != 2 || order.Items[0] != (Item{"A-1", 3}) || order.Items[1] != (Item{"B-2", 0}) {
+		t.Errorf("Expected the indexed items to be bound, got %+v", order.Items)
+	}
+	if !order.Delivery.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || order.CreatedAt != nil {
+		t.Errorf("Expected the delivery date to be bound, got %v and %v", order.Delivery, order.CreatedAt)
+	}
+	if order.Discount != nil || order.Internal != "" {
+		t.Errorf("Expected the empty and ignored fields to be skipped, got %v and %q", order.Discount, order.Internal)
+	}
+}
+
+func TestFormErrors(t *testing.T) {
+	values := url.Values{"items[0][quantity]": {"many"}, "delivery": {"tomorrow"}, "items[5000][sku]": {"X"}}
+
+	var order Order
+	err := bind.Values(values, &order)
+	var fieldError *bind.FieldError
+	if !errors.As(err, &fieldError) {
+		t.Fatalf("Expected a field error, got %v", err)
+	}
+	for _, field := range []string{"delivery", "items"} {
+		if !strings.Contains(err.Error(), "for "+field+":") {
+			t.Errorf("Expected an error for %s, got %v", field, err)
+		}
+	}
+
+	if err := bind.Values(values, order); err == nil {
+		t.Error("Expected an error when binding into a struct value")
+	}
+}
+
+func TestQueryTimeLayouts(t *testing.T) {
+	type Filter struct {
+		Since time.Time `form:"since"`
+	}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithConfig(router.RouterConfig{TimeLayouts: []string{"02-01-2006"}}))
+	var filter Filter
+	var bindErr error
+	r.GET("/events", func(w http.ResponseWriter, req *http.Request) {
+		bindErr = bind.Query(req, &filter)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events/?since=31-12-2023", nil))
+	if bindErr != nil || !filter.Since.Equal(time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the layout of the router to be used, got %v: %v", filter.Since, bindErr)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events/?since=2023-12-31", nil))
+	if bindErr == nil {
+		t.Error("Expected the default layouts not to be used")
+	}
+}
+
+func TestQueryModelSemantics(t *testing.T) {
+	type Filter struct {
+		Status []string `query:"status"`
+		Sort   string   `form:"sort"`
+		Range  struct {
+			From int `query:"from"`
+		} `query:"range"`
+	}
+
+	// Create a new router instance, Query binds like the query models of routes
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	var filter, model Filter
+	var bindErr error
+	r.GET("/users", func(w http.ResponseWriter, req *http.Request) {
+		bindErr = bind.Query(req, &filter)
+		model, _ = router.QueryModelOf[Filter](req)
+	}).QueryModel(Filter{})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/?status=active,invited&status=blocked&sort=name&range[from]=10", nil))
+	if bindErr != nil {
+		t.Fatalf("Expected no error, got %v", bindErr)
+	}
+	if !reflect.DeepEqual(filter, model) || len(filter.Status) != 3 || filter.Sort != "name" || filter.Range.From != 10 {
+		t.Errorf("Expected the same values as the query model, got %+v and %+v", filter, model)
+	}
+}
+
+func TestBody(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+		err         error
+	}{
+		{"json", "application/json", `{"Name":"Alice","Address":{"City":"Amsterdam"}}`, "Alice Amsterdam", nil},
+		{"xml", "application/xml; charset=utf-8", `<order><name>Bob</name><address><city>Berlin</city></address></order>`, "Bob Berlin", nil},
+		{"form", "application/x-www-form-urlencoded", `name=Carol&address.city=Paris`, "Carol Paris", nil},
+		{"unsupported", "text/csv", `name,city`, " ", bind.ErrUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			var order Order
+			err := bind.Body(req, &order)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if got := order.Name + " " + order.Address.City; got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("<order>"+strings.Repeat(" ", int(bind.MaxBodySize))+"</order>"))
+	var order Order
+	if err := bind.XML(req, &order); !errors.Is(err, bind.ErrBodyTooLarge) {
+		t.Errorf("Expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+// SKU is decoded by a registered decoder, which upper cases it
+type SKU string
+
+func init() {
+	router.RegisterParamDecoder(reflect.TypeOf(SKU("")), func(value string) (any, error) {
+		if value == "" {
+			return nil, errors.New("empty SKU")
+		}
+		return SKU(strings.ToUpper(value)), nil
+	})
+}
+
+func TestPathDecoder(t *testing.T) {
+	type Params struct {
+		SKU       SKU   `path:"sku"`
+		Warehouse int   `path:"warehouse"`
+		Related   []SKU `form:"related"`
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/warehouses/{warehouse}/products/{sku}", func(w http.ResponseWriter, req *http.Request) {
+		var params Params
+		if err := errors.Join(bind.Path(req, &params), bind.Query(req, &params)); err != nil {
+			router.Error(w, req, http.StatusBadRequest, err)
+			return
+		}
+		fmt.Fprintf(w, "%s %d %v", params.SKU, params.Warehouse, params.Related)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/warehouses/3/products/ab-1/?related=cd-2&related=ef-3", nil))
+	if w.Body.String() != "AB-1 3 [CD-2 EF-3]" {
+		t.Errorf("Expected the decoded parameters, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/warehouses/main/products/ab-1/", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid warehouse, got %d", w.Code)
+	}
+}
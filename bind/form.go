@@ -0,0 +1,75 @@
+package bind
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gogo-framework/router"
+)
+
+// Form binds the form values of the request into the struct v points to. They're read from the query string and a
+// url-encoded or multipart body. Fields are matched by their form tag, or their name without a tag, and are skipped
+// with form:"-".
+//
+// Nested structs use dot or bracket notation ("address.city" or "address[city]"). Slices are bound from repeated
+// values ("tags=a&tags=b" or "tags[]=a&tags[]=b"), slices of structs from indexes ("items[0][name]"). Times are
+// parsed with the layouts of the router, see router.TimeLayouts. Types with a decoder registered with
+// router.RegisterParamDecoder or implementing encoding.TextUnmarshaler decode themselves. The values are bound by
+// router.BindValues, which also binds the query models of routes.
+func Form(r *http.Request, v any) error {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return err
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return err
+	}
+	return bindValues(r.Form, v, router.BindConfig{Tags: []string{"form"}, TimeLayouts: router.TimeLayouts(r)})
+}
+
+// Query binds the query string of the request into the struct v points to, the same way as Route.QueryModel. Fields
+// are matched by their query tag, their form tag or their name, and slices take repeated and comma separated values.
+func Query(r *http.Request, v any) error {
+	return bindValues(router.Query(r), v, router.QueryBindConfig(r))
+}
+
+// Path binds the path parameters of the matched route into the struct v points to, the same way as Form but matching
+// fields by their path tag.
+//
+//	type params struct {
+//		UserID UserID `path:"id"`
+//	}
+func Path(r *http.Request, v any) error {
+	values := url.Values{}
+	if route := router.MatchedRoute(r); route != nil {
+		for _, name := range route.ParamNames() {
+			values.Set(name, r.PathValue(name))
+		}
+	}
+	return bindValues(values, v, router.BindConfig{Tags: []string{"path"}, TimeLayouts: router.TimeLayouts(r)})
+}
+
+// Values binds the values into the struct v points to, the same way as Form. Times are parsed with the given layouts,
+// or router.DefaultTimeLayouts. Values that can't be converted return a *FieldError, joined when there are several.
+func Values(values url.Values, v any, layouts ...string) error {
+	return bindValues(values, v, router.BindConfig{Tags: []string{"form"}, TimeLayouts: layouts})
+}
+
+// bindValues binds the values with router.BindValues, its *router.ParamErrors become *FieldErrors
+func bindValues(values url.Values, v any, config router.BindConfig) error {
+	err := router.BindValues(values, v, config)
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return err
+	}
+	var errs []error
+	for _, err := range joined.Unwrap() {
+		if paramError, ok := err.(*router.ParamError); ok {
+			err = &FieldError{Field: paramError.Name, Value: paramError.Value, Err: paramError.Err}
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,261 @@
+package router
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSliceLength is the largest index accepted for slices of structs, so a single value like "items[99999999]" can't
+// allocate a huge slice
+const maxSliceLength = 1000
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// BindConfig configures BindValues.
+type BindConfig struct {
+	// Tags are the struct tags that name the fields, the first tag a field has is used. Fields without one are matched
+	// by their name, fields tagged "-" are skipped
+	Tags []string
+	// SplitCommas splits the values of slices at commas, like QuerySlice
+	SplitCommas bool
+	// TimeLayouts are tried in order to parse times, defaults to DefaultTimeLayouts
+	TimeLayouts []string
+}
+
+// BindValues binds the values into the struct v points to. It's the binder of QueryModel and the bind package.
+//
+// Nested structs use dot or bracket notation ("address.city" or "address[city]"). Slices are bound from repeated
+// values ("tags=a&tags=b" or "tags[]=a&tags[]=b"), slices of structs from indexes ("items[0][name]"). Types with a
+// decoder registered with RegisterParamDecoder or implementing encoding.TextUnmarshaler decode themselves. Values that
+// can't be converted return a *ParamError named by their key, joined when there are several.
+func BindValues(values map[string][]string, v any, config BindConfig) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("router: expected a pointer to a struct, got %T", v)
+	}
+	if len(config.TimeLayouts) == 0 {
+		config.TimeLayouts = DefaultTimeLayouts
+	}
+
+	d := &valuesDecoder{values: make(map[string][]string, len(values)), config: config}
+	for key, vals := range values {
+		key = normalizeKey(key)
+		d.values[key] = append(d.values[key], vals...)
+	}
+	d.decodeStruct(rv.Elem(), "")
+	return errors.Join(d.errs...)
+}
+
+// normalizeKey converts bracket notation to dot notation, e.g. "items[0][name]" to "items.0.name" and "tags[]" to
+// "tags"
+func normalizeKey(key string) string {
+	key = strings.TrimSuffix(key, "[]")
+	key = strings.ReplaceAll(key, "][", ".")
+	key = strings.ReplaceAll(key, "[", ".")
+	return strings.ReplaceAll(key, "]", "")
+}
+
+type valuesDecoder struct {
+	values map[string][]string
+	config BindConfig
+	errs   []error
+}
+
+func (d *valuesDecoder) decodeStruct(v reflect.Value, prefix string) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := d.fieldName(field)
+		if name == "-" {
+			continue
+		}
+		// Embedded structs without a tag share the names of the parent
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct && !isScalar(field.Type) {
+			d.decodeStruct(v.Field(i), prefix)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		d.decodeValue(v.Field(i), prefix+name)
+	}
+}
+
+func (d *valuesDecoder) decodeValue(v reflect.Value, key string) {
+	t := v.Type()
+	switch {
+	case isScalar(t):
+		if values := d.values[key]; len(values) > 0 {
+			d.setScalar(v, key, values[0])
+		}
+	case t.Kind() == reflect.Pointer:
+		if !d.present(key) {
+			return
+		}
+		// Empty inputs leave pointers to single values nil
+		if values := d.values[key]; isScalar(t.Elem()) && (len(values) == 0 || values[0] == "") {
+			return
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		d.decodeValue(v.Elem(), key)
+	case t.Kind() == reflect.Struct:
+		d.decodeStruct(v, key+".")
+	case t.Kind() == reflect.Slice:
+		d.decodeSlice(v, key)
+	default:
+		if d.present(key) {
+			d.errs = append(d.errs, &ParamError{Name: key, Err: fmt.Errorf("unsupported type %s", t)})
+		}
+	}
+}
+
+func (d *valuesDecoder) decodeSlice(v reflect.Value, key string) {
+	if values := d.values[key]; len(values) > 0 && isScalar(v.Type().Elem()) {
+		if d.config.SplitCommas {
+			values = splitCommas(values)
+		}
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			d.setScalar(slice.Index(i), key, value)
+		}
+		v.Set(slice)
+		return
+	}
+
+	// Indexed values, e.g. "items.0.name" or "ids.0"
+	length := 0
+	for k := range d.values {
+		rest, ok := strings.CutPrefix(k, key+".")
+		if !ok {
+			continue
+		}
+		segment, _, _ := strings.Cut(rest, ".")
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 {
+			continue
+		}
+		if index >= maxSliceLength {
+			d.errs = append(d.errs, &ParamError{Name: key, Value: segment, Err: errors.New("index out of range")})
+			return
+		}
+		length = max(length, index+1)
+	}
+	if length == 0 {
+		return
+	}
+	slice := reflect.MakeSlice(v.Type(), length, length)
+	for i := range length {
+		d.decodeValue(slice.Index(i), key+"."+strconv.Itoa(i))
+	}
+	v.Set(slice)
+}
+
+// present reports whether there are values for the key or for fields nested in it
+func (d *valuesDecoder) present(key string) bool {
+	if _, ok := d.values[key]; ok {
+		return true
+	}
+	for k := range d.values {
+		if strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *valuesDecoder) setScalar(v reflect.Value, key string, value string) {
+	if err := d.parseScalar(v, value); err != nil {
+		d.errs = append(d.errs, &ParamError{Name: key, Value: value, Err: err})
+	}
+}
+
+func (d *valuesDecoder) parseScalar(v reflect.Value, value string) error {
+	t := v.Type()
+	if _, ok := ParamDecoderFor(t); ok {
+		// Empty inputs leave types with a decoder at their zero value
+		if value == "" {
+			return nil
+		}
+		return DecodeParam(v, value)
+	}
+	if t == timeType {
+		if value == "" {
+			return nil
+		}
+		var err error
+		for _, layout := range d.config.TimeLayouts {
+			var parsed time.Time
+			if parsed, err = time.Parse(layout, value); err == nil {
+				v.Set(reflect.ValueOf(parsed))
+				return nil
+			}
+		}
+		return err
+	}
+
+	switch {
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+	case value == "" && t.Kind() != reflect.String:
+		// Empty inputs leave numbers and booleans at their zero value
+		return nil
+	case value == "on" && t.Kind() == reflect.Bool:
+		// Checkboxes are sent as "on"
+		v.SetBool(true)
+		return nil
+	}
+	return DecodeParam(v, value)
+}
+
+// isScalar reports whether a type is bound from a single value
+func isScalar(t reflect.Type) bool {
+	if _, ok := ParamDecoderFor(t); ok {
+		return true
+	}
+	if t == timeType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// fieldName returns the name of the field in the first of the tags it has, or an empty string
+func (d *valuesDecoder) fieldName(field reflect.StructField) string {
+	for _, tag := range d.config.Tags {
+		if name, ok := field.Tag.Lookup(tag); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// splitCommas splits comma separated values, empty parts are dropped
+func splitCommas(values []string) []string {
+	var parts []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return parts
+}
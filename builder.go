@@ -0,0 +1,55 @@
+package router
+
+import "context"
+
+// Builder registers routes and configures the router, Build returns the router that serves them. The built router
+// can't be changed anymore, so it can be shared between goroutines without races between registration and serving.
+// All registration methods of Router are available on the builder.
+type Builder struct {
+	*Router
+}
+
+func NewBuilder(options ...Option) *Builder {
+	return &Builder{Router: NewRouter(options...)}
+}
+
+// Build sets up the routes, runs the warmups and returns the router. Registering routes, changing the configuration
+// of the built router or changing its routes and groups, e.g. with Use or Set, panics. When a warmup fails the errors are returned without a router.
+func (b *Builder) Build(ctx context.Context) (*Router, error) {
+	r := b.Router
+	r.mustBeMutable()
+	if err := r.Build(ctx); err != nil {
+		return nil, err
+	}
+	r.built = true
+	for _, route := range r.Routes() {
+		route.built = true
+	}
+	return r, nil
+}
+
+// mustBeMutable panics when the router was built by a Builder
+func (r *Router) mustBeMutable() {
+	if r.built {
+		panic("router: a built router can't be changed, register routes and set options on the Builder")
+	}
+}
+
+// mustBeMutable panics when the route belongs to a router that was built by a Builder, the routes are read by the
+// requests then
+func (r *Route) mustBeMutable() {
+	if r.built {
+		panic("router: a route of a built router can't be changed, configure it on the Builder")
+	}
+}
+
+// mustNotBeSetUp panics when the router was built or has set up its routes, which compiled the configuration into the
+// handlers of the routes
+func (r *Router) mustNotBeSetUp() {
+	r.mustBeMutable()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.hasSetupRoutes.Load() {
+		panic("router: the mux and config can't be changed after the routes were set up, pass them as options to NewRouter")
+	}
+}
@@ -0,0 +1,86 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestBuilder(t *testing.T) {
+	b := router.NewBuilder(router.WithMux(http.NewServeMux()))
+	b.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Built", "true")
+			next(w, r)
+		}
+	})
+	users := b.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("users"))
+	})
+	admin := b.Group("/admin", func(r *router.Router) {})
+
+	r, err := b.Build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The built router can be shared between goroutines
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/", nil))
+			if rr.Body.String() != "users" || rr.Header().Get("X-Built") != "true" {
+				t.Errorf("Unexpected response %d %q", rr.Code, rr.Body.String())
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Define test cases, every change to the built router panics
+	tests := map[string]func(){
+		"register route": func() { r.GET("/orders", func(w http.ResponseWriter, r *http.Request) {}) },
+		"group":          func() { r.Group("/admin", func(r *router.Router) {}) },
+		"middleware":     func() { r.Use() },
+		"config":         func() { r.SetConfig(router.RouterConfig{}) },
+		"builder":        func() { b.POST("/users", func(w http.ResponseWriter, r *http.Request) {}) },
+		"build again":    func() { b.Build(context.Background()) },
+		"route metadata": func() { users.Set("team", "billing") },
+		"route use":      func() { users.Use() },
+		"route name":     func() { users.Name("users") },
+		"route mirror":   func() { users.Mirror("http://shadow.internal", 1) },
+		"group use":      func() { admin.Use() },
+		"group metadata": func() { admin.Require("admin") },
+	}
+
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected a panic")
+				}
+			}()
+			change()
+		})
+	}
+}
+
+func TestBuilderWarmupError(t *testing.T) {
+	errWarmup := errors.New("cache unavailable")
+
+	b := router.NewBuilder(router.WithMux(http.NewServeMux()))
+	b.GET("/users", func(w http.ResponseWriter, r *http.Request) {}).
+		Warmup(func(ctx context.Context) error { return errWarmup })
+
+	r, err := b.Build(context.Background())
+	if r != nil || !errors.Is(err, errWarmup) {
+		t.Errorf("Expected the warmup error without a router, got %v %v", r, err)
+	}
+}
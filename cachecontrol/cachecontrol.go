@@ -0,0 +1,144 @@
+// Package cachecontrol builds Cache-Control policies and applies them to routes and groups, so static files, API
+// responses and HTML pages get consistent caching headers.
+//
+//	r.GET("/products", listProducts).Use(cachecontrol.Middleware(
+//		cachecontrol.Public(5 * time.Minute).StaleWhileRevalidate(30 * time.Second),
+//	))
+package cachecontrol
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+// Policy is a Cache-Control header. Its methods return a copy with the directive added, so policies can be shared
+// and extended.
+type Policy struct {
+	directives []string
+}
+
+// Public allows browsers and shared caches like CDNs to store the response for the duration.
+func Public(maxAge time.Duration) Policy {
+	return Policy{}.with("public").withDuration("max-age", maxAge)
+}
+
+// Private allows only browsers to store the response for the duration, e.g. for pages of a signed in user.
+func Private(maxAge time.Duration) Policy {
+	return Policy{}.with("private").withDuration("max-age", maxAge)
+}
+
+// NoCache allows caches to store the response, but they have to revalidate it before every use.
+func NoCache() Policy {
+	return Policy{}.with("no-cache")
+}
+
+// NoStore doesn't allow caches to store the response at all, e.g. for responses with personal data.
+func NoStore() Policy {
+	return Policy{}.with("no-store")
+}
+
+// SharedMaxAge sets how long shared caches store the response, instead of the max-age.
+func (p Policy) SharedMaxAge(d time.Duration) Policy {
+	return p.withDuration("s-maxage", d)
+}
+
+// StaleWhileRevalidate allows caches to serve the response for the duration after it became stale, while they
+// revalidate it in the background.
+func (p Policy) StaleWhileRevalidate(d time.Duration) Policy {
+	return p.withDuration("stale-while-revalidate", d)
+}
+
+// StaleIfError allows caches to serve the response for the duration after it became stale, when revalidating it
+// fails.
+func (p Policy) StaleIfError(d time.Duration) Policy {
+	return p.withDuration("stale-if-error", d)
+}
+
+// MustRevalidate doesn't allow caches to serve the response once it's stale.
+func (p Policy) MustRevalidate() Policy {
+	return p.with("must-revalidate")
+}
+
+// ProxyRevalidate doesn't allow shared caches to serve the response once it's stale.
+func (p Policy) ProxyRevalidate() Policy {
+	return p.with("proxy-revalidate")
+}
+
+// Immutable tells browsers the response never changes while it's fresh, e.g. for assets with a hash in their name.
+func (p Policy) Immutable() Policy {
+	return p.with("immutable")
+}
+
+// NoTransform doesn't allow proxies to change the response, e.g. to recompress images.
+func (p Policy) NoTransform() Policy {
+	return p.with("no-transform")
+}
+
+// String returns the value of the Cache-Control header.
+func (p Policy) String() string {
+	return strings.Join(p.directives, ", ")
+}
+
+// with returns a copy of the policy with the directive, replacing the directive with the same name
+func (p Policy) with(directive string) Policy {
+	name, _, _ := strings.Cut(directive, "=")
+	directives := make([]string, 0, len(p.directives)+1)
+	for _, d := range p.directives {
+		if n, _, _ := strings.Cut(d, "="); n != name {
+			directives = append(directives, d)
+		}
+	}
+	return Policy{directives: append(directives, directive)}
+}
+
+func (p Policy) withDuration(name string, d time.Duration) Policy {
+	return p.with(name + "=" + strconv.FormatInt(int64(max(d, 0)/time.Second), 10))
+}
+
+// Middleware sets the Cache-Control header of successful responses and redirects to the policy, it can be used on
+// routes and groups. Handlers can still set their own header. Error responses don't get the header, so a failure
+// isn't cached for the duration of the policy.
+func Middleware(policy Policy) router.Middleware {
+	value := policy.String()
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", value)
+			next(&writer{ResponseWriter: w, value: value}, r)
+		}
+	}
+}
+
+// writer removes the Cache-Control header of the policy from error responses
+type writer struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (w *writer) WriteHeader(statusCode int) {
+	if !w.wroteHeader && statusCode >= http.StatusBadRequest && w.Header().Get("Cache-Control") == w.value {
+		w.Header().Del("Cache-Control")
+	}
+	if statusCode >= http.StatusOK {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *writer) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *writer) Flush() {
+	w.wroteHeader = true
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,84 @@
+package cachecontrol_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/cachecontrol"
+)
+
+func TestPolicy(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name     string
+		policy   cachecontrol.Policy
+		expected string
+	}{
+		{"public", cachecontrol.Public(5 * time.Minute), "public, max-age=300"},
+		{"stale while revalidate", cachecontrol.Public(5 * time.Minute).StaleWhileRevalidate(30 * time.Second), "public, max-age=300, stale-while-revalidate=30"},
+		{"private", cachecontrol.Private(time.Minute).MustRevalidate(), "private, max-age=60, must-revalidate"},
+		{"assets", cachecontrol.Public(365 * 24 * time.Hour).Immutable(), "public, max-age=31536000, immutable"},
+		{"shared", cachecontrol.Public(0).SharedMaxAge(time.Hour).StaleIfError(time.Hour).ProxyRevalidate().NoTransform(), "public, max-age=0, s-maxage=3600, stale-if-error=3600, proxy-revalidate, no-transform"},
+		{"replaces directive", cachecontrol.Public(time.Minute).SharedMaxAge(time.Minute).SharedMaxAge(time.Hour), "public, max-age=60, s-maxage=3600"},
+		{"no cache", cachecontrol.NoCache(), "no-cache"},
+		{"no store", cachecontrol.NoStore(), "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.String(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("policies are copied", func(t *testing.T) {
+		base := cachecontrol.Public(time.Minute)
+		base.Immutable()
+		if got := base.String(); got != "public, max-age=60" {
+			t.Errorf("expected the base policy unchanged, got %q", got)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+
+	r.Group("/api", func(r *router.Router) {
+		r.GET("/products", func(w http.ResponseWriter, req *http.Request) {})
+		r.GET("/missing", func(w http.ResponseWriter, req *http.Request) {
+			router.Error(w, req, http.StatusNotFound, errors.New("not found"))
+		})
+		r.GET("/me", func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Cache-Control", "private")
+		})
+		r.GET("/live", func(w http.ResponseWriter, req *http.Request) {}).Use(cachecontrol.Middleware(cachecontrol.NoStore()))
+	}).Use(cachecontrol.Middleware(cachecontrol.Public(5 * time.Minute).StaleWhileRevalidate(30 * time.Second)))
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"group policy", "/api/products/", "public, max-age=300, stale-while-revalidate=30"},
+		{"errors aren't cached", "/api/missing/", ""},
+		{"handler overrides", "/api/me/", "private"},
+		{"route overrides group", "/api/live/", "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if got := rec.Header().Get("Cache-Control"); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
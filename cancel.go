@@ -0,0 +1,48 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// IsClientGone reports whether the client of the request disconnected.
+//
+// The router follows the cancellation model of net/http: when a client disconnects, the context of the request is
+// canceled. Handlers doing slow work should pass r.Context() on, so the work stops as well. The streaming helpers of
+// the render package and long polling routes stop writing once the context is canceled, and OnResponse
+// hooks get ClientClosed, which AccessLog records as client_closed. Handlers that still need to clean up can use
+// IsClientGone to tell a disconnect apart from their own timeouts, which end with context.DeadlineExceeded.
+func IsClientGone(r *http.Request) bool {
+	return errors.Is(context.Cause(r.Context()), context.Canceled)
+}
+
+// AccessLog returns an OnResponse hook that logs every response, including whether the client disconnected before
+// the response was complete, whether the response was aborted, and the team of the route.
+//
+//	r.OnResponse(router.AccessLog(logger))
+func AccessLog(logger *slog.Logger) func(ResponseInfo) {
+	return func(info ResponseInfo) {
+		level := slog.LevelInfo
+		if info.StatusCode >= http.StatusInternalServerError || info.Aborted {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", info.Request.Method),
+			slog.String("path", info.Request.URL.Path),
+			slog.String("route", info.Route.FullPattern()),
+			slog.Int("status", info.StatusCode),
+			slog.Int64("bytes", info.BytesWritten),
+			slog.Duration("duration", info.Duration),
+			slog.Bool("client_closed", info.ClientClosed),
+		}
+		if info.Aborted {
+			attrs = append(attrs, slog.Bool("aborted", true))
+		}
+		if team := info.Route.GetTeam(); team != "" {
+			attrs = append(attrs, slog.String("team", team))
+		}
+		logger.LogAttrs(info.Request.Context(), level, "request", attrs...)
+	}
+}
@@ -0,0 +1,78 @@
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestClientGone(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+
+	var logs bytes.Buffer
+	r.OnResponse(router.AccessLog(slog.New(slog.NewTextHandler(&logs, nil))))
+	responses := make(chan router.ResponseInfo, 1)
+	r.OnResponse(func(info router.ResponseInfo) { responses <- info })
+
+	started := make(chan struct{})
+	var handlerGone bool
+	r.GET("/slow", func(w http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-req.Context().Done()
+		handlerGone = router.IsClientGone(req)
+	})
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/slow/", nil)
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := http.DefaultClient.Do(req); err == nil {
+		t.Fatal("Expected the request to be canceled")
+	}
+
+	select {
+	case info := <-responses:
+		if !info.ClientClosed || !handlerGone {
+			t.Errorf("Expected the client to be gone, got ClientClosed %v and IsClientGone %v", info.ClientClosed, handlerGone)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the handler to return after the client disconnected")
+	}
+	if !strings.Contains(logs.String(), "client_closed=true") {
+		t.Errorf("Expected the access log to record client_closed, got %s", logs.String())
+	}
+}
+
+func TestClientNotGone(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	var info router.ResponseInfo
+	r.OnResponse(func(i router.ResponseInfo) { info = i })
+
+	var gone bool
+	r.GET("/timeout", func(w http.ResponseWriter, req *http.Request) {
+		// A timeout of the handler itself isn't a disconnect
+		ctx, cancel := context.WithTimeout(req.Context(), time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+		gone = router.IsClientGone(req.WithContext(ctx))
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/timeout/", nil))
+	if gone || info.ClientClosed {
+		t.Error("Expected a timeout not to be reported as a disconnect")
+	}
+}
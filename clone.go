@@ -0,0 +1,86 @@
+package router
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// Clone returns a deep copy of the routes, groups, middlewares and configuration of the router. The clone is not set
+// up yet and has a new ServeMux, so routes can be added to it without changing the original, e.g. to add test
+// fixtures to a shared base router or to derive a variant of the router per tenant. The handlers and middlewares
+// themselves are shared. Cloning a built router returns a router that can be changed again.
+func (r *Router) Clone() *Router {
+	clone := &Router{
+		mux:           http.NewServeMux(),
+		middlewares:   slices.Clone(r.middlewares),
+		requestHooks:  slices.Clone(r.requestHooks),
+		responseHooks: slices.Clone(r.responseHooks),
+		mockEnabled:   r.mockEnabled,
+		mockExamples:  maps.Clone(r.mockExamples),
+
+		errorHandler:            r.errorHandler,
+		notFoundHandler:         r.notFoundHandler,
+		methodNotAllowedHandler: r.methodNotAllowedHandler,
+		devErrors:               r.devErrors,
+		logger:                  r.logger,
+		signingKey:              r.signingKey,
+		setupProgress:           r.setupProgress,
+		responseSerializer:      r.responseSerializer,
+		panicReporter:           r.panicReporter,
+
+		config: r.config,
+	}
+	if r.tracer != nil {
+		clone.tracer = newTracer()
+	}
+	clone.matchEvents.listeners = slices.Clone(r.matchEvents.listeners)
+	clone.admin.maintenance.Store(r.admin.maintenance.Load())
+	clone.admin.logLevel.Set(r.admin.logLevel.Level())
+	r.admin.flags.Range(func(name, enabled any) bool {
+		clone.admin.flags.Store(name, enabled)
+		return true
+	})
+
+	r.routesMutex.RLock()
+	defer r.routesMutex.RUnlock()
+	for _, route := range r.routes {
+		clone.routes = append(clone.routes, route.clone(nil))
+	}
+	for _, routeGroup := range r.routeGroups {
+		group := &RouteGroup{
+			Prefix:      routeGroup.Prefix,
+			Middlewares: slices.Clone(routeGroup.Middlewares),
+			Metadata:    maps.Clone(routeGroup.Metadata),
+			router:      clone,
+		}
+		for _, route := range routeGroup.Routes {
+			group.Routes = append(group.Routes, route.clone(group))
+		}
+		clone.routeGroups = append(clone.routeGroups, group)
+	}
+	return clone
+}
+
+func (r *Route) clone(group *RouteGroup) *Route {
+	clone := &Route{
+		Method:      r.Method,
+		Pattern:     r.Pattern,
+		HandlerFunc: r.HandlerFunc,
+		Middlewares: slices.Clone(r.Middlewares),
+		Metadata:    maps.Clone(r.Metadata),
+		mount:       r.mount,
+		exact:       r.exact,
+		pushAssets:  slices.Clone(r.pushAssets),
+		name:        r.name,
+		group:       group,
+		warmups:     slices.Clone(r.warmups),
+		constraints: slices.Clone(r.constraints),
+		file:        r.file,
+		line:        r.line,
+	}
+	if r.mirror != nil {
+		clone.mirror = &mirror{target: r.mirror.target, sampleRate: r.mirror.sampleRate, queue: make(chan *http.Request, mirrorQueueSize)}
+	}
+	return clone
+}
@@ -0,0 +1,72 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestClone(t *testing.T) {
+	text := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}
+	}
+
+	// Create the base router
+	base := router.NewRouter()
+	base.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Base", "true")
+			next(w, r)
+		}
+	})
+	base.GET("/users", text("users")).Set("tier", "default")
+	base.Group("/api", func(r *router.Router) {
+		r.GET("/orders", text("orders"))
+	})
+	base.Mount("/static", http.StripPrefix("", text("static")))
+
+	// The clone gets a fixture and changed metadata, without changing the base
+	clone := base.Clone()
+	clone.GET("/fixtures", text("fixtures"))
+	clone.Routes()[0].Set("tier", "test")
+
+	// Define test cases
+	tests := []struct {
+		router     *router.Router
+		path       string
+		statusCode int
+		body       string
+	}{
+		{base, "/users/", http.StatusOK, "users"},
+		{base, "/api/orders/", http.StatusOK, "orders"},
+		{base, "/fixtures/", http.StatusNotFound, ""},
+		{clone, "/users/", http.StatusOK, "users"},
+		{clone, "/api/orders/", http.StatusOK, "orders"},
+		{clone, "/static/app.js", http.StatusOK, "static"},
+		{clone, "/fixtures/", http.StatusOK, "fixtures"},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		rr := httptest.NewRecorder()
+		tc.router.ServeHTTP(rr, req)
+
+		if rr.Code != tc.statusCode {
+			t.Errorf("%s: expected status code %d, got %d", tc.path, tc.statusCode, rr.Code)
+		}
+		if tc.statusCode == http.StatusOK && (rr.Body.String() != tc.body || rr.Header().Get("X-Base") != "true") {
+			t.Errorf("%s: expected body %q with the base middleware, got %q", tc.path, tc.body, rr.Body.String())
+		}
+	}
+
+	if tier, _ := base.Routes()[0].Get("tier"); tier != "default" {
+		t.Errorf("Expected the metadata of the base router to be unchanged, got %v", tier)
+	}
+	if len(base.Routes()) != 3 || len(clone.Routes()) != 4 {
+		t.Errorf("Expected 3 base routes and 4 cloned routes, got %d and %d", len(base.Routes()), len(clone.Routes()))
+	}
+}
@@ -0,0 +1,273 @@
+// Package configsync keeps the routes of a gateway in sync with a declarative config, e.g. a route manifest in a
+// file, on a config server or in etcd or Consul. Every new version of the config is built into a router, validated
+// and swapped in atomically with Router.Swap, and every change is written to an audit log.
+package configsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+// ErrNotModified is returned by sources when the config didn't change since the given version.
+var ErrNotModified = errors.New("configsync: config not modified")
+
+// maxConfigSize is the largest config the HTTP source reads
+const maxConfigSize = 10 << 20
+
+// Source fetches the config. Version is the version of the config that is currently applied, sources return
+// ErrNotModified when it's still the latest, or the config with its new version. File, HTTP, Etcd and Consul are
+// the built-in sources.
+type Source interface {
+	Fetch(ctx context.Context, version string) (config []byte, newVersion string, err error)
+}
+
+type SourceFunc func(ctx context.Context, version string) ([]byte, string, error)
+
+func (f SourceFunc) Fetch(ctx context.Context, version string) ([]byte, string, error) {
+	return f(ctx, version)
+}
+
+// File reads the config from a file, its version is a hash of its content.
+func File(path string) Source {
+	return SourceFunc(func(ctx context.Context, version string) ([]byte, string, error) {
+		config, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", err
+		}
+		sum := sha256.Sum256(config)
+		newVersion := hex.EncodeToString(sum[:8])
+		if newVersion == version {
+			return nil, "", ErrNotModified
+		}
+		return config, newVersion, nil
+	})
+}
+
+// HTTP polls the config from the URL, its version is the ETag of the response. Requests send the version in the
+// If-None-Match header, so the server can respond with 304 Not Modified. The client defaults to a client with a
+// timeout of 30 seconds.
+func HTTP(url string, client *http.Client) Source {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return SourceFunc(func(ctx context.Context, version string) ([]byte, string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		if version != "" {
+			req.Header.Set("If-None-Match", version)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusNotModified:
+			return nil, "", ErrNotModified
+		case resp.StatusCode != http.StatusOK:
+			return nil, "", fmt.Errorf("configsync: fetching %s: %s", url, resp.Status)
+		}
+		config, err := io.ReadAll(io.LimitReader(resp.Body, maxConfigSize+1))
+		if err != nil {
+			return nil, "", err
+		}
+		if len(config) > maxConfigSize {
+			return nil, "", fmt.Errorf("configsync: fetching %s: the config is larger than %d bytes", url, maxConfigSize)
+		}
+		newVersion := resp.Header.Get("ETag")
+		if newVersion == "" {
+			// Without an ETag the content is compared, so an unchanged config isn't applied again
+			sum := sha256.Sum256(config)
+			newVersion = hex.EncodeToString(sum[:8])
+		}
+		if newVersion == version {
+			return nil, "", ErrNotModified
+		}
+		return config, newVersion, nil
+	})
+}
+
+// Config configures a Syncer. Source and Build are required.
+type Config struct {
+	Source Source
+	// Build creates the router for a version of the config, Manifest builds it from a route manifest
+	Build func(ctx context.Context, config []byte) (*router.Router, error)
+	// Validate can reject a new router before it's swapped in, e.g. when its diff with the current routes is breaking
+	Validate func(current *router.Router, next *router.Router) error
+	// Interval is how often Run fetches the config, defaults to 10 seconds
+	Interval time.Duration
+	// History is the number of changes that are kept, defaults to 20
+	History int
+	// Logger gets the audit log of the changes, defaults to the logger of the router
+	Logger *slog.Logger
+}
+
+// Change is an attempt to apply a version of the config. Err is set when it was rejected, and the previous version
+// kept serving.
+type Change struct {
+	Version  string
+	Previous string
+	Time     time.Time
+	Diff     router.RouteDiff
+	Err      error
+}
+
+// Syncer applies new versions of the config to a router.
+type Syncer struct {
+	router *router.Router
+	config Config
+
+	mutex   sync.Mutex
+	version string
+	// rejected is the last version that was rejected, it isn't applied again until the version changes
+	rejected string
+	history  []Change
+}
+
+// New returns a syncer that swaps the routes of the router. Call Sync to apply the first version, or Run to keep
+// the routes in sync.
+func New(r *router.Router, config Config) *Syncer {
+	if config.Source == nil || config.Build == nil {
+		panic("configsync: the config needs a Source and a Build function")
+	}
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+	if config.History <= 0 {
+		config.History = 20
+	}
+	if config.Logger == nil {
+		config.Logger = r.Logger()
+	}
+	return &Syncer{router: r, config: config}
+}
+
+// Sync fetches the config and applies it when it changed. Configs that fail to fetch, build or validate are rejected
+// and the current routes keep serving, a rejected version is skipped until the version changes again.
+func (s *Syncer) Sync(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	config, version, err := s.config.Source.Fetch(ctx, s.version)
+	if errors.Is(err, ErrNotModified) {
+		return nil
+	}
+	if err != nil {
+		s.config.Logger.Error("configsync: fetching the config failed", "version", s.version, "error", err)
+		return err
+	}
+	if version == s.rejected {
+		return nil
+	}
+
+	change := Change{Version: version, Previous: s.version, Time: time.Now()}
+	change.Err = s.apply(ctx, config, &change)
+	s.history = append(s.history, change)
+	if len(s.history) > s.config.History {
+		s.history = s.history[len(s.history)-s.config.History:]
+	}
+
+	if change.Err != nil {
+		s.config.Logger.Error("configsync: rejected config", "version", version, "current_version", s.version, "error", change.Err)
+		s.rejected = version
+		return change.Err
+	}
+	s.version = version
+	s.rejected = ""
+	s.config.Logger.Info("configsync: applied config",
+		"version", version,
+		"previous_version", change.Previous,
+		"added", len(change.Diff.Added),
+		"removed", len(change.Diff.Removed),
+		"changed", len(change.Diff.Changed),
+		"warnings", change.Diff.Warnings(),
+	)
+	return nil
+}
+
+func (s *Syncer) apply(ctx context.Context, config []byte, change *Change) error {
+	next, err := s.config.Build(ctx, config)
+	if err != nil {
+		return fmt.Errorf("configsync: building version %s: %w", change.Version, err)
+	}
+	current := s.router.Active()
+	if s.config.Validate != nil {
+		if err := s.config.Validate(current, next); err != nil {
+			return fmt.Errorf("configsync: validating version %s: %w", change.Version, err)
+		}
+	}
+	if err := s.router.Swap(ctx, next); err != nil {
+		return err
+	}
+	change.Diff = next.Diff(current.Manifest())
+	return nil
+}
+
+// Run syncs the config every interval until the context is canceled. Errors are written to the audit log.
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		s.Sync(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Version returns the version of the config that is applied, or an empty string before the first one is.
+func (s *Syncer) Version() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.version
+}
+
+// History returns the latest changes, including rejected ones, oldest first.
+func (s *Syncer) History() []Change {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Change(nil), s.history...)
+}
+
+// Manifest returns a Build function for route manifests, see router.RouteManifest. The handlers are looked up by the
+// names of the routes, routes with names without a handler reject the config. Deprecated routes get the
+// Deprecation header.
+//
+//	configsync.Manifest(map[string]http.HandlerFunc{"users.list": listUsers}, router.WithLogger(logger))
+func Manifest(handlers map[string]http.HandlerFunc, options ...router.Option) func(ctx context.Context, config []byte) (*router.Router, error) {
+	return func(ctx context.Context, config []byte) (*router.Router, error) {
+		manifest, err := router.ReadManifest(bytes.NewReader(config))
+		if err != nil {
+			return nil, err
+		}
+		r := router.NewRouter(append([]router.Option{router.WithMux(http.NewServeMux())}, options...)...)
+		for _, mr := range manifest.Routes {
+			handler, ok := handlers[mr.Name]
+			if !ok {
+				return nil, fmt.Errorf("configsync: no handler for route %s named %q", mr, mr.Name)
+			}
+			route := r.RegisterRoute(mr.Method, mr.Path, handler).Name(mr.Name)
+			if mr.Deprecated {
+				route.Deprecated(time.Time{}, "")
+			}
+		}
+		return r, nil
+	}
+}
@@ -0,0 +1,191 @@
+package configsync_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/configsync"
+)
+
+var handlers = map[string]http.HandlerFunc{
+	"users.list":  func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "users") },
+	"orders.list": func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "orders") },
+}
+
+func TestSyncer(t *testing.T) {
+	var logs bytes.Buffer
+	path := filepath.Join(t.TempDir(), "routes.json")
+	write := func(config string) {
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	syncer := configsync.New(r, configsync.Config{
+		Source: configsync.File(path),
+		Build:  configsync.Manifest(handlers),
+		Validate: func(current *router.Router, next *router.Router) error {
+			if len(next.Routes()) == 0 {
+				return errors.New("no routes")
+			}
+			return nil
+		},
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	// Define test cases, the configs are applied in order
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+		status  map[string]int
+	}{
+		{"first version", `{"routes":[{"method":"GET","path":"/users","name":"users.list"}]}`, false, map[string]int{"/users/": 200, "/orders/": 404}},
+		{"unchanged", "", false, map[string]int{"/users/": 200}},
+		{"added route", `{"routes":[{"method":"GET","path":"/users","name":"users.list"},{"method":"GET","path":"/orders","name":"orders.list"}]}`, false, map[string]int{"/users/": 200, "/orders/": 200}},
+		{"invalid json", `{"routes":`, true, map[string]int{"/orders/": 200}},
+		{"unknown handler", `{"routes":[{"method":"GET","path":"/admin","name":"admin"}]}`, true, map[string]int{"/orders/": 200}},
+		{"failed validation", `{"routes":[]}`, true, map[string]int{"/orders/": 200}},
+		{"rejected version again", "", false, map[string]int{"/orders/": 200}},
+		{"removed route", `{"routes":[{"method":"GET","path":"/orders","name":"orders.list"}]}`, false, map[string]int{"/users/": 404, "/orders/": 200}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.config != "" {
+				write(tt.config)
+			}
+			err := syncer.Sync(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			for path, status := range tt.status {
+				if got := get(path); got != status {
+					t.Errorf("expected status %d for %s, got %d", status, path, got)
+				}
+			}
+		})
+	}
+
+	history := syncer.History()
+	if len(history) != 6 {
+		t.Fatalf("expected 6 changes, got %d", len(history))
+	}
+	last := history[len(history)-1]
+	if last.Err != nil || last.Version != syncer.Version() || last.Previous != history[1].Version {
+		t.Errorf("expected the last change to be applied on top of the second, got %+v", last)
+	}
+	if len(last.Diff.Removed) != 1 || last.Diff.Removed[0].Name != "users.list" {
+		t.Errorf("expected users.list to be removed, got %+v", last.Diff)
+	}
+	if !strings.Contains(logs.String(), "rejected config") || !strings.Contains(logs.String(), "route GET /users was removed") {
+		t.Errorf("expected the audit log to contain the changes, got %s", logs.String())
+	}
+
+	// The previous routes can be rolled back
+	r.Rollback()
+	if got := get("/users/"); got != http.StatusOK {
+		t.Errorf("expected the rollback to serve /users, got %d", got)
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"routes":[]}`)
+	}))
+	defer server.Close()
+
+	source := configsync.HTTP(server.URL, nil)
+	config, version, err := source.Fetch(context.Background(), "")
+	if err != nil || version != `"v1"` || string(config) != `{"routes":[]}` {
+		t.Fatalf("expected the config with version v1, got %q, %q, %v", config, version, err)
+	}
+	if _, _, err := source.Fetch(context.Background(), version); !errors.Is(err, configsync.ErrNotModified) {
+		t.Errorf("expected ErrNotModified, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestKVSources(t *testing.T) {
+	revision := "7"
+	etcd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Key []byte `json:"key"`
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/v3/kv/range" || json.NewDecoder(r.Body).Decode(&body) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if string(body.Key) != "/gateway/routes" {
+			fmt.Fprint(w, `{"header":{"revision":"9"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"header":{"revision":"9"},"kvs":[{"key":"L2dhdGV3YXkvcm91dGVz","mod_revision":%q,"value":"eyJyb3V0ZXMiOltdfQ=="}],"count":"1"}`, revision)
+	}))
+	defer etcd.Close()
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/gateway/routes" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `[{"Key":"gateway/routes","ModifyIndex":%s,"Value":"eyJyb3V0ZXMiOltdfQ=="}]`, revision)
+	}))
+	defer consul.Close()
+
+	// Define test cases
+	tests := []struct {
+		name    string
+		source  configsync.Source
+		missing configsync.Source
+	}{
+		{"etcd", configsync.Etcd(etcd.URL, "/gateway/routes", nil), configsync.Etcd(etcd.URL, "/gateway/other", nil)},
+		{"consul", configsync.Consul(consul.URL, "gateway/routes", nil), configsync.Consul(consul.URL, "gateway/other", nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revision = "7"
+			config, version, err := tt.source.Fetch(context.Background(), "")
+			if err != nil || version != "7" || string(config) != `{"routes":[]}` {
+				t.Fatalf("expected the config with version 7, got %q, %q, %v", config, version, err)
+			}
+			if _, _, err := tt.source.Fetch(context.Background(), version); !errors.Is(err, configsync.ErrNotModified) {
+				t.Errorf("expected ErrNotModified, got %v", err)
+			}
+			revision = "8"
+			if _, version, err := tt.source.Fetch(context.Background(), version); err != nil || version != "8" {
+				t.Errorf("expected version 8, got %q, %v", version, err)
+			}
+			if _, _, err := tt.missing.Fetch(context.Background(), ""); err == nil {
+				t.Errorf("expected an error for a missing key")
+			}
+		})
+	}
+}
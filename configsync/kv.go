@@ -0,0 +1,107 @@
+package configsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Etcd reads the config from a key in etcd with the JSON gateway of the v3 API, its version is the mod revision of
+// the key. The endpoint is the URL of an etcd member, e.g. "http://127.0.0.1:2379". The client defaults to a client
+// with a timeout of 30 seconds, authentication can be added by its transport.
+func Etcd(endpoint string, key string, client *http.Client) Source {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	rangeURL := strings.TrimSuffix(endpoint, "/") + "/v3/kv/range"
+	return SourceFunc(func(ctx context.Context, version string) ([]byte, string, error) {
+		body, err := json.Marshal(map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(key))})
+		if err != nil {
+			return nil, "", err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, rangeURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		// The gateway encodes 64 bit integers as strings and bytes as base64
+		var result struct {
+			Kvs []struct {
+				ModRevision string `json:"mod_revision"`
+				Value       []byte `json:"value"`
+			} `json:"kvs"`
+		}
+		if err := fetchJSON(client, req, &result); err != nil {
+			return nil, "", fmt.Errorf("configsync: fetching %s from etcd: %w", key, err)
+		}
+		if len(result.Kvs) == 0 {
+			return nil, "", fmt.Errorf("configsync: fetching %s from etcd: the key doesn't exist", key)
+		}
+		if result.Kvs[0].ModRevision == version {
+			return nil, "", ErrNotModified
+		}
+		return result.Kvs[0].Value, result.Kvs[0].ModRevision, nil
+	})
+}
+
+// Consul reads the config from a key in the KV store of Consul, its version is the modify index of the key. The
+// address is the URL of the agent, e.g. "http://127.0.0.1:8500". The client defaults to a client with a timeout of
+// 30 seconds, ACL tokens can be added by its transport.
+func Consul(address string, key string, client *http.Client) Source {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	keyURL := strings.TrimSuffix(address, "/") + "/v1/kv/" + (&url.URL{Path: strings.TrimPrefix(key, "/")}).EscapedPath()
+	return SourceFunc(func(ctx context.Context, version string) ([]byte, string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, keyURL, nil)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var result []struct {
+			ModifyIndex uint64
+			Value       []byte
+		}
+		if err := fetchJSON(client, req, &result); err != nil {
+			return nil, "", fmt.Errorf("configsync: fetching %s from Consul: %w", key, err)
+		}
+		if len(result) == 0 {
+			return nil, "", fmt.Errorf("configsync: fetching %s from Consul: the key doesn't exist", key)
+		}
+		newVersion := strconv.FormatUint(result[0].ModifyIndex, 10)
+		if newVersion == version {
+			return nil, "", ErrNotModified
+		}
+		return result[0].Value, newVersion, nil
+	})
+}
+
+// fetchJSON sends the request and decodes the JSON response, which can't be larger than the largest config
+func fetchJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Redacted(), resp.Status)
+	}
+	// The value is base64 encoded, which makes it a third larger
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 2*maxConfigSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > 2*maxConfigSize {
+		return fmt.Errorf("the response is larger than %d bytes", 2*maxConfigSize)
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,106 @@
+package router
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ConnStats are the connections of the servers created by Server, Run and RunTLS, to see connection churn and slow
+// clients. New, Active and Idle are the current connections in that state, Accepted, Hijacked and Closed are totals.
+type ConnStats struct {
+	// New is the number of connections that haven't sent a complete request yet
+	New int64 `json:"new"`
+	// Active is the number of connections that are serving a request
+	Active int64 `json:"active"`
+	// Idle is the number of keep-alive connections waiting for the next request
+	Idle     int64 `json:"idle"`
+	Accepted int64 `json:"accepted"`
+	// Hijacked is the number of connections taken over by handlers, e.g. for WebSockets
+	Hijacked int64 `json:"hijacked"`
+	Closed   int64 `json:"closed"`
+}
+
+// connTracker counts the connections by their state
+type connTracker struct {
+	states   sync.Map // net.Conn -> http.ConnState
+	new      atomic.Int64
+	active   atomic.Int64
+	idle     atomic.Int64
+	accepted atomic.Int64
+	hijacked atomic.Int64
+	closed   atomic.Int64
+}
+
+// WithConnState calls the hook whenever a connection changes its state, in addition to the connection stats of the
+// router. Several hooks are called in the order they're given.
+func WithConnState(hook func(conn net.Conn, state http.ConnState)) ServerOption {
+	return func(server *http.Server) {
+		previous := server.ConnState
+		server.ConnState = func(conn net.Conn, state http.ConnState) {
+			if previous != nil {
+				previous(conn, state)
+			}
+			hook(conn, state)
+		}
+	}
+}
+
+// ConnStats returns the connection counts of the servers created by Server, Run and RunTLS.
+func (r *Router) ConnStats() ConnStats {
+	return r.conns.stats()
+}
+
+func (t *connTracker) stats() ConnStats {
+	return ConnStats{
+		New:      t.new.Load(),
+		Active:   t.active.Load(),
+		Idle:     t.idle.Load(),
+		Accepted: t.accepted.Load(),
+		Hijacked: t.hijacked.Load(),
+		Closed:   t.closed.Load(),
+	}
+}
+
+func (t *connTracker) track(conn net.Conn, state http.ConnState) {
+	if previous, ok := t.states.Load(conn); ok {
+		if gauge := t.gauge(previous.(http.ConnState)); gauge != nil {
+			gauge.Add(-1)
+		}
+	}
+	if gauge := t.gauge(state); gauge != nil {
+		gauge.Add(1)
+	}
+
+	switch state {
+	case http.StateNew:
+		t.accepted.Add(1)
+	case http.StateHijacked:
+		t.hijacked.Add(1)
+	case http.StateClosed:
+		t.closed.Add(1)
+	}
+	if state == http.StateHijacked || state == http.StateClosed {
+		t.states.Delete(conn)
+	} else {
+		t.states.Store(conn, state)
+	}
+}
+
+// gauge returns the counter of the connections in the state, hijacked and closed connections aren't tracked anymore
+func (t *connTracker) gauge(state http.ConnState) *atomic.Int64 {
+	switch state {
+	case http.StateNew:
+		return &t.new
+	case http.StateActive:
+		return &t.active
+	case http.StateIdle:
+		return &t.idle
+	}
+	return nil
+}
+
+func (r *Router) adminConnections(w http.ResponseWriter, req *http.Request) {
+	writeAdminJSON(w, r.ConnStats())
+}
@@ -0,0 +1,73 @@
+package router_test
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestConnStats(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.GET("/", func(w http.ResponseWriter, req *http.Request) {})
+
+	var mutex sync.Mutex
+	var states []http.ConnState
+	server := r.Server("", router.WithConnState(func(conn net.Conn, state http.ConnState) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		states = append(states, state)
+	}))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	// waitFor polls the stats, as the server changes the state of connections asynchronously
+	waitFor := func(expected router.ConnStats) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for r.ConnStats() != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %+v, got %+v", expected, r.ConnStats())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	transport := &http.Transport{}
+	client := &http.Client{Transport: transport}
+	for range 2 {
+		resp, err := client.Get("http://" + listener.Addr().String() + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// Both requests used the same keep-alive connection
+	waitFor(router.ConnStats{Idle: 1, Accepted: 1})
+
+	transport.CloseIdleConnections()
+	waitFor(router.ConnStats{Accepted: 1, Closed: 1})
+
+	// A connection that never sends a request stays new
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(router.ConnStats{New: 1, Accepted: 2, Closed: 1})
+	conn.Close()
+	waitFor(router.ConnStats{Accepted: 2, Closed: 2})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(states) == 0 || states[0] != http.StateNew {
+		t.Errorf("expected the hook to be called for every state, got %v", states)
+	}
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// paramConstraint checks the value of a path parameter before the handler is called
+type paramConstraint struct {
+	name string
+	// status is returned when the check fails, 404 for constraints in the pattern as the route doesn't match
+	status int
+	check  func(value string) error
+}
+
+// ParamEnum only allows the given values for the path parameter, other values are rejected with 400 before the
+// handler is called. The same constraint can be written in the pattern as {status:one_of=active|archived}, which
+// responds with 404 instead.
+func (r *Route) ParamEnum(name string, values ...string) *Route {
+	return r.constrain(name, http.StatusBadRequest, oneOf(values))
+}
+
+// ParamRange only allows integers between min and max, inclusive, for the path parameter. Other values are rejected
+// with 400 before the handler is called.
+func (r *Route) ParamRange(name string, min int, max int) *Route {
+	return r.constrain(name, http.StatusBadRequest, func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < min || n > max {
+			return fmt.Errorf("must be a number between %d and %d", min, max)
+		}
+		return nil
+	})
+}
+
+// ParamDate only allows dates in the layout, e.g. "2006-01-02", for the path parameter. Other values are rejected
+// with 400 before the handler is called.
+func (r *Route) ParamDate(name string, layout string) *Route {
+	return r.constrain(name, http.StatusBadRequest, func(value string) error {
+		if _, err := time.Parse(layout, value); err != nil {
+			return fmt.Errorf("must be a date like %s", layout)
+		}
+		return nil
+	})
+}
+
+func (r *Route) constrain(name string, status int, check func(value string) error) *Route {
+	r.mustBeMutable()
+	r.constraints = append(r.constraints, paramConstraint{name: name, status: status, check: check})
+	return r
+}
+
+func oneOf(values []string) func(value string) error {
+	return func(value string) error {
+		if !slices.Contains(values, value) {
+			return fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+		}
+		return nil
+	}
+}
+
+// parseConstraints removes the constraints from the wildcards of a pattern, e.g. "/posts/{status:one_of=a|b}"
+// becomes "/posts/{status}"
+func parseConstraints(pattern string) (string, []paramConstraint) {
+	var b strings.Builder
+	var constraints []paramConstraint
+	rest := pattern
+	for {
+		start := strings.Index(rest, "{")
+		end := strings.Index(rest, "}")
+		if start < 0 || end < start {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:start])
+		name, constraint, ok := strings.Cut(rest[start+1:end], ":")
+		b.WriteString("{" + name + "}")
+		rest = rest[end+1:]
+		if !ok {
+			continue
+		}
+
+		kind, args, _ := strings.Cut(constraint, "=")
+		switch kind {
+		case "one_of":
+			constraints = append(constraints, paramConstraint{
+				name:   strings.TrimSuffix(name, "..."),
+				status: http.StatusNotFound,
+				check:  oneOf(strings.Split(args, "|")),
+			})
+		default:
+			panic(fmt.Sprintf("router: unknown constraint %q in pattern %q", kind, pattern))
+		}
+	}
+	return b.String(), constraints
+}
+
+// constraintHandler checks the path parameters of the route before calling the handler
+func (r *Router) constraintHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	if len(route.constraints) == 0 {
+		return handler
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		for _, constraint := range route.constraints {
+			err := constraint.check(req.PathValue(constraint.name))
+			if err == nil {
+				continue
+			}
+			if constraint.status == http.StatusNotFound {
+				if r.notFoundHandler != nil {
+					r.notFoundHandler(w, req)
+				} else {
+					Error(w, req, http.StatusNotFound, nil)
+				}
+				return
+			}
+			Error(w, req, constraint.status, fmt.Errorf("invalid value for %s: %w", constraint.name, err))
+			return
+		}
+		handler(w, req)
+	}
+}
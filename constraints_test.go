@@ -0,0 +1,101 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestParamEnum(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.PathValue("status")))
+	}
+	r.GET("/posts/{status:one_of=active|archived|deleted}", handler)
+	r.GET("/users/{status}", handler).ParamEnum("status", "active", "blocked")
+
+	// Define test cases
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+	}{
+		{"pattern constraint allows value", "/posts/archived/", http.StatusOK},
+		{"pattern constraint rejects value", "/posts/draft/", http.StatusNotFound},
+		{"route constraint allows value", "/users/blocked/", http.StatusOK},
+		{"route constraint rejects value", "/users/deleted/", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status %d, got %d: %s", tt.statusCode, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// The constraint isn't part of the pattern of the route
+	if pattern := r.Routes()[0].Pattern; pattern != "/posts/{status}" {
+		t.Errorf("Expected the constraint to be removed from the pattern, got %s", pattern)
+	}
+}
+
+func TestParamEnumNotFoundHandler(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithNotFound(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	r.GET("/posts/{status:one_of=active}", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/draft/", nil))
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Expected the not found handler, got %d", w.Code)
+	}
+}
+
+func TestUnknownConstraint(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for an unknown constraint")
+		}
+	}()
+	router.NewRouter().GET("/posts/{id:uuid}", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func TestParamRangeAndDate(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/pages/{page}", handler).ParamRange("page", 1, 10000)
+	r.GET("/reports/{day}", handler).ParamDate("day", "2006-01-02")
+
+	// Define test cases
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		body       string
+	}{
+		{"number in range", "/pages/10000/", http.StatusOK, ""},
+		{"number below range", "/pages/0/", http.StatusBadRequest, "invalid value for page: must be a number between 1 and 10000\n"},
+		{"not a number", "/pages/first/", http.StatusBadRequest, "invalid value for page: must be a number between 1 and 10000\n"},
+		{"valid date", "/reports/2024-02-29/", http.StatusOK, ""},
+		{"invalid date", "/reports/2023-02-29/", http.StatusBadRequest, "invalid value for day: must be a date like 2006-01-02\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.statusCode || w.Body.String() != tt.body {
+				t.Errorf("Expected %d %q, got %d %q", tt.statusCode, tt.body, w.Code, w.Body.String())
+			}
+		})
+	}
+}
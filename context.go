@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+type contextKey int
+
+const (
+	routeContextKey contextKey = iota
+	requestContextKey
+)
+
+// routeContext is stored in the request context for matched routes
+type routeContext struct {
+	router *Router
+	route  *Route
+}
+
+// MatchedRoute returns the route that matched the request, or nil when called outside of a matched route.
+func MatchedRoute(r *http.Request) *Route {
+	rc, _ := r.Context().Value(routeContextKey).(*routeContext)
+	if rc == nil {
+		return nil
+	}
+	return rc.route
+}
+
+func routerFromRequest(r *http.Request) *Router {
+	rc, _ := r.Context().Value(routeContextKey).(*routeContext)
+	if rc == nil {
+		return nil
+	}
+	return rc.router
+}
+
+// requestContext carries the route of a request and caches data per request, like the parsed query. It replaces
+// the context value holding the route, so the cache doesn't cost another allocation.
+type requestContext struct {
+	context.Context
+	rc *routeContext
+
+	mutex    sync.Mutex
+	rawQuery string
+	query    url.Values
+	// queryModel is the pointer to the query model bound by Route.QueryModel
+	queryModel any
+	// header is the header of the request and outbound the headers set with SetOutboundHeader, see OutboundHeaders
+	header   http.Header
+	outbound http.Header
+	// jobs is the work passed to Async, started when the handler returned and done is set
+	jobs []func()
+	done bool
+}
+
+func (c *requestContext) Value(key any) any {
+	if key == routeContextKey {
+		return c.rc
+	}
+	if key == requestContextKey {
+		return c
+	}
+	return c.Context.Value(key)
+}
+
+// withRouteContext returns the request with the route context in its context, and the request context
+func withRouteContext(req *http.Request, rc *routeContext) (*http.Request, *requestContext) {
+	c := &requestContext{Context: req.Context(), rc: rc, header: req.Header}
+	return req.WithContext(c), c
+}
+
+func (r *Router) withRoute(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	rc := &routeContext{router: r, route: route}
+	return func(w http.ResponseWriter, req *http.Request) {
+		req, c := withRouteContext(req, rc)
+		defer c.startJobs()
+		handler(w, req)
+	}
+}
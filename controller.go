@@ -0,0 +1,61 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var handlerFuncType = reflect.TypeOf(http.HandlerFunc(nil))
+
+// RegisterStruct registers the handler fields of a controller as a group below the prefix, as a declarative
+// alternative for large sets of routes. Fields are declared with a route tag like `route:"GET /{id}"`, a tag without
+// a method matches every method. Routes can be named with a name tag. When the controller has a
+// Middlewares() []Middleware method, the middlewares are used for the group.
+//
+//	type UsersController struct {
+//		Index http.HandlerFunc `route:"GET /"`
+//		Show  http.HandlerFunc `route:"GET /{id}" name:"users.show"`
+//	}
+//
+// It panics when the controller isn't a pointer to a struct, or when a tagged field isn't an exported, non-nil
+// http.HandlerFunc.
+func (r *Router) RegisterStruct(prefix string, controller any) *RouteGroup {
+	value := reflect.ValueOf(controller)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("router: RegisterStruct needs a pointer to a struct, got %T", controller))
+	}
+	value = value.Elem()
+	structType := value.Type()
+
+	return r.Group(prefix, func(group *Router) {
+		if withMiddlewares, ok := controller.(interface{ Middlewares() []Middleware }); ok {
+			group.Use(withMiddlewares.Middlewares()...)
+		}
+
+		for i := range structType.NumField() {
+			field := structType.Field(i)
+			tag, ok := field.Tag.Lookup("route")
+			if !ok {
+				continue
+			}
+			if !field.IsExported() || !field.Type.ConvertibleTo(handlerFuncType) {
+				panic(fmt.Sprintf("router: field %s.%s must be an exported http.HandlerFunc", structType.Name(), field.Name))
+			}
+			fieldValue := value.Field(i)
+			if fieldValue.IsNil() {
+				panic(fmt.Sprintf("router: field %s.%s has no handler", structType.Name(), field.Name))
+			}
+
+			method, pattern := "", tag
+			if before, after, found := strings.Cut(tag, " "); found {
+				method, pattern = before, strings.TrimSpace(after)
+			}
+			route := group.RegisterRoute(method, pattern, fieldValue.Convert(handlerFuncType).Interface().(http.HandlerFunc))
+			if name := field.Tag.Get("name"); name != "" {
+				route.Name(name)
+			}
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+type usersController struct {
+	Index  http.HandlerFunc                         `route:"GET /"`
+	Show   func(http.ResponseWriter, *http.Request) `route:"GET /{id}" name:"users.show"`
+	Any    http.HandlerFunc                         `route:"/export/all"`
+	helper string
+}
+
+func (c *usersController) Middlewares() []router.Middleware {
+	return []router.Middleware{func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Controller", "users")
+			next(w, r)
+		}
+	}}
+}
+
+func TestRegisterStruct(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.RegisterStruct("/users", &usersController{
+		Index: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("index")) },
+		Show:  func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("show " + r.PathValue("id"))) },
+		Any:   func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("any " + r.Method)) },
+	})
+
+	// Define test cases
+	tests := []struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{http.MethodGet, "/users/", http.StatusOK, "index"},
+		{http.MethodGet, "/users/5/", http.StatusOK, "show 5"},
+		{http.MethodPost, "/users/export/all/", http.StatusOK, "any POST"},
+		{http.MethodPost, "/users/", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.expectedCode {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.expectedCode, w.Code)
+		}
+		if tt.expectedCode == http.StatusOK {
+			if w.Body.String() != tt.expectedBody {
+				t.Errorf("%s %s: expected body %q, got %q", tt.method, tt.path, tt.expectedBody, w.Body.String())
+			}
+			if w.Header().Get("X-Controller") != "users" {
+				t.Errorf("%s %s: expected the controller middlewares to run", tt.method, tt.path)
+			}
+		}
+	}
+
+	if url, err := r.URL("users.show", map[string]string{"id": "7"}); err != nil || url != "/users/7/" {
+		t.Errorf("Expected the named route to generate /users/7/, got %q (%v)", url, err)
+	}
+}
+
+func TestRegisterStructInvalid(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name       string
+		controller any
+	}{
+		{"not a pointer", usersController{}},
+		{"nil handler", &usersController{}},
+		{"wrong type", &struct {
+			Index string `route:"GET /"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected RegisterStruct to panic")
+				}
+			}()
+			router.NewRouter().RegisterStruct("/users", tt.controller)
+		})
+	}
+}
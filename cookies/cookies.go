@@ -0,0 +1,196 @@
+// Package cookies sets and reads signed and encrypted cookies with secure defaults, and supports rotating keys.
+package cookies
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// KeySize is the size of the keys of a Jar
+	KeySize = 32
+	// maxSize is the size browsers are guaranteed to store for a cookie
+	maxSize = 4096
+)
+
+var (
+	// ErrInvalid is returned for cookies that were changed, or signed or encrypted with an unknown key
+	ErrInvalid = errors.New("cookies: invalid cookie")
+	// ErrTooLarge is returned when a cookie doesn't fit in the 4096 bytes browsers store
+	ErrTooLarge = errors.New("cookies: cookie is too large")
+)
+
+type Config struct {
+	// Keys sign and encrypt the cookies, they have to be KeySize random bytes. New cookies use the first key, the
+	// others are only used to read cookies, so a key can be rotated by adding a new key in front of it
+	Keys [][]byte
+	// Insecure allows the cookies to be sent over HTTP, e.g. in development. Cookies are Secure by default
+	Insecure bool
+	// SameSite defaults to http.SameSiteLaxMode
+	SameSite http.SameSite
+}
+
+// Jar sets and reads cookies with the keys of its config. Cookies get the secure defaults: HttpOnly, Secure, SameSite
+// Lax and the path "/", unless the cookie sets its own path or SameSite mode.
+type Jar struct {
+	config Config
+	keys   []derivedKeys
+}
+
+// derivedKeys are derived from a key, so signing and encryption don't use the same key
+type derivedKeys struct {
+	sign    []byte
+	encrypt cipher.AEAD
+}
+
+func New(config Config) (*Jar, error) {
+	if len(config.Keys) == 0 {
+		return nil, errors.New("cookies: at least one key is needed")
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+
+	jar := &Jar{config: config}
+	for i, key := range config.Keys {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("cookies: key %d has %d bytes instead of %d", i, len(key), KeySize)
+		}
+		block, err := aes.NewCipher(derive(key, "encrypt"))
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		jar.keys = append(jar.keys, derivedKeys{sign: derive(key, "sign"), encrypt: aead})
+	}
+	return jar, nil
+}
+
+func derive(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// SetSigned sets the cookie with a signature, so clients can read but not change the value.
+func (j *Jar) SetSigned(w http.ResponseWriter, cookie *http.Cookie) error {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(cookie.Value))
+	return j.set(w, cookie, payload+"."+sign(j.keys[0].sign, cookie.Name, payload))
+}
+
+// Signed returns the value of a cookie set with SetSigned. It returns http.ErrNoCookie when the request doesn't have
+// the cookie and ErrInvalid when the signature doesn't match.
+func (j *Jar) Signed(r *http.Request, name string) (string, error) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	payload, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return "", ErrInvalid
+	}
+	for _, key := range j.keys {
+		if hmac.Equal([]byte(signature), []byte(sign(key.sign, name, payload))) {
+			value, err := base64.RawURLEncoding.DecodeString(payload)
+			if err != nil {
+				return "", ErrInvalid
+			}
+			return string(value), nil
+		}
+	}
+	return "", ErrInvalid
+}
+
+// SetEncrypted sets the cookie with an encrypted value, so clients can neither read nor change it.
+func (j *Jar) SetEncrypted(w http.ResponseWriter, cookie *http.Cookie) error {
+	aead := j.keys[0].encrypt
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	// The name is authenticated as well, so a value can't be moved to another cookie
+	sealed := aead.Seal(nonce, nonce, []byte(cookie.Value), []byte(cookie.Name))
+	return j.set(w, cookie, base64.RawURLEncoding.EncodeToString(sealed))
+}
+
+// Encrypted returns the value of a cookie set with SetEncrypted. It returns http.ErrNoCookie when the request doesn't
+// have the cookie and ErrInvalid when it can't be decrypted.
+func (j *Jar) Encrypted(r *http.Request, name string) (string, error) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	for _, key := range j.keys {
+		nonceSize := key.encrypt.NonceSize()
+		if len(sealed) < nonceSize {
+			return "", ErrInvalid
+		}
+		if value, err := key.encrypt.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(name)); err == nil {
+			return string(value), nil
+		}
+	}
+	return "", ErrInvalid
+}
+
+// Delete removes the cookie from the client, the path has to match the one it was set with.
+func (j *Jar) Delete(w http.ResponseWriter, cookie *http.Cookie) {
+	deleted := *cookie
+	deleted.Value = ""
+	deleted.MaxAge = -1
+	j.set(w, &deleted, "")
+}
+
+func (j *Jar) set(w http.ResponseWriter, cookie *http.Cookie, value string) error {
+	c := *cookie
+	c.Value = value
+	c.HttpOnly = true
+	c.Secure = !j.config.Insecure
+	if c.SameSite == 0 {
+		c.SameSite = j.config.SameSite
+	}
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if len(c.String()) > maxSize {
+		return ErrTooLarge
+	}
+	http.SetCookie(w, &c)
+	return nil
+}
+
+// Set sets a cookie that doesn't need to be signed or encrypted with the secure defaults: HttpOnly, SameSite Lax and
+// the path "/", and Secure when the request was made over HTTPS.
+func Set(w http.ResponseWriter, r *http.Request, cookie *http.Cookie) {
+	c := *cookie
+	c.HttpOnly = true
+	c.Secure = c.Secure || r.TLS != nil
+	if c.SameSite == 0 {
+		c.SameSite = http.SameSiteLaxMode
+	}
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	http.SetCookie(w, &c)
+}
+
+// sign includes the name of the cookie, so a value can't be moved to another cookie
+func sign(key []byte, name string, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name + "=" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,141 @@
+package cookies_test
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router/cookies"
+)
+
+var (
+	oldKey = []byte(strings.Repeat("o", cookies.KeySize))
+	newKey = []byte(strings.Repeat("n", cookies.KeySize))
+)
+
+// roundTrip sets a cookie with set and returns a request that sends it back
+func roundTrip(t *testing.T, set func(w http.ResponseWriter) error) (*http.Request, *http.Cookie) {
+	w := httptest.NewRecorder()
+	if err := set(w); err != nil {
+		t.Fatalf("Failed to set the cookie: %v", err)
+	}
+	cookie := w.Result().Cookies()[0]
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	return req, cookie
+}
+
+func TestSigned(t *testing.T) {
+	jar, err := cookies.New(cookies.Config{Keys: [][]byte{newKey}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, cookie := roundTrip(t, func(w http.ResponseWriter) error {
+		return jar.SetSigned(w, &http.Cookie{Name: "variant", Value: "b"})
+	})
+	if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" {
+		t.Errorf("Expected the secure defaults, got %+v", cookie)
+	}
+	if value, err := jar.Signed(req, "variant"); err != nil || value != "b" {
+		t.Errorf("Expected b, got %q (%v)", value, err)
+	}
+
+	// Define test cases
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"changed value", "Yw." + strings.Split(cookie.Value, ".")[1]},
+		{"missing signature", strings.Split(cookie.Value, ".")[0]},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "variant", Value: tt.value})
+		if _, err := jar.Signed(req, "variant"); !errors.Is(err, cookies.ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", tt.name, err)
+		}
+	}
+
+	// A value can't be moved to another cookie
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "role", Value: cookie.Value})
+	if _, err := jar.Signed(req, "role"); !errors.Is(err, cookies.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a moved value, got %v", err)
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	jar, err := cookies.New(cookies.Config{Keys: [][]byte{newKey}, Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, cookie := roundTrip(t, func(w http.ResponseWriter) error {
+		return jar.SetEncrypted(w, &http.Cookie{Name: "session", Value: "user=alice"})
+	})
+	if strings.Contains(cookie.Value, "alice") || cookie.Secure {
+		t.Errorf("Expected an encrypted, insecure cookie, got %+v", cookie)
+	}
+	if value, err := jar.Encrypted(req, "session"); err != nil || value != "user=alice" {
+		t.Errorf("Expected user=alice, got %q (%v)", value, err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, err := jar.Encrypted(req, "session"); !errors.Is(err, http.ErrNoCookie) {
+		t.Errorf("Expected http.ErrNoCookie, got %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	if err := jar.SetEncrypted(w, &http.Cookie{Name: "session", Value: strings.Repeat("x", 4096)}); !errors.Is(err, cookies.ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldJar, _ := cookies.New(cookies.Config{Keys: [][]byte{oldKey}})
+	rotatedJar, _ := cookies.New(cookies.Config{Keys: [][]byte{newKey, oldKey}})
+	newJar, _ := cookies.New(cookies.Config{Keys: [][]byte{newKey}})
+
+	req, _ := roundTrip(t, func(w http.ResponseWriter) error {
+		return oldJar.SetEncrypted(w, &http.Cookie{Name: "session", Value: "alice"})
+	})
+	if value, err := rotatedJar.Encrypted(req, "session"); err != nil || value != "alice" {
+		t.Errorf("Expected the rotated jar to read cookies of the old key, got %q (%v)", value, err)
+	}
+	if _, err := newJar.Encrypted(req, "session"); !errors.Is(err, cookies.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid once the old key is removed, got %v", err)
+	}
+
+	req, _ = roundTrip(t, func(w http.ResponseWriter) error {
+		return oldJar.SetSigned(w, &http.Cookie{Name: "variant", Value: "a"})
+	})
+	if value, err := rotatedJar.Signed(req, "variant"); err != nil || value != "a" {
+		t.Errorf("Expected the rotated jar to verify cookies of the old key, got %q (%v)", value, err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	w := httptest.NewRecorder()
+	cookies.Set(w, req, &http.Cookie{Name: "variant", Value: "a"})
+
+	cookie := w.Result().Cookies()[0]
+	if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" {
+		t.Errorf("Expected the secure defaults, got %+v", cookie)
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	if _, err := cookies.New(cookies.Config{}); err == nil {
+		t.Error("Expected an error without keys")
+	}
+	if _, err := cookies.New(cookies.Config{Keys: [][]byte{[]byte("short")}}); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Deprecation describes when a deprecated route stops working, and where clients can read about it.
+type Deprecation struct {
+	Sunset time.Time
+	Link   string
+}
+
+// Deprecated marks the route as deprecated. Responses get the Deprecation header, and the Sunset and Link headers
+// when the sunset time or link are set. The deprecation is stored as "deprecated" metadata, so response hooks can
+// tag metrics of deprecated routes, and route manifests include it.
+func (r *Route) Deprecated(sunset time.Time, link string) *Route {
+	return r.Set("deprecated", Deprecation{Sunset: sunset, Link: link})
+}
+
+// Deprecation returns the deprecation of the route, if it's deprecated.
+func (r *Route) Deprecation() (Deprecation, bool) {
+	value, ok := r.Get("deprecated")
+	if !ok {
+		return Deprecation{}, false
+	}
+	deprecation, _ := value.(Deprecation)
+	return deprecation, true
+}
+
+func deprecationHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	deprecation, ok := route.Deprecation()
+	if !ok {
+		return handler
+	}
+
+	var sunset, link string
+	if !deprecation.Sunset.IsZero() {
+		sunset = deprecation.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if deprecation.Link != "" {
+		link = fmt.Sprintf("<%s>; rel=\"deprecation\"", deprecation.Link)
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		if sunset != "" {
+			w.Header().Set("Sunset", sunset)
+		}
+		if link != "" {
+			w.Header().Add("Link", link)
+		}
+		handler(w, req)
+	}
+}
@@ -0,0 +1,78 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestDeprecated(t *testing.T) {
+	sunset := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var deprecatedResponses int
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.OnResponse(func(info router.ResponseInfo) {
+		if _, ok := info.Route.Deprecation(); ok {
+			deprecatedResponses++
+		}
+	})
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next(w, r)
+		}
+	})
+
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/v1/users", noop).Deprecated(sunset, "https://example.com/docs/v2-migration")
+	r.GET("/v1/orders", noop).Deprecated(time.Time{}, "")
+	r.GET("/v2/users", noop)
+
+	// Define test cases
+	tests := []struct {
+		path          string
+		authorization string
+		deprecation   string
+		sunset        string
+		link          string
+	}{
+		{"/v1/users/", "token", "true", "Tue, 01 Jan 2030 00:00:00 GMT", `<https://example.com/docs/v2-migration>; rel="deprecation"`},
+		{"/v1/users/", "", "true", "Tue, 01 Jan 2030 00:00:00 GMT", `<https://example.com/docs/v2-migration>; rel="deprecation"`},
+		{"/v1/orders/", "token", "true", "", ""},
+		{"/v2/users/", "token", "", "", ""},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Deprecation"); got != tc.deprecation {
+			t.Errorf("%s: expected Deprecation %q, got %q", tc.path, tc.deprecation, got)
+		}
+		if got := rr.Header().Get("Sunset"); got != tc.sunset {
+			t.Errorf("%s: expected Sunset %q, got %q", tc.path, tc.sunset, got)
+		}
+		if got := rr.Header().Get("Link"); got != tc.link {
+			t.Errorf("%s: expected Link %q, got %q", tc.path, tc.link, got)
+		}
+	}
+
+	if deprecatedResponses != 3 {
+		t.Errorf("Expected 3 responses of deprecated routes, got %d", deprecatedResponses)
+	}
+
+	if !r.Manifest().Routes[0].Deprecated {
+		t.Error("Expected the route to be deprecated in the manifest")
+	}
+}
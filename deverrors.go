@@ -0,0 +1,203 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"runtime"
+	"strings"
+)
+
+const (
+	maxStackFrames     = 32
+	sourceSnippetLines = 3
+)
+
+// PanicError is the error passed to the error handler when a handler panics and the router recovers it.
+type PanicError struct {
+	Value any
+	Stack []StackFrame
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// StackFrame is a frame of the stack trace of a panic, with the surrounding source code when dev errors are enabled
+// and the file is available.
+type StackFrame struct {
+	Function string       `json:"function"`
+	File     string       `json:"file"`
+	Line     int          `json:"line"`
+	Source   []SourceLine `json:"source,omitempty"`
+}
+
+type SourceLine struct {
+	Number  int    `json:"number"`
+	Code    string `json:"code"`
+	Current bool   `json:"current,omitempty"`
+}
+
+// EnableDevErrors shows errors as a page with the error, the stack trace of panics with source snippets, the route
+// and a dump of the request. API clients that don't accept HTML get the same information as JSON.
+// Panics are recovered, so they show up as a page too. Dev errors are never shown when RouterConfig.Env is
+// "production", as they expose the internals of the application.
+func (r *Router) EnableDevErrors() {
+	r.mustBeMutable()
+	r.devErrors = true
+}
+
+func (r *Router) devErrorsEnabled() bool {
+	return r.devErrors && r.config.Env != "production"
+}
+
+// recoverHandler turns panics into a PanicError for the error handler. Once the response was started an error
+// response can't be written anymore, the handler is aborted instead, so the connection is closed and the client sees
+// that the response is truncated, instead of waiting for the rest or taking it as complete.
+func (r *Router) recoverHandler(handler http.HandlerFunc) http.HandlerFunc {
+	if !r.config.Recover && !r.devErrorsEnabled() {
+		return handler
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				err := &PanicError{Value: recovered, Stack: stackTrace(3, r.devErrorsEnabled())}
+				if r.panicReporter != nil {
+					r.panicReporter(req, err)
+				}
+				if rw.statusCode != 0 {
+					r.Logger().Error("router: panic after the response was started", "method", req.Method,
+						"path", req.URL.Path, "bytes", rw.bytesWritten, "error", err)
+					panic(http.ErrAbortHandler)
+				}
+				r.writeError(w, req, http.StatusInternalServerError, err)
+			}
+		}()
+		handler(rw, req)
+	}
+}
+
+// stackTrace returns the frames of the caller, source snippets are only read from disk for the dev error page
+func stackTrace(skip int, withSource bool) []StackFrame {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []StackFrame
+	goroot := runtime.GOROOT()
+	for {
+		frame, more := frames.Next()
+		stackFrame := StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line}
+		if withSource && (goroot == "" || !strings.HasPrefix(frame.File, goroot)) {
+			stackFrame.Source = sourceSnippet(frame.File, frame.Line)
+		}
+		stack = append(stack, stackFrame)
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+func sourceSnippet(file string, line int) []SourceLine {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(data), "\n")
+	var snippet []SourceLine
+	for number := max(line-sourceSnippetLines, 1); number <= min(line+sourceSnippetLines, len(lines)); number++ {
+		snippet = append(snippet, SourceLine{Number: number, Code: lines[number-1], Current: number == line})
+	}
+	return snippet
+}
+
+type devError struct {
+	Status  int          `json:"status"`
+	Title   string       `json:"title"`
+	Error   string       `json:"error,omitempty"`
+	Route   string       `json:"route,omitempty"`
+	Request string       `json:"request"`
+	Stack   []StackFrame `json:"stack,omitempty"`
+}
+
+func (r *Router) writeDevError(w http.ResponseWriter, req *http.Request, status int, err error) {
+	page := devError{Status: status, Title: http.StatusText(status)}
+	if err != nil {
+		page.Error = err.Error()
+		if panicErr, ok := err.(*PanicError); ok {
+			page.Stack = panicErr.Stack
+		}
+	}
+	if route := MatchedRoute(req); route != nil {
+		page.Route = route.FullPattern()
+	}
+	dumped := req.Clone(req.Context())
+	for _, header := range []string{"Authorization", "Cookie"} {
+		if dumped.Header.Get(header) != "" {
+			dumped.Header.Set(header, "[REDACTED]")
+		}
+	}
+	dump, _ := httputil.DumpRequest(dumped, false)
+	page.Request = string(bytes.TrimSpace(dump))
+
+	w.Header().Del("Content-Length")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	Vary(w, "Accept")
+	if !strings.Contains(req.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(page)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	devErrorTemplate.Execute(w, page)
+}
+
+var devErrorTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Status}} {{.Title}}</title>
+<style>
+body { margin: 0; font-family: system-ui, sans-serif; background: #1e1e2e; color: #cdd6f4; }
+header { padding: 24px 32px; background: #f38ba8; color: #1e1e2e; }
+header h1 { margin: 0; font-size: 20px; }
+header p { margin: 8px 0 0; font-family: monospace; font-size: 16px; white-space: pre-wrap; }
+section { padding: 16px 32px; }
+h2 { font-size: 14px; text-transform: uppercase; color: #89b4fa; }
+pre { margin: 0; padding: 12px; background: #181825; border-radius: 4px; overflow-x: auto; }
+.frame { margin-bottom: 16px; }
+.function { color: #f9e2af; font-family: monospace; }
+.file { color: #a6adc8; font-family: monospace; font-size: 12px; }
+.line { display: block; }
+.number { display: inline-block; width: 48px; color: #6c7086; }
+.current { background: #45475a; color: #f38ba8; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Status}} {{.Title}}</h1>
+{{if .Error}}<p>{{.Error}}</p>{{end}}
+</header>
+{{if .Route}}<section><h2>Route</h2><pre>{{.Route}}</pre></section>{{end}}
+{{if .Stack}}<section><h2>Stack trace</h2>
+{{range .Stack}}<div class="frame">
+<div class="function">{{.Function}}</div>
+<div class="file">{{.File}}:{{.Line}}</div>
+{{if .Source}}<pre>{{range .Source}}<span class="line{{if .Current}} current{{end}}"><span class="number">{{.Number}}</span>{{.Code}}</span>{{end}}</pre>{{end}}
+</div>{{end}}
+</section>{{end}}
+<section><h2>Request</h2><pre>{{.Request}}</pre></section>
+</body>
+</html>
+`))
@@ -0,0 +1,159 @@
+package router_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestDevErrors(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.EnableDevErrors()
+
+	r.GET("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	})
+	r.GET("/error", func(w http.ResponseWriter, r *http.Request) {
+		router.Error(w, r, http.StatusBadGateway, errors.New("upstream timed out"))
+	})
+
+	// An HTML page for browsers
+	req := httptest.NewRequest(http.MethodGet, "/panic/", nil)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Authorization", "Bearer secret")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	body := rr.Body.String()
+	for _, expected := range []string{"panic: something went wrong", "GET /panic/{$}", "deverrors_test.go", `panic(&#34;something went wrong&#34;)`} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected the page to contain %q", expected)
+		}
+	}
+	if !strings.Contains(body, "Authorization: [REDACTED]") {
+		t.Error("Expected the Authorization header to be redacted")
+	}
+
+	// JSON for API clients
+	req = httptest.NewRequest(http.MethodGet, "/error/", nil)
+	req.Header.Set("Accept", "application/json")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var page struct {
+		Status  int    `json:"status"`
+		Error   string `json:"error"`
+		Route   string `json:"route"`
+		Request string `json:"request"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Status != http.StatusBadGateway || page.Error != "upstream timed out" || page.Route != "GET /error/{$}" || !strings.HasPrefix(page.Request, "GET /error/ HTTP/1.1") {
+		t.Errorf("Unexpected error %+v", page)
+	}
+
+	// Unmatched requests get a dev error too
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/missing/", nil))
+	if rr.Code != http.StatusNotFound || rr.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON 404, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
+
+func TestDevErrorsProduction(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.SetConfig(router.RouterConfig{Env: "production"})
+	r.EnableDevErrors()
+
+	r.GET("/error", func(w http.ResponseWriter, r *http.Request) {
+		router.Error(w, r, http.StatusInternalServerError, errors.New("database password is hunter2"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/error/", nil)
+	req.Header.Set("Accept", "text/html")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError || strings.Contains(rr.Body.String(), "hunter2") {
+		t.Errorf("Expected the default error in production, got %d %q", rr.Code, rr.Body.String())
+	}
+}
+
+func TestRecoverMidStream(t *testing.T) {
+	reported := make(chan *router.PanicError, 1)
+	responses := make(chan router.ResponseInfo, 1)
+
+	// Create a new router instance
+	r := router.NewRouter(
+		router.WithMux(http.NewServeMux()),
+		router.WithEnv(router.EnvProduction),
+		router.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		router.WithPanicReporter(func(r *http.Request, err *router.PanicError) {
+			reported <- err
+		}),
+	)
+	r.OnResponse(func(info router.ResponseInfo) {
+		responses <- info
+	})
+	r.GET("/before", func(w http.ResponseWriter, r *http.Request) {
+		panic("before the response")
+	})
+	r.GET("/stream", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "first chunk\n")
+		http.NewResponseController(w).Flush()
+		panic("during the response")
+	})
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	// Define test cases
+	tests := []struct {
+		path       string
+		statusCode int
+		body       string
+		truncated  bool
+		panic      string
+	}{
+		{"/before/", http.StatusInternalServerError, "Internal Server Error\n", false, "before the response"},
+		{"/stream/", http.StatusOK, "first chunk\n", true, "during the response"},
+	}
+
+	for _, tc := range tests {
+		resp, err := http.Get(server.URL + tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.statusCode || string(body) != tc.body {
+			t.Errorf("%s: expected %d %q, got %d %q", tc.path, tc.statusCode, tc.body, resp.StatusCode, body)
+		}
+		if (err != nil) != tc.truncated {
+			t.Errorf("%s: expected truncated %v, got error %v", tc.path, tc.truncated, err)
+		}
+		panicErr := <-reported
+		if panicErr.Value != tc.panic {
+			t.Errorf("%s: expected the panic %q to be reported, got %v", tc.path, tc.panic, panicErr.Value)
+		}
+		if len(panicErr.Stack) == 0 || panicErr.Stack[0].Source != nil {
+			t.Errorf("%s: expected a stack trace without source snippets in production", tc.path)
+		}
+		if info := <-responses; info.Aborted != tc.truncated {
+			t.Errorf("%s: expected the response hook to get aborted %v, got %v", tc.path, tc.truncated, info.Aborted)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// errResponseTooLarge is returned to handlers writing more than the maximum size of a recorded response
+var errResponseTooLarge = errors.New("router: response too large")
+
+// Response is the response to a request that was dispatched in-process.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Dispatch handles a request in-process, without a network hop: it's matched and runs through the middlewares and
+// handler of its route like a request of a client, e.g. to compose a response of several routes or to test the full
+// chain of a route. The request has the values of the context. It returns an error when the method or path are
+// invalid, errors of the route are in the status code of the response.
+//
+//	resp, err := r.Dispatch(ctx, http.MethodGet, "/users/1/", nil)
+func (r *Router) Dispatch(ctx context.Context, method string, path string, body io.Reader) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	return r.DispatchRequest(req), nil
+}
+
+// DispatchRequest handles the request in-process like Dispatch, e.g. with the headers of another request.
+func (r *Router) DispatchRequest(req *http.Request) *Response {
+	rec := &responseRecorder{header: http.Header{}}
+	r.ServeHTTP(rec, req)
+	return &Response{StatusCode: rec.Status(), Header: rec.header, Body: rec.body.Bytes()}
+}
+
+// responseRecorder keeps the response of a request that is handled in-process
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	// maxBytes limits the body when it's set, truncated reports whether the handler wrote more
+	maxBytes  int64
+	truncated bool
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if rec.status == 0 && statusCode >= 200 {
+		rec.status = statusCode
+	}
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if rec.maxBytes > 0 && int64(rec.body.Len()+len(b)) > rec.maxBytes {
+		rec.truncated = true
+		return 0, errResponseTooLarge
+	}
+	return rec.body.Write(b)
+}
+
+func (rec *responseRecorder) Status() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
@@ -0,0 +1,64 @@
+package router_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+type dispatchContextKey struct{}
+
+func TestDispatch(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Middleware", "true")
+			next(w, r)
+		}
+	})
+	r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "user "+r.PathValue("id")+" for "+r.Context().Value(dispatchContextKey{}).(string))
+	})
+	r.POST("/orders", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+
+	ctx := context.WithValue(context.Background(), dispatchContextKey{}, "acme")
+
+	// Define test cases
+	tests := []struct {
+		method     string
+		path       string
+		body       string
+		statusCode int
+		response   string
+	}{
+		{http.MethodGet, "/users/1/", "", http.StatusOK, "user 1 for acme"},
+		{http.MethodPost, "/orders/", "item=42", http.StatusCreated, "item=42"},
+		{http.MethodGet, "/missing/", "", http.StatusNotFound, "404 page not found\n"},
+	}
+
+	for _, tc := range tests {
+		resp, err := r.Dispatch(ctx, tc.method, tc.path, strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.method, tc.path, err)
+		}
+		if resp.StatusCode != tc.statusCode || string(resp.Body) != tc.response {
+			t.Errorf("%s %s: expected %d %q, got %d %q", tc.method, tc.path, tc.statusCode, tc.response, resp.StatusCode, resp.Body)
+		}
+		if resp.StatusCode != http.StatusNotFound && resp.Header.Get("X-Middleware") != "true" {
+			t.Errorf("%s %s: expected the middlewares to run", tc.method, tc.path)
+		}
+	}
+
+	if _, err := r.Dispatch(ctx, "BAD METHOD", "/users/1/", nil); err == nil {
+		t.Error("Expected an error for an invalid method")
+	}
+}
@@ -0,0 +1,73 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// EncodedPathParams is how path parameters with percent-encoded characters are handled, see
+// RouterConfig.EncodedPathParams.
+type EncodedPathParams int
+
+const (
+	// DecodePathParams decodes the path parameters, "/files/a%2Fb" gives "a/b" for {name}. It's the default.
+	DecodePathParams EncodedPathParams = iota
+	// RejectEncodedSlashes responds with 400 to requests with an encoded slash in a path parameter, as it can be
+	// mistaken for a path separator after decoding
+	RejectEncodedSlashes
+	// RawPathParams keeps the path parameters encoded, "/files/a%2Fb" gives "a%2Fb" for {name}
+	RawPathParams
+)
+
+// ErrEncodedSlash is the error of requests that are rejected by RejectEncodedSlashes.
+var ErrEncodedSlash = errors.New("router: encoded slash in path parameter")
+
+// RawPathValue returns the path parameter as it was sent, without decoding it, e.g. "a%2Fb" instead of "a/b". It
+// returns "" when the request didn't match a route with the parameter.
+func RawPathValue(r *http.Request, name string) string {
+	if r.Pattern == "" {
+		return ""
+	}
+	patternSegments := patternSegments(r.Pattern)
+	pathSegments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+	for i, segment := range patternSegments {
+		if i >= len(pathSegments) {
+			break
+		}
+		switch segment {
+		case "{" + name + "}":
+			return pathSegments[i]
+		case "{" + name + "...}":
+			return strings.Join(pathSegments[i:], "/")
+		}
+	}
+	return ""
+}
+
+// encodedParamsHandler applies RouterConfig.EncodedPathParams to the path parameters of the route
+func (r *Router) encodedParamsHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	mode := r.config.EncodedPathParams
+	names := route.ParamNames()
+	if mode == DecodePathParams || len(names) == 0 {
+		return handler
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		for _, name := range names {
+			raw := RawPathValue(req, name)
+			if !strings.Contains(raw, "%") {
+				continue
+			}
+			if mode == RawPathParams {
+				req.SetPathValue(name, raw)
+				continue
+			}
+			// Remainder wildcards contain real slashes, only encoded ones are rejected
+			if strings.Contains(strings.ToUpper(raw), "%2F") {
+				Error(w, req, http.StatusBadRequest, ErrEncodedSlash)
+				return
+			}
+		}
+		handler(w, req)
+	}
+}
@@ -0,0 +1,53 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestEncodedPathParams(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s", r.PathValue("name"), router.RawPathValue(r, "name"))
+	}
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		mode   router.EncodedPathParams
+		path   string
+		status int
+		body   string
+	}{
+		{"decoded", router.DecodePathParams, "/api/packages/%40scope%2Fpkg/", http.StatusOK, "@scope/pkg|%40scope%2Fpkg"},
+		{"rejected", router.RejectEncodedSlashes, "/api/packages/%40scope%2fpkg/", http.StatusBadRequest, ""},
+		{"other escapes are allowed", router.RejectEncodedSlashes, "/api/packages/%40scope/", http.StatusOK, "@scope|%40scope"},
+		{"raw", router.RawPathParams, "/api/packages/%40scope%2Fpkg/", http.StatusOK, "%40scope%2Fpkg|%40scope%2Fpkg"},
+		{"remainder decoded", router.DecodePathParams, "/api/files/a/b%20c", http.StatusOK, "a/b c|a/b%20c"},
+		{"remainder with real slashes", router.RejectEncodedSlashes, "/api/files/a/b%20c", http.StatusOK, "a/b c|a/b%20c"},
+		{"remainder raw", router.RawPathParams, "/api/files/a/b%20c", http.StatusOK, "a/b%20c|a/b%20c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new router instance
+			r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithConfig(router.RouterConfig{EncodedPathParams: tt.mode}))
+			r.Group("/api", func(r *router.Router) {
+				r.GET("/packages/{name}", handler)
+				r.GET("/files/{name...}", handler)
+			})
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+			if tt.status == http.StatusOK && rr.Body.String() != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, rr.Body.String())
+			}
+		})
+	}
+}
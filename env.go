@@ -0,0 +1,14 @@
+package router
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// NewRouterWithEnv creates a router with defaults for the environment. In development errors are shown as dev error
+// pages and the log level is debug. In production panics are recovered with a plain 500 response and the log level
+// is warn. Other environments get the defaults of NewRouter. The defaults can be changed afterwards, e.g. by
+// changing the result of Config and passing it to SetConfig.
+func NewRouterWithEnv(env string) *Router {
+	return NewRouter(WithEnv(env))
+}
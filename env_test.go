@@ -0,0 +1,50 @@
+package router_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestNewRouterWithEnv(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		env        string
+		logLevel   slog.Level
+		devErrors  bool
+		statusCode int
+	}{
+		{router.EnvDevelopment, slog.LevelDebug, true, http.StatusInternalServerError},
+		{router.EnvProduction, slog.LevelWarn, false, http.StatusInternalServerError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.env, func(t *testing.T) {
+			// Create a new router instance
+			r := router.NewRouterWithEnv(tc.env)
+			r.GET("/panic", func(w http.ResponseWriter, r *http.Request) {
+				panic("something went wrong")
+			})
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/panic/", nil))
+
+			if rr.Code != tc.statusCode {
+				t.Errorf("Expected status code %d, got %d", tc.statusCode, rr.Code)
+			}
+			if devError := strings.Contains(rr.Body.String(), "something went wrong"); devError != tc.devErrors {
+				t.Errorf("Expected dev error %v, got body %q", tc.devErrors, rr.Body.String())
+			}
+			if r.LogLevel().Level() != tc.logLevel {
+				t.Errorf("Expected log level %s, got %s", tc.logLevel, r.LogLevel().Level())
+			}
+			if r.Config().Env != tc.env {
+				t.Errorf("Expected env %q, got %q", tc.env, r.Config().Env)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package router
+
+import (
+	"net/http"
+)
+
+// ErrorHandlerFunc writes an error response, err can be nil when there is nothing more to say than the status code.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, status int, err error)
+
+// SetErrorHandler sets the handler used by Error, and for 404 and 405 responses when no specific handlers are set.
+func (r *Router) SetErrorHandler(handler ErrorHandlerFunc) {
+	r.mustBeMutable()
+	r.errorHandler = handler
+}
+
+// NotFound sets the handler for requests that don't match any route.
+func (r *Router) NotFound(handler http.HandlerFunc) {
+	r.mustBeMutable()
+	r.notFoundHandler = handler
+}
+
+// MethodNotAllowed sets the handler for requests that match a route, but not its method.
+// The Allow header is set before the handler is called.
+func (r *Router) MethodNotAllowed(handler http.HandlerFunc) {
+	r.mustBeMutable()
+	r.methodNotAllowedHandler = handler
+}
+
+// Error writes an error response using the error handler of the router that matched the request. Middlewares and
+// handlers should use it instead of http.Error, so the router can render all errors the same way.
+func Error(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if router := routerFromRequest(r); router != nil {
+		router.writeError(w, r, status, err)
+		return
+	}
+	defaultErrorHandler(w, r, status, err)
+}
+
+func (r *Router) writeError(w http.ResponseWriter, req *http.Request, status int, err error) {
+	if r.devErrorsEnabled() {
+		r.writeDevError(w, req, status, err)
+		return
+	}
+	if r.errorHandler != nil {
+		r.errorHandler(w, req, status, err)
+		return
+	}
+	defaultErrorHandler(w, req, status, err)
+}
+
+func defaultErrorHandler(w http.ResponseWriter, r *http.Request, status int, err error) {
+	// Only client errors include the message, server errors could leak internals
+	if err != nil && status < http.StatusInternalServerError {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+func (r *Router) hasUnmatchedHandlers() bool {
+	return r.errorHandler != nil || r.devErrorsEnabled() || r.notFoundHandler != nil || r.methodNotAllowedHandler != nil
+}
+
+// serveUnmatched serves a request the mux has no route for. The mux either redirects (e.g. to add a trailing slash),
+// or responds with 404 or 405, which are replaced by the handlers of the router.
+func (r *Router) serveUnmatched(w http.ResponseWriter, req *http.Request, handler http.Handler) {
+	interceptor := &unmatchedWriter{ResponseWriter: w}
+	handler.ServeHTTP(interceptor, req)
+
+	switch interceptor.status {
+	case http.StatusNotFound:
+		if r.notFoundHandler != nil {
+			r.notFoundHandler(w, req)
+			return
+		}
+		r.writeError(w, req, http.StatusNotFound, nil)
+	case http.StatusMethodNotAllowed:
+		w.Header().Set("Allow", interceptor.Header().Get("Allow"))
+		if r.methodNotAllowedHandler != nil {
+			r.methodNotAllowedHandler(w, req)
+			return
+		}
+		r.writeError(w, req, http.StatusMethodNotAllowed, nil)
+	}
+}
+
+// compileUnmatched wraps the handling of requests without a route with the global middlewares. The request gets the
+// router in its context without a route, so router.Error uses the error handler of the router.
+func (r *Router) compileUnmatched() http.HandlerFunc {
+	rc := &routeContext{router: r}
+	handler := applyMiddlewares(func(w http.ResponseWriter, req *http.Request) {
+		// The middlewares may have changed the request, so it's matched again
+		handler, _ := r.matcher.Handler(req)
+		if r.hasUnmatchedHandlers() {
+			r.serveUnmatched(w, req, handler)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	}, r.middlewares...)
+	return func(w http.ResponseWriter, req *http.Request) {
+		req, c := withRouteContext(req, rc)
+		defer c.startJobs()
+		handler(w, req)
+	}
+}
+
+// unmatchedWriter passes through everything, except 404 and 405 responses of the mux.
+type unmatchedWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (uw *unmatchedWriter) WriteHeader(status int) {
+	if status == http.StatusNotFound || status == http.StatusMethodNotAllowed {
+		uw.status = status
+		// The mux sets these for its plain text body
+		uw.ResponseWriter.Header().Del("Content-Type")
+		uw.ResponseWriter.Header().Del("X-Content-Type-Options")
+		return
+	}
+	uw.ResponseWriter.WriteHeader(status)
+}
+
+func (uw *unmatchedWriter) Write(b []byte) (int, error) {
+	if uw.status != 0 {
+		return len(b), nil
+	}
+	return uw.ResponseWriter.Write(b)
+}
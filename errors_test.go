@@ -0,0 +1,152 @@
+package router_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestErrorHandlers(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, status int, err error) {
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "error handler: %d %v", status, err)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte("method not allowed, use " + w.Header().Get("Allow")))
+	})
+
+	r.GET("/users", func(w http.ResponseWriter, req *http.Request) {
+		router.Error(w, req, http.StatusForbidden, errors.New("not yours"))
+	})
+
+	// Define test cases for each kind of error
+	tests := []struct {
+		method     string
+		path       string
+		statusCode int
+		response   string
+	}{
+		{http.MethodGet, "/users/", http.StatusForbidden, "error handler: 403 not yours"},
+		{http.MethodGet, "/unknown/", http.StatusNotFound, "error handler: 404 <nil>"},
+		{http.MethodPost, "/users/", http.StatusMethodNotAllowed, "method not allowed, use GET, HEAD"},
+		// The mux redirects to the path with a trailing slash, which should not be intercepted
+		{http.MethodGet, "/users", http.StatusTemporaryRedirect, "<a href=\"/users/\">Temporary Redirect</a>.\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.statusCode)
+			}
+			if body := rr.Body.String(); body != tt.response {
+				t.Errorf("handler returned unexpected body: got %q want %q", body, tt.response)
+			}
+		})
+	}
+}
+
+func TestDefaultErrorHandler(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/client", func(w http.ResponseWriter, req *http.Request) {
+		router.Error(w, req, http.StatusBadRequest, errors.New("missing name"))
+	})
+	r.GET("/server", func(w http.ResponseWriter, req *http.Request) {
+		router.Error(w, req, http.StatusInternalServerError, errors.New("database password is hunter2"))
+	})
+
+	tests := []struct {
+		path     string
+		response string
+	}{
+		{"/client/", "missing name\n"},
+		{"/server/", "Internal Server Error\n"},
+		{"/unknown/", "404 page not found\n"},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if body := rr.Body.String(); body != tt.response {
+			t.Errorf("%s returned unexpected body: got %q want %q", tt.path, body, tt.response)
+		}
+	}
+}
+
+func TestUnmatchedMiddlewares(t *testing.T) {
+	requestID := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", "1")
+			if router.MatchedRoute(r) == nil {
+				w.Header().Set("X-Unmatched", "true")
+			}
+			next(w, r)
+		}
+	}
+	cors := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next(w, r)
+		}
+	}
+
+	// Define test cases
+	tests := []struct {
+		name      string
+		unmatched bool
+		handlers  bool
+		method    string
+		path      string
+		status    int
+		requestID string
+	}{
+		{"matched", false, false, http.MethodGet, "/users/", http.StatusOK, "1"},
+		{"not found without the option", false, false, http.MethodGet, "/posts/", http.StatusNotFound, ""},
+		{"not found", true, false, http.MethodGet, "/posts/", http.StatusNotFound, "1"},
+		{"not found with error handler", true, true, http.MethodGet, "/posts/", http.StatusTeapot, "1"},
+		{"method not allowed", true, false, http.MethodDelete, "/users/", http.StatusMethodNotAllowed, "1"},
+		{"preflight of an unknown path", true, false, http.MethodOptions, "/posts/", http.StatusNoContent, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new router instance
+			r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithConfig(router.RouterConfig{UnmatchedMiddlewares: tt.unmatched}))
+			r.Use(requestID, cors)
+			if tt.handlers {
+				r.SetErrorHandler(func(w http.ResponseWriter, req *http.Request, status int, err error) {
+					w.WriteHeader(http.StatusTeapot)
+				})
+			}
+			r.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			if rr.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+			if got := rr.Header().Get("X-Request-Id"); got != tt.requestID {
+				t.Errorf("Expected request ID %q, got %q", tt.requestID, got)
+			}
+			if unmatched := rr.Header().Get("X-Unmatched") == "true"; unmatched != (tt.requestID != "" && tt.status != http.StatusOK) {
+				t.Errorf("Expected MatchedRoute to be nil only for unmatched requests, got %v", unmatched)
+			}
+		})
+	}
+}
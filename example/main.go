@@ -46,12 +46,9 @@ func main() {
 	r.Group("/api/users", func(r *router.Router) {
 		r.GET("/", usersListHandler)
 		r.GET("/{id}", usersGetHandler)
-		r.GET("/create", usersCreateHandler)
-		r.POST("/create", usersStoreHandler)
-		r.GET("{id}/edit", usersEditHandler)
-		r.POST("{id}/edit", usersUpdateHandler)
-		r.GET("{id}/delete", usersDeleteHandler)
-		r.POST("{id}/delete", usersDeletePerformHandler)
+		r.Route("/create").GET(usersCreateHandler).POST(usersStoreHandler)
+		r.Route("{id}/edit").GET(usersEditHandler).POST(usersUpdateHandler)
+		r.Route("{id}/delete").GET(usersDeleteHandler).POST(usersDeletePerformHandler)
 	})
 
 	err := http.ListenAndServe(":8000", r)
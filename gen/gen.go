@@ -0,0 +1,224 @@
+// Package gen generates typed clients for the routes of a router, so clients stay in lockstep with the registered
+// routes. The request and response types of a route are declared with Route.Types. It's meant to be run from a small
+// program with go:generate:
+//
+//	//go:generate go run ./cmd/client
+//
+//	func main() {
+//		if err := gen.File("client/client.go", app.Routes(), gen.Config{Package: "client"}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gogo-framework/router"
+)
+
+type Config struct {
+	// Package is the package name of the generated Go client, defaults to "client"
+	Package string
+}
+
+// endpoint is a route a client method is generated for
+type endpoint struct {
+	name     string
+	method   string
+	pattern  string
+	path     string
+	params   []string
+	request  reflect.Type
+	response reflect.Type
+}
+
+// File writes a client for the routes to the file, a TypeScript client for ".ts" files and a Go client otherwise.
+func File(path string, r *router.Router, config Config) error {
+	var buf bytes.Buffer
+	var err error
+	if filepath.Ext(path) == ".ts" {
+		err = TypeScript(&buf, r, config)
+	} else {
+		err = Go(&buf, r, config)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// endpoints returns the routes a client method can be generated for. Mounted handlers, routes without a method and
+// the admin and development routes of the router are skipped.
+func endpoints(r *router.Router) ([]endpoint, error) {
+	manifest := r.Manifest()
+	names := make(map[string]string)
+
+	var result []endpoint
+	// The manifest lists the routes in the same order as Routes
+	for i, route := range r.Routes() {
+		if route.Method == "" || route.HandlerFunc == nil {
+			continue
+		}
+		if _, ok := route.Get("admin"); ok {
+			continue
+		}
+		if _, ok := route.Get("devtools"); ok {
+			continue
+		}
+
+		manifestRoute := manifest.Routes[i]
+		e := endpoint{
+			method:  route.Method,
+			pattern: manifestRoute.String(),
+			// The client requests the path as it's registered on the mux, to prevent redirects
+			path:   strings.TrimSuffix(r.SanitizePath(manifestRoute.Path), "{$}"),
+			params: route.ParamNames(),
+		}
+		if manifestRoute.Name != "" {
+			e.name = exportedName(manifestRoute.Name)
+		} else {
+			e.name = exportedName(strings.ToLower(route.Method) + " " + manifestRoute.Path)
+		}
+		if previous, ok := names[e.name]; ok {
+			return nil, fmt.Errorf("gen: %s and %s both generate the method %s, name one of the routes", previous, e.pattern, e.name)
+		}
+		names[e.name] = e.pattern
+
+		if value, ok := route.Get("types"); ok {
+			if types, ok := value.(router.RouteTypes); ok {
+				if types.Request != nil {
+					e.request = reflect.TypeOf(types.Request)
+				}
+				if types.Response != nil {
+					e.response = reflect.TypeOf(types.Response)
+				}
+			}
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// exportedName turns a route name or path into an exported identifier, e.g. "users.show" into "UsersShow"
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	result := b.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "Route" + result
+	}
+	return result
+}
+
+// reservedNames are the identifiers used in the bodies of the generated methods, and the reserved words of
+// TypeScript that aren't Go keywords
+var reservedNames = map[string]bool{
+	"c": true, "ctx": true, "body": true, "result": true, "url": true, "time": true,
+	"await": true, "catch": true, "class": true, "debugger": true, "delete": true, "do": true, "enum": true,
+	"export": true, "extends": true, "finally": true, "function": true, "implements": true, "in": true,
+	"instanceof": true, "let": true, "new": true, "private": true, "protected": true, "public": true, "static": true,
+	"super": true, "this": true, "throw": true, "try": true, "typeof": true, "void": true, "while": true, "with": true,
+	"yield": true,
+}
+
+// paramName turns a path parameter into an identifier, e.g. "user-id" into "userID". Keywords, predeclared
+// identifiers like "string" and the reserved names get a "Param" suffix, e.g. "type" becomes "typeParam".
+func paramName(name string) string {
+	exported := exportedName(name)
+	identifier := strings.ToLower(exported[:1]) + exported[1:]
+	if token.IsKeyword(identifier) || types.Universe.Lookup(identifier) != nil || reservedNames[identifier] {
+		return identifier + "Param"
+	}
+	return identifier
+}
+
+// jsonField returns the JSON name of a struct field and whether it's omitted when empty, or false for skipped fields
+func jsonField(field reflect.StructField) (name string, omitEmpty bool, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty") || strings.Contains(options, "omitzero"), true
+}
+
+// collectTypes returns the named types used by the endpoints in the order they're first used
+func collectTypes(endpoints []endpoint) []reflect.Type {
+	seen := make(map[reflect.Type]bool)
+	var types []reflect.Type
+	var visit func(t reflect.Type)
+	visit = func(t reflect.Type) {
+		if t == nil || seen[t] {
+			return
+		}
+		if t.Name() != "" && t.PkgPath() != "" && !isTime(t) {
+			seen[t] = true
+			types = append(types, t)
+		}
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			visit(t.Elem())
+		case reflect.Map:
+			visit(t.Key())
+			visit(t.Elem())
+		case reflect.Struct:
+			if isTime(t) {
+				return
+			}
+			for _, field := range structFields(t) {
+				visit(field.Type)
+			}
+		}
+	}
+	for _, e := range endpoints {
+		visit(e.request)
+		visit(e.response)
+	}
+	return types
+}
+
+// structFields returns the fields that are encoded as JSON, the fields of embedded structs are promoted
+func structFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			fields = append(fields, structFields(field.Type)...)
+			continue
+		}
+		if _, _, ok := jsonField(field); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func isTime(t reflect.Type) bool {
+	return t.PkgPath() == "time" && t.Name() == "Time"
+}
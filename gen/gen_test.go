@@ -0,0 +1,150 @@
+package gen_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/gen"
+)
+
+type Status string
+
+type Address struct {
+	City string `json:"city"`
+}
+
+type User struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Address   *Address  `json:"address,omitempty"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	password  string
+}
+
+type CreateUser struct {
+	Name string `json:"name"`
+}
+
+func newRouter() *router.Router {
+	r := router.NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/users", handler).Types(nil, []User{})
+	r.POST("/users", handler).Types(CreateUser{}, User{}).Name("users.create")
+	r.Group("/users", func(r *router.Router) {
+		r.GET("/{id}", handler).Types(nil, User{}).Name("users.show")
+		r.DELETE("/{id}", handler).Name("users.delete")
+	})
+	r.Mount("/static", http.NotFoundHandler())
+	r.EnableAdmin("/__router", router.AdminConfig{Token: "secret"})
+	return r
+}
+
+func TestGo(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gen.Go(&buf, newRouter(), gen.Config{Package: "users"}); err != nil {
+		t.Fatalf("Failed to generate the Go client: %v", err)
+	}
+	source := buf.String()
+
+	// Define test cases
+	expected := []string{
+		"package users",
+		"type User struct {",
+		"Status    Status    `json:\"status\"`",
+		"type Status string",
+		"type Address struct {",
+		"func (c *Client) GetUsers(ctx context.Context) ([]User, error) {",
+		"func (c *Client) UsersCreate(ctx context.Context, body CreateUser) (*User, error) {",
+		`return nil, err`,
+		"func (c *Client) UsersShow(ctx context.Context, id string) (*User, error) {",
+		`c.do(ctx, "GET", "/users/"+url.PathEscape(id)+"/", nil, &result)`,
+		"func (c *Client) UsersDelete(ctx context.Context, id string) error {",
+	}
+	for _, want := range expected {
+		if !strings.Contains(source, want) {
+			t.Errorf("Expected the client to contain %q, got:\n%s", want, source)
+		}
+	}
+	for _, unexpected := range []string{"password", "Static", "Routes(", "Maintenance"} {
+		if strings.Contains(source, unexpected) {
+			t.Errorf("Expected the client not to contain %q", unexpected)
+		}
+	}
+}
+
+func TestTypeScript(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gen.TypeScript(&buf, newRouter(), gen.Config{}); err != nil {
+		t.Fatalf("Failed to generate the TypeScript client: %v", err)
+	}
+	source := buf.String()
+
+	// Define test cases
+	expected := []string{
+		"export interface User {",
+		`"address"?: Address | null;`,
+		`"tags": string[];`,
+		`"created_at": string;`,
+		"export type Status = string;",
+		"getUsers(): Promise<User[]> {",
+		"usersCreate(body: CreateUser): Promise<User> {",
+		"return this.request<User>(\"GET\", `/users/${encodeURIComponent(id)}/`);",
+		"usersDelete(id: string): Promise<void> {",
+	}
+	for _, want := range expected {
+		if !strings.Contains(source, want) {
+			t.Errorf("Expected the client to contain %q, got:\n%s", want, source)
+		}
+	}
+}
+
+func TestDuplicateMethods(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/users", handler).Name("users")
+	r.GET("/all-users", handler).Name("users")
+
+	if err := gen.Go(&bytes.Buffer{}, r, gen.Config{}); err == nil {
+		t.Error("Expected an error for routes generating the same method")
+	}
+}
+
+func TestGoReservedParams(t *testing.T) {
+	// Create a new router instance with parameters named like keywords, predeclared identifiers and locals
+	r := router.NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	r.PUT("/things/{type}/{ctx}/{c}/{result}/{url}/{string}/{nil}/{new}", handler).Types(CreateUser{}, User{}).Name("things.update")
+	r.GET("/clocks/{body}/{time}", handler).Types(nil, time.Time{}).Name("clocks.show")
+	r.GET("/files/{func}/{path...}", handler).Types(nil, []string{}).Name("files.show")
+
+	var buf bytes.Buffer
+	if err := gen.Go(&buf, r, gen.Config{}); err != nil {
+		t.Fatalf("Failed to generate the Go client: %v", err)
+	}
+	if !strings.Contains(buf.String(), "typeParam string, ctxParam string, cParam string, resultParam string") {
+		t.Errorf("Expected the reserved parameters to be renamed, got:\n%s", buf.String())
+	}
+
+	// Type check the client, so shadowed identifiers are caught as well
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "client.go", buf.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("Failed to parse the Go client: %v\n%s", err, buf.String())
+	}
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := config.Check("client", fset, []*ast.File{file}, nil); err != nil {
+		t.Errorf("Expected the Go client to compile, got %v\n%s", err, buf.String())
+	}
+}
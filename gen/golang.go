@@ -0,0 +1,204 @@
+package gen
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gogo-framework/router"
+)
+
+// Go writes a Go client for the routes of the router, with a method per route and the request and response types.
+func Go(w io.Writer, r *router.Router, config Config) error {
+	if config.Package == "" {
+		config.Package = "client"
+	}
+	endpoints, err := endpoints(r)
+	if err != nil {
+		return err
+	}
+
+	g := &goGenerator{imports: map[string]bool{
+		"bytes": true, "context": true, "encoding/json": true, "fmt": true, "io": true, "net/http": true,
+	}}
+	var body strings.Builder
+	for _, t := range collectTypes(endpoints) {
+		if t.Name() == "Client" || t.Name() == "Error" || t.Name() == "New" {
+			return fmt.Errorf("gen: the type %s conflicts with the generated client", t.Name())
+		}
+		fmt.Fprintf(&body, "type %s %s\n\n", t.Name(), g.typeExpr(t, true))
+	}
+	body.WriteString(goClient)
+	for _, e := range endpoints {
+		g.writeMethod(&body, e)
+	}
+
+	var source strings.Builder
+	source.WriteString("// Code generated by gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&source, "package %s\n\nimport (\n", config.Package)
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	slices.Sort(imports)
+	for _, path := range imports {
+		fmt.Fprintf(&source, "\t%q\n", path)
+	}
+	source.WriteString(")\n\n")
+	source.WriteString(body.String())
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return fmt.Errorf("gen: invalid Go client: %w", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+type goGenerator struct {
+	imports map[string]bool
+}
+
+// typeExpr returns the Go type of t, named types are referred to by name unless their definition is requested
+func (g *goGenerator) typeExpr(t reflect.Type, definition bool) string {
+	if isTime(t) {
+		g.imports["time"] = true
+		return "time.Time"
+	}
+	if t.Name() != "" && !definition {
+		return t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + g.typeExpr(t.Elem(), false)
+	case reflect.Slice:
+		return "[]" + g.typeExpr(t.Elem(), false)
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), g.typeExpr(t.Elem(), false))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", g.typeExpr(t.Key(), false), g.typeExpr(t.Elem(), false))
+	case reflect.Interface:
+		return "any"
+	case reflect.Struct:
+		var b strings.Builder
+		b.WriteString("struct {\n")
+		for _, field := range structFields(t) {
+			fmt.Fprintf(&b, "\t%s %s", field.Name, g.typeExpr(field.Type, false))
+			if tag := field.Tag.Get("json"); tag != "" {
+				fmt.Fprintf(&b, " `json:%q`", tag)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}")
+		return b.String()
+	default:
+		// The underlying type of a named basic type, e.g. string for "type Status string"
+		return t.Kind().String()
+	}
+}
+
+func (g *goGenerator) writeMethod(b *strings.Builder, e endpoint) {
+	params := []string{"ctx context.Context"}
+	path := fmt.Sprintf("%q", e.path)
+	for _, name := range e.params {
+		identifier := paramName(name)
+		params = append(params, identifier+" string")
+		if placeholder := "{" + name + "}"; strings.Contains(e.path, placeholder) {
+			g.imports["net/url"] = true
+			path = strings.Replace(path, placeholder, `" + url.PathEscape(`+identifier+`) + "`, 1)
+		} else {
+			// A remainder wildcard can contain slashes, so it isn't escaped
+			path = strings.Replace(path, "{"+name+"...}", `" + `+identifier+` + "`, 1)
+		}
+	}
+	path = strings.TrimSuffix(strings.TrimPrefix(path, `"" + `), ` + ""`)
+
+	body := "nil"
+	if e.request != nil {
+		params = append(params, "body "+g.typeExpr(e.request, false))
+		body = "body"
+	}
+
+	fmt.Fprintf(b, "// %s calls %s\n", e.name, e.pattern)
+	if e.response == nil {
+		fmt.Fprintf(b, "func (c *Client) %s(%s) error {\n", e.name, strings.Join(params, ", "))
+		fmt.Fprintf(b, "\treturn c.do(ctx, %q, %s, %s, nil)\n}\n\n", e.method, path, body)
+		return
+	}
+
+	result := g.typeExpr(e.response, false)
+	returned, value, zero := result, "result", "result"
+	if e.response.Kind() == reflect.Struct {
+		returned, value, zero = "*"+result, "&result", "nil"
+	}
+	fmt.Fprintf(b, "func (c *Client) %s(%s) (%s, error) {\n", e.name, strings.Join(params, ", "), returned)
+	fmt.Fprintf(b, "\tvar result %s\n", result)
+	fmt.Fprintf(b, "\tif err := c.do(ctx, %q, %s, %s, &result); err != nil {\n", e.method, path, body)
+	fmt.Fprintf(b, "\t\treturn %s, err\n\t}\n", zero)
+	fmt.Fprintf(b, "\treturn %s, nil\n}\n\n", value)
+}
+
+const goClient = `// Error is returned when the server responds with a status code of 400 or higher.
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("client: unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+type Client struct {
+	// BaseURL is the URL the paths of the routes are added to, e.g. "https://api.example.com"
+	BaseURL string
+	// HTTPClient is used to send the requests, defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL}
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		data, _ := io.ReadAll(res.Body)
+		return &Error{StatusCode: res.StatusCode, Body: data}
+	}
+	if result == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
+
+`
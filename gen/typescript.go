@@ -0,0 +1,151 @@
+package gen
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gogo-framework/router"
+)
+
+// TypeScript writes a TypeScript client for the routes of the router, using fetch and interfaces for the request and
+// response types.
+func TypeScript(w io.Writer, r *router.Router, config Config) error {
+	endpoints, err := endpoints(r)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by gen. DO NOT EDIT.\n\n")
+	for _, t := range collectTypes(endpoints) {
+		if t.Kind() == reflect.Struct {
+			fmt.Fprintf(&b, "export interface %s %s\n\n", t.Name(), tsTypeExpr(t, true))
+		} else {
+			fmt.Fprintf(&b, "export type %s = %s;\n\n", t.Name(), tsTypeExpr(t, true))
+		}
+	}
+	b.WriteString(tsClient)
+	for _, e := range endpoints {
+		writeTSMethod(&b, e)
+	}
+	b.WriteString("}\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// tsTypeExpr returns the TypeScript type of t, named types are referred to by name unless their definition is
+// requested
+func tsTypeExpr(t reflect.Type, definition bool) string {
+	if isTime(t) {
+		return "string"
+	}
+	if t.Name() != "" && t.PkgPath() != "" && !definition {
+		return t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return tsTypeExpr(t.Elem(), false) + " | null"
+	case reflect.Slice, reflect.Array:
+		// Byte slices are encoded as base64
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		elem := tsTypeExpr(t.Elem(), false)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		return fmt.Sprintf("Record<string, %s>", tsTypeExpr(t.Elem(), false))
+	case reflect.Interface:
+		return "unknown"
+	case reflect.Struct:
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, field := range structFields(t) {
+			name, omitEmpty, _ := jsonField(field)
+			optional := ""
+			if omitEmpty {
+				optional = "?"
+			}
+			fmt.Fprintf(&b, "  %q%s: %s;\n", name, optional, tsTypeExpr(field.Type, false))
+		}
+		b.WriteString("}")
+		return b.String()
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "unknown"
+	}
+}
+
+func writeTSMethod(b *strings.Builder, e endpoint) {
+	var params []string
+	path := e.path
+	for _, name := range e.params {
+		identifier := paramName(name)
+		params = append(params, identifier+": string")
+		if placeholder := "{" + name + "}"; strings.Contains(path, placeholder) {
+			path = strings.Replace(path, placeholder, "${encodeURIComponent("+identifier+")}", 1)
+		} else {
+			path = strings.Replace(path, "{"+name+"...}", "${"+identifier+"}", 1)
+		}
+	}
+
+	body := ""
+	if e.request != nil {
+		params = append(params, "body: "+tsTypeExpr(e.request, false))
+		body = ", body"
+	}
+	result := "void"
+	if e.response != nil {
+		result = tsTypeExpr(e.response, false)
+	}
+
+	name := strings.ToLower(e.name[:1]) + e.name[1:]
+	fmt.Fprintf(b, "  /** %s */\n", e.pattern)
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", name, strings.Join(params, ", "), result)
+	fmt.Fprintf(b, "    return this.request<%s>(%q, `%s`%s);\n  }\n\n", result, e.method, path, body)
+}
+
+const tsClient = `export class ApiError extends Error {
+  constructor(public status: number, public body: string) {
+    super(` + "`unexpected status ${status}: ${body}`" + `);
+  }
+}
+
+export class Client {
+  constructor(private baseURL: string, private init: RequestInit = {}) {}
+
+  private async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+    const headers = new Headers(this.init.headers);
+    headers.set("Accept", "application/json");
+    if (body !== undefined) {
+      headers.set("Content-Type", "application/json");
+    }
+    const response = await fetch(this.baseURL + path, {
+      ...this.init,
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (!response.ok) {
+      throw new ApiError(response.status, await response.text());
+    }
+    if (response.status === 204) {
+      return undefined as T;
+    }
+    return (await response.json()) as T;
+  }
+
+`
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// DefaultGraphQLMaxBodySize is used when GraphQLConfig.MaxBodySize is not set.
+const DefaultGraphQLMaxBodySize = 1 << 20
+
+type GraphQLConfig struct {
+	// Playground serves the GraphiQL playground on GET requests
+	Playground bool
+	// MaxBodySize is the maximum size of a query request body, defaults to DefaultGraphQLMaxBodySize
+	MaxBodySize int64
+}
+
+// GraphQL mounts a GraphQL handler, which only receives POST requests with a limited body size.
+// The returned route is the query route, so middlewares like authentication can be added to it.
+func (r *Router) GraphQL(pattern string, handler http.Handler, config GraphQLConfig) *Route {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultGraphQLMaxBodySize
+	}
+
+	if config.Playground {
+		r.GET(pattern, func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			graphiqlTemplate.Execute(w, req.URL.Path)
+		})
+	}
+
+	return r.POST(pattern, func(w http.ResponseWriter, req *http.Request) {
+		req.Body = http.MaxBytesReader(w, req.Body, config.MaxBodySize)
+		handler.ServeHTTP(w, req)
+	})
+}
+
+var graphiqlTemplate = template.Must(template.New("graphiql").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>GraphiQL</title>
+	<link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+	<style>body { margin: 0; height: 100vh; } #graphiql { height: 100vh; }</style>
+</head>
+<body>
+	<div id="graphiql">Loading...</div>
+	<script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+	<script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+	<script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+	<script>
+		const fetcher = GraphiQL.createFetcher({ url: {{.}} });
+		ReactDOM.createRoot(document.getElementById('graphiql')).render(React.createElement(GraphiQL, { fetcher }));
+	</script>
+</body>
+</html>
+`))
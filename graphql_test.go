@@ -0,0 +1,58 @@
+package router_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestGraphQL(t *testing.T) {
+	schemaHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write([]byte(`{"data":{"query":` + string(body) + `}}`))
+	})
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GraphQL("/graphql", schemaHandler, router.GraphQLConfig{Playground: true, MaxBodySize: 64})
+	r.GraphQL("/internal/graphql", schemaHandler, router.GraphQLConfig{})
+
+	// Define test cases for each route
+	tests := []struct {
+		method      string
+		path        string
+		body        string
+		statusCode  int
+		contentType string
+	}{
+		{http.MethodPost, "/graphql/", `"{ users { id } }"`, http.StatusOK, ""},
+		{http.MethodPost, "/graphql/", `"` + strings.Repeat("a", 100) + `"`, http.StatusRequestEntityTooLarge, ""},
+		{http.MethodGet, "/graphql/", "", http.StatusOK, "text/html; charset=utf-8"},
+		{http.MethodPut, "/graphql/", "", http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "/internal/graphql/", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.statusCode)
+			}
+			if tt.contentType != "" && rr.Header().Get("Content-Type") != tt.contentType {
+				t.Errorf("handler returned wrong content type: got %v want %v", rr.Header().Get("Content-Type"), tt.contentType)
+			}
+		})
+	}
+}
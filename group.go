@@ -0,0 +1,60 @@
+package router
+
+import "net/http"
+
+// RegisterRoute registers a route below the prefix of the group, like Router.RegisterRoute.
+func (rg *RouteGroup) RegisterRoute(method string, pattern string, handler http.HandlerFunc) *Route {
+	r := rg.router
+	if r == nil {
+		panic("router: routes can only be registered on groups created by Router.Group")
+	}
+	r.mustBeMutable()
+	route := newRoute(method, pattern, handler)
+	route.group = rg
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.hasSetupRoutes.Load() {
+		r.handleLateRoute(route, rg)
+	}
+	r.routesMutex.Lock()
+	rg.Routes = append(rg.Routes, route)
+	r.routesMutex.Unlock()
+	return route
+}
+
+func (rg *RouteGroup) GET(pattern string, handler http.HandlerFunc) *Route {
+	return rg.RegisterRoute(http.MethodGet, pattern, handler)
+}
+
+func (rg *RouteGroup) POST(pattern string, handler http.HandlerFunc) *Route {
+	return rg.RegisterRoute(http.MethodPost, pattern, handler)
+}
+
+func (rg *RouteGroup) PUT(pattern string, handler http.HandlerFunc) *Route {
+	return rg.RegisterRoute(http.MethodPut, pattern, handler)
+}
+
+func (rg *RouteGroup) DELETE(pattern string, handler http.HandlerFunc) *Route {
+	return rg.RegisterRoute(http.MethodDelete, pattern, handler)
+}
+
+func (rg *RouteGroup) PATCH(pattern string, handler http.HandlerFunc) *Route {
+	return rg.RegisterRoute(http.MethodPatch, pattern, handler)
+}
+
+func (rg *RouteGroup) OPTIONS(pattern string, handler http.HandlerFunc) *Route {
+	return rg.RegisterRoute(http.MethodOptions, pattern, handler)
+}
+
+func (rg *RouteGroup) HEAD(pattern string, handler http.HandlerFunc) *Route {
+	return rg.RegisterRoute(http.MethodHead, pattern, handler)
+}
+
+func (rg *RouteGroup) CONNECT(pattern string, handler http.HandlerFunc) *Route {
+	return rg.RegisterRoute(http.MethodConnect, pattern, handler)
+}
+
+func (rg *RouteGroup) TRACE(pattern string, handler http.HandlerFunc) *Route {
+	return rg.RegisterRoute(http.MethodTrace, pattern, handler)
+}
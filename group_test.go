@@ -0,0 +1,71 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestGroupRegistrar(t *testing.T) {
+	handler := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}
+	}
+	header := func(name string) router.Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", name)
+				next(w, r)
+			}
+		}
+	}
+
+	// Create a new router instance, with a group registered by a callback and routes added to it afterwards
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(header("global"))
+	api := r.Group("/api", func(r *router.Router) {
+		r.GET("/users", handler("users"))
+	})
+	api.Use(header("api"))
+	api.POST("/users", handler("created"))
+	api.GET("/users/{id}", handler("user")).Use(header("route"))
+
+	// A group without a callback
+	admin := r.Group("/admin").Use(header("admin"))
+	admin.GET("/stats", handler("stats"))
+
+	// Define test cases
+	tests := []struct {
+		method      string
+		path        string
+		body        string
+		middlewares string
+	}{
+		{http.MethodGet, "/api/users/", "users", "global,api"},
+		{http.MethodPost, "/api/users/", "created", "global,api"},
+		{http.MethodGet, "/api/users/1/", "user", "global,api,route"},
+		{http.MethodGet, "/admin/stats/", "stats", "global,admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			if rr.Body.String() != tt.body {
+				t.Errorf("Expected body %s, got %s", tt.body, rr.Body.String())
+			}
+			// The global middlewares are applied once, also when they were added before the group
+			if got := strings.Join(rr.Header().Values("X-Middleware"), ","); got != tt.middlewares {
+				t.Errorf("Expected middlewares %s, got %s", tt.middlewares, got)
+			}
+		})
+	}
+
+	if len(api.Routes) != 3 || api.Routes[2].Source() == "" {
+		t.Errorf("Expected the routes of the group with their source, got %v", api.Routes)
+	}
+}
@@ -0,0 +1,56 @@
+package router
+
+import (
+	"maps"
+	"net/http"
+)
+
+// Headers sets static headers on the responses of the route before the handler runs, e.g. Cache-Control or
+// X-Robots-Tag. They're added to the headers of the group, and replace headers of the group with the same name.
+// Middlewares and the handler can still change them. The headers are stored as "headers" metadata.
+//
+//	r.GET("/admin", adminHandler).Headers(map[string]string{"X-Robots-Tag": "noindex"})
+func (r *Route) Headers(headers map[string]string) *Route {
+	return r.Set("headers", mergeHeaders(r.Metadata["headers"], headers))
+}
+
+// Headers sets static headers on the responses of all routes of the group, like Route.Headers.
+func (rg *RouteGroup) Headers(headers map[string]string) *RouteGroup {
+	return rg.Set("headers", mergeHeaders(rg.Metadata["headers"], headers))
+}
+
+// mergeHeaders returns a copy of the headers stored as metadata with the given headers added, with canonical names
+func mergeHeaders(existing any, headers map[string]string) http.Header {
+	merged, _ := existing.(http.Header)
+	merged = merged.Clone()
+	if merged == nil {
+		merged = make(http.Header, len(headers))
+	}
+	for name, value := range headers {
+		merged.Set(name, value)
+	}
+	return merged
+}
+
+// headersHandler sets the static headers of the route and its group, routes without any aren't wrapped
+func headersHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	headers := http.Header{}
+	if route.group != nil {
+		if groupHeaders, ok := route.group.Metadata["headers"].(http.Header); ok {
+			maps.Copy(headers, groupHeaders)
+		}
+	}
+	if routeHeaders, ok := route.Metadata["headers"].(http.Header); ok {
+		maps.Copy(headers, routeHeaders)
+	}
+	if len(headers) == 0 {
+		return handler
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		header := w.Header()
+		for name, values := range headers {
+			header[name] = values
+		}
+		handler(w, req)
+	}
+}
@@ -0,0 +1,48 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestHeaders(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	r.GET("/public", noop).Headers(map[string]string{"cache-control": "public, max-age=60"})
+	r.Group("/admin", func(r *router.Router) {
+		r.GET("/users", noop)
+		r.GET("/stats", noop).Headers(map[string]string{"Cache-Control": "no-store"}).Headers(map[string]string{"X-Frame-Options": "DENY"})
+		r.GET("/override", func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Robots-Tag", "all")
+		})
+	}).Headers(map[string]string{"X-Robots-Tag": "noindex", "Cache-Control": "private"})
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		path     string
+		expected map[string]string
+	}{
+		{"route headers", "/public/", map[string]string{"Cache-Control": "public, max-age=60", "X-Robots-Tag": ""}},
+		{"group headers", "/admin/users/", map[string]string{"Cache-Control": "private", "X-Robots-Tag": "noindex"}},
+		{"route overrides group", "/admin/stats/", map[string]string{"Cache-Control": "no-store", "X-Robots-Tag": "noindex", "X-Frame-Options": "DENY"}},
+		{"handler overrides", "/admin/override/", map[string]string{"Cache-Control": "private", "X-Robots-Tag": "all"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			for name, expected := range tt.expected {
+				if got := rec.Header().Get(name); got != expected {
+					t.Errorf("expected %s %q, got %q", name, expected, got)
+				}
+			}
+		})
+	}
+}
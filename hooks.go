@@ -0,0 +1,74 @@
+package router
+
+import (
+	"net/http"
+	"time"
+)
+
+// ResponseInfo describes a response that has been written by a matched route, it's passed to OnResponse hooks.
+type ResponseInfo struct {
+	Request      *http.Request
+	Route        *Route
+	StatusCode   int
+	BytesWritten int64
+	Header       http.Header
+	Duration     time.Duration
+	// ClientClosed reports whether the client disconnected before the handler returned, see IsClientGone
+	ClientClosed bool
+	// Aborted reports whether the handler was aborted by a panic, e.g. after the response was started or by the
+	// limits of the route. The connection is closed, so the client got a truncated response or none at all
+	Aborted bool
+}
+
+// OnRequest registers a hook that is called for every matched route before any middleware runs.
+// The returned request is passed on, so hooks can for example scrub headers or add context values.
+func (r *Router) OnRequest(hook func(*http.Request) *http.Request) {
+	r.mustBeMutable()
+	r.requestHooks = append(r.requestHooks, hook)
+}
+
+// OnResponse registers a hook that is called for every matched route after the handler has returned.
+func (r *Router) OnResponse(hook func(ResponseInfo)) {
+	r.mustBeMutable()
+	r.responseHooks = append(r.responseHooks, hook)
+}
+
+func (r *Router) applyHooks(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	if len(r.requestHooks) == 0 && len(r.responseHooks) == 0 {
+		return handler
+	}
+
+	requestHooks := r.requestHooks
+	responseHooks := r.responseHooks
+	return func(w http.ResponseWriter, req *http.Request) {
+		for _, hook := range requestHooks {
+			req = hook(req)
+		}
+		if len(responseHooks) == 0 {
+			handler(w, req)
+			return
+		}
+
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w}
+		// The hooks run while a panic unwinds as well, without recovering it
+		aborted := true
+		defer func() {
+			info := ResponseInfo{
+				Request:      req,
+				Route:        route,
+				StatusCode:   rw.Status(),
+				BytesWritten: rw.bytesWritten,
+				Header:       rw.Header(),
+				Duration:     time.Since(start),
+				ClientClosed: IsClientGone(req),
+				Aborted:      aborted,
+			}
+			for _, hook := range responseHooks {
+				hook(info)
+			}
+		}()
+		handler(rw, req)
+		aborted = false
+	}
+}
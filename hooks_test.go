@@ -0,0 +1,76 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestHooks(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+
+	var infos []router.ResponseInfo
+
+	// Scrub a header before any middleware or handler sees it
+	r.OnRequest(func(req *http.Request) *http.Request {
+		req.Header.Del("X-Internal-Secret")
+		return req
+	})
+	r.OnResponse(func(info router.ResponseInfo) {
+		infos = append(infos, info)
+	})
+
+	r.GET("/get-endpoint", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Internal-Secret")))
+	})
+	r.Group("group", func(rg *router.Router) {
+		rg.POST("/post", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("created"))
+		})
+	})
+
+	// Define test cases for each route
+	tests := []struct {
+		method       string
+		path         string
+		statusCode   int
+		bytesWritten int64
+	}{
+		{http.MethodGet, "/get-endpoint/", http.StatusOK, 0},
+		{http.MethodPost, "/group/post/", http.StatusCreated, 7},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-Internal-Secret", "secret")
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if len(infos) != i+1 {
+				t.Fatalf("expected %d response infos, got %d", i+1, len(infos))
+			}
+			info := infos[i]
+			if info.StatusCode != tt.statusCode {
+				t.Errorf("hook got wrong status code: got %v want %v", info.StatusCode, tt.statusCode)
+			}
+			if info.BytesWritten != tt.bytesWritten {
+				t.Errorf("hook got wrong bytes written: got %v want %v", info.BytesWritten, tt.bytesWritten)
+			}
+			if info.Route == nil || info.Route.Method != tt.method {
+				t.Errorf("hook got wrong route: %+v", info.Route)
+			}
+		})
+	}
+
+	// Unmatched requests should not trigger the hooks
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown/", nil))
+	if len(infos) != len(tests) {
+		t.Errorf("expected hooks to only run for matched routes, got %d calls", len(infos))
+	}
+}
@@ -0,0 +1,276 @@
+// Package jsonrpc serves JSON-RPC 2.0 methods from a single route on the router.
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/gogo-framework/router"
+)
+
+// Standard JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeServerError is used for errors returned by methods that aren't an *Error
+	CodeServerError = -32000
+)
+
+// Error is a JSON-RPC error, methods can return it to control the code and data of the error response.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %d %s", e.Code, e.Message)
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type response struct {
+	JSONRPC string
+	Result  any
+	Error   *Error
+	ID      json.RawMessage
+}
+
+// MarshalJSON includes either the result or the error, a result is always included on success even when it's empty.
+func (r *response) MarshalJSON() ([]byte, error) {
+	if r.Error != nil {
+		return json.Marshal(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			Error   *Error          `json:"error"`
+			ID      json.RawMessage `json:"id"`
+		}{r.JSONRPC, r.Error, r.ID})
+	}
+	return json.Marshal(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Result  any             `json:"result"`
+		ID      json.RawMessage `json:"id"`
+	}{r.JSONRPC, r.Result, r.ID})
+}
+
+type method struct {
+	fn        reflect.Value
+	paramType reflect.Type
+}
+
+type Server struct {
+	// MaxBodySize is the maximum size of a request body, defaults to 1MB
+	MaxBodySize int64
+	// MaxBatchSize is the maximum number of requests in a batch, defaults to 100. Larger batches get an error response
+	MaxBatchSize int
+	// Concurrency is the number of requests of a batch that are executed at the same time, defaults to 4
+	Concurrency int
+
+	mutex   sync.RWMutex
+	methods map[string]*method
+}
+
+func NewServer() *Server {
+	return &Server{methods: make(map[string]*method)}
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Register adds a method. The function must have the signature func(context.Context) (R, error) or
+// func(context.Context, P) (R, error), where P is decoded from the params of the request. It panics otherwise.
+func (s *Server) Register(name string, fn any) {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() < 1 || t.NumIn() > 2 || t.In(0) != contextType ||
+		t.NumOut() != 2 || t.Out(1) != errorType {
+		panic(fmt.Sprintf("jsonrpc: method %q must be func(context.Context[, P]) (R, error), got %s", name, t))
+	}
+
+	m := &method{fn: v}
+	if t.NumIn() == 2 {
+		m.paramType = t.In(1)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.methods[name] = m
+}
+
+// Mount registers the server as a POST route on the router.
+func (s *Server) Mount(r *router.Router, pattern string) *router.Route {
+	return r.POST(pattern, s.ServeHTTP)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	maxBodySize := s.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = 1 << 20
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			router.Error(w, r, http.StatusRequestEntityTooLarge, nil)
+			return
+		}
+		writeJSON(w, errorResponse(nil, CodeParseError, "Parse error"))
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeJSON(w, errorResponse(nil, CodeParseError, "Parse error"))
+			return
+		}
+		if len(batch) == 0 {
+			writeJSON(w, errorResponse(nil, CodeInvalidRequest, "Invalid Request"))
+			return
+		}
+		maxBatchSize := s.MaxBatchSize
+		if maxBatchSize <= 0 {
+			maxBatchSize = 100
+		}
+		if len(batch) > maxBatchSize {
+			writeJSON(w, errorResponse(nil, CodeInvalidRequest, fmt.Sprintf("Invalid Request: a batch has at most %d requests", maxBatchSize)))
+			return
+		}
+		concurrency := s.Concurrency
+		if concurrency <= 0 {
+			concurrency = 4
+		}
+
+		// A fixed number of workers executes the requests, so a batch can't start a goroutine per request
+		responses := make([]*response, len(batch))
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for range min(concurrency, len(batch)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					responses[i] = s.handle(r, batch[i])
+				}
+			}()
+		}
+		for i := range batch {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+
+		// Notifications don't get a response
+		results := make([]*response, 0, len(responses))
+		for _, res := range responses {
+			if res != nil {
+				results = append(results, res)
+			}
+		}
+		if len(results) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, results)
+		return
+	}
+
+	res := s.handle(r, body)
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, res)
+}
+
+// handle executes a single request of the HTTP request, it returns nil for notifications.
+func (s *Server) handle(r *http.Request, raw json.RawMessage) *response {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		var syntaxError *json.SyntaxError
+		if errors.As(err, &syntaxError) {
+			return errorResponse(nil, CodeParseError, "Parse error")
+		}
+		return errorResponse(nil, CodeInvalidRequest, "Invalid Request")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, "Invalid Request")
+	}
+	isNotification := len(req.ID) == 0
+
+	res := s.call(r, &req)
+	if isNotification {
+		return nil
+	}
+	return res
+}
+
+func (s *Server) call(r *http.Request, req *request) (res *response) {
+	s.mutex.RLock()
+	m, ok := s.methods[req.Method]
+	s.mutex.RUnlock()
+	if !ok {
+		return errorResponse(req.ID, CodeMethodNotFound, "Method not found")
+	}
+
+	args := []reflect.Value{reflect.ValueOf(r.Context())}
+	if m.paramType != nil {
+		param := reflect.New(m.paramType)
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, param.Interface()); err != nil {
+				return errorResponse(req.ID, CodeInvalidParams, "Invalid params")
+			}
+		}
+		args = append(args, param.Elem())
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			router.Logger(r).Error("jsonrpc: method panicked", "method", req.Method, "error", recovered)
+			res = errorResponse(req.ID, CodeInternalError, "Internal error")
+		}
+	}()
+	out := m.fn.Call(args)
+
+	if err, _ := out[1].Interface().(error); err != nil {
+		var rpcError *Error
+		if errors.As(err, &rpcError) {
+			return &response{JSONRPC: "2.0", Error: rpcError, ID: nullID(req.ID)}
+		}
+		return errorResponse(req.ID, CodeServerError, err.Error())
+	}
+	return &response{JSONRPC: "2.0", Result: out[0].Interface(), ID: req.ID}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	return &response{JSONRPC: "2.0", Error: &Error{Code: code, Message: message}, ID: nullID(id)}
+}
+
+func nullID(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,94 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/jsonrpc"
+)
+
+type addParams struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+func TestServer(t *testing.T) {
+	s := jsonrpc.NewServer()
+	s.MaxBatchSize = 3
+	s.Register("add", func(ctx context.Context, p addParams) (int, error) {
+		return p.A + p.B, nil
+	})
+	s.Register("subtract", func(ctx context.Context, p []int) (int, error) {
+		return p[0] - p[1], nil
+	})
+	s.Register("ping", func(ctx context.Context) (string, error) {
+		return "pong", nil
+	})
+	s.Register("fail", func(ctx context.Context) (any, error) {
+		return nil, errors.New("something went wrong")
+	})
+	s.Register("forbidden", func(ctx context.Context) (any, error) {
+		return nil, &jsonrpc.Error{Code: 403, Message: "Forbidden"}
+	})
+	s.Register("panic", func(ctx context.Context) (any, error) {
+		panic("method panicked")
+	})
+
+	// Create a new router instance
+	var logs bytes.Buffer
+	r := router.NewRouter(router.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	s.Mount(r, "/rpc")
+
+	// Define test cases for each kind of request
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		response   string
+	}{
+		{"named params", `{"jsonrpc":"2.0","method":"add","params":{"a":1,"b":2},"id":1}`, http.StatusOK, `{"jsonrpc":"2.0","result":3,"id":1}`},
+		{"positional params", `{"jsonrpc":"2.0","method":"subtract","params":[42,23],"id":"a"}`, http.StatusOK, `{"jsonrpc":"2.0","result":19,"id":"a"}`},
+		{"zero result", `{"jsonrpc":"2.0","method":"add","params":{"a":0,"b":0},"id":2}`, http.StatusOK, `{"jsonrpc":"2.0","result":0,"id":2}`},
+		{"no params", `{"jsonrpc":"2.0","method":"ping","id":3}`, http.StatusOK, `{"jsonrpc":"2.0","result":"pong","id":3}`},
+		{"method not found", `{"jsonrpc":"2.0","method":"nope","id":4}`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":4}`},
+		{"invalid params", `{"jsonrpc":"2.0","method":"add","params":"x","id":5}`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":5}`},
+		{"server error", `{"jsonrpc":"2.0","method":"fail","id":6}`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32000,"message":"something went wrong"},"id":6}`},
+		{"custom error", `{"jsonrpc":"2.0","method":"forbidden","id":7}`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":403,"message":"Forbidden"},"id":7}`},
+		{"parse error", `{"jsonrpc":"2.0","method`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`},
+		{"invalid request", `{"jsonrpc":"1.0","method":"ping","id":8}`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":8}`},
+		{"notification", `{"jsonrpc":"2.0","method":"ping"}`, http.StatusNoContent, ``},
+		{"empty batch", `[]`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		{"batch", `[{"jsonrpc":"2.0","method":"ping","id":1},{"jsonrpc":"2.0","method":"ping"},1]`, http.StatusOK, `[{"jsonrpc":"2.0","result":"pong","id":1},{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}]`},
+		{"batch of notifications", `[{"jsonrpc":"2.0","method":"ping"}]`, http.StatusNoContent, ``},
+		{"batch too large", `[{"jsonrpc":"2.0","method":"ping","id":1},{"jsonrpc":"2.0","method":"ping","id":2},{"jsonrpc":"2.0","method":"ping","id":3},{"jsonrpc":"2.0","method":"ping","id":4}]`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request: a batch has at most 3 requests"},"id":null}`},
+		{"panic", `{"jsonrpc":"2.0","method":"panic","id":9}`, http.StatusOK, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":9}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/rpc/", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.statusCode)
+			}
+			if body := strings.TrimSpace(rr.Body.String()); body != tt.response {
+				t.Errorf("handler returned unexpected body:\ngot  %v\nwant %v", body, tt.response)
+			}
+		})
+	}
+
+	// Panics are logged to the logger of the router
+	if !strings.Contains(logs.String(), "method panicked") {
+		t.Errorf("expected the panic to be logged, got %q", logs.String())
+	}
+}
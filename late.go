@@ -0,0 +1,98 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// PostStartRegistration is what happens to routes that are registered after the router set up its routes, which
+// happens on the first request.
+type PostStartRegistration int
+
+const (
+	// IgnoreLateRoutes logs a warning, the route isn't served. It's the default.
+	IgnoreLateRoutes PostStartRegistration = iota
+	// PanicOnLateRoutes panics, to catch routes that are registered too late during development
+	PanicOnLateRoutes
+	// RegisterLateRoutes registers the route on the running router. The handler is compiled on the first request of
+	// the route, so middlewares and metadata added right after registering it are applied.
+	RegisterLateRoutes
+)
+
+// handleLateRoute applies RouterConfig.PostStartRegistration to a route registered after the routes were set up.
+// The mutex of the router is held.
+func (r *Router) handleLateRoute(route *Route, routeGroup *RouteGroup) {
+	if routeGroup != nil {
+		route.fullPattern = r.GetPathForRouteWithRouteGroup(route, routeGroup)
+	} else {
+		route.fullPattern = r.GetPathForRoute(route)
+	}
+
+	switch r.config.PostStartRegistration {
+	case PanicOnLateRoutes:
+		panic(fmt.Sprintf("router: %s registered at %s after the router started serving, register routes before "+
+			"the first request or set RouterConfig.PostStartRegistration", route.fullPattern, route.Source()))
+	case RegisterLateRoutes:
+		handler := r.lazyHandler(route, routeGroup)
+		if r.customMatcher != nil {
+			r.addRoute(route, handler)
+			return
+		}
+		for _, mux := range r.lateMuxes(route) {
+			r.handleRoute(mux, route, handler)
+		}
+	default:
+		r.Logger().Warn("router: route registered after the router started serving is ignored",
+			"pattern", route.fullPattern, "source", route.Source())
+	}
+}
+
+// lazyHandler compiles the route with its middlewares on its first request. When compiling panics, e.g. in a
+// middleware, the error is logged once and every request of the route gets a 500.
+func (r *Router) lazyHandler(route *Route, routeGroup *RouteGroup) http.HandlerFunc {
+	var once sync.Once
+	var compiled http.HandlerFunc
+	var compileErr error
+	return func(w http.ResponseWriter, req *http.Request) {
+		once.Do(func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					compileErr = fmt.Errorf("router: compiling %s registered at %s panicked: %v", route.fullPattern, route.Source(), recovered)
+					r.Logger().Error("router: the late route can't be served", "pattern", route.fullPattern,
+						"source", route.Source(), "panic", recovered)
+				}
+			}()
+			middlewares := route.Middlewares
+			if routeGroup != nil {
+				middlewares = append(append([]Middleware{}, routeGroup.Middlewares...), route.Middlewares...)
+			}
+			compiled = r.compileRoute(route, append(append([]Middleware{}, r.middlewares...), middlewares...))
+		})
+		if compileErr != nil {
+			Error(w, req, http.StatusInternalServerError, compileErr)
+			return
+		}
+		compiled(w, req)
+	}
+}
+
+// lateMuxes returns the muxes a late route is registered on. With sharded routes a route with a literal goes to its
+// shard, or to the fallback when there's no shard for the literal, and other routes go to every mux.
+func (r *Router) lateMuxes(route *Route) []*http.ServeMux {
+	m, ok := r.matcher.(*shardedMux)
+	if !ok {
+		return []*http.ServeMux{r.mux}
+	}
+	if literal, ok := patternLiteral(route.fullPattern, m.position); ok {
+		if shard, ok := m.shards[literal]; ok {
+			return []*http.ServeMux{shard}
+		}
+		return []*http.ServeMux{m.fallback}
+	}
+	muxes := []*http.ServeMux{m.fallback}
+	for _, shard := range m.shards {
+		muxes = append(muxes, shard)
+	}
+	return muxes
+}
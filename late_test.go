@@ -0,0 +1,167 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestPostStartRegistration(t *testing.T) {
+	handler := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}
+	}
+	header := func(name string) router.Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", name)
+				next(w, r)
+			}
+		}
+	}
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		config router.RouterConfig
+		status int
+		panics bool
+	}{
+		{"ignored by default", router.RouterConfig{}, http.StatusNotFound, false},
+		{"panic", router.RouterConfig{PostStartRegistration: router.PanicOnLateRoutes}, http.StatusNotFound, true},
+		{"registered", router.RouterConfig{PostStartRegistration: router.RegisterLateRoutes}, http.StatusOK, false},
+		{"registered on shards", router.RouterConfig{PostStartRegistration: router.RegisterLateRoutes, ShardRoutes: true}, http.StatusOK, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new router instance, which starts serving before the late routes are registered
+			r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithConfig(tt.config))
+			r.Use(header("global"))
+			r.GET("/tenants/a/users", handler("a"))
+			r.GET("/tenants/b/users", handler("b"))
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tenants/a/users/", nil))
+
+			panicked := func() (panicked bool) {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						panicked = true
+						if !strings.Contains(fmt.Sprint(recovered), "after the router started serving") {
+							t.Errorf("Expected a clear panic message, got %v", recovered)
+						}
+					}
+				}()
+				r.GET("/tenants/a/posts", handler("posts")).Use(header("route"))
+				r.Group("/tenants/c", func(r *router.Router) {
+					r.Use(header("group"))
+					r.GET("/users", handler("c"))
+				})
+				return false
+			}()
+			if panicked != tt.panics {
+				t.Fatalf("Expected panic %v, got %v", tt.panics, panicked)
+			}
+
+			for path, want := range map[string]string{
+				"/tenants/a/posts/": "global,route",
+				"/tenants/c/users/": "global,group",
+			} {
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+				if rr.Code != tt.status {
+					t.Errorf("Expected status %d for %s, got %d", tt.status, path, rr.Code)
+				}
+				if got := strings.Join(rr.Header().Values("X-Middleware"), ","); tt.status == http.StatusOK && got != want {
+					t.Errorf("Expected middlewares %s for %s, got %s", want, path, got)
+				}
+			}
+
+			// The routes registered before serving still work
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tenants/b/users/", nil))
+			if rr.Body.String() != "b" {
+				t.Errorf("Expected body b, got %s", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestLateRoutesWhileServing(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(
+		router.WithMux(http.NewServeMux()),
+		router.WithConfig(router.RouterConfig{PostStartRegistration: router.RegisterLateRoutes}),
+	)
+	r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {}).Name("users.show")
+	api := r.Group("/api")
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1/", nil))
+
+	// Routes are registered while other goroutines serve requests and read the routes, for the race detector
+	var wg, started sync.WaitGroup
+	done := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if _, err := r.URL("users.show", map[string]string{"id": "1"}); err != nil {
+					t.Error(err)
+					return
+				}
+				r.Routes()
+				r.Stats()
+				r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1/", nil))
+			}
+		}()
+	}
+	started.Wait()
+	for i := range 50 {
+		r.GET(fmt.Sprintf("/late/%d", i), func(w http.ResponseWriter, r *http.Request) {})
+		api.GET(fmt.Sprintf("/late/%d", i), func(w http.ResponseWriter, r *http.Request) {})
+	}
+	close(done)
+	wg.Wait()
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/late/49/", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected the late route to be served, got status %d", rr.Code)
+	}
+	if len(r.Routes()) != 101 {
+		t.Errorf("Expected 101 routes, got %d", len(r.Routes()))
+	}
+}
+
+func TestPostStartRegistrationPanic(t *testing.T) {
+	// Create a new router instance, which starts serving before the late route is registered
+	r := router.NewRouter(router.WithMux(http.NewServeMux()),
+		router.WithConfig(router.RouterConfig{PostStartRegistration: router.RegisterLateRoutes}))
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+
+	// A middleware that panics when the route is compiled on its first request
+	r.GET("/posts", func(w http.ResponseWriter, r *http.Request) {}).Use(func(next http.HandlerFunc) http.HandlerFunc {
+		panic("invalid middleware config")
+	})
+
+	for i := range 3 {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/posts/", nil))
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("Request %d: expected status %d, got %d", i+1, http.StatusInternalServerError, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxResponseBytes caps the size of the response body of the route, e.g. of exports that could grow without bound. A
+// handler writing past the cap is aborted: the connection is closed, so the client gets a truncated response instead
+// of one that looks complete, and the router logs a warning. Responses with a larger Content-Length are aborted before
+// anything is sent. The cap is stored as "maxResponseBytes" metadata.
+func (r *Route) MaxResponseBytes(n int64) *Route {
+	return r.Set("maxResponseBytes", n)
+}
+
+// MaxResponseTime limits how long the route can take to write its response, counted from the start of the request, so
+// the time spent in the global and route middlewares counts as well. It sets the write deadline of the connection,
+// and a handler writing after the deadline is aborted like with MaxResponseBytes. The limit is stored as
+// "maxResponseTime" metadata.
+func (r *Route) MaxResponseTime(d time.Duration) *Route {
+	return r.Set("maxResponseTime", d)
+}
+
+// limitsHandler aborts handlers writing past the limits of the route, routes without limits aren't wrapped
+func (r *Router) limitsHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	maxBytes, _ := route.Metadata["maxResponseBytes"].(int64)
+	maxTime, _ := route.Metadata["maxResponseTime"].(time.Duration)
+	if maxBytes <= 0 && maxTime <= 0 {
+		return handler
+	}
+	logger := r.Logger()
+	return func(w http.ResponseWriter, req *http.Request) {
+		lw := &limitWriter{ResponseWriter: w, maxBytes: maxBytes}
+		if maxTime > 0 {
+			lw.deadline = time.Now().Add(maxTime)
+			http.NewResponseController(w).SetWriteDeadline(lw.deadline)
+		}
+		lw.abort = func(reason string) {
+			logger.Warn("router: response aborted", "method", req.Method, "path", req.URL.Path,
+				"route", route.FullPattern(), "reason", reason, "bytes", lw.written)
+			panic(http.ErrAbortHandler)
+		}
+		handler(lw, req)
+	}
+}
+
+// limitWriter aborts the handler when the response gets larger than maxBytes or is written after the deadline
+type limitWriter struct {
+	http.ResponseWriter
+	maxBytes int64
+	deadline time.Time
+	written  int64
+	abort    func(reason string)
+	// wroteHeader is set once the final status code is written
+	wroteHeader bool
+}
+
+func (w *limitWriter) WriteHeader(statusCode int) {
+	if w.maxBytes > 0 {
+		if length, err := strconv.ParseInt(w.Header().Get("Content-Length"), 10, 64); err == nil && length > w.maxBytes {
+			w.abort("Content-Length exceeds the maximum response size")
+		}
+	}
+	if statusCode >= http.StatusOK {
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *limitWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.maxBytes > 0 && w.written+int64(len(b)) > w.maxBytes {
+		w.abort("maximum response size exceeded")
+	}
+	if !w.deadline.IsZero() && time.Now().After(w.deadline) {
+		w.abort("maximum response time exceeded")
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+func (w *limitWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *limitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
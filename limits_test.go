@@ -0,0 +1,138 @@
+package router_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestResponseLimits(t *testing.T) {
+	var logs bytes.Buffer
+	var mutex sync.Mutex
+	logger := slog.New(slog.NewTextHandler(&syncWriter{mutex: &mutex, w: &logs}, nil))
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithLogger(logger))
+	// The client can see the aborted response before the handler returned, the hook runs after the abort was logged.
+	// The client retries aborted requests, so a path can finish more than once
+	finished := make(chan string, 16)
+	r.OnResponse(func(info router.ResponseInfo) {
+		select {
+		case finished <- info.Request.URL.Path:
+		default:
+		}
+	})
+	export := func(w http.ResponseWriter, r *http.Request) {
+		for range 10 {
+			io.WriteString(w, strings.Repeat("x", 100))
+		}
+	}
+	r.GET("/small", export).MaxResponseBytes(2000)
+	r.GET("/large", export).MaxResponseBytes(500)
+	r.GET("/length", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1000")
+		export(w, r)
+	}).MaxResponseBytes(500)
+	r.GET("/fast", export).MaxResponseTime(time.Second)
+	r.GET("/slow", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "started")
+		http.NewResponseController(w).Flush()
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "finished")
+	}).MaxResponseTime(50 * time.Millisecond)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	// Define test cases
+	tests := []struct {
+		path    string
+		body    string
+		aborted string
+	}{
+		{"/small/", strings.Repeat("x", 1000), ""},
+		{"/large/", "", "maximum response size exceeded"},
+		{"/length/", "", "Content-Length exceeds the maximum response size"},
+		{"/fast/", strings.Repeat("x", 1000), ""},
+		{"/slow/", "started", "maximum response time exceeded"},
+	}
+
+	for _, tc := range tests {
+		mutex.Lock()
+		logs.Reset()
+		mutex.Unlock()
+		body, err := get(server.URL + tc.path)
+		for path := range finished {
+			if path == tc.path {
+				break
+			}
+		}
+		// The client gets what was flushed before the abort
+		if body != tc.body {
+			t.Errorf("%s: expected body %q, got %q", tc.path, tc.body, body)
+		}
+		if (err != nil) != (tc.aborted != "") {
+			t.Errorf("%s: expected aborted %v, got error %v", tc.path, tc.aborted != "", err)
+		}
+		mutex.Lock()
+		logged := logs.String()
+		mutex.Unlock()
+		if tc.aborted != "" && !strings.Contains(logged, tc.aborted) {
+			t.Errorf("%s: expected the abort to be logged with %q, got %q", tc.path, tc.aborted, logged)
+		}
+	}
+}
+
+func TestResponseTimeIncludesMiddlewares(t *testing.T) {
+	var logs bytes.Buffer
+	var mutex sync.Mutex
+	logger := slog.New(slog.NewTextHandler(&syncWriter{mutex: &mutex, w: &logs}, nil))
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithLogger(logger))
+	finished := make(chan struct{}, 16)
+	r.OnResponse(func(info router.ResponseInfo) {
+		finished <- struct{}{}
+	})
+	// A slow global middleware uses up the time of the route
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			next(w, r)
+		}
+	})
+	r.GET("/report", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "report")
+	}).MaxResponseTime(50 * time.Millisecond)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	body, err := get(server.URL + "/report/")
+	<-finished
+	if err == nil || body != "" {
+		t.Errorf("Expected the response to be aborted, got %q and error %v", body, err)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !strings.Contains(logs.String(), "maximum response time exceeded") {
+		t.Errorf("Expected the abort to be logged, got %q", logs.String())
+	}
+}
+
+// get returns the body of the response, and an error when it was cut off
+func get(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
@@ -0,0 +1,80 @@
+// Package links builds hypermedia links from named routes, so JSON responses can embed URLs that stay correct when
+// route patterns change.
+package links
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gogo-framework/router"
+)
+
+// Link is a hypermedia link, Method is the method of the route the link points to.
+type Link struct {
+	Href   string `json:"href"`
+	Method string `json:"method,omitempty"`
+}
+
+// Links maps relations like "self" to links, it can be embedded in a response as "_links" or "links".
+type Links map[string]Link
+
+// Builder collects links for a response. Errors are collected and returned by Build, so calls can be chained.
+type Builder struct {
+	request *http.Request
+	links   Links
+	errs    []error
+}
+
+// New returns a builder for links on the router that matched the request.
+func New(r *http.Request) *Builder {
+	return &Builder{request: r, links: make(Links)}
+}
+
+// Add adds a link to the named route. Path parameters that are not in params are taken from the current request,
+// so the link to "users.show" from within "/users/{id}" only needs the route name.
+func (b *Builder) Add(rel string, routeName string, params map[string]string) *Builder {
+	route := router.RouteFor(b.request, routeName)
+	if route == nil {
+		b.errs = append(b.errs, fmt.Errorf("links: %s: %w: %q", rel, router.ErrRouteNotFound, routeName))
+		return b
+	}
+
+	values := make(map[string]string, len(params))
+	for _, name := range route.ParamNames() {
+		if value := b.request.PathValue(name); value != "" {
+			values[name] = value
+		}
+	}
+	for name, value := range params {
+		values[name] = value
+	}
+
+	href, err := router.URLFor(b.request, routeName, values)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("links: %s: %w", rel, err))
+		return b
+	}
+	b.links[rel] = Link{Href: href, Method: route.Method}
+	return b
+}
+
+// Self adds the "self" link.
+func (b *Builder) Self(routeName string, params map[string]string) *Builder {
+	return b.Add("self", routeName, params)
+}
+
+// Edit adds the "edit" link.
+func (b *Builder) Edit(routeName string, params map[string]string) *Builder {
+	return b.Add("edit", routeName, params)
+}
+
+// Delete adds the "delete" link.
+func (b *Builder) Delete(routeName string, params map[string]string) *Builder {
+	return b.Add("delete", routeName, params)
+}
+
+// Build returns the links that could be built, and the errors of the ones that couldn't.
+func (b *Builder) Build() (Links, error) {
+	return b.links, errors.Join(b.errs...)
+}
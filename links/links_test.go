@@ -0,0 +1,91 @@
+package links_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/links"
+)
+
+func TestLinks(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	r.Group("/api", func(r *router.Router) {
+		r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			l, err := links.New(r).
+				Self("users.show", nil).
+				Edit("users.update", nil).
+				Delete("users.delete", nil).
+				Add("posts", "posts.list", map[string]string{"user": r.PathValue("id")}).
+				Build()
+			if err != nil {
+				t.Error(err)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"_links": l})
+		}).Name("users.show")
+		r.PATCH("/users/{id}", noop).Name("users.update")
+		r.DELETE("/users/{id}", noop).Name("users.delete")
+		r.GET("/users/{user}/posts", noop).Name("posts.list")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/5/", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var body struct {
+		Links links.Links `json:"_links"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	// Define test cases
+	tests := []struct {
+		rel    string
+		href   string
+		method string
+	}{
+		{"self", "/api/users/5/", http.MethodGet},
+		{"edit", "/api/users/5/", http.MethodPatch},
+		{"delete", "/api/users/5/", http.MethodDelete},
+		{"posts", "/api/users/5/posts/", http.MethodGet},
+	}
+
+	for _, tc := range tests {
+		link, ok := body.Links[tc.rel]
+		if !ok {
+			t.Errorf("Expected link %q", tc.rel)
+			continue
+		}
+		if link.Href != tc.href || link.Method != tc.method {
+			t.Errorf("Expected %s link to be %s %s, got %s %s", tc.rel, tc.method, tc.href, link.Method, link.Href)
+		}
+	}
+}
+
+func TestLinksErrors(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+
+	var l links.Links
+	var err error
+	r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		l, err = links.New(r).Self("users.show", nil).Add("missing", "does.not.exist", nil).Build()
+	}).Name("users.show")
+
+	req := httptest.NewRequest(http.MethodGet, "/users/5/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !errors.Is(err, router.ErrRouteNotFound) {
+		t.Errorf("Expected ErrRouteNotFound, got %v", err)
+	}
+	if l["self"].Href != "/users/5/" {
+		t.Errorf("Expected the self link to be built, got %v", l)
+	}
+}
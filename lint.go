@@ -0,0 +1,157 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Issue is a suspicious route found by Lint.
+type Issue struct {
+	// Pattern is the pattern the route was registered with
+	Pattern string
+	// File and Line are where the route was registered
+	File    string
+	Line    int
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Pattern, i.Message)
+}
+
+// UsesParams declares the path parameters the handler reads, so Lint can report parameters of the pattern that
+// aren't used. They're stored as "params" metadata.
+func (r *Route) UsesParams(names ...string) *Route {
+	return r.Set("params", names)
+}
+
+// Lint checks the registered routes for suspicious patterns, e.g. in a test:
+//
+//	for _, issue := range r.Lint() {
+//		t.Error(issue)
+//	}
+//
+// It reports patterns without a leading slash or with empty segments, routes that conflict with another route, which
+// makes setting up the routes panic, and path parameters that aren't declared with UsesParams. Routes that are
+// shadowed by another route, e.g. by a wildcard registered before them, are reported as unreachable. They're found
+// by matching a request for every route, which is done by the ServeMux or, once the routes are set up, by the custom
+// Matcher of the router.
+func (r *Router) Lint() []Issue {
+	var issues []Issue
+	report := func(route *Route, format string, args ...any) {
+		issues = append(issues, Issue{
+			Pattern: route.Pattern,
+			File:    route.file,
+			Line:    route.line,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	mux := http.NewServeMux()
+	var registered []*Route
+	for _, route := range r.Routes() {
+		if route.Pattern != "" && route.Pattern[0] != '/' {
+			report(route, "pattern doesn't start with a slash")
+		}
+		if strings.Contains(strings.TrimPrefix(route.Pattern, "/"), "//") {
+			report(route, "pattern has an empty segment")
+		}
+
+		pattern := r.routePattern(route)
+		if err := handlePattern(http.NewServeMux(), pattern); err != nil {
+			report(route, "invalid pattern: %v", err)
+		} else if handlePattern(mux, pattern) != nil {
+			// Find the route it conflicts with
+			for _, other := range registered {
+				conflicts := http.NewServeMux()
+				if handlePattern(conflicts, r.routePattern(other)) == nil && handlePattern(conflicts, pattern) != nil {
+					report(route, "conflicts with %s registered at %s:%d", r.routePattern(other), other.file, other.line)
+					break
+				}
+			}
+		} else {
+			registered = append(registered, route)
+		}
+
+		if value, ok := route.Get("params"); ok {
+			used, _ := value.([]string)
+			names := route.ParamNames()
+			for _, name := range names {
+				if !slices.Contains(used, name) {
+					report(route, "path parameter %s isn't used by the handler", name)
+				}
+			}
+			for _, name := range used {
+				if !slices.Contains(names, name) {
+					report(route, "the handler uses %s, which isn't a path parameter", name)
+				}
+			}
+		}
+	}
+
+	// Match a request for every route. The ServeMux is used unless the routes are served by a custom matcher.
+	patternOf := r.routePattern
+	match := func(method string, host string, path string) *Route {
+		_, pattern := mux.Handler(&http.Request{Method: method, Host: host, URL: &url.URL{Path: path}})
+		for _, route := range registered {
+			if r.routePattern(route) == pattern {
+				return route
+			}
+		}
+		return nil
+	}
+	if r.customMatcher != nil && r.hasSetupRoutes.Load() {
+		registered = r.Routes()
+		patternOf = (*Route).FullPattern
+		match = func(method string, host string, path string) *Route {
+			route, _, _ := r.customMatcher.Match(method, host, (&url.URL{Path: path}).EscapedPath())
+			return route
+		}
+	}
+	for _, route := range registered {
+		pattern := patternOf(route)
+		if matched := match(sampleRequest(pattern)); matched != nil && patternOf(matched) != pattern {
+			report(route, "unreachable, requests are matched by %s registered at %s:%d", patternOf(matched), matched.file, matched.line)
+		}
+	}
+	return issues
+}
+
+// sampleRequest returns the method, host and path of a request matching the pattern. Parameters get their name in
+// braces as value, which a static segment can't have, and a method is made up for patterns without one.
+func sampleRequest(pattern string) (method string, host string, path string) {
+	method, rest, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, rest = "LINT", pattern
+	}
+	host, path, _ = strings.Cut(rest, "/")
+	if host == "" {
+		host = "lint.invalid"
+	}
+
+	// The parameters are kept as they are, the path is escaped when it's matched
+	segments := strings.Split(path, "/")
+	switch last := len(segments) - 1; segments[last] {
+	case "{$}":
+		segments[last] = ""
+	case "":
+		// A prefix pattern matches every path below it
+		segments[last] = "{...}"
+	}
+	return method, host, "/" + strings.Join(segments, "/")
+}
+
+// handlePattern registers the pattern on the mux, returning the panic of the mux for invalid or conflicting patterns
+// as error
+func handlePattern(mux *http.ServeMux, pattern string) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {})
+	return nil
+}
@@ -0,0 +1,93 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestLint(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/users/{id}", handler).UsesParams("id")
+	r.Group("/api/users", func(r *router.Router) {
+		r.GET("{id}/edit", handler)
+		r.GET("/{id}//delete", handler)
+	})
+	r.GET("/posts/{id}/comments/{comment}", handler).UsesParams("id", "slug")
+	r.GET("/users/{name}", handler)
+	r.GET("/files/{path...}/raw", handler)
+
+	// Define the expected issues, group routes come after the other routes
+	expected := []string{
+		"/posts/{id}/comments/{comment}: path parameter comment isn't used by the handler",
+		"/posts/{id}/comments/{comment}: the handler uses slug, which isn't a path parameter",
+		"/users/{name}: conflicts with GET /users/{id}/{$} registered at ",
+		"/files/{path...}/raw: invalid pattern",
+		"{id}/edit: pattern doesn't start with a slash",
+		"/{id}//delete: pattern has an empty segment",
+	}
+
+	issues := r.Lint()
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %d: %v", len(expected), len(issues), issues)
+	}
+	for i, issue := range issues {
+		if !strings.Contains(issue.String(), expected[i]) {
+			t.Errorf("Expected issue %q, got %q", expected[i], issue.String())
+		}
+		if filepath.Base(issue.File) != "lint_test.go" || issue.Line == 0 {
+			t.Errorf("Expected the issue to point to the registration, got %s:%d", issue.File, issue.Line)
+		}
+	}
+}
+
+func TestLintUnreachable(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	t.Run("matcher", func(t *testing.T) {
+		// Create a new router instance with a matcher that returns the first matching route
+		r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithMatcher(&segmentMatcher{}))
+		r.GET("/packages/{name}", handler)
+		r.GET("/packages/latest", handler)
+		r.GET("/users/me", handler)
+		r.GET("/users/{id}", handler)
+		r.POST("/packages/latest", handler)
+
+		// The matcher has the routes once they're set up
+		if issues := r.Lint(); len(issues) != 0 {
+			t.Errorf("Expected no issues before the routes are set up, got %v", issues)
+		}
+		if err := r.Build(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		issues := r.Lint()
+		if len(issues) != 1 {
+			t.Fatalf("Expected 1 issue, got %d: %v", len(issues), issues)
+		}
+		if expected := "/packages/latest: unreachable, requests are matched by GET /packages/{name}/{$} registered at "; !strings.Contains(issues[0].String(), expected) {
+			t.Errorf("Expected issue %q, got %q", expected, issues[0].String())
+		}
+	})
+
+	t.Run("mux", func(t *testing.T) {
+		// The ServeMux prefers the most specific pattern, whatever the order
+		r := router.NewRouter()
+		r.GET("/packages/{name}", handler)
+		r.GET("/packages/latest", handler)
+		r.Mount("/static", http.NotFoundHandler())
+		r.GET("/static/logo.png", handler)
+		r.GET("/files/{path...}", handler)
+
+		if issues := r.Lint(); len(issues) != 0 {
+			t.Errorf("Expected no issues, got %v", issues)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+// Package listquery parses the sorting and filtering parameters of list endpoints, e.g.
+// "?sort=-created_at,name&filter[status]=active", against the fields a route allows.
+package listquery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gogo-framework/router"
+)
+
+// Operators are the filter operators, "eq" is used when a filter has no operator.
+var Operators = []string{"eq", "ne", "gt", "gte", "lt", "lte", "contains"}
+
+type Config struct {
+	// Sortable are the fields the list can be sorted by
+	Sortable []string
+	// Filterable are the fields the list can be filtered by
+	Filterable []string
+	// DefaultSort is used when the request doesn't have a sort parameter, e.g. "-created_at"
+	DefaultSort string
+}
+
+// Error is returned by Parse for unknown fields and invalid parameters.
+type Error struct {
+	Param   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("listquery: %s %s", e.Param, e.Message)
+}
+
+// Sort is a field to sort by, fields prefixed with "-" are sorted descending.
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+// Filter restricts the list to items where the field matches one of the values with the operator, e.g.
+// "filter[status]=active,invited" or "filter[created_at][gte]=2024-01-01".
+type Filter struct {
+	Field    string
+	Operator string
+	Values   []string
+}
+
+// Query is the parsed sorting and filtering of a request.
+type Query struct {
+	Sort    []Sort
+	Filters []Filter
+}
+
+// Filter returns the filters for the field.
+func (q Query) Filter(field string) []Filter {
+	var filters []Filter
+	for _, filter := range q.Filters {
+		if filter.Field == field {
+			filters = append(filters, filter)
+		}
+	}
+	return filters
+}
+
+// Parse reads the sort and filter query parameters of the request. Fields that aren't allowed by the config return an
+// *Error, which should be responded to with 400.
+func Parse(r *http.Request, config Config) (Query, error) {
+	query := router.Query(r)
+	var result Query
+
+	sort := query.Get("sort")
+	if sort == "" {
+		sort = config.DefaultSort
+	}
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		s := Sort{Field: strings.TrimPrefix(field, "-"), Descending: strings.HasPrefix(field, "-")}
+		if !slices.Contains(config.Sortable, s.Field) {
+			return Query{}, &Error{Param: "sort", Message: fmt.Sprintf("can't sort by %s, allowed are %s", s.Field, allowed(config.Sortable))}
+		}
+		result.Sort = append(result.Sort, s)
+	}
+
+	// Filters are sorted by their parameter, so the result doesn't depend on the order of the map
+	var params []string
+	for param := range query {
+		if strings.HasPrefix(param, "filter[") {
+			params = append(params, param)
+		}
+	}
+	slices.Sort(params)
+	for _, param := range params {
+		field, operator, ok := parseFilterParam(param)
+		if !ok {
+			return Query{}, &Error{Param: param, Message: "must be filter[field] or filter[field][operator]"}
+		}
+		if !slices.Contains(config.Filterable, field) {
+			return Query{}, &Error{Param: param, Message: fmt.Sprintf("can't filter by %s, allowed are %s", field, allowed(config.Filterable))}
+		}
+		if !slices.Contains(Operators, operator) {
+			return Query{}, &Error{Param: param, Message: fmt.Sprintf("has unknown operator %s, allowed are %s", operator, allowed(Operators))}
+		}
+		filter := Filter{Field: field, Operator: operator}
+		for _, value := range query[param] {
+			for _, part := range strings.Split(value, ",") {
+				if part != "" {
+					filter.Values = append(filter.Values, part)
+				}
+			}
+		}
+		if len(filter.Values) > 0 {
+			result.Filters = append(result.Filters, filter)
+		}
+	}
+	return result, nil
+}
+
+// parseFilterParam splits "filter[field]" and "filter[field][operator]" into the field and the operator
+func parseFilterParam(param string) (string, string, bool) {
+	rest := strings.TrimPrefix(param, "filter[")
+	field, rest, ok := strings.Cut(rest, "]")
+	if !ok || field == "" {
+		return "", "", false
+	}
+	if rest == "" {
+		return field, "eq", true
+	}
+	if !strings.HasPrefix(rest, "[") || !strings.HasSuffix(rest, "]") || len(rest) < 3 {
+		return "", "", false
+	}
+	return field, rest[1 : len(rest)-1], true
+}
+
+func allowed(fields []string) string {
+	if len(fields) == 0 {
+		return "none"
+	}
+	return strings.Join(fields, ", ")
+}
+
+type contextKey struct{}
+
+// Middleware parses the query for the route, invalid queries get a 400 response through the error handler of the
+// router. Handlers read the result with FromRequest.
+//
+//	r.GET("/users", listUsers).Use(listquery.Middleware(listquery.Config{Sortable: []string{"name", "created_at"}}))
+func Middleware(config Config) router.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			query, err := Parse(r, config)
+			if err != nil {
+				router.Error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			next(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, query)))
+		}
+	}
+}
+
+// FromRequest returns the query parsed by Middleware.
+func FromRequest(r *http.Request) (Query, bool) {
+	query, ok := r.Context().Value(contextKey{}).(Query)
+	return query, ok
+}
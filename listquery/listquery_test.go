@@ -0,0 +1,85 @@
+package listquery_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/listquery"
+)
+
+func TestListQuery(t *testing.T) {
+	config := listquery.Config{
+		Sortable:    []string{"name", "created_at"},
+		Filterable:  []string{"status", "created_at"},
+		DefaultSort: "-created_at",
+	}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		query, _ := listquery.FromRequest(r)
+		json.NewEncoder(w).Encode(query)
+	}).Use(listquery.Middleware(config))
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   listquery.Query
+		error  string
+	}{
+		{
+			name:   "default sort",
+			status: http.StatusOK,
+			want:   listquery.Query{Sort: []listquery.Sort{{Field: "created_at", Descending: true}}},
+		},
+		{
+			name:   "sort and filters",
+			query:  "?sort=-created_at,name&filter[status]=active,invited&filter[created_at][gte]=2024-01-01",
+			status: http.StatusOK,
+			want: listquery.Query{
+				Sort: []listquery.Sort{{Field: "created_at", Descending: true}, {Field: "name"}},
+				Filters: []listquery.Filter{
+					{Field: "created_at", Operator: "gte", Values: []string{"2024-01-01"}},
+					{Field: "status", Operator: "eq", Values: []string{"active", "invited"}},
+				},
+			},
+		},
+		{name: "unknown sort field", query: "?sort=password", status: http.StatusBadRequest, error: "can't sort by password"},
+		{name: "unknown filter field", query: "?filter[role]=admin", status: http.StatusBadRequest, error: "can't filter by role"},
+		{name: "unknown operator", query: "?filter[status][like]=a", status: http.StatusBadRequest, error: "unknown operator like"},
+		{name: "invalid filter", query: "?filter[status=a", status: http.StatusBadRequest, error: "must be filter[field]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/", nil)
+			req.URL.RawQuery = strings.TrimPrefix(tt.query, "?")
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				if !strings.Contains(rr.Body.String(), tt.error) {
+					t.Errorf("Expected error %q, got %q", tt.error, rr.Body.String())
+				}
+				return
+			}
+			var got listquery.Query
+			json.NewDecoder(rr.Body).Decode(&got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if filters := got.Filter("status"); len(tt.want.Filters) > 0 && len(filters) != 1 {
+				t.Errorf("Expected one status filter, got %v", filters)
+			}
+		})
+	}
+}
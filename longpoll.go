@@ -0,0 +1,89 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// EventSource provides the data for long polling routes.
+type EventSource interface {
+	// Wait blocks until data is available for the request, or the context is done in which case it returns the
+	// context error.
+	Wait(ctx context.Context, r *http.Request) (any, error)
+}
+
+// EventSourceFunc is an adapter to allow the use of ordinary functions as an EventSource.
+type EventSourceFunc func(ctx context.Context, r *http.Request) (any, error)
+
+func (f EventSourceFunc) Wait(ctx context.Context, r *http.Request) (any, error) {
+	return f(ctx, r)
+}
+
+// LongPoll registers a GET route that holds the request open until the source has data, which is sent as JSON.
+// When the timeout elapses first the response is 204 No Content, so the client can poll again.
+// Nothing is written when the client disconnects.
+func (r *Router) LongPoll(pattern string, source EventSource, timeout time.Duration) *Route {
+	return r.GET(pattern, func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+
+		data, err := source.Wait(ctx, req)
+		if err != nil {
+			switch {
+			case req.Context().Err() != nil:
+				// The client is gone, there is no one to respond to
+			case errors.Is(err, context.DeadlineExceeded):
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				Error(w, req, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(data)
+	})
+}
+
+// Broadcaster is an EventSource that sends every published value to all requests waiting at that moment.
+type Broadcaster struct {
+	mutex   sync.Mutex
+	current *broadcast
+}
+
+type broadcast struct {
+	done  chan struct{}
+	value any
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{current: &broadcast{done: make(chan struct{})}}
+}
+
+// Publish wakes up all waiting requests with the value.
+func (b *Broadcaster) Publish(value any) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.current.value = value
+	close(b.current.done)
+	b.current = &broadcast{done: make(chan struct{})}
+}
+
+func (b *Broadcaster) Wait(ctx context.Context, r *http.Request) (any, error) {
+	b.mutex.Lock()
+	current := b.current
+	b.mutex.Unlock()
+
+	select {
+	case <-current.done:
+		return current.value, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
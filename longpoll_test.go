@@ -0,0 +1,68 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestLongPoll(t *testing.T) {
+	broadcaster := router.NewBroadcaster()
+	waiting := make(chan struct{}, 1)
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.LongPoll("/events", router.EventSourceFunc(func(ctx context.Context, req *http.Request) (any, error) {
+		waiting <- struct{}{}
+		return broadcaster.Wait(ctx, req)
+	}), 50*time.Millisecond)
+
+	t.Run("data available", func(t *testing.T) {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/events/", nil))
+			done <- rr
+		}()
+		<-waiting
+		broadcaster.Publish(map[string]string{"message": "hello"})
+
+		rr := <-done
+		if rr.Code != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		if body := rr.Body.String(); body != "{\"message\":\"hello\"}\n" {
+			t.Errorf("handler returned unexpected body: got %v", body)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/events/", nil))
+		<-waiting
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNoContent)
+		}
+	})
+
+	t.Run("client disconnect", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/events/", nil).WithContext(ctx))
+			done <- rr
+		}()
+		<-waiting
+		cancel()
+
+		rr := <-done
+		if rr.Body.Len() != 0 || rr.Code != http.StatusOK {
+			t.Errorf("expected nothing to be written, got %v %v", rr.Code, rr.Body.String())
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ManifestRoute describes a route in a RouteManifest.
+type ManifestRoute struct {
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path"`
+	Name       string `json:"name,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+}
+
+func (mr ManifestRoute) String() string {
+	if mr.Method == "" {
+		return mr.Path
+	}
+	return mr.Method + " " + mr.Path
+}
+
+// RouteManifest is a snapshot of the route table, it can be saved as JSON and compared with a later version of the
+// routes using Router.Diff.
+type RouteManifest struct {
+	Routes []ManifestRoute `json:"routes"`
+}
+
+// ReadManifest reads a manifest that was written with RouteManifest.WriteTo.
+func ReadManifest(reader io.Reader) (RouteManifest, error) {
+	var manifest RouteManifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("router: invalid route manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+func (m RouteManifest) WriteTo(writer io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := writer.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// Manifest returns a snapshot of the registered routes. Routes are deprecated when their "deprecated" metadata is set.
+func (r *Router) Manifest() RouteManifest {
+	var manifest RouteManifest
+	for _, route := range r.Routes() {
+		_, deprecated := route.Get("deprecated")
+		manifest.Routes = append(manifest.Routes, ManifestRoute{
+			Method:     route.Method,
+			Path:       pathFromPattern(route.Method, r.routePattern(route)),
+			Name:       route.name,
+			Deprecated: deprecated,
+		})
+	}
+	return manifest
+}
+
+// RouteChange is a named route of which the method or path changed.
+type RouteChange struct {
+	Previous ManifestRoute
+	Current  ManifestRoute
+}
+
+// RouteDiff lists the differences between two route tables. Named routes are matched by name, so a changed path
+// shows up as a change, other routes are matched by method and path.
+type RouteDiff struct {
+	Added   []ManifestRoute
+	Removed []ManifestRoute
+	Changed []RouteChange
+}
+
+// Breaking reports whether routes were removed or changed, which breaks existing clients.
+func (d RouteDiff) Breaking() bool {
+	return len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// Warnings describes the breaking changes, routes that were deprecated in the previous manifest are mentioned as
+// such, as removing them is expected.
+func (d RouteDiff) Warnings() []string {
+	var warnings []string
+	for _, route := range d.Removed {
+		if route.Deprecated {
+			warnings = append(warnings, fmt.Sprintf("deprecated route %s was removed", route))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("route %s was removed without being deprecated", route))
+		}
+	}
+	for _, change := range d.Changed {
+		warnings = append(warnings, fmt.Sprintf("route %q changed from %s to %s", change.Current.Name, change.Previous, change.Current))
+	}
+	return warnings
+}
+
+// Diff compares the current routes with a previous manifest, e.g. one saved at the last deploy.
+func (r *Router) Diff(previous RouteManifest) RouteDiff {
+	var diff RouteDiff
+	current := r.Manifest()
+
+	key := func(route ManifestRoute) string {
+		if route.Name != "" {
+			return "name:" + route.Name
+		}
+		return "route:" + route.String()
+	}
+	currentByKey := make(map[string]ManifestRoute, len(current.Routes))
+	for _, route := range current.Routes {
+		currentByKey[key(route)] = route
+	}
+	previousByKey := make(map[string]ManifestRoute, len(previous.Routes))
+	for _, route := range previous.Routes {
+		previousByKey[key(route)] = route
+
+		now, ok := currentByKey[key(route)]
+		switch {
+		case !ok:
+			diff.Removed = append(diff.Removed, route)
+		case now.Method != route.Method || now.Path != route.Path:
+			diff.Changed = append(diff.Changed, RouteChange{Previous: route, Current: now})
+		}
+	}
+	for _, route := range current.Routes {
+		if _, ok := previousByKey[key(route)]; !ok {
+			diff.Added = append(diff.Added, route)
+		}
+	}
+	return diff
+}
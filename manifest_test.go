@@ -0,0 +1,65 @@
+package router_test
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestDiff(t *testing.T) {
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	// Create the router of the previous deploy and save its manifest
+	previous := router.NewRouter()
+	previous.GET("/users", noop).Name("users.list")
+	previous.GET("/users/{id}", noop).Name("users.show")
+	previous.DELETE("/users/{id}", noop)
+	previous.GET("/legacy", noop).Set("deprecated", true)
+	previous.Group("/admin", func(r *router.Router) {
+		r.GET("/stats", noop)
+	})
+
+	var buf bytes.Buffer
+	if _, err := previous.Manifest().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := router.ReadManifest(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/users", noop).Name("users.list")
+	r.GET("/members/{id}", noop).Name("users.show")
+	r.POST("/users", noop)
+	r.Group("/admin", func(r *router.Router) {
+		r.GET("/stats", noop)
+	})
+
+	diff := r.Diff(manifest)
+	if !diff.Breaking() {
+		t.Error("Expected the diff to be breaking")
+	}
+
+	expectedAdded := []router.ManifestRoute{{Method: http.MethodPost, Path: "/users"}}
+	if !reflect.DeepEqual(diff.Added, expectedAdded) {
+		t.Errorf("Expected added %v, got %v", expectedAdded, diff.Added)
+	}
+
+	expectedWarnings := []string{
+		"route DELETE /users/{id} was removed without being deprecated",
+		"deprecated route GET /legacy was removed",
+		`route "users.show" changed from GET /users/{id} to GET /members/{id}`,
+	}
+	if !reflect.DeepEqual(diff.Warnings(), expectedWarnings) {
+		t.Errorf("Expected warnings %q, got %q", expectedWarnings, diff.Warnings())
+	}
+
+	if previous.Diff(previous.Manifest()).Breaking() {
+		t.Error("Expected no differences for the same routes")
+	}
+}
@@ -0,0 +1,151 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// MatchExplanation describes how the router handles a request, it's returned by the match endpoint of the admin API.
+type MatchExplanation struct {
+	Method string `json:"method"`
+	Host   string `json:"host,omitempty"`
+	Path   string `json:"path"`
+	// Redirect is set when the mux redirects the request, e.g. to add a trailing slash. The rest of the
+	// explanation is about the request to the new path
+	Redirect   string            `json:"redirect,omitempty"`
+	Matched    *MatchedRouteInfo `json:"matched"`
+	Candidates []MatchCandidate  `json:"candidates"`
+}
+
+type MatchedRouteInfo struct {
+	Pattern string `json:"pattern"`
+	Name    string `json:"name,omitempty"`
+	// Source is where the route was registered, see Route.Source
+	Source string `json:"source,omitempty"`
+	// Middlewares are the middlewares that run, in order
+	Middlewares []string `json:"middlewares"`
+}
+
+// MatchCandidate is a route that didn't match, with the reason why.
+type MatchCandidate struct {
+	Pattern string `json:"pattern"`
+	Source  string `json:"source,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// Explain describes which route matches a request with the method, host and path, which middlewares run, and why
+// the other routes don't match. The host matters for patterns with a host and custom matchers, it can be empty.
+func (r *Router) Explain(method string, host string, path string) (MatchExplanation, error) {
+	if !r.hasSetupRoutes.Load() {
+		r.setup()
+	}
+	explanation := MatchExplanation{Method: method, Host: host, Path: path, Candidates: []MatchCandidate{}}
+
+	req, err := http.NewRequest(method, "/", nil)
+	if err != nil {
+		return explanation, fmt.Errorf("router: can't explain %s %s: %w", method, path, err)
+	}
+	req.Host = host
+	req.URL.Path = path
+	handler, pattern := r.matcher.Handler(req)
+	if fmt.Sprintf("%T", handler) == fmt.Sprintf("%T", http.RedirectHandler("", 0)) {
+		// The mux redirects, e.g. to add a trailing slash, running the redirect handler is harmless
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		location, err := req.URL.Parse(rr.Header().Get("Location"))
+		if err != nil {
+			return explanation, fmt.Errorf("router: can't follow the redirect of %s %s: %w", method, path, err)
+		}
+		explanation.Redirect = location.Path
+		req.URL.Path = location.Path
+		if location.Host != "" {
+			req.Host = location.Host
+		}
+		_, pattern = r.matcher.Handler(req)
+	}
+	matched := r.routeByPattern(pattern)
+
+	if matched != nil {
+		var names []string
+		for _, middleware := range r.middlewaresFor(matched) {
+			names = append(names, funcName(middleware))
+		}
+		explanation.Matched = &MatchedRouteInfo{
+			Pattern:     matched.fullPattern,
+			Name:        matched.name,
+			Source:      matched.Source(),
+			Middlewares: names,
+		}
+	}
+
+	for _, route := range r.Routes() {
+		if route == matched {
+			continue
+		}
+		explanation.Candidates = append(explanation.Candidates, MatchCandidate{
+			Pattern: route.fullPattern,
+			Source:  route.Source(),
+			Reason:  rejectionReason(route, matched, req),
+		})
+	}
+	return explanation, nil
+}
+
+func (r *Router) routeByPattern(pattern string) *Route {
+	if pattern == "" {
+		return nil
+	}
+	for _, route := range r.Routes() {
+		if route.fullPattern == pattern {
+			return route
+		}
+	}
+	return nil
+}
+
+// middlewaresFor returns the middlewares of the route in the order SetupRoutes applies them
+func (r *Router) middlewaresFor(route *Route) []Middleware {
+	middlewares := append([]Middleware{}, r.middlewares...)
+	if route.group != nil {
+		middlewares = append(middlewares, route.group.Middlewares...)
+	}
+	return append(middlewares, route.Middlewares...)
+}
+
+func rejectionReason(route *Route, matched *Route, req *http.Request) string {
+	if !patternMatches(route.fullPattern, req) {
+		if route.Method != "" && patternMatches(strings.TrimPrefix(route.fullPattern, route.Method+" "), req) {
+			return fmt.Sprintf("the path matches, but the method is %s instead of %s", route.Method, req.Method)
+		}
+		return "the path doesn't match"
+	}
+	if matched != nil {
+		return fmt.Sprintf("matches, but %q is more specific", matched.fullPattern)
+	}
+	return "matches, but the request is redirected"
+}
+
+// patternMatches checks if the pattern on its own would match the request
+func patternMatches(pattern string, req *http.Request) bool {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, func(http.ResponseWriter, *http.Request) {})
+	_, matchedPattern := mux.Handler(req)
+	return matchedPattern == pattern
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// funcName returns a readable name of a function, e.g. "middleware.RateLimit" for a middleware it returned
+func funcName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = closureSuffix.ReplaceAllString(name, "")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,165 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestExplain(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.EnableAdmin("/__router", router.AdminConfig{Token: "secret"})
+	r.Use(middleware.Chaos(middleware.ChaosConfig{}))
+
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/users/{id}", noop).Name("users.show").Use(middleware.LoadShed(middleware.LoadShedConfig{}))
+	r.GET("/users/me", noop)
+	r.POST("/users/{id}", noop)
+	r.GET("/orders", noop)
+
+	// Define test cases
+	tests := []struct {
+		method      string
+		path        string
+		redirect    string
+		matched     string
+		middlewares []string
+		reasons     map[string]string
+	}{
+		{
+			method:      http.MethodGet,
+			path:        "/users/5",
+			redirect:    "/users/5/",
+			matched:     "GET /users/{id}/{$}",
+			middlewares: []string{"middleware.Chaos", "middleware.LoadShed"},
+			reasons: map[string]string{
+				"GET /users/me/{$}":    "the path doesn't match",
+				"POST /users/{id}/{$}": "the path matches, but the method is POST instead of GET",
+				"GET /orders/{$}":      "the path doesn't match",
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/users/me/",
+			matched:     "GET /users/me/{$}",
+			middlewares: []string{"middleware.Chaos"},
+			reasons: map[string]string{
+				"GET /users/{id}/{$}": `matches, but "GET /users/me/{$}" is more specific`,
+			},
+		},
+		{
+			method: http.MethodDelete,
+			path:   "/users/5/",
+			reasons: map[string]string{
+				"GET /users/{id}/{$}": "the path matches, but the method is GET instead of DELETE",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		explanation, err := r.Explain(tc.method, "", tc.path)
+		if err != nil {
+			t.Fatalf("%s %s: expected no error, got %v", tc.method, tc.path, err)
+		}
+		if explanation.Redirect != tc.redirect {
+			t.Errorf("%s %s: expected redirect %q, got %q", tc.method, tc.path, tc.redirect, explanation.Redirect)
+		}
+		if tc.matched == "" {
+			if explanation.Matched != nil {
+				t.Errorf("%s %s: expected no match, got %q", tc.method, tc.path, explanation.Matched.Pattern)
+			}
+		} else if explanation.Matched == nil || explanation.Matched.Pattern != tc.matched {
+			t.Errorf("%s %s: expected match %q, got %+v", tc.method, tc.path, tc.matched, explanation.Matched)
+		} else if !reflect.DeepEqual(explanation.Matched.Middlewares, tc.middlewares) {
+			t.Errorf("%s %s: expected middlewares %v, got %v", tc.method, tc.path, tc.middlewares, explanation.Matched.Middlewares)
+		}
+
+		reasons := map[string]string{}
+		for _, candidate := range explanation.Candidates {
+			reasons[candidate.Pattern] = candidate.Reason
+		}
+		for pattern, reason := range tc.reasons {
+			if reasons[pattern] != reason {
+				t.Errorf("%s %s: expected %s to be rejected because %q, got %q", tc.method, tc.path, pattern, reason, reasons[pattern])
+			}
+		}
+	}
+
+	// The explanation is available through the admin API
+	req := httptest.NewRequest(http.MethodGet, "/__router/match/?method=POST&path=/users/5/", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var explanation router.MatchExplanation
+	if err := json.Unmarshal(rr.Body.Bytes(), &explanation); err != nil {
+		t.Fatal(err)
+	}
+	if explanation.Matched == nil || explanation.Matched.Pattern != "POST /users/{id}/{$}" {
+		t.Errorf("Expected the admin API to explain the match, got %s", rr.Body.String())
+	}
+}
+
+func TestExplainHost(t *testing.T) {
+	// Create a new router instance with a matcher that restricts routes to a host
+	mux := http.NewServeMux()
+	r := router.NewRouter(router.WithMux(mux), router.WithMatcher(&segmentMatcher{}))
+	r.EnableAdmin("/__router", router.AdminConfig{Token: "secret"})
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/internal/{name}", noop).Set("host", "internal.example.com")
+	mux.Handle("/old/", http.RedirectHandler("http://[::1", http.StatusMovedPermanently))
+
+	// Define test cases
+	tests := []struct {
+		host    string
+		matched bool
+	}{
+		{"internal.example.com", true},
+		{"example.com", false},
+		{"", false},
+	}
+
+	for _, tc := range tests {
+		explanation, err := r.Explain(http.MethodGet, tc.host, "/internal/metrics/")
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tc.host, err)
+		}
+		if matched := explanation.Matched != nil; matched != tc.matched {
+			t.Errorf("%s: expected matched to be %v, got %+v", tc.host, tc.matched, explanation.Matched)
+		}
+	}
+
+	// A redirect to an invalid location and an invalid method are errors instead of a panic
+	if _, err := r.Explain(http.MethodGet, "", "/old/page"); err == nil {
+		t.Error("Expected an error for an invalid redirect")
+	}
+	if _, err := r.Explain("GET /", "", "/internal/metrics/"); err == nil {
+		t.Error("Expected an error for an invalid method")
+	}
+
+	// The admin API explains requests to the host it was requested at, unless a host is given
+	for target, matched := range map[string]bool{
+		"http://internal.example.com/__router/match/?path=/internal/metrics/":            true,
+		"http://example.com/__router/match/?path=/internal/metrics/":                     false,
+		"http://example.com/__router/match/?host=internal.example.com&path=/internal/x/": true,
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		var explanation router.MatchExplanation
+		if err := json.Unmarshal(rr.Body.Bytes(), &explanation); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+		if (explanation.Matched != nil) != matched {
+			t.Errorf("%s: expected matched to be %v, got %s", target, matched, rr.Body.String())
+		}
+	}
+}
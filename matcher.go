@@ -0,0 +1,58 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Matcher is a routing engine that can replace the ServeMux, e.g. to route by IP prefix or to match encoded slashes
+// in path parameters. Add is called for every route when the routes are set up, with FullPattern and Path of the
+// route set, and for routes registered after the router started serving. Match is called concurrently for every
+// request with the escaped path. It returns the route and the values of its path parameters, which handlers read
+// with r.PathValue.
+type Matcher interface {
+	Add(route *Route) error
+	Match(method string, host string, path string) (*Route, map[string]string, bool)
+}
+
+// WithMatcher sets a custom matcher for the routes. Requests that it doesn't match are served by the ServeMux of the
+// router, which responds with 404 unless handlers were registered on it directly. RouterConfig.ShardRoutes doesn't
+// apply to a custom matcher. Clones of the router use the ServeMux, as the matcher holds the routes of this router.
+func WithMatcher(m Matcher) Option {
+	return func(r *Router) {
+		r.customMatcher = m
+	}
+}
+
+// customMatcher adapts a Matcher to the interface of the ServeMux used by the router
+type customMatcher struct {
+	matcher  Matcher
+	fallback *http.ServeMux
+}
+
+func (m *customMatcher) Handler(req *http.Request) (http.Handler, string) {
+	route, params, ok := m.matcher.Match(req.Method, req.Host, req.URL.EscapedPath())
+	if !ok || route.handler == nil {
+		return m.fallback.Handler(req)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.Pattern = route.fullPattern
+		for name, value := range params {
+			req.SetPathValue(name, value)
+		}
+		route.handler(w, req)
+	}), route.fullPattern
+}
+
+func (m *customMatcher) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	handler, _ := m.Handler(req)
+	handler.ServeHTTP(w, req)
+}
+
+// addRoute adds the route with its compiled handler to the custom matcher
+func (r *Router) addRoute(route *Route, handler http.HandlerFunc) {
+	route.handler = handler
+	if err := r.customMatcher.Add(route); err != nil {
+		panic(fmt.Sprintf("router: %s registered at %s: %v", route.fullPattern, route.Source(), err))
+	}
+}
@@ -0,0 +1,120 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+// segmentMatcher matches the escaped path by segments, so a parameter can contain an encoded slash, and routes can be
+// restricted to a host with "host" metadata
+type segmentMatcher struct {
+	mutex  sync.RWMutex
+	routes []*router.Route
+}
+
+func (m *segmentMatcher) Add(route *router.Route) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, other := range m.routes {
+		if other.Method == route.Method && other.Path() == route.Path() {
+			return fmt.Errorf("duplicate route %s", route.Path())
+		}
+	}
+	m.routes = append(m.routes, route)
+	return nil
+}
+
+func (m *segmentMatcher) Match(method string, host string, path string) (*router.Route, map[string]string, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, route := range m.routes {
+		if route.Method != method {
+			continue
+		}
+		if value, ok := route.Get("host"); ok && value != host {
+			continue
+		}
+		patternSegments := strings.Split(strings.Trim(route.Path(), "/"), "/")
+		if len(patternSegments) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		for i, segment := range patternSegments {
+			if strings.HasPrefix(segment, "{") {
+				params[strings.Trim(segment, "{}")], _ = url.PathUnescape(segments[i])
+			} else if segment != segments[i] {
+				params = nil
+				break
+			}
+		}
+		if params != nil {
+			return route, params, true
+		}
+	}
+	return nil, nil, false
+}
+
+func TestMatcher(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", router.MatchedRoute(r).Path(), r.PathValue("name"))
+	}
+	header := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Middleware", "global")
+			next(w, r)
+		}
+	}
+
+	// Create a new router instance with a custom matcher
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithMatcher(&segmentMatcher{}))
+	r.Use(header)
+	r.GET("/packages/{name}", handler)
+	r.GET("/internal/{name}", handler).Set("host", "internal.example.com")
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		host   string
+		path   string
+		status int
+		body   string
+	}{
+		{"encoded slash", "example.com", "/packages/%40scope%2Fpkg", http.StatusOK, "/packages/{name} @scope/pkg"},
+		{"host", "internal.example.com", "/internal/stats", http.StatusOK, "/internal/{name} stats"},
+		{"other host", "example.com", "/internal/stats", http.StatusNotFound, ""},
+		{"unknown path", "example.com", "/users/1", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://"+tt.host+tt.path, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+			if tt.status == http.StatusOK && (rr.Body.String() != tt.body || rr.Header().Get("X-Middleware") != "global") {
+				t.Errorf("Expected body %q with the middleware, got %q", tt.body, rr.Body.String())
+			}
+		})
+	}
+
+	// Errors of the matcher panic with the source of the route
+	defer func() {
+		if recovered := fmt.Sprint(recover()); !strings.Contains(recovered, "duplicate route") {
+			t.Errorf("Expected the error of the matcher, got %s", recovered)
+		}
+	}()
+	duplicate := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithMatcher(&segmentMatcher{}))
+	duplicate.GET("/packages/{name}", handler)
+	duplicate.GET("/packages/{name}", handler)
+	duplicate.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Matrix splits a path parameter with matrix parameters, as used by some older frameworks, into its value and the
+// parameters. For the route "/map/{point}" and the path "/map/point;lat=50;long=20" it returns "point" and
+// lat=50&long=20. Parameters without a value get an empty value, repeated parameters keep all values.
+func Matrix(r *http.Request, name string) (string, url.Values) {
+	value, rest, _ := strings.Cut(r.PathValue(name), ";")
+	params := url.Values{}
+	for _, param := range strings.Split(rest, ";") {
+		if param == "" {
+			continue
+		}
+		key, paramValue, _ := strings.Cut(param, "=")
+		params.Add(key, paramValue)
+	}
+	return value, params
+}
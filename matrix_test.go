@@ -0,0 +1,39 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestMatrix(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/map/{point}", func(w http.ResponseWriter, r *http.Request) {
+		value, params := router.Matrix(r, "point")
+		w.Write([]byte(value + " " + params.Encode()))
+	})
+
+	// Define test cases
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"matrix parameters", "/map/point;lat=50;long=20/", "point lat=50&long=20"},
+		{"repeated and empty parameters", "/map/point;tag=a;tag=b;;visible/", "point tag=a&tag=b&visible="},
+		{"without parameters", "/map/point/", "point "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Body.String() != tt.body {
+				t.Errorf("Expected %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
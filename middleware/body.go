@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/gogo-framework/router"
+)
+
+// DefaultBufferBodySize is used when BufferBody is given a size of 0.
+const DefaultBufferBodySize = 1 << 20
+
+type bufferedBodyKey struct{}
+
+// BufferBody reads request bodies of up to max bytes into memory, so several middlewares and the handler can read
+// them, e.g. to verify a signature, bind the body and log it for an audit. Readers get the body with BufferedBody,
+// which restores r.Body every time. Larger bodies aren't buffered but streamed to the handler as usual.
+func BufferBody(max int64) router.Middleware {
+	if max <= 0 {
+		max = DefaultBufferBodySize
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				next(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, max+1))
+			if err != nil {
+				router.Error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			if int64(len(body)) > max {
+				// Pass the part that was already read on, followed by the rest of the stream
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+				next(w, r)
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), bufferedBodyKey{}, body))
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+			next(w, r)
+		}
+	}
+}
+
+// BufferedBody returns the body buffered by BufferBody and resets r.Body, so the next reader gets the whole body
+// again. It returns false when the body wasn't buffered, because the middleware isn't used or the body was too large.
+func BufferedBody(r *http.Request) ([]byte, bool) {
+	body, ok := r.Context().Value(bufferedBodyKey{}).([]byte)
+	if !ok {
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
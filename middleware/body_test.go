@@ -0,0 +1,94 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestBufferBody(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+
+	// audit reads the body before the handler does
+	var audited string
+	audit := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			body, ok := middleware.BufferedBody(r)
+			if !ok {
+				body, _ = io.ReadAll(r.Body)
+			}
+			audited = string(body)
+			next(w, r)
+		}
+	}
+	r.POST("/events", func(w http.ResponseWriter, r *http.Request) {
+		// Reading the body twice returns it both times
+		first, _ := middleware.BufferedBody(r)
+		second, _ := io.ReadAll(r.Body)
+		w.Write(first)
+		w.Write(second)
+	}).Use(middleware.BufferBody(8), audit)
+	r.POST("/uploads", func(w http.ResponseWriter, r *http.Request) {
+		_, buffered := middleware.BufferedBody(r)
+		body, _ := io.ReadAll(r.Body)
+		if buffered {
+			w.Write([]byte("buffered "))
+		}
+		w.Write(body)
+	}).Use(middleware.BufferBody(8))
+
+	// Define test cases
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		audited string
+		want    string
+	}{
+		{"small body is read by everyone", "/events/", "payload", "payload", "payloadpayload"},
+		{"large body is streamed", "/uploads/", "a large upload", "", "a large upload"},
+		{"small upload is buffered", "/uploads/", "small", "", "buffered small"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audited = ""
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			if w.Body.String() != tt.want {
+				t.Errorf("Expected body %q, got %q", tt.want, w.Body.String())
+			}
+			if audited != tt.audited {
+				t.Errorf("Expected the audit to read %q, got %q", tt.audited, audited)
+			}
+		})
+	}
+}
+
+func TestBufferBodyWebhook(t *testing.T) {
+	secret := "s3cr3t"
+	body := `{"event":"push"}`
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.POST("/github", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b)
+	}).Use(middleware.BufferBody(0), middleware.WebhookSignature(middleware.WebhookConfig{
+		Verifier: middleware.GitHubVerifier(secret),
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/github/", strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+sign(secret, body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != body {
+		t.Errorf("Expected the verified body, got %d %q", w.Code, w.Body.String())
+	}
+}
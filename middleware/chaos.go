@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+// ErrChaos is the error passed to the router's error handler for injected faults.
+var ErrChaos = errors.New("chaos: injected fault")
+
+type ChaosConfig struct {
+	// LatencyP50 is the median of the latency added to requests, the latency is exponentially distributed so some
+	// requests get a much longer delay. 0 adds no latency
+	LatencyP50 time.Duration
+	// ErrorRate is the fraction of requests that are aborted, between 0 and 1
+	ErrorRate float64
+	// AbortStatus is the status code of aborted requests, defaults to 503
+	AbortStatus int
+	// Toggle enables and disables the chaos at runtime, without it the chaos is always enabled
+	Toggle *ChaosToggle
+}
+
+// Chaos injects latency and errors, to test how clients handle slow and failing routes. Use a toggle to switch it
+// on and off through an admin endpoint. Routes of the admin API are left alone, so the toggle always works.
+func Chaos(cfg ChaosConfig) router.Middleware {
+	if cfg.AbortStatus == 0 {
+		cfg.AbortStatus = http.StatusServiceUnavailable
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if cfg.Toggle != nil && !cfg.Toggle.Enabled() || adminRoute(r) {
+				next(w, r)
+				return
+			}
+
+			if cfg.LatencyP50 > 0 {
+				// An exponential distribution with the median at LatencyP50
+				delay := time.Duration(rand.ExpFloat64() * float64(cfg.LatencyP50) / math.Ln2)
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-r.Context().Done():
+					timer.Stop()
+					return
+				}
+			}
+			if rand.Float64() < cfg.ErrorRate {
+				router.Error(w, r, cfg.AbortStatus, ErrChaos)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// ChaosToggle switches the Chaos middleware on and off. It's a handler for the Endpoints of router.AdminConfig, GET
+// returns the state as {"enabled": true} and PUT or POST with the same body changes it.
+type ChaosToggle struct {
+	enabled atomic.Bool
+}
+
+func NewChaosToggle(enabled bool) *ChaosToggle {
+	t := &ChaosToggle{}
+	t.enabled.Store(enabled)
+	return t
+}
+
+func (t *ChaosToggle) Enabled() bool {
+	return t.enabled.Load()
+}
+
+func (t *ChaosToggle) Set(enabled bool) {
+	t.enabled.Store(enabled)
+}
+
+type chaosState struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (t *ChaosToggle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		var state chaosState
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&state); err != nil {
+			router.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		t.Set(state.Enabled)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		router.Error(w, r, http.StatusMethodNotAllowed, nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chaosState{Enabled: t.Enabled()})
+}
+
+// adminRoute reports whether the request matched a route of the admin API of the router
+func adminRoute(r *http.Request) bool {
+	route := router.MatchedRoute(r)
+	if route == nil {
+		return false
+	}
+	_, ok := route.Get("admin")
+	return ok
+}
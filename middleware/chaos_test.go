@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestChaos(t *testing.T) {
+	toggle := middleware.NewChaosToggle(false)
+	var injected error
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, status int, err error) {
+		if status == http.StatusBadGateway {
+			injected = err
+		}
+		w.WriteHeader(status)
+	})
+	r.Use(middleware.Chaos(middleware.ChaosConfig{ErrorRate: 1, AbortStatus: http.StatusBadGateway, Toggle: toggle}))
+	r.EnableAdmin("/admin", router.AdminConfig{
+		Token: "secret",
+		Endpoints: map[string]http.HandlerFunc{
+			"/chaos":     toggle.ServeHTTP,
+			"PUT /chaos": toggle.ServeHTTP,
+		},
+	})
+	r.GET("/api/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	do := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if strings.HasPrefix(path, "/admin/") && !strings.Contains(body, "unauthenticated") {
+			req.Header.Set("Authorization", "Bearer secret")
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Define test cases, requests are executed in order
+	tests := []struct {
+		method     string
+		path       string
+		body       string
+		statusCode int
+		response   string
+	}{
+		{http.MethodGet, "/api/users/", "", http.StatusOK, ""},
+		{http.MethodPut, "/admin/chaos/", `{"enabled": true, "unauthenticated": true}`, http.StatusUnauthorized, ""},
+		{http.MethodGet, "/api/users/", "", http.StatusOK, ""},
+		{http.MethodPut, "/admin/chaos/", `{"enabled": true}`, http.StatusOK, `{"enabled":true}` + "\n"},
+		{http.MethodGet, "/api/users/", "", http.StatusBadGateway, ""},
+		// The admin API doesn't get injected faults, so the toggle can switch them off again
+		{http.MethodGet, "/admin/chaos/", "", http.StatusOK, `{"enabled":true}` + "\n"},
+		{http.MethodPut, "/admin/chaos/", `{"enabled": false}`, http.StatusOK, `{"enabled":false}` + "\n"},
+		{http.MethodGet, "/api/users/", "", http.StatusOK, ""},
+		{http.MethodDelete, "/admin/chaos/", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tc := range tests {
+		rr := do(tc.method, tc.path, tc.body)
+		if rr.Code != tc.statusCode {
+			t.Errorf("%s %s: expected status code %d, got %d", tc.method, tc.path, tc.statusCode, rr.Code)
+		}
+		if tc.response != "" && rr.Body.String() != tc.response {
+			t.Errorf("%s %s: expected body %q, got %q", tc.method, tc.path, tc.response, rr.Body.String())
+		}
+	}
+
+	if !errors.Is(injected, middleware.ErrChaos) {
+		t.Errorf("Expected the injected fault to be passed to the error handler, got %v", injected)
+	}
+}
+
+func TestChaosLatency(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.Chaos(middleware.ChaosConfig{LatencyP50: 20 * time.Millisecond}))
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	var durations []time.Duration
+	for i := 0; i < 21; i++ {
+		start := time.Now()
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/", nil))
+		durations = append(durations, time.Since(start))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected only latency to be injected, got status code %d", rr.Code)
+		}
+	}
+
+	slices.Sort(durations)
+	if median := durations[len(durations)/2]; median < 5*time.Millisecond || median > 100*time.Millisecond {
+		t.Errorf("Expected a median latency around 20ms, got %s", median)
+	}
+}
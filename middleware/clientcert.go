@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gogo-framework/router"
+)
+
+// ErrClientCertRequired is passed to the error handler when a route requires a client certificate and the request
+// doesn't have a verified one.
+var ErrClientCertRequired = errors.New("client certificate required")
+
+// ClientIdentity is the identity of a verified client certificate. URIs hold identities like SPIFFE IDs.
+type ClientIdentity struct {
+	CommonName  string
+	DNSNames    []string
+	URIs        []*url.URL
+	Certificate *x509.Certificate
+}
+
+// CertValidator decides if a verified client certificate may be used, e.g. by checking its SPIFFE ID. It returns an
+// error to reject the certificate.
+type CertValidator func(cert *x509.Certificate) error
+
+type clientCertContextKey struct{}
+
+// GetClientCert returns the identity of the client certificate of the request, or nil when it doesn't have one.
+func GetClientCert(r *http.Request) *ClientIdentity {
+	identity, _ := r.Context().Value(clientCertContextKey{}).(*ClientIdentity)
+	return identity
+}
+
+// ClientCert makes the identity of the client certificate available through GetClientCert. Only certificates that
+// were verified by the TLS server are used, see router.WithClientAuth, and the validator can reject them with a 403.
+// Routes with RequireClientCert respond with a 401 to requests without a verified certificate. The validator can be
+// nil to accept every verified certificate.
+func ClientCert(validator CertValidator) router.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var cert *x509.Certificate
+			if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+				cert = r.TLS.VerifiedChains[0][0]
+			}
+
+			if cert == nil {
+				if route := router.MatchedRoute(r); route != nil {
+					if required, _ := route.Get("client-cert"); required == true {
+						router.Error(w, r, http.StatusUnauthorized, ErrClientCertRequired)
+						return
+					}
+				}
+				next(w, r)
+				return
+			}
+
+			if validator != nil {
+				if err := validator(cert); err != nil {
+					router.Error(w, r, http.StatusForbidden, err)
+					return
+				}
+			}
+			identity := &ClientIdentity{
+				CommonName:  cert.Subject.CommonName,
+				DNSNames:    cert.DNSNames,
+				URIs:        cert.URIs,
+				Certificate: cert,
+			}
+			next(w, r.WithContext(context.WithValue(r.Context(), clientCertContextKey{}, identity)))
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package middleware_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+// newClientCert creates a CA and a client certificate signed by it
+func newClientCert(t *testing.T, spiffeID string) (*x509.CertPool, tls.Certificate) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	id, _ := url.Parse(spiffeID)
+	clientKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "billing"},
+		URIs:         []*url.URL{id},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, ca, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return pool, tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey}
+}
+
+func TestClientCert(t *testing.T) {
+	pool, clientCert := newClientCert(t, "spiffe://example.com/billing")
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.ClientCert(func(cert *x509.Certificate) error {
+		for _, uri := range cert.URIs {
+			if uri.String() == "spiffe://example.com/billing" {
+				return nil
+			}
+		}
+		return errors.New("unknown service")
+	}))
+	r.GET("/internal", func(w http.ResponseWriter, r *http.Request) {
+		identity := middleware.GetClientCert(r)
+		w.Write([]byte(identity.CommonName + " " + identity.URIs[0].String()))
+	}).RequireClientCert()
+	r.GET("/public", func(w http.ResponseWriter, r *http.Request) {})
+
+	server := httptest.NewUnstartedServer(r)
+	// httptest uses the TLS configuration of the server created by the router
+	server.TLS = r.Server("", router.WithClientAuth(pool, tls.VerifyClientCertIfGiven)).TLSConfig
+	server.StartTLS()
+	defer server.Close()
+
+	// Define test cases
+	tests := []struct {
+		name         string
+		path         string
+		withCert     bool
+		expectedCode int
+		expectedBody string
+	}{
+		{"with certificate", "/internal/", true, http.StatusOK, "billing spiffe://example.com/billing"},
+		{"without certificate", "/internal/", false, http.StatusUnauthorized, ""},
+		{"public route", "/public/", false, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := server.Client().Transport.(*http.Transport).Clone()
+			if tt.withCert {
+				transport.TLSClientConfig.Certificates = []tls.Certificate{clientCert}
+			}
+			res, err := (&http.Client{Transport: transport}).Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			body := make([]byte, 128)
+			n, _ := res.Body.Read(body)
+			if res.StatusCode != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, res.StatusCode)
+			}
+			if tt.expectedBody != "" && string(body[:n]) != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, body[:n])
+			}
+		})
+	}
+}
+
+func TestClientCertRejected(t *testing.T) {
+	_, clientCert := newClientCert(t, "spiffe://example.com/unknown")
+	cert, _ := x509.ParseCertificate(clientCert.Certificate[0])
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.ClientCert(func(cert *x509.Certificate) error {
+		return errors.New("unknown service")
+	}))
+	r.GET("/internal", func(w http.ResponseWriter, r *http.Request) {}).RequireClientCert()
+
+	req := httptest.NewRequest(http.MethodGet, "/internal/", nil)
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+
+	// Certificates that weren't verified are ignored
+	req = httptest.NewRequest(http.MethodGet, "/internal/", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for an unverified certificate, got %d", w.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gogo-framework/router"
+)
+
+// HardenReason is why Harden rejected a request.
+type HardenReason string
+
+const (
+	// RejectedFraming is a request with conflicting Content-Length and Transfer-Encoding headers, or several different
+	// Content-Length headers, which proxies and servers could read differently
+	RejectedFraming HardenReason = "framing"
+	// RejectedHeaderCount is a request with more headers than MaxHeaders
+	RejectedHeaderCount HardenReason = "header-count"
+	// RejectedHeaderSize is a request with headers larger than MaxHeaderBytes
+	RejectedHeaderSize HardenReason = "header-size"
+	// RejectedMethod is a request with a method that isn't in AllowedMethods
+	RejectedMethod HardenReason = "method"
+)
+
+type HardenConfig struct {
+	// MaxHeaders is the largest number of header values a request can have, defaults to 100
+	MaxHeaders int
+	// MaxHeaderBytes is the largest total size of the header names and values of a request, defaults to 32KB
+	MaxHeaderBytes int
+	// AllowedMethods are the methods requests can use, defaults to GET, HEAD, POST, PUT, PATCH, DELETE and OPTIONS
+	AllowedMethods []string
+	// OnReject is called for every rejected request, e.g. to count the rejects per reason
+	OnReject func(r *http.Request, reason HardenReason)
+}
+
+// Harden rejects requests that are commonly used for request smuggling and resource exhaustion, with the default
+// config. It's meant as defense in depth for routers that are exposed to the internet directly.
+func Harden() router.Middleware {
+	return HardenWithConfig(HardenConfig{})
+}
+
+// HardenWithConfig rejects requests with conflicting Content-Length and Transfer-Encoding headers with 400, requests
+// with too many or too large headers with 431, and requests with other methods than the allowed ones with 405.
+// Requests with a conflicting length also close the connection, as the rest of it can't be trusted.
+func HardenWithConfig(cfg HardenConfig) router.Middleware {
+	if cfg.MaxHeaders <= 0 {
+		cfg.MaxHeaders = 100
+	}
+	if cfg.MaxHeaderBytes <= 0 {
+		cfg.MaxHeaderBytes = 32 << 10
+	}
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = []string{
+			http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+		}
+	}
+
+	reject := func(w http.ResponseWriter, r *http.Request, status int, reason HardenReason) {
+		if cfg.OnReject != nil {
+			cfg.OnReject(r, reason)
+		}
+		router.Error(w, r, status, nil)
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(cfg.AllowedMethods, r.Method) {
+				w.Header().Set("Allow", strings.Join(cfg.AllowedMethods, ", "))
+				reject(w, r, http.StatusMethodNotAllowed, RejectedMethod)
+				return
+			}
+			if conflictingFraming(r) {
+				w.Header().Set("Connection", "close")
+				reject(w, r, http.StatusBadRequest, RejectedFraming)
+				return
+			}
+
+			count, size := 0, 0
+			for name, values := range r.Header {
+				count += len(values)
+				for _, value := range values {
+					size += len(name) + len(value)
+				}
+			}
+			if count > cfg.MaxHeaders {
+				reject(w, r, http.StatusRequestHeaderFieldsTooLarge, RejectedHeaderCount)
+				return
+			}
+			if size > cfg.MaxHeaderBytes {
+				reject(w, r, http.StatusRequestHeaderFieldsTooLarge, RejectedHeaderSize)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// conflictingFraming reports whether the length of the body could be read differently. net/http already rejects
+// most of these requests, but not every server in front of the router does.
+func conflictingFraming(r *http.Request) bool {
+	lengths := r.Header.Values("Content-Length")
+	transferEncoding := r.TransferEncoding
+	if len(transferEncoding) == 0 {
+		transferEncoding = r.Header.Values("Transfer-Encoding")
+	}
+	if len(transferEncoding) > 0 {
+		// Only a single chunked encoding is supported, and it can't be combined with a length
+		return len(lengths) > 0 || len(transferEncoding) > 1 || !strings.EqualFold(strings.TrimSpace(transferEncoding[0]), "chunked")
+	}
+	for _, length := range lengths[min(len(lengths), 1):] {
+		if strings.TrimSpace(length) != strings.TrimSpace(lengths[0]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestHarden(t *testing.T) {
+	rejects := map[middleware.HardenReason]int{}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(middleware.HardenWithConfig(middleware.HardenConfig{
+		MaxHeaders:     10,
+		MaxHeaderBytes: 1024,
+		OnReject: func(r *http.Request, reason middleware.HardenReason) {
+			rejects[reason]++
+		},
+	}))
+	r.RegisterRoute("", "/upload", func(w http.ResponseWriter, r *http.Request) {})
+
+	// Define test cases
+	tests := []struct {
+		name       string
+		method     string
+		headers    map[string][]string
+		chunked    bool
+		statusCode int
+		reason     middleware.HardenReason
+	}{
+		{"valid", http.MethodPost, map[string][]string{"Content-Length": {"0"}}, false, http.StatusOK, ""},
+		{"valid chunked", http.MethodPost, nil, true, http.StatusOK, ""},
+		{"length and chunked", http.MethodPost, map[string][]string{"Content-Length": {"5"}}, true, http.StatusBadRequest, middleware.RejectedFraming},
+		{"different lengths", http.MethodPost, map[string][]string{"Content-Length": {"5", "6"}}, false, http.StatusBadRequest, middleware.RejectedFraming},
+		{"repeated length", http.MethodPost, map[string][]string{"Content-Length": {"0", "0"}}, false, http.StatusOK, ""},
+		{"unknown encoding", http.MethodPost, map[string][]string{"Transfer-Encoding": {"gzip, chunked"}}, false, http.StatusBadRequest, middleware.RejectedFraming},
+		{"too many headers", http.MethodGet, map[string][]string{"X-Test": strings.Split(strings.Repeat("a,", 10), ",")}, false, http.StatusRequestHeaderFieldsTooLarge, middleware.RejectedHeaderCount},
+		{"headers too large", http.MethodGet, map[string][]string{"Cookie": {strings.Repeat("a", 1024)}}, false, http.StatusRequestHeaderFieldsTooLarge, middleware.RejectedHeaderSize},
+		{"trace", http.MethodTrace, nil, false, http.StatusMethodNotAllowed, middleware.RejectedMethod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clear(rejects)
+			req := httptest.NewRequest(tt.method, "/upload/", nil)
+			for name, values := range tt.headers {
+				req.Header[name] = values
+			}
+			if tt.chunked {
+				req.TransferEncoding = []string{"chunked"}
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.statusCode {
+				t.Errorf("expected status %d, got %d", tt.statusCode, rr.Code)
+			}
+			if tt.reason == "" && len(rejects) > 0 {
+				t.Errorf("expected no rejects, got %v", rejects)
+			}
+			if tt.reason != "" && rejects[tt.reason] != 1 {
+				t.Errorf("expected a reject for %s, got %v", tt.reason, rejects)
+			}
+			if tt.reason == middleware.RejectedFraming && rr.Header().Get("Connection") != "close" {
+				t.Error("expected the connection to be closed")
+			}
+			if tt.reason == middleware.RejectedMethod && rr.Header().Get("Allow") == "" {
+				t.Error("expected an Allow header")
+			}
+		})
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		r := router.NewRouter(router.WithMux(http.NewServeMux()))
+		r.Use(middleware.Harden())
+		r.GET("/", func(w http.ResponseWriter, r *http.Request) {})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for i := range 100 {
+			req.Header.Set("X-Header-"+strconv.Itoa(i), "a")
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+		}
+
+		req.Header.Set("X-One-Too-Many", "a")
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusRequestHeaderFieldsTooLarge {
+			t.Errorf("expected status %d, got %d", http.StatusRequestHeaderFieldsTooLarge, rr.Code)
+		}
+	})
+}
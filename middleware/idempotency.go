@@ -0,0 +1,294 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+var (
+	// ErrIdempotencyInFlight is returned by an IdempotencyStore when another request currently holds the key.
+	ErrIdempotencyInFlight = errors.New("idempotency: request with this key is in flight")
+	// ErrIdempotencyKeyReused is passed to the error handler when a key is sent again with another request.
+	ErrIdempotencyKeyReused = errors.New("idempotency: key was used for another request")
+)
+
+const (
+	// DefaultIdempotencyTTL is used when IdempotencyConfig.TTL is not set.
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// DefaultIdempotencyLockTTL is used when IdempotencyConfig.LockTTL is not set.
+	DefaultIdempotencyLockTTL = time.Minute
+	// DefaultIdempotencyMaxSize is used when IdempotencyConfig.MaxRequestSize or MaxResponseSize is not set.
+	DefaultIdempotencyMaxSize = 1 << 20
+)
+
+// IdempotentResponse is a stored response that is replayed for retried requests.
+type IdempotentResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Fingerprint is a hash of the method, path and body of the request, a retry has to send the same request
+	Fingerprint string
+}
+
+// IdempotencyStore stores responses for idempotency keys.
+type IdempotencyStore interface {
+	// Begin reserves the key for the current request for the ttl. It returns the stored response if the key has
+	// already completed, ErrIdempotencyInFlight if another request is holding the key, or nil for both if the request
+	// should be handled.
+	Begin(ctx context.Context, key string, ttl time.Duration) (*IdempotentResponse, error)
+	// Complete stores the response for the key, releasing the reservation.
+	Complete(ctx context.Context, key string, response *IdempotentResponse, ttl time.Duration) error
+	// Abort releases the reservation without storing anything, so the request can be retried.
+	Abort(ctx context.Context, key string) error
+}
+
+type IdempotencyConfig struct {
+	// Store is where responses are kept, defaults to a new MemoryIdempotencyStore
+	Store IdempotencyStore
+	// TTL is how long a response is replayed for, defaults to DefaultIdempotencyTTL
+	TTL time.Duration
+	// LockTTL is how long a key is reserved while its request is handled, defaults to DefaultIdempotencyLockTTL. A
+	// key isn't blocked for longer when the instance handling it crashes, so it should exceed the longest request
+	LockTTL time.Duration
+	// MaxRequestSize limits the body of requests with a key, which is read to compare retries with the first request.
+	// Larger requests get a 413. Defaults to DefaultIdempotencyMaxSize
+	MaxRequestSize int64
+	// MaxResponseSize limits the responses that are stored, defaults to DefaultIdempotencyMaxSize. Larger responses
+	// aren't stored, so retries are handled again
+	MaxResponseSize int
+	// Header is the name of the header containing the key, defaults to "Idempotency-Key"
+	Header string
+	// Methods are the methods the middleware applies to, defaults to POST only
+	Methods []string
+	// KeyFunc can be used to scope keys, e.g. per user. By default the key is combined with the method and path
+	KeyFunc func(r *http.Request, key string) string
+}
+
+// Idempotency replays the first response for requests carrying the same Idempotency-Key header. Concurrent
+// requests with a key that is still being handled get a 409 Conflict, and requests that reuse a key with another
+// method, path or body a 422 Unprocessable Entity. Responses with a 5xx status are not stored.
+func Idempotency(cfg IdempotencyConfig) router.Middleware {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryIdempotencyStore()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultIdempotencyTTL
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = DefaultIdempotencyLockTTL
+	}
+	if cfg.MaxRequestSize <= 0 {
+		cfg.MaxRequestSize = DefaultIdempotencyMaxSize
+	}
+	if cfg.MaxResponseSize <= 0 {
+		cfg.MaxResponseSize = DefaultIdempotencyMaxSize
+	}
+	if cfg.Header == "" {
+		cfg.Header = "Idempotency-Key"
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost}
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = func(r *http.Request, key string) string {
+			return r.Method + " " + r.URL.Path + " " + key
+		}
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(cfg.Header)
+			if header == "" || !containsMethod(cfg.Methods, r.Method) {
+				next(w, r)
+				return
+			}
+			key := cfg.KeyFunc(r, header)
+			body, err := io.ReadAll(io.LimitReader(r.Body, cfg.MaxRequestSize+1))
+			if err != nil {
+				router.Error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			if int64(len(body)) > cfg.MaxRequestSize {
+				router.Error(w, r, http.StatusRequestEntityTooLarge, fmt.Errorf("idempotency: the request is larger than %d bytes", cfg.MaxRequestSize))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			fingerprint := requestFingerprint(r, body)
+
+			stored, err := cfg.Store.Begin(r.Context(), key, cfg.LockTTL)
+			if errors.Is(err, ErrIdempotencyInFlight) {
+				router.Error(w, r, http.StatusConflict, ErrIdempotencyInFlight)
+				return
+			}
+			if err != nil {
+				router.Error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			if stored != nil && stored.Fingerprint != "" && stored.Fingerprint != fingerprint {
+				router.Error(w, r, http.StatusUnprocessableEntity, ErrIdempotencyKeyReused)
+				return
+			}
+			if stored != nil {
+				for k, v := range stored.Header {
+					w.Header()[k] = v
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.StatusCode)
+				w.Write(stored.Body)
+				return
+			}
+
+			rec := &recordingWriter{ResponseWriter: w, maxSize: cfg.MaxResponseSize}
+			completed := false
+			defer func() {
+				if !completed {
+					cfg.Store.Abort(context.WithoutCancel(r.Context()), key)
+				}
+			}()
+
+			next(rec, r)
+
+			if rec.status() >= http.StatusInternalServerError || rec.truncated {
+				return
+			}
+			completed = true
+			cfg.Store.Complete(context.WithoutCancel(r.Context()), key, &IdempotentResponse{
+				StatusCode:  rec.status(),
+				Header:      rec.Header().Clone(),
+				Body:        rec.body.Bytes(),
+				Fingerprint: fingerprint,
+			}, cfg.TTL)
+		}
+	}
+}
+
+// requestFingerprint hashes the method, path and body of the request
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.Path)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// recordingWriter writes through to the underlying writer while keeping a copy of the response. When maxSize is set,
+// only the first maxSize bytes are kept and truncated is set.
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+	maxSize    int
+	truncated  bool
+}
+
+func (rw *recordingWriter) WriteHeader(statusCode int) {
+	// Informational responses like 103 Early Hints can be followed by the final status code
+	if rw.statusCode == 0 && statusCode >= 200 {
+		rw.statusCode = statusCode
+	}
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if rw.statusCode == 0 {
+		rw.statusCode = http.StatusOK
+	}
+	if !rw.truncated {
+		keep := b
+		if rw.maxSize > 0 && rw.body.Len()+len(b) > rw.maxSize {
+			keep = b[:rw.maxSize-rw.body.Len()]
+			rw.truncated = true
+		}
+		rw.body.Write(keep)
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *recordingWriter) Flush() {
+	http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *recordingWriter) status() int {
+	if rw.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rw.statusCode
+}
+
+type idempotencyEntry struct {
+	response  *IdempotentResponse
+	expiresAt time.Time
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore, it is only suitable for a single instance.
+type MemoryIdempotencyStore struct {
+	mutex   sync.Mutex
+	entries map[string]*idempotencyEntry
+	swept   time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]*idempotencyEntry)}
+}
+
+func (s *MemoryIdempotencyStore) Begin(ctx context.Context, key string, ttl time.Duration) (*IdempotentResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	// Expired entries are removed once per ttl, so keys that are never sent again don't stay in memory
+	if now.Sub(s.swept) > ttl {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.swept = now
+	}
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		if entry.response == nil {
+			return nil, ErrIdempotencyInFlight
+		}
+		return entry.response, nil
+	}
+	s.entries[key] = &idempotencyEntry{expiresAt: now.Add(ttl)}
+	return nil, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, response *IdempotentResponse, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries[key] = &idempotencyEntry{response: response, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Abort(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
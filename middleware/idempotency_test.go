@@ -0,0 +1,154 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestIdempotency(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.Idempotency(middleware.IdempotencyConfig{}))
+
+	r.POST("/payments", func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "payment %d", n)
+	})
+	r.POST("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	})
+
+	do := func(path string, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := do("/payments/", "abc")
+	if first.Code != http.StatusCreated || first.Body.String() != "payment 1" {
+		t.Fatalf("unexpected first response: %v %v", first.Code, first.Body.String())
+	}
+
+	// A retry with the same key should replay the stored response
+	retry := do("/payments/", "abc")
+	if retry.Code != http.StatusCreated || retry.Body.String() != "payment 1" {
+		t.Errorf("unexpected replayed response: %v %v", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected Idempotent-Replayed header to be set")
+	}
+
+	// A new key or no key should reach the handler
+	if rr := do("/payments/", "def"); rr.Body.String() != "payment 2" {
+		t.Errorf("unexpected response for new key: %v", rr.Body.String())
+	}
+	if rr := do("/payments/", ""); rr.Body.String() != "payment 3" {
+		t.Errorf("unexpected response without key: %v", rr.Body.String())
+	}
+
+	// A concurrent duplicate should be rejected with a conflict
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- do("/slow/", "xyz") }()
+	<-started
+	if rr := do("/slow/", "xyz"); rr.Code != http.StatusConflict {
+		t.Errorf("expected conflict for concurrent duplicate, got %v", rr.Code)
+	}
+	close(release)
+	if rr := <-done; rr.Code != http.StatusOK {
+		t.Errorf("unexpected status for in-flight request: %v", rr.Code)
+	}
+}
+
+// lockRecordingStore records the ttl of the reservations
+type lockRecordingStore struct {
+	*middleware.MemoryIdempotencyStore
+	lockTTL time.Duration
+}
+
+func (s *lockRecordingStore) Begin(ctx context.Context, key string, ttl time.Duration) (*middleware.IdempotentResponse, error) {
+	s.lockTTL = ttl
+	return s.MemoryIdempotencyStore.Begin(ctx, key, ttl)
+}
+
+func TestIdempotencyRequests(t *testing.T) {
+	var calls atomic.Int32
+	store := &lockRecordingStore{MemoryIdempotencyStore: middleware.NewMemoryIdempotencyStore()}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(middleware.Idempotency(middleware.IdempotencyConfig{Store: store, MaxRequestSize: 32, MaxResponseSize: 16}))
+	r.POST("/payments", func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "payment %d: %s", n, body)
+	})
+	r.POST("/export", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(strings.Repeat("x", 32)))
+	})
+
+	do := func(path string, key string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		path   string
+		key    string
+		body   string
+		status int
+		calls  int32
+	}{
+		{"first", "/payments/", "abc", "10", http.StatusOK, 1},
+		{"retry", "/payments/", "abc", "10", http.StatusOK, 1},
+		{"other body", "/payments/", "abc", "1000", http.StatusUnprocessableEntity, 1},
+		{"request too large", "/payments/", "def", strings.Repeat("1", 33), http.StatusRequestEntityTooLarge, 1},
+		{"response too large", "/export/", "ghi", "", http.StatusOK, 2},
+		{"response too large again", "/export/", "ghi", "", http.StatusOK, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := do(tt.path, tt.key, tt.body)
+			if rr.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rr.Code)
+			}
+			if tt.status == http.StatusOK && tt.path == "/payments/" && rr.Body.String() != "payment 1: 10" {
+				t.Errorf("expected the first response, got %q", rr.Body.String())
+			}
+			if got := calls.Load(); got != tt.calls {
+				t.Errorf("expected %d calls of the handler, got %d", tt.calls, got)
+			}
+		})
+	}
+
+	if store.lockTTL != middleware.DefaultIdempotencyLockTTL {
+		t.Errorf("expected keys to be reserved for %s, got %s", middleware.DefaultIdempotencyLockTTL, store.lockTTL)
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+type LoadShedConfig struct {
+	// LatencyThreshold starts shedding requests of a route when its average latency exceeds it, 0 disables it
+	LatencyThreshold time.Duration
+	// Probe returns the current load as a fraction, e.g. CPU usage or queue depth, where 1 is fully loaded
+	Probe func() float64
+	// ProbeThreshold is the load from which requests are shed, defaults to 0.8
+	ProbeThreshold float64
+	// MaxShedRatio is the largest fraction of requests that is shed, defaults to 0.5. It's capped at 0.95, so latency
+	// can still be measured and shedding stops once the route recovers
+	MaxShedRatio float64
+	// RetryAfter is sent in the Retry-After header of shed requests, defaults to 5 seconds
+	RetryAfter time.Duration
+	// Decay is the weight of the latest request in the moving average of the latency, defaults to 0.1
+	Decay float64
+}
+
+// LoadShed rejects a percentage of requests with 503 when a route is overloaded. The percentage grows with how far
+// the average latency of the route or the load reported by the probe is over the threshold. The latency is tracked
+// per route, so a slow route doesn't cause requests to other routes to be shed. Use it on a group or route to
+// configure it per group.
+func LoadShed(cfg LoadShedConfig) router.Middleware {
+	if cfg.ProbeThreshold <= 0 || cfg.ProbeThreshold >= 1 {
+		cfg.ProbeThreshold = 0.8
+	}
+	if cfg.MaxShedRatio <= 0 {
+		cfg.MaxShedRatio = 0.5
+	}
+	cfg.MaxShedRatio = math.Min(cfg.MaxShedRatio, 0.95)
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = 5 * time.Second
+	}
+	if cfg.Decay <= 0 || cfg.Decay > 1 {
+		cfg.Decay = 0.1
+	}
+	retryAfter := strconv.Itoa(int(math.Ceil(cfg.RetryAfter.Seconds())))
+
+	var stats sync.Map // *router.Route -> *latencyStats
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			value, _ := stats.LoadOrStore(router.MatchedRoute(r), &latencyStats{})
+			routeStats := value.(*latencyStats)
+
+			if ratio := cfg.shedRatio(routeStats.average()); ratio > 0 && rand.Float64() < ratio {
+				w.Header().Set("Retry-After", retryAfter)
+				router.Error(w, r, http.StatusServiceUnavailable, nil)
+				return
+			}
+
+			start := time.Now()
+			next(w, r)
+			if cfg.LatencyThreshold > 0 {
+				routeStats.observe(time.Since(start), cfg.Decay)
+			}
+		}
+	}
+}
+
+// shedRatio returns the fraction of requests to shed for the given average latency
+func (cfg LoadShedConfig) shedRatio(latency time.Duration) float64 {
+	var overload float64
+	if cfg.LatencyThreshold > 0 && latency > cfg.LatencyThreshold {
+		overload = float64(latency-cfg.LatencyThreshold) / float64(cfg.LatencyThreshold)
+	}
+	if cfg.Probe != nil {
+		if load := cfg.Probe(); load > cfg.ProbeThreshold {
+			overload = math.Max(overload, (load-cfg.ProbeThreshold)/(1-cfg.ProbeThreshold))
+		}
+	}
+	return math.Min(overload, cfg.MaxShedRatio)
+}
+
+// latencyStats keeps an exponential moving average of the latency of a route
+type latencyStats struct {
+	mutex sync.Mutex
+	avg   time.Duration
+}
+
+func (s *latencyStats) observe(latency time.Duration, decay float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.avg == 0 {
+		s.avg = latency
+		return
+	}
+	s.avg = time.Duration(decay*float64(latency) + (1-decay)*float64(s.avg))
+}
+
+func (s *latencyStats) average() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.avg
+}
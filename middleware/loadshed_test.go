@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestLoadShedProbe(t *testing.T) {
+	var load atomic.Value
+	load.Store(0.0)
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.LoadShed(middleware.LoadShedConfig{
+		Probe:          func() float64 { return load.Load().(float64) },
+		ProbeThreshold: 0.5,
+		MaxShedRatio:   0.5,
+		RetryAfter:     10 * time.Second,
+	}))
+	r.GET("/work", func(w http.ResponseWriter, r *http.Request) {})
+
+	// Define test cases, the amount of shed requests out of 1000 is random, so the bounds are wide
+	tests := []struct {
+		load     float64
+		minShed  int
+		maxShed  int
+		describe string
+	}{
+		{0.0, 0, 0, "idle"},
+		{0.5, 0, 0, "at threshold"},
+		{0.6, 100, 300, "slightly overloaded"},
+		{1.0, 400, 600, "fully loaded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.describe, func(t *testing.T) {
+			load.Store(tt.load)
+			shed := 0
+			for i := 0; i < 1000; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/work/", nil)
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, req)
+
+				if rr.Code == http.StatusServiceUnavailable {
+					shed++
+					if rr.Header().Get("Retry-After") != "10" {
+						t.Fatalf("Expected Retry-After 10, got %q", rr.Header().Get("Retry-After"))
+					}
+				}
+			}
+			if shed < tt.minShed || shed > tt.maxShed {
+				t.Errorf("Expected between %d and %d shed requests, got %d", tt.minShed, tt.maxShed, shed)
+			}
+		})
+	}
+}
+
+func TestLoadShedLatencyPerRoute(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.LoadShed(middleware.LoadShedConfig{
+		LatencyThreshold: time.Millisecond,
+		MaxShedRatio:     0.95,
+		Decay:            1,
+	}))
+	r.GET("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	})
+	r.GET("/fast", func(w http.ResponseWriter, r *http.Request) {})
+
+	do := func(path string) int {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr.Code
+	}
+
+	// The first request measures the latency, after that most requests are shed
+	do("/slow/")
+	shed := 0
+	for i := 0; i < 100; i++ {
+		if do("/slow/") == http.StatusServiceUnavailable {
+			shed++
+		}
+	}
+	if shed < 50 {
+		t.Errorf("Expected most requests to the slow route to be shed, got %d of 100", shed)
+	}
+
+	for i := 0; i < 100; i++ {
+		if code := do("/fast/"); code != http.StatusOK {
+			t.Fatalf("Expected the fast route not to be affected, got status code %d", code)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+// RateLimitTier is the amount of requests allowed per window.
+type RateLimitTier struct {
+	Limit  int
+	Window time.Duration
+}
+
+type RateLimitConfig struct {
+	// Default is applied to routes without a tier, or with a tier that's not configured
+	Default RateLimitTier
+	// Tiers maps the tier stored in the route metadata to its limit
+	Tiers map[string]RateLimitTier
+	// TierKey is the route metadata key containing the tier, defaults to "tier"
+	TierKey string
+	// KeyFunc identifies the client, defaults to the remote IP address
+	KeyFunc func(r *http.Request) string
+}
+
+// RateLimit limits the amount of requests per client using a fixed window. Routes can be assigned to a tier using
+// route metadata, e.g. r.GET(...).Set("tier", "expensive"), so a single global middleware can enforce different limits.
+func RateLimit(cfg RateLimitConfig) router.Middleware {
+	if cfg.TierKey == "" {
+		cfg.TierKey = "tier"
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = RemoteIP
+	}
+	limiter := &windowLimiter{windows: make(map[string]*rateWindow)}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			tierName, tier := cfg.tierFor(r)
+			if tier.Limit <= 0 || tier.Window <= 0 {
+				next(w, r)
+				return
+			}
+
+			allowed, remaining, reset := limiter.take(tierName+"|"+cfg.KeyFunc(r), tier)
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(tier.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+				router.Error(w, r, http.StatusTooManyRequests, nil)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+func (cfg RateLimitConfig) tierFor(r *http.Request) (string, RateLimitTier) {
+	if route := router.MatchedRoute(r); route != nil {
+		if value, ok := route.Get(cfg.TierKey); ok {
+			if name, ok := value.(string); ok {
+				if tier, ok := cfg.Tiers[name]; ok {
+					return name, tier
+				}
+			}
+		}
+	}
+	return "", cfg.Default
+}
+
+// RemoteIP returns the IP address of the client from r.RemoteAddr.
+func RemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateWindow is the count of a client in a window, it ends after the window of its tier
+type rateWindow struct {
+	end   time.Time
+	count int
+}
+
+type windowLimiter struct {
+	mutex    sync.Mutex
+	windows  map[string]*rateWindow
+	lastScan time.Time
+}
+
+func (l *windowLimiter) take(key string, tier RateLimitTier) (bool, int, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.evict(now, tier.Window)
+
+	window, ok := l.windows[key]
+	if !ok || !now.Before(window.end) {
+		window = &rateWindow{end: now.Add(tier.Window)}
+		l.windows[key] = window
+	}
+	reset := window.end.Sub(now)
+	if window.count >= tier.Limit {
+		return false, 0, reset
+	}
+	window.count++
+	return true, tier.Limit - window.count, reset
+}
+
+// evict removes the windows that ended, at most once per window duration to keep take cheap. The windows of all
+// tiers share the map, so each window is only removed after the end of its own tier's window.
+func (l *windowLimiter) evict(now time.Time, interval time.Duration) {
+	if now.Sub(l.lastScan) < interval {
+		return
+	}
+	l.lastScan = now
+	for key, w := range l.windows {
+		if !now.Before(w.end) {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestRateLimitTiers(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.RateLimit(middleware.RateLimitConfig{
+		Default: middleware.RateLimitTier{Limit: 3, Window: time.Minute},
+		Tiers: map[string]middleware.RateLimitTier{
+			"expensive": {Limit: 1, Window: time.Minute},
+			"unlimited": {},
+		},
+	}))
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}
+	r.GET("/default", ok)
+	r.GET("/expensive", ok).Set("tier", "expensive")
+	r.GET("/unlimited", ok).Set("tier", "unlimited")
+
+	// Define test cases, the expected status codes are for consecutive requests
+	tests := []struct {
+		path        string
+		statusCodes []int
+	}{
+		{"/default/", []int{200, 200, 200, 429}},
+		{"/expensive/", []int{200, 429}},
+		{"/unlimited/", []int{200, 200, 200, 200, 200}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			for i, statusCode := range tt.statusCodes {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				rr := httptest.NewRecorder()
+
+				r.ServeHTTP(rr, req)
+
+				if rr.Code != statusCode {
+					t.Errorf("request %d returned wrong status code: got %v want %v", i, rr.Code, statusCode)
+				}
+				if statusCode == http.StatusTooManyRequests && rr.Header().Get("Retry-After") == "" {
+					t.Errorf("request %d is missing Retry-After header", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitShortTier(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.RateLimit(middleware.RateLimitConfig{
+		Default: middleware.RateLimitTier{Limit: 1, Window: time.Minute},
+		Tiers: map[string]middleware.RateLimitTier{
+			"burst": {Limit: 100, Window: 10 * time.Millisecond},
+		},
+	}))
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	r.GET("/default", ok)
+	r.GET("/burst", ok).Set("tier", "burst")
+
+	get := func(path string) int {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr.Code
+	}
+	get("/default/")
+	// The requests of the short tier remove the ended windows, the window of the default tier hasn't ended yet
+	for range 3 {
+		time.Sleep(20 * time.Millisecond)
+		get("/burst/")
+	}
+	if code := get("/default/"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the default tier to still be limited, got status %d", code)
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+// Recording is a request and the response to it, as saved by the Record middleware.
+type Recording struct {
+	Time     time.Time        `json:"time"`
+	Route    string           `json:"route,omitempty"`
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded request, Truncated is set when only the first RecordConfig.MaxBodySize bytes of the
+// body were recorded.
+type RecordedRequest struct {
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      string      `json:"body,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// RecordedResponse is a recorded response, Truncated is set when only the first RecordConfig.MaxBodySize bytes of
+// the body were recorded.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Truncated  bool        `json:"truncated,omitempty"`
+}
+
+type RecordConfig struct {
+	// Dir is where the recordings are written, every route gets its own file with one JSON recording per line
+	Dir string
+	// RedactHeaders are replaced by "[REDACTED]", defaults to Authorization, Cookie, Set-Cookie and X-API-Key
+	RedactHeaders []string
+	// MaxBodySize is the largest request or response body that is kept, larger bodies are truncated and marked as
+	// such. Defaults to 64KB
+	MaxBodySize int
+	// Sanitize can remove other sensitive data, e.g. fields in the body, before the recording is saved
+	Sanitize func(recording *Recording)
+}
+
+// Record saves requests and their responses to disk, to debug them or replay them in tests with routertest.Replay.
+// It's meant for development, bodies are kept in memory up to MaxBodySize and files are written for every request.
+func Record(cfg RecordConfig) router.Middleware {
+	if cfg.RedactHeaders == nil {
+		cfg.RedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-API-Key"}
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 64 << 10
+	}
+	var mutex sync.Mutex
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			requestBody := &limitedBuffer{maxSize: cfg.MaxBodySize}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.TeeReader(r.Body, requestBody), r.Body}
+			}
+			recorder := &recordingWriter{ResponseWriter: w, maxSize: cfg.MaxBodySize}
+			recording := Recording{
+				Time: time.Now().UTC(),
+				Request: RecordedRequest{
+					Method: r.Method,
+					URL:    r.URL.RequestURI(),
+					Header: cfg.redact(r.Header),
+				},
+			}
+
+			next(recorder, r)
+
+			recording.Request.Body = requestBody.buffer.String()
+			recording.Request.Truncated = requestBody.truncated
+			recording.Response = RecordedResponse{
+				StatusCode: recorder.status(),
+				Header:     cfg.redact(w.Header()),
+				Body:       recorder.body.String(),
+				Truncated:  recorder.truncated,
+			}
+			file := "unmatched"
+			if route := router.MatchedRoute(r); route != nil {
+				recording.Route = route.FullPattern()
+				file = recordingFileName(route)
+			}
+			if cfg.Sanitize != nil {
+				cfg.Sanitize(&recording)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err := appendRecording(filepath.Join(cfg.Dir, file+".jsonl"), recording); err != nil {
+				// The response has already been sent, so the error can only be logged
+				router.Logger(r).Error("middleware: recording failed", "method", r.Method, "path", r.URL.Path, "error", err)
+			}
+		}
+	}
+}
+
+func (cfg RecordConfig) redact(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range cfg.RedactHeaders {
+		if _, ok := redacted[http.CanonicalHeaderKey(name)]; ok {
+			redacted.Set(name, "[REDACTED]")
+		}
+	}
+	return redacted
+}
+
+var unsafeFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// recordingFileName turns the route into a file name, e.g. "GET_users_id" for "GET /users/{id}"
+func recordingFileName(route *router.Route) string {
+	name := strings.Trim(unsafeFileNameCharacters.ReplaceAllString(route.Method+" "+route.Path(), "_"), "_")
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+func appendRecording(path string, recording Recording) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(recording)
+}
+
+// limitedBuffer keeps the first maxSize bytes written to it, writes never fail
+type limitedBuffer struct {
+	buffer    bytes.Buffer
+	maxSize   int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if keep := b.maxSize - b.buffer.Len(); len(p) > keep {
+		b.buffer.Write(p[:max(keep, 0)])
+		b.truncated = true
+	} else {
+		b.buffer.Write(p)
+	}
+	return len(p), nil
+}
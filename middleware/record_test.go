@@ -0,0 +1,118 @@
+package middleware_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+	"github.com/gogo-framework/router/routertest"
+)
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.Record(middleware.RecordConfig{
+		Dir: dir,
+		Sanitize: func(recording *middleware.Recording) {
+			recording.Request.Body = strings.ReplaceAll(recording.Request.Body, "hunter2", "[REDACTED]")
+		},
+	}))
+	r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + r.PathValue("id")))
+	})
+	r.POST("/login", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "hunter2") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("welcome"))
+	})
+
+	for _, id := range []string{"1", "2"} {
+		req := httptest.NewRequest(http.MethodGet, "/users/"+id+"/", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/login/", strings.NewReader("password=hunter2")))
+
+	recordings, err := routertest.Load(filepath.Join(dir, "GET_users_id.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordings) != 2 {
+		t.Fatalf("Expected 2 recordings, got %d", len(recordings))
+	}
+	if got := recordings[0].Request.Header.Get("Authorization"); got != "[REDACTED]" {
+		t.Errorf("Expected the Authorization header to be redacted, got %q", got)
+	}
+	if recordings[1].Route != "GET /users/{id}/{$}" || recordings[1].Response.Body != "user 2" {
+		t.Errorf("Unexpected recording %+v", recordings[1])
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "POST_login.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Error("Expected the password to be sanitized")
+	}
+
+	// Replaying against the same routes gives the same responses
+	replay := router.NewRouter()
+	replay.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + r.PathValue("id")))
+	})
+	routertest.Replay(t, replay, filepath.Join(dir, "GET_users_id.jsonl"))
+}
+
+func TestRecordTruncated(t *testing.T) {
+	dir := t.TempDir()
+	var logs bytes.Buffer
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	r.Use(middleware.Record(middleware.RecordConfig{Dir: dir, MaxBodySize: 8}))
+	r.POST("/echo", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/echo/", strings.NewReader("0123456789")))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/echo/", strings.NewReader("01234567")))
+
+	recordings, err := routertest.Load(filepath.Join(dir, "POST_echo.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordings) != 2 {
+		t.Fatalf("Expected 2 recordings, got %d", len(recordings))
+	}
+	if got := recordings[0]; got.Request.Body != "01234567" || !got.Request.Truncated || got.Response.Body != "01234567" || !got.Response.Truncated {
+		t.Errorf("Expected truncated bodies, got %+v", got)
+	}
+	if got := recordings[1]; got.Request.Truncated || got.Response.Truncated {
+		t.Errorf("Expected complete bodies, got %+v", got)
+	}
+
+	// Recordings that can't be written are logged to the logger of the router
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	failing := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	failing.Use(middleware.Record(middleware.RecordConfig{Dir: file}))
+	failing.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+	failing.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+	if !strings.Contains(logs.String(), "recording failed") {
+		t.Errorf("Expected the error in the logger of the router, got %q", logs.String())
+	}
+}
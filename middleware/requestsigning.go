@@ -0,0 +1,312 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+var (
+	ErrMissingRequestSignature = errors.New("signing: missing request signature")
+	ErrInvalidRequestSignature = errors.New("signing: invalid request signature")
+	ErrUnknownSigningKey       = errors.New("signing: unknown key")
+	ErrSignatureSkew           = errors.New("signing: signature date outside of the allowed skew")
+	ErrReplayedRequest         = errors.New("signing: replayed request")
+)
+
+const (
+	// SigningAlgorithm is the scheme of the Authorization header of signed requests
+	SigningAlgorithm = "HMAC-SHA256"
+	// SigningDateHeader holds the time the request was signed, in the format "20060102T150405Z"
+	SigningDateHeader = "X-Signature-Date"
+	// ContentHashHeader holds the hex encoded SHA-256 of the body
+	ContentHashHeader = "X-Content-Sha256"
+	// SigningNonceHeader holds a random value, so identical requests signed at the same time aren't taken for replays
+	SigningNonceHeader = "X-Signature-Nonce"
+	// DefaultSigningSkew is used when RequestSigningConfig.MaxSkew is not set.
+	DefaultSigningSkew = 5 * time.Minute
+)
+
+const signingDateLayout = "20060102T150405Z"
+
+// requiredSignedHeaders are the headers every signature has to cover
+var requiredSignedHeaders = []string{
+	"host", strings.ToLower(SigningDateHeader), strings.ToLower(ContentHashHeader), strings.ToLower(SigningNonceHeader),
+}
+
+// ReplayCache remembers the signatures of verified requests, so a captured request can't be sent again.
+type ReplayCache interface {
+	// Seen records the key for the ttl, and reports whether it was already recorded
+	Seen(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+type RequestSigningConfig struct {
+	// Keys returns the secret of a key ID, this is required
+	Keys func(keyID string) ([]byte, bool)
+	// MaxSkew is how far the signing date may be from the time of the server, defaults to DefaultSigningSkew. Older
+	// requests are rejected and signatures are remembered for twice the skew
+	MaxSkew time.Duration
+	// ReplayCache defaults to a new MemoryReplayCache
+	ReplayCache ReplayCache
+	// MaxBodySize is the maximum amount of bytes that will be buffered to hash the body, larger bodies are rejected.
+	// Defaults to DefaultWebhookMaxBodySize
+	MaxBodySize int64
+	// ErrorHandler is called when verification fails, by default it responds with 401 (or 413 for large bodies, and
+	// 500 when the replay cache fails)
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type signingKeyContextKey struct{}
+
+// RequestSignature verifies requests signed with SignRequest, for machine-to-machine APIs. The signature covers the
+// method, path, query, the signed headers and a hash of the body. Requests signed outside of the MaxSkew and
+// requests that were already seen are rejected. The handler gets the key ID with SigningKeyID.
+func RequestSignature(cfg RequestSigningConfig) router.Middleware {
+	if cfg.Keys == nil {
+		panic("middleware: RequestSignature requires Keys")
+	}
+	if cfg.MaxSkew <= 0 {
+		cfg.MaxSkew = DefaultSigningSkew
+	}
+	if cfg.ReplayCache == nil {
+		cfg.ReplayCache = NewMemoryReplayCache()
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DefaultWebhookMaxBodySize
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = defaultSigningErrorHandler
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			keyID, signature, err := verifyRequest(r, cfg)
+			if err != nil {
+				cfg.ErrorHandler(w, r, err)
+				return
+			}
+			// The signature is the canonical hex of the MAC, so changing its case doesn't get past the cache
+			seen, err := cfg.ReplayCache.Seen(r.Context(), keyID+":"+signature, 2*cfg.MaxSkew)
+			if err == nil && seen {
+				err = ErrReplayedRequest
+			}
+			if err != nil {
+				cfg.ErrorHandler(w, r, err)
+				return
+			}
+			next(w, r.WithContext(context.WithValue(r.Context(), signingKeyContextKey{}, keyID)))
+		}
+	}
+}
+
+// SigningKeyID returns the ID of the key that signed the request, or an empty string when it wasn't verified by
+// RequestSignature.
+func SigningKeyID(r *http.Request) string {
+	keyID, _ := r.Context().Value(signingKeyContextKey{}).(string)
+	return keyID
+}
+
+// verifyRequest returns the key ID and the lowercase hex signature of a valid request
+func verifyRequest(r *http.Request, cfg RequestSigningConfig) (string, string, error) {
+	scheme, params, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || scheme != SigningAlgorithm {
+		return "", "", ErrMissingRequestSignature
+	}
+	var keyID, signature string
+	var signed []string
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch key {
+		case "Credential":
+			keyID = value
+		case "SignedHeaders":
+			signed = strings.Split(value, ";")
+		case "Signature":
+			signature = value
+		}
+	}
+	if keyID == "" || signature == "" {
+		return "", "", ErrMissingRequestSignature
+	}
+	for _, name := range requiredSignedHeaders {
+		if !slices.Contains(signed, name) {
+			return "", "", ErrInvalidRequestSignature
+		}
+	}
+
+	date, err := time.Parse(signingDateLayout, r.Header.Get(SigningDateHeader))
+	if err != nil {
+		return "", "", ErrInvalidRequestSignature
+	}
+	if skew := time.Since(date); skew > cfg.MaxSkew || skew < -cfg.MaxSkew {
+		return "", "", ErrSignatureSkew
+	}
+	secret, ok := cfg.Keys(keyID)
+	if !ok {
+		return "", "", ErrUnknownSigningKey
+	}
+
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		if body, err = webhookBody(r, cfg.MaxBodySize); err != nil {
+			return "", "", err
+		}
+	}
+	contentHash := sha256.Sum256(body)
+	if r.Header.Get(ContentHashHeader) != hex.EncodeToString(contentHash[:]) {
+		return "", "", ErrInvalidRequestSignature
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(requestSignature(r, r.Host, signed, secret), expected) {
+		return "", "", ErrInvalidRequestSignature
+	}
+	return keyID, hex.EncodeToString(expected), nil
+}
+
+func defaultSigningErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrBodyTooLarge):
+		router.Error(w, r, http.StatusRequestEntityTooLarge, nil)
+	case errors.Is(err, ErrMissingRequestSignature), errors.Is(err, ErrInvalidRequestSignature),
+		errors.Is(err, ErrUnknownSigningKey), errors.Is(err, ErrSignatureSkew), errors.Is(err, ErrReplayedRequest):
+		w.Header().Set("WWW-Authenticate", SigningAlgorithm)
+		router.Error(w, r, http.StatusUnauthorized, err)
+	default:
+		router.Error(w, r, http.StatusInternalServerError, err)
+	}
+}
+
+// SignRequest signs an outbound request for a router that uses RequestSignature. It sets the date, content hash, nonce
+// and Authorization headers, and signs the Content-Type as well when it's set. The body is buffered to hash it, and
+// GetBody is set so redirects can send it again. Each try has to be signed again, the router rejects replays.
+func SignRequest(req *http.Request, keyID string, secret []byte) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return fmt.Errorf("signing: reading the body: %w", err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	contentHash := sha256.Sum256(body)
+	req.Header.Set(SigningDateHeader, time.Now().UTC().Format(signingDateLayout))
+	req.Header.Set(ContentHashHeader, hex.EncodeToString(contentHash[:]))
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("signing: generating a nonce: %w", err)
+	}
+	req.Header.Set(SigningNonceHeader, hex.EncodeToString(nonce))
+
+	signed := slices.Clone(requiredSignedHeaders)
+	if req.Header.Get("Content-Type") != "" {
+		signed = append(signed, "content-type")
+	}
+	slices.Sort(signed)
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	signature := requestSignature(req, host, signed, secret)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s, SignedHeaders=%s, Signature=%s",
+		SigningAlgorithm, keyID, strings.Join(signed, ";"), hex.EncodeToString(signature)))
+	return nil
+}
+
+// SigningTransport signs every request with SignRequest before sending it, e.g. as the Transport of an http.Client
+// that calls another service.
+type SigningTransport struct {
+	KeyID  string
+	Secret []byte
+	// Base sends the signed requests, defaults to http.DefaultTransport
+	Base http.RoundTripper
+}
+
+func (t *SigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not change the request it was given
+	req = req.Clone(req.Context())
+	if err := SignRequest(req, t.KeyID, t.Secret); err != nil {
+		return nil, err
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// requestSignature is the HMAC of the string to sign, which is made of the algorithm, the signing date and the hash of
+// the canonical request
+func requestSignature(r *http.Request, host string, signed []string, secret []byte) []byte {
+	var canonical strings.Builder
+	canonical.WriteString(r.Method + "\n")
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical.WriteString(path + "\n")
+	canonical.WriteString(r.URL.Query().Encode() + "\n")
+	for _, name := range signed {
+		value := strings.Join(r.Header.Values(name), ",")
+		if name == "host" {
+			value = host
+		}
+		canonical.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonical.WriteString(strings.Join(signed, ";") + "\n")
+	canonical.WriteString(r.Header.Get(ContentHashHeader))
+
+	canonicalHash := sha256.Sum256([]byte(canonical.String()))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(SigningAlgorithm + "\n" + r.Header.Get(SigningDateHeader) + "\n" + hex.EncodeToString(canonicalHash[:])))
+	return mac.Sum(nil)
+}
+
+// MemoryReplayCache is an in-memory ReplayCache, it is only suitable for a single instance.
+type MemoryReplayCache struct {
+	mutex   sync.Mutex
+	entries map[string]time.Time
+	swept   time.Time
+}
+
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{entries: make(map[string]time.Time)}
+}
+
+func (c *MemoryReplayCache) Seen(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	// Expired entries are removed once per ttl, so the map doesn't grow with every request
+	if now.Sub(c.swept) > ttl {
+		for k, expiresAt := range c.entries {
+			if !now.Before(expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.swept = now
+	}
+	if expiresAt, ok := c.entries[key]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	c.entries[key] = now.Add(ttl)
+	return false, nil
+}
@@ -0,0 +1,111 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestRequestSignature(t *testing.T) {
+	keys := map[string][]byte{"billing": []byte("billing-secret")}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.POST("/invoices", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(middleware.SigningKeyID(r) + ":" + string(body)))
+	}).Use(middleware.RequestSignature(middleware.RequestSigningConfig{
+		Keys: func(keyID string) ([]byte, bool) {
+			secret, ok := keys[keyID]
+			return secret, ok
+		},
+	}))
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	// signed returns a request signed with the key, changed by the function after signing
+	signed := func(keyID string, secret string, change func(req *http.Request)) *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/invoices/?month=2024-05&b=1", strings.NewReader(`{"total":10}`))
+		req.Header.Set("Content-Type", "application/json")
+		if err := middleware.SignRequest(req, keyID, []byte(secret)); err != nil {
+			t.Fatalf("Failed to sign the request: %v", err)
+		}
+		if change != nil {
+			change(req)
+		}
+		return req
+	}
+
+	// Define test cases
+	tests := []struct {
+		name         string
+		req          *http.Request
+		expectedCode int
+	}{
+		{"unsigned", signed("billing", "billing-secret", func(req *http.Request) { req.Header.Del("Authorization") }), http.StatusUnauthorized},
+		{"wrong secret", signed("billing", "other-secret", nil), http.StatusUnauthorized},
+		{"unknown key", signed("shipping", "billing-secret", nil), http.StatusUnauthorized},
+		{"changed body", signed("billing", "billing-secret", func(req *http.Request) {
+			req.Body = io.NopCloser(strings.NewReader(`{"total":99}`))
+			req.GetBody = nil
+		}), http.StatusUnauthorized},
+		{"changed query", signed("billing", "billing-secret", func(req *http.Request) { req.URL.RawQuery = "month=2024-06&b=1" }), http.StatusUnauthorized},
+		{"changed content type", signed("billing", "billing-secret", func(req *http.Request) { req.Header.Set("Content-Type", "text/plain") }), http.StatusUnauthorized},
+		{"old date", signed("billing", "billing-secret", func(req *http.Request) {
+			req.Header.Set(middleware.SigningDateHeader, time.Now().Add(-time.Hour).UTC().Format("20060102T150405Z"))
+		}), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		res, err := http.DefaultClient.Do(tt.req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tt.name, err)
+		}
+		res.Body.Close()
+		if res.StatusCode != tt.expectedCode {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expectedCode, res.StatusCode)
+		}
+	}
+
+	// A valid request passes once, sending it again is a replay, also with the signature in upper case
+	req := signed("billing", "billing-secret", nil)
+	replay := req.Clone(req.Context())
+	replay.Body, _ = req.GetBody()
+	upper := req.Clone(req.Context())
+	upper.Body, _ = req.GetBody()
+	authorization, signature, _ := strings.Cut(req.Header.Get("Authorization"), "Signature=")
+	upper.Header.Set("Authorization", authorization+"Signature="+strings.ToUpper(signature))
+	for i, expectedCode := range []int{http.StatusOK, http.StatusUnauthorized, http.StatusUnauthorized} {
+		res, err := http.DefaultClient.Do([]*http.Request{req, replay, upper}[i])
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode != expectedCode {
+			t.Errorf("Request %d: expected status %d, got %d", i, expectedCode, res.StatusCode)
+		}
+		if i == 0 && string(body) != `billing:{"total":10}` {
+			t.Errorf("Expected the key ID and body, got %q", body)
+		}
+	}
+
+	// The transport signs every request of a client
+	client := &http.Client{Transport: &middleware.SigningTransport{KeyID: "billing", Secret: keys["billing"]}}
+	for range 2 {
+		res, err := client.Post(server.URL+"/invoices/", "application/json", strings.NewReader(`{"total":10}`))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("Expected status %d for a request of the signing transport, got %d", http.StatusOK, res.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gogo-framework/router"
+)
+
+// VerifySignature only allows requests to URLs generated by router.SignedURL, other requests get a 403. The signing
+// key is the one of the router that matched the request.
+func VerifySignature() router.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if err := router.VerifySignedURL(r); err != nil {
+				router.Error(w, r, http.StatusForbidden, err)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
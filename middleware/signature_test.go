@@ -0,0 +1,43 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestVerifySignature(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithSigningKey([]byte(strings.Repeat("k", 32))))
+	r.GET("/confirm/{token}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("confirmed"))
+	}).Name("confirm").Use(middleware.VerifySignature())
+
+	signed, err := r.SignedURL("confirm", map[string]string{"token": "abc"}, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to sign the URL: %v", err)
+	}
+
+	// Define test cases
+	tests := []struct {
+		url          string
+		expectedCode int
+	}{
+		{signed, http.StatusOK},
+		{strings.Replace(signed, "abc", "abd", 1), http.StatusForbidden},
+		{"/confirm/abc/", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if w.Code != tt.expectedCode {
+			t.Errorf("%s: expected status %d, got %d", tt.url, tt.expectedCode, w.Code)
+		}
+	}
+}
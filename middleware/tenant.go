@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gogo-framework/router"
+)
+
+// ErrUnknownTenant can be returned by a TenantLookup when there is no tenant with the ID.
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// TenantInfo is the tenant a request belongs to. Data can hold application specific details, like database settings.
+type TenantInfo struct {
+	ID   string
+	Plan string
+	Data any
+}
+
+// TenantResolver resolves the tenant of a request, it returns nil when the request has no tenant.
+type TenantResolver interface {
+	ResolveTenant(r *http.Request) (*TenantInfo, error)
+}
+
+type TenantResolverFunc func(r *http.Request) (*TenantInfo, error)
+
+func (f TenantResolverFunc) ResolveTenant(r *http.Request) (*TenantInfo, error) {
+	return f(r)
+}
+
+// TenantLookup loads the tenant with the given ID, e.g. from a database.
+type TenantLookup func(ctx context.Context, id string) (*TenantInfo, error)
+
+// TenantHeader is the header with the ID of the tenant that router.OutboundHeaders passes on to downstream services.
+const TenantHeader = "X-Tenant-Id"
+
+type tenantContextKey struct{}
+
+// GetTenant returns the tenant of the request, or nil when it doesn't have one.
+func GetTenant(r *http.Request) *TenantInfo {
+	tenant, _ := r.Context().Value(tenantContextKey{}).(*TenantInfo)
+	return tenant
+}
+
+// Tenant resolves the tenant of the request and stores it in the request context, handlers can get it using
+// GetTenant. Requests with an unknown tenant get a 404. Routes of groups restricted with ForTenants or ForPlans respond
+// with a 404 to requests of other tenants, so they can't find out the routes exist. The ID of the tenant is passed on
+// to downstream services as the TenantHeader of router.OutboundHeaders.
+func Tenant(resolver TenantResolver) router.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			tenant, err := resolver.ResolveTenant(r)
+			if errors.Is(err, ErrUnknownTenant) {
+				router.Error(w, r, http.StatusNotFound, nil)
+				return
+			}
+			if err != nil {
+				router.Error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			if !tenantAllowed(r, tenant) {
+				router.Error(w, r, http.StatusNotFound, nil)
+				return
+			}
+			if tenant != nil {
+				r = r.WithContext(context.WithValue(r.Context(), tenantContextKey{}, tenant))
+				router.SetOutboundHeader(r, TenantHeader, tenant.ID)
+			}
+			next(w, r)
+		}
+	}
+}
+
+// tenantAllowed checks the IDs and plans the route is restricted to. They are matched separately, the ID can come
+// from the client, so a tenant named after a plan doesn't get the routes of the plan.
+func tenantAllowed(r *http.Request, tenant *TenantInfo) bool {
+	route := router.MatchedRoute(r)
+	if route == nil {
+		return true
+	}
+	ids, restrictedIDs := route.Get("tenants")
+	plans, restrictedPlans := route.Get("plans")
+	if !restrictedIDs && !restrictedPlans {
+		return true
+	}
+	if tenant == nil {
+		return false
+	}
+	allowedIDs, _ := ids.([]string)
+	allowedPlans, _ := plans.([]string)
+	return slices.Contains(allowedIDs, tenant.ID) || tenant.Plan != "" && slices.Contains(allowedPlans, tenant.Plan)
+}
+
+// SubdomainTenant resolves the tenant from the subdomain of the host, e.g. "acme" for "acme.example.com" when the
+// domain is "example.com". Without a lookup the tenant only has an ID.
+func SubdomainTenant(domain string, lookup TenantLookup) TenantResolver {
+	suffix := "." + strings.TrimPrefix(domain, ".")
+	return TenantResolverFunc(func(r *http.Request) (*TenantInfo, error) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		id, ok := strings.CutSuffix(strings.ToLower(host), suffix)
+		if !ok || id == "" || strings.Contains(id, ".") {
+			return nil, nil
+		}
+		return lookupTenant(r, id, lookup)
+	})
+}
+
+// HeaderTenant resolves the tenant from a request header, e.g. "X-Tenant-ID".
+func HeaderTenant(header string, lookup TenantLookup) TenantResolver {
+	return TenantResolverFunc(func(r *http.Request) (*TenantInfo, error) {
+		return lookupTenant(r, r.Header.Get(header), lookup)
+	})
+}
+
+// PathTenant resolves the tenant from a path parameter, for routes with a tenant prefix like "/{tenant}/users".
+func PathTenant(param string, lookup TenantLookup) TenantResolver {
+	return TenantResolverFunc(func(r *http.Request) (*TenantInfo, error) {
+		return lookupTenant(r, r.PathValue(param), lookup)
+	})
+}
+
+func lookupTenant(r *http.Request, id string, lookup TenantLookup) (*TenantInfo, error) {
+	if id == "" {
+		return nil, nil
+	}
+	if lookup == nil {
+		return &TenantInfo{ID: id}, nil
+	}
+	return lookup(r.Context(), id)
+}
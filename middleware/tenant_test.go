@@ -0,0 +1,130 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func TestTenant(t *testing.T) {
+	tenants := map[string]*middleware.TenantInfo{
+		"acme":   {ID: "acme", Plan: "enterprise"},
+		"globex": {ID: "globex", Plan: "free"},
+		// A tenant named after a plan doesn't get the routes of the plan
+		"enterprise": {ID: "enterprise", Plan: "free"},
+	}
+	lookup := func(ctx context.Context, id string) (*middleware.TenantInfo, error) {
+		tenant, ok := tenants[id]
+		if !ok {
+			return nil, middleware.ErrUnknownTenant
+		}
+		return tenant, nil
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(middleware.Tenant(middleware.SubdomainTenant("example.com", lookup)))
+
+	whoami := func(w http.ResponseWriter, r *http.Request) {
+		if tenant := middleware.GetTenant(r); tenant != nil {
+			w.Write([]byte(tenant.ID))
+		}
+	}
+	r.GET("/whoami", whoami)
+	r.Group("/sso", func(r *router.Router) {
+		r.GET("/config", whoami)
+	}).ForPlans("enterprise")
+	r.Group("/beta", func(r *router.Router) {
+		r.GET("/feature", whoami)
+	}).ForTenants("globex")
+
+	// Define test cases
+	tests := []struct {
+		host       string
+		path       string
+		statusCode int
+		body       string
+	}{
+		{"acme.example.com", "/whoami/", http.StatusOK, "acme"},
+		{"globex.example.com:8080", "/whoami/", http.StatusOK, "globex"},
+		{"example.com", "/whoami/", http.StatusOK, ""},
+		{"initech.example.com", "/whoami/", http.StatusNotFound, ""},
+		{"acme.example.com", "/sso/config/", http.StatusOK, "acme"},
+		{"globex.example.com", "/sso/config/", http.StatusNotFound, ""},
+		{"example.com", "/sso/config/", http.StatusNotFound, ""},
+		{"enterprise.example.com", "/sso/config/", http.StatusNotFound, ""},
+		{"globex.example.com", "/beta/feature/", http.StatusOK, "globex"},
+		{"acme.example.com", "/beta/feature/", http.StatusNotFound, ""},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Host = tc.host
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tc.statusCode {
+			t.Errorf("%s%s: expected status code %d, got %d", tc.host, tc.path, tc.statusCode, rr.Code)
+		}
+		if rr.Code == http.StatusOK && rr.Body.String() != tc.body {
+			t.Errorf("%s%s: expected body %q, got %q", tc.host, tc.path, tc.body, rr.Body.String())
+		}
+	}
+}
+
+func TestTenantResolvers(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+
+	whoami := func(w http.ResponseWriter, r *http.Request) {
+		if tenant := middleware.GetTenant(r); tenant != nil {
+			w.Write([]byte(tenant.ID))
+		}
+	}
+	r.GET("/header", whoami).Use(middleware.Tenant(middleware.HeaderTenant("X-Tenant-ID", nil)))
+	r.GET("/{tenant}/users", whoami).Use(middleware.Tenant(middleware.PathTenant("tenant", nil)))
+
+	// Define test cases
+	tests := []struct {
+		path   string
+		header string
+		body   string
+	}{
+		{"/header/", "acme", "acme"},
+		{"/header/", "", ""},
+		{"/globex/users/", "", "globex"},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("X-Tenant-ID", tc.header)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Body.String() != tc.body {
+			t.Errorf("%s: expected body %q, got %q", tc.path, tc.body, rr.Body.String())
+		}
+	}
+}
+
+func TestTenantOutboundHeader(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(middleware.Tenant(middleware.HeaderTenant("X-Tenant-ID", nil)))
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(router.OutboundHeaders(r.Context()).Get(middleware.TenantHeader)))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Body.String() != "acme" {
+		t.Errorf("Expected the tenant in the outbound headers, got %q", rr.Body.String())
+	}
+}
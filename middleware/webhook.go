@@ -0,0 +1,231 @@
+// Package middleware contains ready to use middlewares for the router.
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+var (
+	ErrMissingSignature = errors.New("webhook: missing signature")
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrExpiredTimestamp = errors.New("webhook: timestamp outside of tolerance")
+	ErrBodyTooLarge     = errors.New("webhook: body too large")
+)
+
+// DefaultWebhookMaxBodySize is used when WebhookConfig.MaxBodySize is not set.
+const DefaultWebhookMaxBodySize = 1 << 20
+
+// DefaultWebhookTolerance is the maximum age of a signed timestamp for the Stripe and Slack verifiers.
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// WebhookVerifier verifies the signature of a webhook request against its raw body.
+type WebhookVerifier interface {
+	Verify(r *http.Request, body []byte) error
+}
+
+// WebhookVerifierFunc is an adapter to allow the use of ordinary functions as a WebhookVerifier.
+type WebhookVerifierFunc func(r *http.Request, body []byte) error
+
+func (f WebhookVerifierFunc) Verify(r *http.Request, body []byte) error {
+	return f(r, body)
+}
+
+type WebhookConfig struct {
+	// Verifier is used to verify the request, this is required
+	Verifier WebhookVerifier
+	// MaxBodySize is the maximum amount of bytes that will be buffered, larger bodies are rejected
+	MaxBodySize int64
+	// ErrorHandler is called when verification fails, by default it responds with 401 (or 413 for large bodies)
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// WebhookSignature verifies incoming webhook requests using the configured verifier. The body is buffered before
+// verification and restored afterwards, so the handler can still read it. A body buffered by BufferBody is reused.
+func WebhookSignature(cfg WebhookConfig) router.Middleware {
+	if cfg.Verifier == nil {
+		panic("middleware: WebhookSignature requires a Verifier")
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DefaultWebhookMaxBodySize
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = defaultWebhookErrorHandler
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			body, err := webhookBody(r, cfg.MaxBodySize)
+			if err != nil {
+				cfg.ErrorHandler(w, r, err)
+				return
+			}
+			if err := cfg.Verifier.Verify(r, body); err != nil {
+				cfg.ErrorHandler(w, r, err)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// webhookBody returns the body buffered by BufferBody, or buffers the body itself
+func webhookBody(r *http.Request, max int64) ([]byte, error) {
+	body, ok := BufferedBody(r)
+	if !ok {
+		var err error
+		if body, err = io.ReadAll(io.LimitReader(r.Body, max+1)); err != nil {
+			return nil, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if int64(len(body)) > max {
+		return nil, ErrBodyTooLarge
+	}
+	return body, nil
+}
+
+func defaultWebhookErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrBodyTooLarge) {
+		router.Error(w, r, http.StatusRequestEntityTooLarge, nil)
+		return
+	}
+	router.Error(w, r, http.StatusUnauthorized, nil)
+}
+
+type HMACConfig struct {
+	// Secret is the shared secret used to compute the signature
+	Secret []byte
+	// Header is the name of the header containing the signature
+	Header string
+	// Prefix is stripped from the header value before comparing, e.g. "sha256="
+	Prefix string
+	// Hash is the hash function used, defaults to sha256.New
+	Hash func() hash.Hash
+}
+
+// HMACVerifier verifies a hex encoded HMAC of the raw body, sent in a single header.
+func HMACVerifier(cfg HMACConfig) WebhookVerifier {
+	if cfg.Hash == nil {
+		cfg.Hash = sha256.New
+	}
+	return WebhookVerifierFunc(func(r *http.Request, body []byte) error {
+		signature := r.Header.Get(cfg.Header)
+		if signature == "" {
+			return ErrMissingSignature
+		}
+		signature, ok := strings.CutPrefix(signature, cfg.Prefix)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		return compareHexMAC(cfg.Hash, cfg.Secret, body, signature)
+	})
+}
+
+// GitHubVerifier verifies the X-Hub-Signature-256 header sent by GitHub.
+func GitHubVerifier(secret string) WebhookVerifier {
+	return HMACVerifier(HMACConfig{
+		Secret: []byte(secret),
+		Header: "X-Hub-Signature-256",
+		Prefix: "sha256=",
+	})
+}
+
+// StripeVerifier verifies the Stripe-Signature header sent by Stripe. A tolerance of 0 uses DefaultWebhookTolerance.
+func StripeVerifier(secret string, tolerance time.Duration) WebhookVerifier {
+	if tolerance <= 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	return WebhookVerifierFunc(func(r *http.Request, body []byte) error {
+		header := r.Header.Get("Stripe-Signature")
+		if header == "" {
+			return ErrMissingSignature
+		}
+
+		var timestamp string
+		var signatures []string
+		for _, part := range strings.Split(header, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = value
+			case "v1":
+				signatures = append(signatures, value)
+			}
+		}
+		if timestamp == "" || len(signatures) == 0 {
+			return ErrMissingSignature
+		}
+		if err := checkTimestamp(timestamp, tolerance); err != nil {
+			return err
+		}
+
+		payload := append([]byte(timestamp+"."), body...)
+		for _, signature := range signatures {
+			if compareHexMAC(sha256.New, []byte(secret), payload, signature) == nil {
+				return nil
+			}
+		}
+		return ErrInvalidSignature
+	})
+}
+
+// SlackVerifier verifies the X-Slack-Signature header sent by Slack. A tolerance of 0 uses DefaultWebhookTolerance.
+func SlackVerifier(signingSecret string, tolerance time.Duration) WebhookVerifier {
+	if tolerance <= 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	return WebhookVerifierFunc(func(r *http.Request, body []byte) error {
+		timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+		signature := r.Header.Get("X-Slack-Signature")
+		if timestamp == "" || signature == "" {
+			return ErrMissingSignature
+		}
+		if err := checkTimestamp(timestamp, tolerance); err != nil {
+			return err
+		}
+		signature, ok := strings.CutPrefix(signature, "v0=")
+		if !ok {
+			return ErrInvalidSignature
+		}
+		payload := append([]byte(fmt.Sprintf("v0:%s:", timestamp)), body...)
+		return compareHexMAC(sha256.New, []byte(signingSecret), payload, signature)
+	})
+}
+
+func checkTimestamp(timestamp string, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := time.Since(time.Unix(seconds, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrExpiredTimestamp
+	}
+	return nil
+}
+
+func compareHexMAC(h func() hash.Hash, secret []byte, payload []byte, signature string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(h, secret)
+	mac.Write(payload)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
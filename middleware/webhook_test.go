@@ -0,0 +1,89 @@
+package middleware_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+)
+
+func sign(secret string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookSignature(t *testing.T) {
+	secret := "s3cr3t"
+	body := `{"event":"push"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	// Create a new router instance
+	r := router.NewRouter()
+
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b)
+	}
+
+	r.POST("/github", echo).Use(middleware.WebhookSignature(middleware.WebhookConfig{
+		Verifier: middleware.GitHubVerifier(secret),
+	}))
+	r.POST("/stripe", echo).Use(middleware.WebhookSignature(middleware.WebhookConfig{
+		Verifier: middleware.StripeVerifier(secret, 0),
+	}))
+	r.POST("/slack", echo).Use(middleware.WebhookSignature(middleware.WebhookConfig{
+		Verifier: middleware.SlackVerifier(secret, 0),
+	}))
+	r.POST("/small", echo).Use(middleware.WebhookSignature(middleware.WebhookConfig{
+		Verifier:    middleware.GitHubVerifier(secret),
+		MaxBodySize: 4,
+	}))
+
+	// Define test cases for each verifier
+	tests := []struct {
+		name       string
+		path       string
+		headers    map[string]string
+		statusCode int
+	}{
+		{"github valid", "/github/", map[string]string{"X-Hub-Signature-256": "sha256=" + sign(secret, body)}, http.StatusOK},
+		{"github invalid", "/github/", map[string]string{"X-Hub-Signature-256": "sha256=" + sign("wrong", body)}, http.StatusUnauthorized},
+		{"github missing", "/github/", nil, http.StatusUnauthorized},
+		{"stripe valid", "/stripe/", map[string]string{"Stripe-Signature": "t=" + now + ",v1=" + sign(secret, now+"."+body)}, http.StatusOK},
+		{"stripe expired", "/stripe/", map[string]string{"Stripe-Signature": "t=" + old + ",v1=" + sign(secret, old+"."+body)}, http.StatusUnauthorized},
+		{"slack valid", "/slack/", map[string]string{"X-Slack-Request-Timestamp": now, "X-Slack-Signature": "v0=" + sign(secret, "v0:"+now+":"+body)}, http.StatusOK},
+		{"slack invalid", "/slack/", map[string]string{"X-Slack-Request-Timestamp": now, "X-Slack-Signature": "v0=abcd"}, http.StatusUnauthorized},
+		{"body too large", "/small/", map[string]string{"X-Hub-Signature-256": "sha256=" + sign(secret, body)}, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(body))
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.statusCode)
+			}
+			// The handler should still be able to read the body after verification
+			if tt.statusCode == http.StatusOK && rr.Body.String() != body {
+				t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), body)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// mirrorQueueSize is the amount of mirrored requests a route buffers, requests are dropped when it's full
+	mirrorQueueSize = 100
+	// mirrorMaxBodySize is the largest body that is mirrored, requests with larger bodies are not mirrored
+	mirrorMaxBodySize = 1 << 20
+	mirrorTimeout     = 10 * time.Second
+)
+
+// mirror replays copies of requests to a shadow backend
+type mirror struct {
+	target     *url.URL
+	sampleRate float64
+	queue      chan *http.Request
+	start      sync.Once
+	logger     *slog.Logger
+}
+
+// Mirror sends a copy of a share of the requests to the target, e.g. to validate a rewrite with real traffic.
+// The copies are sent in the background after the request has been handled, so the client response isn't
+// affected. The body is copied while the handler reads it, requests whose body the handler didn't read completely or
+// that are larger than 1MB aren't mirrored. The copies get the Host of the target, the original host is sent as
+// X-Forwarded-Host. The responses of the target are discarded.
+func (r *Route) Mirror(target string, sampleRate float64) *Route {
+	r.mustBeMutable()
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		panic(fmt.Sprintf("router: invalid mirror target %q", target))
+	}
+	r.mirror = &mirror{target: u, sampleRate: sampleRate, queue: make(chan *http.Request, mirrorQueueSize)}
+	return r
+}
+
+func (r *Router) mirrorHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	m := route.mirror
+	if m == nil || m.sampleRate <= 0 {
+		return handler
+	}
+	m.logger = r.Logger()
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		if rand.Float64() >= m.sampleRate {
+			handler(w, req)
+			return
+		}
+
+		// The body is copied while the handler reads it, reading the rest afterwards would delay the response
+		var body bytes.Buffer
+		var tee *teeBody
+		if req.Body != nil && req.Body != http.NoBody {
+			tee = &teeBody{ReadCloser: req.Body, copy: &limitedWriter{buffer: &body, limit: mirrorMaxBodySize + 1}}
+			req.Body = tee
+		}
+		handler(w, req)
+
+		if tee != nil && (!tee.complete(req.ContentLength) || body.Len() > mirrorMaxBodySize) {
+			m.logger.Debug("router: request not mirrored, the body wasn't read completely or is too large",
+				"method", req.Method, "path", req.URL.Path)
+			return
+		}
+		m.enqueue(req, body.Bytes())
+	}
+}
+
+func (m *mirror) enqueue(req *http.Request, body []byte) {
+	u := *m.target
+	u.Path = u.JoinPath(req.URL.Path).Path
+	u.RawQuery = req.URL.RawQuery
+
+	copied, err := http.NewRequestWithContext(context.Background(), req.Method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	copied.Header = req.Header.Clone()
+	copied.Header.Del("Connection")
+	copied.Header.Set("X-Mirrored", "true")
+	copied.Header.Set("X-Forwarded-Host", req.Host)
+	copied.Host = m.target.Host
+
+	m.start.Do(func() { go m.run() })
+	select {
+	case m.queue <- copied:
+	default:
+		// The shadow backend can't keep up, dropping copies is better than slowing down the route
+	}
+}
+
+func (m *mirror) run() {
+	client := &http.Client{Timeout: mirrorTimeout}
+	for req := range m.queue {
+		resp, err := client.Do(req)
+		if err != nil {
+			m.logger.Warn("router: mirroring failed", "method", req.Method, "url", req.URL.String(), "error", err)
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// teeBody copies the body while it's read
+type teeBody struct {
+	io.ReadCloser
+	copy io.Writer
+	read int64
+	eof  bool
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.copy.Write(p[:n])
+	b.read += int64(n)
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+// complete reports whether the body was read completely, decoders like encoding/json can stop before they get io.EOF
+// so the Content-Length is checked as well
+func (b *teeBody) complete(contentLength int64) bool {
+	return b.eof || contentLength > 0 && b.read == contentLength
+}
+
+// limitedWriter writes to the buffer until the limit is reached, and discards the rest
+type limitedWriter struct {
+	buffer *bytes.Buffer
+	limit  int
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if remaining := lw.limit - lw.buffer.Len(); remaining > 0 {
+		lw.buffer.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,74 @@
+package router_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+type mirroredRequest struct {
+	method string
+	uri    string
+	body   string
+	header string
+	host   string
+}
+
+func TestMirror(t *testing.T) {
+	mirrored := make(chan mirroredRequest, 10)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mirrored <- mirroredRequest{r.Method, r.URL.RequestURI(), string(body), r.Header.Get("X-Request-ID"), r.Host}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer shadow.Close()
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.POST("/orders", func(w http.ResponseWriter, r *http.Request) {
+		var order map[string]any
+		json.NewDecoder(r.Body).Decode(&order)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(order)
+	}).Mirror(shadow.URL+"/shadow", 1)
+	r.POST("/partial", func(w http.ResponseWriter, r *http.Request) {
+		// Only part of the body is read, so there is no complete copy to mirror
+		io.ReadFull(r.Body, make([]byte, 5))
+	}).Mirror(shadow.URL, 1)
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {}).Mirror(shadow.URL, 0)
+
+	req := httptest.NewRequest(http.MethodPost, "/orders/?dry=1", strings.NewReader(`{"item": 42}`))
+	req.Header.Set("X-Request-ID", "abc")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	// The client gets the response of the route, not of the shadow backend
+	if rr.Code != http.StatusCreated || rr.Body.String() != "{\"item\":42}\n" {
+		t.Errorf("Expected 201 with the route response, got %d %q", rr.Code, rr.Body.String())
+	}
+
+	select {
+	case m := <-mirrored:
+		expected := mirroredRequest{http.MethodPost, "/shadow/orders/?dry=1", `{"item": 42}`, "abc", strings.TrimPrefix(shadow.URL, "http://")}
+		if m != expected {
+			t.Errorf("Expected mirrored request %+v, got %+v", expected, m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the request to be mirrored")
+	}
+
+	// A sample rate of 0 mirrors nothing, and neither do bodies that weren't read completely
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/partial/", strings.NewReader(`{"item": 42}`)))
+	select {
+	case m := <-mirrored:
+		t.Errorf("Expected no mirrored request, got %+v", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
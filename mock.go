@@ -0,0 +1,70 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+)
+
+// MockResponse can be used as an example to control the status code and headers of a mocked response.
+type MockResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       any
+}
+
+// MockMode makes the router serve example responses instead of calling the real handlers. Examples are looked up
+// by the "example" route metadata value first, and then in the given map by method and path, e.g. "GET /users/{id}".
+// Routes without an example still use their real handler, middlewares are applied in both cases.
+// An example can be a MockResponse, a string or []byte which are written as is, or any value which is encoded as JSON.
+func (r *Router) MockMode(examples map[string]any) {
+	r.mustBeMutable()
+	r.mockEnabled = true
+	r.mockExamples = examples
+}
+
+func (r *Router) mockHandler(route *Route) http.HandlerFunc {
+	if !r.mockEnabled {
+		return route.HandlerFunc
+	}
+
+	example, ok := route.Get("example")
+	if !ok {
+		example, ok = r.mockExamples[route.Method+" "+route.Path()]
+	}
+	if !ok {
+		return route.HandlerFunc
+	}
+
+	response, ok := example.(MockResponse)
+	if !ok {
+		response = MockResponse{Body: example}
+	}
+	if response.StatusCode == 0 {
+		response.StatusCode = http.StatusOK
+	}
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		for key, values := range response.Header {
+			w.Header()[key] = slices.Clone(values)
+		}
+		w.Header().Set("X-Mock-Response", "true")
+
+		switch body := response.Body.(type) {
+		case nil:
+			w.WriteHeader(response.StatusCode)
+		case string:
+			w.WriteHeader(response.StatusCode)
+			w.Write([]byte(body))
+		case []byte:
+			w.WriteHeader(response.StatusCode)
+			w.Write(body)
+		default:
+			if w.Header().Get("Content-Type") == "" {
+				w.Header().Set("Content-Type", "application/json")
+			}
+			w.WriteHeader(response.StatusCode)
+			json.NewEncoder(w).Encode(body)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestMockMode(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.MockMode(map[string]any{
+		"GET /users/{id}": map[string]any{"id": 1, "name": "Jane"},
+		"POST /users":     router.MockResponse{StatusCode: http.StatusCreated, Body: "created"},
+	})
+
+	notImplemented := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+	r.GET("/users/{id}", notImplemented)
+	r.POST("/users", notImplemented)
+	r.GET("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	r.Group("orders", func(rg *router.Router) {
+		rg.GET("/", notImplemented).Set("example", []map[string]any{{"id": 7}})
+	})
+
+	// Define test cases for each route
+	tests := []struct {
+		method     string
+		path       string
+		statusCode int
+		response   string
+		mocked     bool
+	}{
+		{http.MethodGet, "/users/5/", http.StatusOK, "{\"id\":1,\"name\":\"Jane\"}\n", true},
+		{http.MethodPost, "/users/", http.StatusCreated, "created", true},
+		{http.MethodGet, "/orders/", http.StatusOK, "[{\"id\":7}]\n", true},
+		{http.MethodGet, "/health/", http.StatusOK, "ok", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.statusCode)
+			}
+			if body := rr.Body.String(); body != tt.response {
+				t.Errorf("handler returned unexpected body: got %v want %v", body, tt.response)
+			}
+			if mocked := rr.Header().Get("X-Mock-Response") == "true"; mocked != tt.mocked {
+				t.Errorf("unexpected mock header: got %v want %v", mocked, tt.mocked)
+			}
+		})
+	}
+}
+
+func TestMockModeHeader(t *testing.T) {
+	// The values have room for another one, so appending to a shared slice would overwrite them
+	tags := make([]string, 1, 2)
+	tags[0] = "mock"
+	r := router.NewRouter()
+	r.MockMode(map[string]any{
+		"GET /users": router.MockResponse{Header: http.Header{"X-Tag": tags}},
+	})
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			next(w, req)
+			w.Header().Add("X-Tag", req.URL.Query().Get("tag"))
+		}
+	})
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+
+	first := httptest.NewRecorder()
+	r.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/users/?tag=first", nil))
+	second := httptest.NewRecorder()
+	r.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/users/?tag=second", nil))
+
+	for rr, want := range map[*httptest.ResponseRecorder]string{first: "mock first", second: "mock second"} {
+		if got := strings.Join(rr.Header().Values("X-Tag"), " "); got != want {
+			t.Errorf("Expected X-Tag %q, got %q", want, got)
+		}
+	}
+	if len(tags) != 1 || tags[0] != "mock" {
+		t.Errorf("Expected the header of the mock response to be unchanged, got %v", tags)
+	}
+}
@@ -0,0 +1,72 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Mount registers a handler for every method and every path below the prefix. The prefix is stripped from the
+// request path before the handler is called, middlewares and hooks are applied as usual. The prefix can have path
+// parameters, e.g. "/tenants/{tenant}", as many segments as the prefix has are stripped then.
+func (r *Router) Mount(prefix string, handler http.Handler) *Route {
+	route := r.RegisterRoute("", prefix, nil)
+	route.mount = true
+	route.HandlerFunc = func(w http.ResponseWriter, req *http.Request) {
+		// The matched route is used instead of route, so the handler keeps working for clones of the route
+		prefix := MatchedRoute(req).Path()
+		if i := strings.Index(prefix, "/"); i >= 0 {
+			prefix = prefix[i:]
+		}
+		if prefix == "/" {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		stripped, ok := stripSegments(req, strings.Count(prefix, "/"))
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		handler.ServeHTTP(w, stripped)
+	}
+	return route
+}
+
+// stripSegments returns a shallow copy of the request without the first n segments of its path, like
+// http.StripPrefix. The segments are counted in the escaped path, so an encoded slash in a parameter counts as part
+// of its segment.
+func stripSegments(req *http.Request, n int) (*http.Request, bool) {
+	escaped := req.URL.EscapedPath()
+	end := 0
+	for range n {
+		next := strings.IndexByte(escaped[end+1:], '/')
+		if next < 0 {
+			return nil, false
+		}
+		end += 1 + next
+	}
+	rawPath := escaped[end:]
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return nil, false
+	}
+
+	stripped := new(http.Request)
+	*stripped = *req
+	stripped.URL = new(url.URL)
+	*stripped.URL = *req.URL
+	stripped.URL.Path = path
+	stripped.URL.RawPath = ""
+	// Like url.URL, the raw path is only kept if it differs from the default encoding of the path
+	if rawPath != (&url.URL{Path: path}).EscapedPath() {
+		stripped.URL.RawPath = rawPath
+	}
+	return stripped, true
+}
+
+// MountGRPCGateway mounts a grpc-gateway *runtime.ServeMux below the prefix, so REST and gRPC services can share the
+// router and its middlewares. The mux is accepted as a http.Handler to keep this package free of gRPC dependencies.
+// Streaming responses are flushed through the middleware chain as long as the middlewares don't buffer the response.
+func (r *Router) MountGRPCGateway(prefix string, mux http.Handler) *Route {
+	return r.Mount(prefix, mux).Set("grpc-gateway", true)
+}
@@ -0,0 +1,109 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestMountGRPCGateway(t *testing.T) {
+	// A stand-in for a grpc-gateway runtime.ServeMux, which is a plain http.Handler
+	gateway := http.NewServeMux()
+	gateway.HandleFunc("GET /v1/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + r.PathValue("id")))
+	})
+	gateway.HandleFunc("GET /v1/stream", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("chunk"))
+		flusher.Flush()
+	})
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.OnResponse(func(info router.ResponseInfo) {})
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Shared-Middleware", "true")
+			next(w, r)
+		}
+	})
+	r.MountGRPCGateway("/grpc", gateway)
+	r.GET("/rest", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("rest"))
+	})
+
+	// Define test cases for each route
+	tests := []struct {
+		method     string
+		path       string
+		statusCode int
+		response   string
+	}{
+		{http.MethodGet, "/grpc/v1/users/5", http.StatusOK, "user 5"},
+		{http.MethodGet, "/grpc/v1/stream", http.StatusOK, "chunk"},
+		{http.MethodGet, "/grpc/v1/unknown", http.StatusNotFound, "404 page not found\n"},
+		{http.MethodGet, "/rest/", http.StatusOK, "rest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.statusCode)
+			}
+			if body := rr.Body.String(); body != tt.response {
+				t.Errorf("handler returned unexpected body: got %v want %v", body, tt.response)
+			}
+			if rr.Header().Get("X-Shared-Middleware") != "true" {
+				t.Errorf("expected shared middleware to run")
+			}
+		})
+	}
+}
+
+func TestMountPrefixParams(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path + " " + r.URL.RawPath))
+	})
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Mount("/tenants/{tenant}/files", handler)
+	r.Mount("/static", handler)
+
+	// Define test cases
+	tests := []struct {
+		path     string
+		response string
+	}{
+		{"/tenants/acme/files/docs/a.txt", "/docs/a.txt "},
+		{"/tenants/acme/files/", "/ "},
+		{"/tenants/a%2Fb/files/docs/a.txt", "/docs/a.txt "},
+		{"/tenants/acme/files/a%2Fb.txt", "/a/b.txt /a%2Fb.txt"},
+		{"/static/css/app.css", "/css/app.css "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", rr.Code)
+			}
+			if body := rr.Body.String(); body != tt.response {
+				t.Errorf("Expected body %q, got %q", tt.response, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package router
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// matchQueueSize is the amount of match events the router buffers, events are dropped when it's full
+const matchQueueSize = 1000
+
+// MatchEvent is a request that matched a route, for analytics like page views or endpoint usage.
+type MatchEvent struct {
+	Route  *Route
+	Params map[string]string
+	Method string
+	Path   string
+	Host   string
+	// RemoteAddr, UserAgent and Referer are copied from the request
+	RemoteAddr string
+	UserAgent  string
+	Referer    string
+	Time       time.Time
+}
+
+// matchEvents delivers the match events to the listeners in the background. The delivery goroutine is started for
+// the first event and exits once the queue is empty, so routers that are no longer used don't keep it around.
+type matchEvents struct {
+	listeners []func(MatchEvent)
+	dropped   atomic.Int64
+
+	mutex   sync.Mutex
+	queue   chan MatchEvent
+	running bool
+	// pending counts the queued events that weren't delivered yet, for Drain
+	pending asyncWork
+}
+
+// OnMatch registers a listener that gets an event for every request that matched a route, before the middlewares
+// run. Events are delivered in the background through a buffered queue, so slow listeners don't slow down requests.
+// Events are dropped when the queue is full, DroppedMatchEvents counts them. Listeners are called one at a time, in
+// the order of the requests. Router.Drain waits for the queued events on shutdown.
+//
+//	r.OnMatch(func(event router.MatchEvent) {
+//		analytics.Track("endpoint_used", event.Route.FullPattern(), event.Params)
+//	})
+func (r *Router) OnMatch(listener func(MatchEvent)) {
+	r.mustBeMutable()
+	r.matchEvents.listeners = append(r.matchEvents.listeners, listener)
+}
+
+// DroppedMatchEvents returns the amount of match events that were dropped because the listeners couldn't keep up.
+func (r *Router) DroppedMatchEvents() int64 {
+	return r.matchEvents.dropped.Load()
+}
+
+// matchEventHandler emits a match event for the requests of the route, routers without listeners aren't wrapped
+func (r *Router) matchEventHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	events := &r.matchEvents
+	if len(events.listeners) == 0 {
+		return handler
+	}
+	paramNames := route.ParamNames()
+	return func(w http.ResponseWriter, req *http.Request) {
+		event := MatchEvent{
+			Route:      route,
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			Host:       req.Host,
+			RemoteAddr: req.RemoteAddr,
+			UserAgent:  req.UserAgent(),
+			Referer:    req.Referer(),
+			Time:       time.Now(),
+		}
+		if len(paramNames) > 0 {
+			event.Params = make(map[string]string, len(paramNames))
+			for _, name := range paramNames {
+				event.Params[name] = req.PathValue(name)
+			}
+		}
+
+		events.emit(r, event)
+		handler(w, req)
+	}
+}
+
+// emit queues the event, and starts the delivery when it isn't running
+func (e *matchEvents) emit(r *Router, event MatchEvent) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.queue == nil {
+		e.queue = make(chan MatchEvent, matchQueueSize)
+	}
+	select {
+	case e.queue <- event:
+		e.pending.add()
+	default:
+		e.dropped.Add(1)
+		return
+	}
+	if !e.running {
+		e.running = true
+		go e.run(r)
+	}
+}
+
+// run delivers the queued events, and returns once the queue is empty
+func (e *matchEvents) run(r *Router) {
+	for {
+		e.mutex.Lock()
+		var event MatchEvent
+		select {
+		case event = <-e.queue:
+		default:
+			e.running = false
+			e.mutex.Unlock()
+			return
+		}
+		e.mutex.Unlock()
+		for _, listener := range e.listeners {
+			e.deliver(r, listener, event)
+		}
+		e.pending.done()
+	}
+}
+
+// deliver calls the listener, a listener that panics doesn't stop the delivery of the other events
+func (e *matchEvents) deliver(r *Router, listener func(MatchEvent), event MatchEvent) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			r.Logger().Error("router: match listener panicked", "route", event.Route.FullPattern(), "error", recovered)
+		}
+	}()
+	listener(event)
+}
@@ -0,0 +1,101 @@
+package router_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestOnMatch(t *testing.T) {
+	events := make(chan router.MatchEvent, 10)
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	r.OnMatch(func(event router.MatchEvent) {
+		panic("listeners can panic")
+	})
+	r.OnMatch(func(event router.MatchEvent) {
+		events <- event
+	})
+	r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	r.GET("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	// Define test cases
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/users/42/", "GET /users/{id}/{$}", map[string]string{"id": "42"}},
+		{"/health/", "GET /health/{$}", nil},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("User-Agent", "test-agent")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		select {
+		case event := <-events:
+			if event.Route.FullPattern() != tc.pattern || event.Path != tc.path || event.UserAgent != "test-agent" {
+				t.Errorf("%s: expected an event for %s, got %+v", tc.path, tc.pattern, event)
+			}
+			if len(event.Params) != len(tc.params) || event.Params["id"] != tc.params["id"] {
+				t.Errorf("%s: expected the params %v, got %v", tc.path, tc.params, event.Params)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: expected a match event", tc.path)
+		}
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing/", nil))
+	select {
+	case event := <-events:
+		t.Errorf("Expected no event for unmatched requests, got %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if dropped := r.DroppedMatchEvents(); dropped != 0 {
+		t.Errorf("Expected no dropped events, got %d", dropped)
+	}
+}
+
+func TestOnMatchDrain(t *testing.T) {
+	var delivered atomic.Int64
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.OnMatch(func(event router.MatchEvent) {
+		time.Sleep(10 * time.Millisecond)
+		delivered.Add(1)
+	})
+	r.GET("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	goroutines := runtime.NumGoroutine()
+	for range 5 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health/", nil))
+	}
+	// Drain waits for the queued events
+	if err := r.Drain(context.Background()); err != nil {
+		t.Fatalf("Failed to drain: %v", err)
+	}
+	if n := delivered.Load(); n != 5 {
+		t.Errorf("Expected 5 delivered events after draining, got %d", n)
+	}
+
+	// The delivery stops once the queue is empty
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Errorf("Expected the delivery to stop once the queue is empty, got %d goroutines instead of %d", n, goroutines)
+	}
+}
@@ -0,0 +1,221 @@
+// Package openapi contains a subset of the OpenAPI 3 document model and validates requests against it.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters,omitempty"`
+	Get        *Operation   `json:"get,omitempty"`
+	Put        *Operation   `json:"put,omitempty"`
+	Post       *Operation   `json:"post,omitempty"`
+	Delete     *Operation   `json:"delete,omitempty"`
+	Options    *Operation   `json:"options,omitempty"`
+	Head       *Operation   `json:"head,omitempty"`
+	Patch      *Operation   `json:"patch,omitempty"`
+	Trace      *Operation   `json:"trace,omitempty"`
+}
+
+// Operation returns the operation for the given HTTP method, or nil if there is none.
+func (p *PathItem) Operation(method string) *Operation {
+	switch method {
+	case http.MethodGet:
+		return p.Get
+	case http.MethodPut:
+		return p.Put
+	case http.MethodPost:
+		return p.Post
+	case http.MethodDelete:
+		return p.Delete
+	case http.MethodOptions:
+		return p.Options
+	case http.MethodHead:
+		return p.Head
+	case http.MethodPatch:
+		return p.Patch
+	case http.MethodTrace:
+		return p.Trace
+	}
+	return nil
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema  *Schema `json:"schema,omitempty"`
+	Example any     `json:"example,omitempty"`
+}
+
+// Parse decodes a JSON OpenAPI document, resolves references to component schemas and compiles their patterns.
+func Parse(r io.Reader) (*Document, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("openapi: failed to decode document: %w", err)
+	}
+	if err := doc.resolveRefs(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Find returns the operation for the method and OpenAPI path, e.g. "/users/{id}".
+func (d *Document) Find(method string, path string) (*PathItem, *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil, nil
+	}
+	return item, item.Operation(method)
+}
+
+// PathFromPattern converts a pattern as registered on the ServeMux, e.g. "GET /users/{id}/{$}", to the method and
+// the path used in OpenAPI documents, e.g. "GET" and "/users/{id}".
+func PathFromPattern(pattern string) (string, string) {
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		method, path = "", pattern
+	}
+	// Patterns can start with a host, which is not part of the OpenAPI path
+	if i := strings.Index(path, "/"); i > 0 {
+		path = path[i:]
+	}
+	path = strings.TrimSuffix(path, "{$}")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	path = strings.ReplaceAll(path, "...}", "}")
+	return method, path
+}
+
+func (d *Document) resolveRefs() error {
+	visited := make(map[*Schema]bool)
+	var resolve func(s *Schema) (*Schema, error)
+	resolve = func(s *Schema) (*Schema, error) {
+		if s == nil {
+			return nil, nil
+		}
+		if s.Ref != "" {
+			name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+			if !ok {
+				return nil, fmt.Errorf("openapi: unsupported reference %q", s.Ref)
+			}
+			target, ok := d.Components.Schemas[name]
+			if !ok {
+				return nil, fmt.Errorf("openapi: unknown schema %q", s.Ref)
+			}
+			return resolve(target)
+		}
+		if visited[s] {
+			return s, nil
+		}
+		visited[s] = true
+
+		if _, err := s.compiledPattern(); err != nil {
+			return nil, fmt.Errorf("openapi: invalid pattern %q: %w", s.Pattern, err)
+		}
+		var err error
+		for name, property := range s.Properties {
+			if s.Properties[name], err = resolve(property); err != nil {
+				return nil, err
+			}
+		}
+		if s.Items, err = resolve(s.Items); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	resolveContent := func(content map[string]*MediaType) error {
+		for _, mediaType := range content {
+			var err error
+			if mediaType.Schema, err = resolve(mediaType.Schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	resolveParameters := func(parameters []*Parameter) error {
+		for _, parameter := range parameters {
+			var err error
+			if parameter.Schema, err = resolve(parameter.Schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for name, schema := range d.Components.Schemas {
+		resolved, err := resolve(schema)
+		if err != nil {
+			return err
+		}
+		d.Components.Schemas[name] = resolved
+	}
+	for _, item := range d.Paths {
+		if err := resolveParameters(item.Parameters); err != nil {
+			return err
+		}
+		for _, operation := range []*Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace} {
+			if operation == nil {
+				continue
+			}
+			if err := resolveParameters(operation.Parameters); err != nil {
+				return err
+			}
+			if operation.RequestBody != nil {
+				if err := resolveContent(operation.RequestBody.Content); err != nil {
+					return err
+				}
+			}
+			for _, response := range operation.Responses {
+				if err := resolveContent(response.Content); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
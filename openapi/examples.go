@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gogo-framework/router"
+)
+
+var methods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// Examples collects the example of the first successful JSON response of every operation, in the format expected by
+// Router.MockMode.
+func Examples(doc *Document) map[string]any {
+	examples := make(map[string]any)
+	for path, item := range doc.Paths {
+		for _, method := range methods {
+			operation := item.Operation(method)
+			if operation == nil {
+				continue
+			}
+			if response, ok := exampleResponse(operation); ok {
+				examples[method+" "+path] = response
+			}
+		}
+	}
+	return examples
+}
+
+func exampleResponse(operation *Operation) (router.MockResponse, bool) {
+	codes := make([]string, 0, len(operation.Responses))
+	for code := range operation.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		statusCode, err := strconv.Atoi(code)
+		if err != nil || statusCode < 200 || statusCode > 299 {
+			continue
+		}
+		mediaType, ok := operation.Responses[code].Content["application/json"]
+		if !ok {
+			continue
+		}
+		example := mediaType.Example
+		if example == nil && mediaType.Schema != nil {
+			example = mediaType.Schema.Example
+		}
+		if example == nil {
+			continue
+		}
+		return router.MockResponse{StatusCode: statusCode, Body: example}, true
+	}
+	return router.MockResponse{}, false
+}
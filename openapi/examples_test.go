@@ -0,0 +1,50 @@
+package openapi_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/openapi"
+)
+
+func TestExamples(t *testing.T) {
+	doc, err := openapi.Parse(strings.NewReader(`{
+		"openapi": "3.0.3",
+		"paths": {
+			"/users/{id}": {
+				"get": {
+					"responses": {
+						"404": {"description": "Not found", "content": {"application/json": {"example": {"error": "not found"}}}},
+						"200": {"description": "User", "content": {"application/json": {"example": {"id": 1}}}}
+					}
+				},
+				"delete": {"responses": {"204": {"description": "Deleted"}}}
+			},
+			"/users": {
+				"post": {
+					"responses": {
+						"201": {"description": "Created", "content": {"application/json": {"schema": {"type": "object", "example": {"id": 2}}}}}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+
+	examples := openapi.Examples(doc)
+	if len(examples) != 2 {
+		t.Fatalf("expected 2 examples, got %d: %v", len(examples), examples)
+	}
+	get := examples["GET /users/{id}"].(router.MockResponse)
+	if get.StatusCode != http.StatusOK || get.Body.(map[string]any)["id"] != float64(1) {
+		t.Errorf("unexpected example for GET /users/{id}: %+v", get)
+	}
+	post := examples["POST /users"].(router.MockResponse)
+	if post.StatusCode != http.StatusCreated || post.Body.(map[string]any)["id"] != float64(2) {
+		t.Errorf("unexpected example for POST /users: %+v", post)
+	}
+}
@@ -0,0 +1,155 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gogo-framework/router"
+)
+
+type ResponseValidatorConfig struct {
+	// Enabled turns the validation on, when false the middleware doesn't do anything. Enable it in development only,
+	// as every response has to be buffered to validate it
+	Enabled bool
+	// Document is used to look up the response schema by status code, it's optional when routes set a schema
+	Document *Document
+	// SchemaKey is the route metadata key containing a *Schema for successful responses, defaults to "response_schema"
+	SchemaKey string
+	// Fail replaces invalid responses with a 500 containing the errors, by default the errors are only logged
+	Fail bool
+	// Logger is called with the errors of invalid responses, defaults to a warning in the logger of the router
+	Logger func(r *http.Request, errs []ValidationError)
+}
+
+// ValidateResponses validates outgoing JSON responses against the schema of the matched route, catching drift between
+// the implementation and the contract early.
+func ValidateResponses(cfg ResponseValidatorConfig) router.Middleware {
+	if !cfg.Enabled {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return next
+		}
+	}
+	if cfg.SchemaKey == "" {
+		cfg.SchemaKey = "response_schema"
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = func(r *http.Request, errs []ValidationError) {
+			router.Logger(r).Warn("openapi: response does not match its schema", "method", r.Method, "path", r.URL.Path, "errors", errs)
+		}
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			route := router.MatchedRoute(r)
+			if route == nil {
+				next(w, r)
+				return
+			}
+
+			buffered := &bufferedWriter{header: make(http.Header)}
+			next(buffered, r)
+
+			errs := cfg.validate(route, buffered)
+			if len(errs) > 0 {
+				cfg.Logger(r, errs)
+				if cfg.Fail {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusInternalServerError)
+					json.NewEncoder(w).Encode(map[string]any{
+						"error":  "response validation failed",
+						"errors": errs,
+					})
+					return
+				}
+			}
+
+			for key, values := range buffered.header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(buffered.status())
+			w.Write(buffered.body.Bytes())
+		}
+	}
+}
+
+func (cfg ResponseValidatorConfig) validate(route *router.Route, buffered *bufferedWriter) []ValidationError {
+	mediaType, _, _ := mime.ParseMediaType(buffered.header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return nil
+	}
+
+	schema := cfg.schemaFor(route, buffered.status())
+	if schema == nil {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(buffered.body.Bytes(), &value); err != nil {
+		return []ValidationError{{In: "response", Message: "invalid JSON"}}
+	}
+	return schema.Validate("response", "", value)
+}
+
+func (cfg ResponseValidatorConfig) schemaFor(route *router.Route, statusCode int) *Schema {
+	if statusCode >= 200 && statusCode <= 299 {
+		if value, ok := route.Get(cfg.SchemaKey); ok {
+			if schema, ok := value.(*Schema); ok {
+				return schema
+			}
+		}
+	}
+	if cfg.Document == nil {
+		return nil
+	}
+
+	_, operation := cfg.Document.Find(PathFromPattern(route.FullPattern()))
+	if operation == nil {
+		return nil
+	}
+	response, ok := operation.Responses[strconv.Itoa(statusCode)]
+	if !ok {
+		response, ok = operation.Responses["default"]
+	}
+	if !ok {
+		return nil
+	}
+	if mediaType, ok := response.Content["application/json"]; ok {
+		return mediaType.Schema
+	}
+	return nil
+}
+
+// bufferedWriter keeps the whole response in memory, so it can be validated before it's sent.
+type bufferedWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedWriter) WriteHeader(statusCode int) {
+	// Informational responses like 103 Early Hints aren't the status of the response
+	if bw.statusCode == 0 && statusCode >= 200 {
+		bw.statusCode = statusCode
+	}
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	if bw.statusCode == 0 {
+		bw.statusCode = http.StatusOK
+	}
+	return bw.body.Write(b)
+}
+
+func (bw *bufferedWriter) status() int {
+	if bw.statusCode == 0 {
+		return http.StatusOK
+	}
+	return bw.statusCode
+}
@@ -0,0 +1,100 @@
+package openapi_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/openapi"
+)
+
+func TestValidateResponses(t *testing.T) {
+	userSchema := &openapi.Schema{
+		Type:     "object",
+		Required: []string{"id", "name"},
+		Properties: map[string]*openapi.Schema{
+			"id":   {Type: "integer"},
+			"name": {Type: "string"},
+		},
+	}
+
+	var logged []openapi.ValidationError
+
+	// Create a new router instance
+	var logs bytes.Buffer
+	r := router.NewRouter(router.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	json := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}
+	}
+
+	r.Group("log", func(rg *router.Router) {
+		rg.GET("/valid", json(`{"id":1,"name":"Jane"}`)).Set("response_schema", userSchema)
+		rg.GET("/invalid", json(`{"id":"1"}`)).Set("response_schema", userSchema)
+		rg.GET("/hints", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Link", "</app.css>; rel=preload")
+			w.WriteHeader(http.StatusEarlyHints)
+			json(`{"id":1,"name":"Jane"}`)(w, r)
+		}).Set("response_schema", userSchema)
+	}).Use(openapi.ValidateResponses(openapi.ResponseValidatorConfig{
+		Enabled: true,
+		Logger: func(r *http.Request, errs []openapi.ValidationError) {
+			logged = append(logged, errs...)
+		},
+	}))
+	r.Group("fail", func(rg *router.Router) {
+		rg.GET("/invalid", json(`{"id":"1"}`)).Set("response_schema", userSchema)
+	}).Use(openapi.ValidateResponses(openapi.ResponseValidatorConfig{
+		Enabled: true,
+		Fail:    true,
+		Logger:  func(r *http.Request, errs []openapi.ValidationError) {},
+	}))
+	r.Group("default", func(rg *router.Router) {
+		rg.GET("/invalid", json(`{"id":"1"}`)).Set("response_schema", userSchema)
+	}).Use(openapi.ValidateResponses(openapi.ResponseValidatorConfig{Enabled: true}))
+	r.Group("disabled", func(rg *router.Router) {
+		rg.GET("/invalid", json(`{"id":"1"}`)).Set("response_schema", userSchema)
+	}).Use(openapi.ValidateResponses(openapi.ResponseValidatorConfig{}))
+
+	// Define test cases for each route
+	tests := []struct {
+		path       string
+		statusCode int
+		logged     int
+	}{
+		{"/log/valid/", http.StatusOK, 0},
+		{"/log/hints/", http.StatusOK, 0},
+		{"/log/invalid/", http.StatusOK, 2},
+		{"/fail/invalid/", http.StatusInternalServerError, 2},
+		{"/disabled/invalid/", http.StatusOK, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.statusCode)
+			}
+			if len(logged) != tt.logged {
+				t.Errorf("unexpected amount of logged errors: got %v want %v", len(logged), tt.logged)
+			}
+		})
+	}
+
+	// Without a Logger the errors go to the logger of the router
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/default/invalid/", nil))
+	if !strings.Contains(logs.String(), "response does not match its schema") || !strings.Contains(logs.String(), "path=/default/invalid/") {
+		t.Errorf("expected a warning in the logger of the router, got %q", logs.String())
+	}
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gogo-framework/router/problem"
+)
+
+type Schema struct {
+	Ref        string             `json:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Enum       []any              `json:"enum,omitempty"`
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Maximum    *float64           `json:"maximum,omitempty"`
+	MinLength  *int               `json:"minLength,omitempty"`
+	MaxLength  *int               `json:"maxLength,omitempty"`
+	MinItems   *int               `json:"minItems,omitempty"`
+	MaxItems   *int               `json:"maxItems,omitempty"`
+	Pattern    string             `json:"pattern,omitempty"`
+	Example    any                `json:"example,omitempty"`
+
+	patternOnce  sync.Once
+	pattern      *regexp.Regexp
+	patternError error
+}
+
+// ValidationError describes why a value doesn't match a schema. It's declared in the problem package, so
+// problem.ValidationErrorHandler can write them without depending on this package.
+type ValidationError = problem.ValidationError
+
+// Validate validates a value as decoded by encoding/json against the schema.
+func (s *Schema) Validate(in string, field string, value any) []ValidationError {
+	if s == nil {
+		return nil
+	}
+
+	fail := func(format string, args ...any) []ValidationError {
+		return []ValidationError{{In: in, Field: field, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return fail("must not be null")
+	}
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		return fail("must be one of %v", s.Enum)
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fail("must be an object")
+		}
+		var errs []ValidationError
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				errs = append(errs, ValidationError{In: in, Field: joinField(field, name), Message: "is required"})
+			}
+		}
+		for name, property := range s.Properties {
+			if v, ok := object[name]; ok {
+				errs = append(errs, property.Validate(in, joinField(field, name), v)...)
+			}
+		}
+		return errs
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return fail("must be an array")
+		}
+		if s.MinItems != nil && len(array) < *s.MinItems {
+			return fail("must contain at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(array) > *s.MaxItems {
+			return fail("must contain at most %d items", *s.MaxItems)
+		}
+		var errs []ValidationError
+		for i, item := range array {
+			errs = append(errs, s.Items.Validate(in, fmt.Sprintf("%s[%d]", field, i), item)...)
+		}
+		return errs
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		length := utf8.RuneCountInString(str)
+		if s.MinLength != nil && length < *s.MinLength {
+			return fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fail("must be at most %d characters", *s.MaxLength)
+		}
+		re, err := s.compiledPattern()
+		if err != nil {
+			return fail("can't be validated, the pattern %s is invalid", s.Pattern)
+		}
+		if re != nil && !re.MatchString(str) {
+			return fail("must match pattern %s", s.Pattern)
+		}
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok {
+			return fail("must be a %s", s.Type)
+		}
+		if s.Type == "integer" && number != math.Trunc(number) {
+			return fail("must be an integer")
+		}
+		if s.Minimum != nil && number < *s.Minimum {
+			return fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && number > *s.Maximum {
+			return fail("must be at most %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("must be a boolean")
+		}
+	}
+	return nil
+}
+
+// ValidateString validates a raw string value, as found in path, query and header parameters, converting it to
+// the type of the schema first.
+func (s *Schema) ValidateString(in string, field string, raw string) []ValidationError {
+	if s == nil {
+		return nil
+	}
+	var value any = raw
+	switch s.Type {
+	case "integer", "number":
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return []ValidationError{{In: in, Field: field, Message: fmt.Sprintf("must be a %s", s.Type)}}
+		}
+		value = number
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return []ValidationError{{In: in, Field: field, Message: "must be a boolean"}}
+		}
+		value = b
+	}
+	return s.Validate(in, field, value)
+}
+
+// inEnum compares deeply, enum values can be objects and arrays
+func (s *Schema) inEnum(value any) bool {
+	return slices.ContainsFunc(s.Enum, func(option any) bool {
+		return reflect.DeepEqual(option, value)
+	})
+}
+
+// compiledPattern returns the compiled pattern, or nil when the schema has none. Parse returns the error of invalid
+// patterns, schemas built in code fail validation instead of ignoring the pattern.
+func (s *Schema) compiledPattern() (*regexp.Regexp, error) {
+	s.patternOnce.Do(func() {
+		if s.Pattern != "" {
+			s.pattern, s.patternError = regexp.Compile(s.Pattern)
+		}
+	})
+	return s.pattern, s.patternError
+}
+
+func joinField(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,157 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/gogo-framework/router"
+)
+
+var errBodyTooLarge = errors.New("openapi: body too large")
+
+type ValidatorConfig struct {
+	// MaxBodySize is the maximum size of a JSON body that will be validated, defaults to 1MB
+	MaxBodySize int64
+	// ErrorHandler writes the response for invalid requests, by default a 400 with the errors as JSON
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, errs []ValidationError)
+}
+
+// ValidateRequests validates the path, query and header parameters and the JSON body of requests against the
+// operation for the matched route in the document. Routes that aren't in the document are not validated.
+func ValidateRequests(doc *Document, cfg ValidatorConfig) router.Middleware {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = WriteValidationErrors
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			route := router.MatchedRoute(r)
+			if route == nil {
+				next(w, r)
+				return
+			}
+			item, operation := doc.Find(PathFromPattern(route.FullPattern()))
+			if operation == nil {
+				next(w, r)
+				return
+			}
+
+			errs := validateParameters(r, operationParameters(item, operation))
+			bodyErrs, err := validateBody(r, operation.RequestBody, cfg.MaxBodySize)
+			if errors.Is(err, errBodyTooLarge) {
+				router.Error(w, r, http.StatusRequestEntityTooLarge, nil)
+				return
+			}
+			if err != nil {
+				router.Error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			errs = append(errs, bodyErrs...)
+			if len(errs) > 0 {
+				cfg.ErrorHandler(w, r, errs)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// WriteValidationErrors responds with 400 Bad Request and the errors as JSON.
+func WriteValidationErrors(w http.ResponseWriter, r *http.Request, errs []ValidationError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":  "request validation failed",
+		"errors": errs,
+	})
+}
+
+// operationParameters returns the parameters of the path item and the operation in a new slice, the documents are
+// shared by concurrent requests. Operation parameters override path item parameters with the same name and location.
+func operationParameters(item *PathItem, operation *Operation) []*Parameter {
+	inherited := slices.DeleteFunc(slices.Clone(item.Parameters), func(parameter *Parameter) bool {
+		return slices.ContainsFunc(operation.Parameters, func(override *Parameter) bool {
+			return override.Name == parameter.Name && override.In == parameter.In
+		})
+	})
+	return slices.Concat(inherited, operation.Parameters)
+}
+
+func validateParameters(r *http.Request, parameters []*Parameter) []ValidationError {
+	var errs []ValidationError
+	for _, parameter := range parameters {
+		var value string
+		var present bool
+		switch parameter.In {
+		case "path":
+			value = r.PathValue(parameter.Name)
+			present = value != ""
+		case "query":
+			values, ok := router.Query(r)[parameter.Name]
+			if ok && len(values) > 0 {
+				value, present = values[0], true
+			}
+		case "header":
+			values := r.Header.Values(parameter.Name)
+			if len(values) > 0 {
+				value, present = values[0], true
+			}
+		default:
+			continue
+		}
+
+		if !present {
+			if parameter.Required {
+				errs = append(errs, ValidationError{In: parameter.In, Field: parameter.Name, Message: "is required"})
+			}
+			continue
+		}
+		errs = append(errs, parameter.Schema.ValidateString(parameter.In, parameter.Name, value)...)
+	}
+	return errs
+}
+
+func validateBody(r *http.Request, requestBody *RequestBody, maxBodySize int64) ([]ValidationError, error) {
+	if requestBody == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBodySize {
+		return nil, errBodyTooLarge
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if requestBody.Required {
+			return []ValidationError{{In: "body", Message: "is required"}}, nil
+		}
+		return nil, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	content, ok := requestBody.Content[mediaType]
+	if !ok {
+		return []ValidationError{{In: "body", Message: "unsupported content type " + mediaType}}, nil
+	}
+	if mediaType != "application/json" || content.Schema == nil {
+		return nil, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []ValidationError{{In: "body", Message: "invalid JSON"}}, nil
+	}
+	return content.Schema.Validate("body", "", value), nil
+}
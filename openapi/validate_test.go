@@ -0,0 +1,183 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/openapi"
+)
+
+const usersSpec = `{
+	"openapi": "3.0.3",
+	"info": {"title": "Users", "version": "1.0.0"},
+	"paths": {
+		"/users": {
+			"get": {
+				"parameters": [
+					{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}}
+				]
+			},
+			"post": {
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}
+				}
+			}
+		},
+		"/users/{id}": {
+			"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+			"get": {}
+		},
+		"/orders/{id}": {
+			"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+			"get": {
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string", "pattern": "^[a-z]+$"}}]
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"User": {
+				"type": "object",
+				"required": ["name", "email"],
+				"properties": {
+					"name": {"type": "string", "minLength": 1},
+					"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+					"role": {"type": "string", "enum": ["admin", "member"]},
+					"theme": {"type": "object", "enum": [{"mode": "dark"}, {"mode": "light"}]}
+				}
+			}
+		}
+	}
+}`
+
+func TestValidateRequests(t *testing.T) {
+	doc, err := openapi.Parse(strings.NewReader(usersSpec))
+	if err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(openapi.ValidateRequests(doc, openapi.ValidatorConfig{}))
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}
+	r.GET("/users", ok)
+	r.POST("/users", ok)
+	r.GET("/users/{id}", ok)
+	r.GET("/orders/{id}", ok)
+	r.GET("/undocumented", ok)
+
+	// Define test cases for each route
+	tests := []struct {
+		method     string
+		path       string
+		body       string
+		statusCode int
+		fields     []string
+	}{
+		{http.MethodGet, "/users/?limit=10", "", http.StatusOK, nil},
+		{http.MethodGet, "/users/?limit=500", "", http.StatusBadRequest, []string{"limit"}},
+		{http.MethodGet, "/users/?limit=abc", "", http.StatusBadRequest, []string{"limit"}},
+		{http.MethodGet, "/users/5/", "", http.StatusOK, nil},
+		{http.MethodGet, "/users/five/", "", http.StatusBadRequest, []string{"id"}},
+		{http.MethodPost, "/users/", `{"name":"Jane","email":"jane@example.com","role":"admin"}`, http.StatusOK, nil},
+		{http.MethodPost, "/users/", `{"name":"","email":"jane","role":"owner"}`, http.StatusBadRequest, []string{"name", "email", "role"}},
+		{http.MethodPost, "/users/", `{"name":"Jane"}`, http.StatusBadRequest, []string{"email"}},
+		{http.MethodPost, "/users/", `{"name":"Jane","email":"jane@example.com","theme":{"mode":"dark"}}`, http.StatusOK, nil},
+		{http.MethodPost, "/users/", `{"name":"Jane","email":"jane@example.com","theme":{"mode":"blue"}}`, http.StatusBadRequest, []string{"theme"}},
+		{http.MethodGet, "/orders/abc/", "", http.StatusOK, nil},
+		{http.MethodGet, "/orders/5/", "", http.StatusBadRequest, []string{"id"}},
+		{http.MethodPost, "/users/", ``, http.StatusBadRequest, []string{""}},
+		{http.MethodGet, "/undocumented/?anything=goes", "", http.StatusOK, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v (%s)", status, tt.statusCode, rr.Body.String())
+			}
+			if tt.statusCode != http.StatusBadRequest {
+				return
+			}
+
+			var response struct {
+				Errors []openapi.ValidationError `json:"errors"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			fields := map[string]bool{}
+			for _, e := range response.Errors {
+				fields[e.Field] = true
+			}
+			for _, field := range tt.fields {
+				if !fields[field] {
+					t.Errorf("expected validation error for %q, got %+v", field, response.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestParseInvalidPattern(t *testing.T) {
+	_, err := openapi.Parse(strings.NewReader(`{
+		"openapi": "3.0.3",
+		"paths": {
+			"/users/{id}": {
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string", "pattern": "[a-z"}}],
+				"get": {}
+			}
+		}
+	}`))
+	if err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("Expected an invalid pattern error, got %v", err)
+	}
+}
+
+func TestValidateRequestsConcurrently(t *testing.T) {
+	doc, err := openapi.Parse(strings.NewReader(usersSpec))
+	if err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+	// Documents built in code can have spare capacity in their slices
+	item := doc.Paths["/orders/{id}"]
+	item.Parameters = slices.Grow(item.Parameters, 4)
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(openapi.ValidateRequests(doc, openapi.ValidatorConfig{}))
+	r.GET("/orders/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	// The document is shared by the requests, validating them must not change it
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders/abc/", nil))
+				if rr.Code != http.StatusOK {
+					t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,197 @@
+// Package ops implements long-running operations: a handler starts an operation in the background and responds with
+// 202 Accepted, and clients poll the status of the operation until it's done. The operations are kept in a Store, so
+// their status can survive restarts and be read from every instance.
+package ops
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/render"
+)
+
+// ErrNotFound is returned by a Store when there is no operation with the ID.
+var ErrNotFound = errors.New("ops: operation not found")
+
+// StatusRoute is the name of the status route, see Router.URL.
+const StatusRoute = "ops.status"
+
+type Status string
+
+const (
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+)
+
+// Operation is the status of a long-running operation, which is returned by the status route. Result is the result
+// of a task that succeeded and Error the message of a task that failed.
+type Operation struct {
+	ID      string    `json:"id"`
+	Status  Status    `json:"status"`
+	Result  any       `json:"result,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Store keeps the operations, e.g. in a database or Redis.
+type Store interface {
+	// Save creates or replaces the operation, it can be removed after the TTL
+	Save(ctx context.Context, op *Operation, ttl time.Duration) error
+	// Get returns the operation with the ID, or ErrNotFound
+	Get(ctx context.Context, id string) (*Operation, error)
+}
+
+// Task is the work of an operation. The result is stored when it succeeds, and the message of the error when it
+// fails, both are shown to clients.
+type Task func(ctx context.Context) (result any, err error)
+
+type Config struct {
+	// Store is where the operations are kept, defaults to a new MemoryStore
+	Store Store
+	// Prefix is the path of the status route, which is <prefix>/{id}. Defaults to "/operations"
+	Prefix string
+	// TTL is how long operations are kept after they changed, defaults to 24 hours
+	TTL time.Duration
+	// RetryAfter is the Retry-After header of the status of running operations, defaults to 1 second
+	RetryAfter time.Duration
+}
+
+// Operations starts operations and serves their status.
+type Operations struct {
+	config Config
+	router *router.Router
+}
+
+type operationsContextKey struct{}
+
+// Register registers the status route of the operations on the router, and lets its handlers start operations with
+// Start. Unknown operations get a 404.
+func Register(r *router.Router, config Config) *Operations {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.Prefix == "" {
+		config.Prefix = "/operations"
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+
+	o := &Operations{config: config, router: r}
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			next(w, req.WithContext(context.WithValue(req.Context(), operationsContextKey{}, o)))
+		}
+	})
+	r.GET(config.Prefix+"/{id}", o.status).Name(StatusRoute)
+	return o
+}
+
+// Start starts the task as an operation and returns its ID. The task runs with router.Async after the handler has
+// returned, so it isn't canceled with the request and is drained on shutdown. A task that panics fails the operation,
+// and the panic is reported by the router. Start panics when Register wasn't called for the router.
+//
+//	r.POST("/exports", func(w http.ResponseWriter, r *http.Request) {
+//		id, err := ops.Start(r, func(ctx context.Context) (any, error) {
+//			return exports.Create(ctx)
+//		})
+//		if err != nil {
+//			router.Error(w, r, http.StatusInternalServerError, err)
+//			return
+//		}
+//		ops.Accepted(w, r, id)
+//	})
+func Start(r *http.Request, task Task) (string, error) {
+	return fromRequest(r).Start(r, task)
+}
+
+// Start starts the task as an operation, like the Start function.
+func (o *Operations) Start(r *http.Request, task Task) (string, error) {
+	now := time.Now()
+	op := &Operation{ID: newID(), Status: Running, Created: now, Updated: now}
+	if err := o.config.Store.Save(r.Context(), op, o.config.TTL); err != nil {
+		return "", err
+	}
+
+	router.Async(r, func(ctx context.Context) error {
+		finished := false
+		defer func() {
+			if !finished {
+				// The panic goes on to the router, the client only learns that the operation failed
+				o.finish(ctx, op, nil, errors.New("internal error"))
+			}
+		}()
+		result, err := task(ctx)
+		finished = true
+		return errors.Join(err, o.finish(ctx, op, result, err))
+	})
+	return op.ID, nil
+}
+
+func (o *Operations) finish(ctx context.Context, op *Operation, result any, err error) error {
+	op.Status, op.Result, op.Updated = Succeeded, result, time.Now()
+	if err != nil {
+		op.Status, op.Error = Failed, err.Error()
+	}
+	return o.config.Store.Save(ctx, op, o.config.TTL)
+}
+
+// Accepted responds with 202 Accepted, the status of the operation as body and its URL in the Location header.
+func Accepted(w http.ResponseWriter, r *http.Request, id string) {
+	o := fromRequest(r)
+	op, err := o.config.Store.Get(r.Context(), id)
+	if err != nil {
+		router.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if location, err := o.router.URL(StatusRoute, map[string]string{"id": id}); err == nil {
+		w.Header().Set("Location", location)
+	}
+	w.Header().Set("Retry-After", o.retryAfter())
+	render.JSON(w, http.StatusAccepted, op)
+}
+
+func (o *Operations) status(w http.ResponseWriter, r *http.Request) {
+	op, err := o.config.Store.Get(r.Context(), r.PathValue("id"))
+	if errors.Is(err, ErrNotFound) {
+		router.Error(w, r, http.StatusNotFound, nil)
+		return
+	}
+	if err != nil {
+		router.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if op.Status == Running {
+		w.Header().Set("Retry-After", o.retryAfter())
+	}
+	render.JSON(w, http.StatusOK, op)
+}
+
+func (o *Operations) retryAfter() string {
+	return strconv.Itoa(max(int(o.config.RetryAfter.Seconds()), 1))
+}
+
+func fromRequest(r *http.Request) *Operations {
+	o, _ := r.Context().Value(operationsContextKey{}).(*Operations)
+	if o == nil {
+		panic("ops: Register wasn't called for the router of the request")
+	}
+	return o
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
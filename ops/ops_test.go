@@ -0,0 +1,139 @@
+package ops_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/ops"
+)
+
+func TestOperations(t *testing.T) {
+	release := make(chan struct{})
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithEnv(router.EnvProduction),
+		router.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	ops.Register(r, ops.Config{})
+	start := func(task ops.Task) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id, err := ops.Start(r, task)
+			if err != nil {
+				router.Error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			ops.Accepted(w, r, id)
+		}
+	}
+	r.POST("/exports", start(func(ctx context.Context) (any, error) {
+		<-release
+		return map[string]string{"url": "/exports/1.csv"}, nil
+	}))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/exports/", nil))
+	accepted := decode(t, rr)
+	location := rr.Header().Get("Location")
+	if rr.Code != http.StatusAccepted || accepted.Status != ops.Running || location != "/operations/"+accepted.ID+"/" {
+		t.Fatalf("Expected 202 with a running operation at its location, got %d %+v at %q", rr.Code, accepted, location)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, location, nil))
+	if op := decode(t, rr); op.Status != ops.Running || rr.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected the operation to be running with a Retry-After, got %+v", op)
+	}
+
+	close(release)
+	if err := r.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, location, nil))
+	if op := decode(t, rr); op.Status != ops.Succeeded || op.Result.(map[string]any)["url"] != "/exports/1.csv" {
+		t.Errorf("Expected the operation to succeed with its result, got %+v", op)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/operations/unknown/", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for an unknown operation, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestOperationsFailed(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithEnv(router.EnvProduction),
+		router.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	store := ops.NewMemoryStore()
+	ops.Register(r, ops.Config{Store: store})
+
+	// Define test cases
+	tests := []struct {
+		name  string
+		task  ops.Task
+		error string
+	}{
+		{"error", func(ctx context.Context) (any, error) { return nil, errors.New("no data to export") }, "no data to export"},
+		{"panic", func(ctx context.Context) (any, error) { panic("database password is hunter2") }, "internal error"},
+	}
+
+	ids := map[string]string{}
+	for _, tc := range tests {
+		r.POST("/"+tc.name, func(w http.ResponseWriter, r *http.Request) {
+			ids[tc.name], _ = ops.Start(r, tc.task)
+		})
+	}
+
+	for _, tc := range tests {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/"+tc.name+"/", nil))
+		if err := r.Drain(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		op, err := store.Get(context.Background(), ids[tc.name])
+		if err != nil || op.Status != ops.Failed || op.Error != tc.error {
+			t.Errorf("%s: expected the operation to fail with %q, got %+v, %v", tc.name, tc.error, op, err)
+		}
+	}
+}
+
+func decode(t *testing.T, rr *httptest.ResponseRecorder) ops.Operation {
+	t.Helper()
+	var op ops.Operation
+	if err := json.Unmarshal(rr.Body.Bytes(), &op); err != nil {
+		t.Fatalf("Expected an operation, got %d %q", rr.Code, rr.Body.String())
+	}
+	return op
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	store := ops.NewMemoryStore()
+	ctx := context.Background()
+	if err := store.Save(ctx, &ops.Operation{ID: "short", Status: ops.Running}, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ctx, &ops.Operation{ID: "long", Status: ops.Running}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := store.Get(ctx, "short"); !errors.Is(err, ops.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an expired operation, got %v", err)
+	}
+	// Saving again sweeps the expired operation, the other one is kept
+	if err := store.Save(ctx, &ops.Operation{ID: "next", Status: ops.Running}, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if op, err := store.Get(ctx, "long"); err != nil || op.ID != "long" {
+		t.Errorf("Expected the operation that didn't expire, got %+v, %v", op, err)
+	}
+}
@@ -0,0 +1,53 @@
+package ops
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryOperation struct {
+	op        Operation
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-memory Store, it is only suitable for a single instance and loses the operations on restart.
+type MemoryStore struct {
+	mutex      sync.Mutex
+	operations map[string]memoryOperation
+	swept      time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{operations: make(map[string]memoryOperation)}
+}
+
+func (s *MemoryStore) Save(ctx context.Context, op *Operation, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	// Expired operations are removed once per ttl, not on every save
+	if now.Sub(s.swept) > ttl {
+		for id, stored := range s.operations {
+			if now.After(stored.expiresAt) {
+				delete(s.operations, id)
+			}
+		}
+		s.swept = now
+	}
+	s.operations[op.ID] = memoryOperation{op: *op, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Operation, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, ok := s.operations[id]
+	if !ok || time.Now().After(stored.expiresAt) {
+		return nil, ErrNotFound
+	}
+	op := stored.op
+	return &op, nil
+}
@@ -0,0 +1,87 @@
+package router
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// Option configures a router when it's created with NewRouter.
+type Option func(r *Router)
+
+// WithMux sets the ServeMux the routes are registered on, a new one is created by default.
+func WithMux(mux *http.ServeMux) Option {
+	return func(r *Router) {
+		r.mux = mux
+	}
+}
+
+func WithConfig(config RouterConfig) Option {
+	return func(r *Router) {
+		r.config = config
+	}
+}
+
+// WithPanicReporter sets a function that is called with every panic the router recovers, including panics after the
+// response was started, e.g. to send them to an error tracker. See RouterConfig.Recover.
+func WithPanicReporter(reporter func(r *http.Request, err *PanicError)) Option {
+	return func(r *Router) {
+		r.panicReporter = reporter
+	}
+}
+
+// WithLogger sets the logger the router logs warnings and errors to, defaults to slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Router) {
+		r.logger = logger
+	}
+}
+
+func WithErrorHandler(handler ErrorHandlerFunc) Option {
+	return func(r *Router) {
+		r.errorHandler = handler
+	}
+}
+
+func WithNotFound(handler http.HandlerFunc) Option {
+	return func(r *Router) {
+		r.notFoundHandler = handler
+	}
+}
+
+func WithMethodNotAllowed(handler http.HandlerFunc) Option {
+	return func(r *Router) {
+		r.methodNotAllowedHandler = handler
+	}
+}
+
+// WithEnv applies the defaults for the environment, see NewRouterWithEnv. Options after it can change the defaults.
+func WithEnv(env string) Option {
+	return func(r *Router) {
+		r.config.Env = env
+		switch env {
+		case EnvDevelopment:
+			r.devErrors = true
+			r.admin.logLevel.Set(slog.LevelDebug)
+		case EnvProduction:
+			r.config.Recover = true
+			r.admin.logLevel.Set(slog.LevelWarn)
+		}
+	}
+}
+
+// Logger returns the logger of the router.
+func (r *Router) Logger() *slog.Logger {
+	if r.logger == nil {
+		return slog.Default()
+	}
+	return r.logger
+}
+
+// Logger returns the logger of the router that handles the request, for code that only has the request, like
+// middlewares. Requests that aren't handled by a router get slog.Default().
+func Logger(req *http.Request) *slog.Logger {
+	if r := routerFromRequest(req); r != nil {
+		return r.Logger()
+	}
+	return slog.Default()
+}
@@ -0,0 +1,102 @@
+package router_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestOptions(t *testing.T) {
+	var logs bytes.Buffer
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	// Create a new router instance
+	r := router.NewRouter(
+		router.WithMux(mux),
+		router.WithConfig(router.RouterConfig{DisableAutoAddTrailingSlash: true, DisableAutoAddExactMatchWildcard: true}),
+		router.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		router.WithNotFound(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("custom not found"))
+		}),
+		router.WithMethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte("custom method not allowed"))
+		}),
+	)
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("users"))
+	})
+
+	// Define test cases
+	tests := []struct {
+		method     string
+		path       string
+		statusCode int
+		body       string
+	}{
+		{http.MethodGet, "/health", http.StatusOK, "ok"},
+		{http.MethodGet, "/users", http.StatusOK, "users"},
+		{http.MethodGet, "/missing", http.StatusNotFound, "custom not found"},
+		{http.MethodPost, "/users", http.StatusMethodNotAllowed, "custom method not allowed"},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tc.statusCode || rr.Body.String() != tc.body {
+			t.Errorf("%s %s: expected %d %q, got %d %q", tc.method, tc.path, tc.statusCode, tc.body, rr.Code, rr.Body.String())
+		}
+	}
+
+	if logs.Len() != 0 {
+		t.Errorf("Expected no warnings with a mux, got %q", logs.String())
+	}
+}
+
+func TestConfigAfterSetup(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+
+	// Define test cases, the first request set up the routes with the configuration
+	tests := map[string]func(){
+		"config": func() { r.SetConfig(router.RouterConfig{}) },
+		"mux":    func() { r.SetMux(http.NewServeMux()) },
+	}
+
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected a panic")
+				}
+			}()
+			change()
+		})
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var logs bytes.Buffer
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+
+	if !strings.Contains(logs.String(), "ServeMux is nil") {
+		t.Errorf("Expected the warning to be logged to the logger, got %q", logs.String())
+	}
+}
@@ -0,0 +1,93 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"slices"
+)
+
+// RequestIDHeader is the header with the ID of a request, which is passed on to downstream services so their logs
+// can be correlated.
+const RequestIDHeader = "X-Request-Id"
+
+// propagatedHeaders are the headers of the incoming request that are passed on by OutboundHeaders: the request ID, the
+// W3C trace context and the locale
+var propagatedHeaders = []string{RequestIDHeader, "Traceparent", "Tracestate", "Baggage", "Accept-Language"}
+
+// OutboundHeaders returns the headers for HTTP calls to downstream services made while handling the request of the
+// context: the request ID, the W3C trace context and the locale of the request, and the headers set with
+// SetOutboundHeader, like the tenant set by middleware.Tenant. Requests without an ID get one, which is the same for
+// every call of the request. Contexts of requests outside of a matched route return empty headers.
+//
+//	req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, "http://billing.internal/invoices", nil)
+//	maps.Copy(req.Header, router.OutboundHeaders(r.Context()))
+func OutboundHeaders(ctx context.Context) http.Header {
+	header := http.Header{}
+	chain := requestContexts(ctx)
+	if len(chain) == 0 {
+		return header
+	}
+
+	// Mounted routers have their own request context, the outermost one has the headers of the request
+	outer := chain[0]
+	for _, name := range propagatedHeaders {
+		if values := outer.header.Values(name); len(values) > 0 {
+			header[name] = slices.Clone(values)
+		}
+	}
+	if header.Get(RequestIDHeader) == "" {
+		outer.mutex.Lock()
+		if outer.outbound.Get(RequestIDHeader) == "" {
+			outer.setOutbound(RequestIDHeader, newRequestID())
+		}
+		outer.mutex.Unlock()
+	}
+	for _, c := range chain {
+		c.mutex.Lock()
+		for name, values := range c.outbound {
+			header[name] = slices.Clone(values)
+		}
+		c.mutex.Unlock()
+	}
+	return header
+}
+
+// SetOutboundHeader sets a header that OutboundHeaders returns for the request, it replaces the headers of the request
+// with the same name. Middlewares use it to pass on what they resolved, e.g. the negotiated locale as Accept-Language.
+// It does nothing outside of a matched route.
+func SetOutboundHeader(r *http.Request, name string, value string) {
+	c, _ := r.Context().Value(requestContextKey).(*requestContext)
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setOutbound(name, value)
+}
+
+func (c *requestContext) setOutbound(name string, value string) {
+	if c.outbound == nil {
+		c.outbound = http.Header{}
+	}
+	c.outbound.Set(name, value)
+}
+
+// requestContexts returns the request contexts of the context, the outermost first
+func requestContexts(ctx context.Context) []*requestContext {
+	var chain []*requestContext
+	c, _ := ctx.Value(requestContextKey).(*requestContext)
+	for c != nil {
+		chain = append(chain, c)
+		c, _ = c.Context.Value(requestContextKey).(*requestContext)
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,95 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestOutboundHeaders(t *testing.T) {
+	var headers []http.Header
+	outbound := func(w http.ResponseWriter, r *http.Request) {
+		// Every downstream call of the request gets the same headers
+		headers = append(headers, router.OutboundHeaders(r.Context()), router.OutboundHeaders(r.Context()))
+	}
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			router.SetOutboundHeader(r, "Accept-Language", "de-CH")
+			next(w, r)
+		}
+	})
+	r.GET("/orders", outbound)
+	mounted := router.NewRouter(router.WithMux(http.NewServeMux()))
+	mounted.GET("/invoices", func(w http.ResponseWriter, r *http.Request) {
+		router.SetOutboundHeader(r, "X-Billing-Account", "42")
+		outbound(w, r)
+	})
+	r.Mount("/billing", mounted)
+
+	// Define test cases
+	tests := []struct {
+		path   string
+		header http.Header
+		want   map[string]string
+	}{
+		{
+			path: "/orders/",
+			header: http.Header{
+				"X-Request-Id":    {"req-1"},
+				"Traceparent":     {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+				"Tracestate":      {"vendor=1"},
+				"Accept-Language": {"en"},
+				"Cookie":          {"session=secret"},
+			},
+			want: map[string]string{
+				"X-Request-Id":    "req-1",
+				"Traceparent":     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"Tracestate":      "vendor=1",
+				"Accept-Language": "de-CH",
+				"Cookie":          "",
+			},
+		},
+		{
+			path: "/orders/",
+			want: map[string]string{"Traceparent": "", "Accept-Language": "de-CH"},
+		},
+		{
+			path:   "/billing/invoices/",
+			header: http.Header{"X-Request-Id": {"req-2"}},
+			want:   map[string]string{"X-Request-Id": "req-2", "Accept-Language": "de-CH", "X-Billing-Account": "42"},
+		},
+	}
+
+	for _, tc := range tests {
+		headers = nil
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		for name, values := range tc.header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if len(headers) != 2 {
+			t.Fatalf("%s: expected the handler to be called, got %d", tc.path, w.Code)
+		}
+		for name, value := range tc.want {
+			if got := headers[0].Get(name); got != value {
+				t.Errorf("%s: expected %s %q, got %q", tc.path, name, value, got)
+			}
+		}
+		id := headers[0].Get(router.RequestIDHeader)
+		if id == "" || headers[1].Get(router.RequestIDHeader) != id {
+			t.Errorf("%s: expected the same request ID for every call, got %q and %q", tc.path, id, headers[1].Get(router.RequestIDHeader))
+		}
+	}
+
+	if header := router.OutboundHeaders(context.Background()); len(header) != 0 {
+		t.Errorf("Expected no headers outside of a request, got %v", header)
+	}
+}
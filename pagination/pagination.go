@@ -0,0 +1,225 @@
+// Package pagination parses pagination query parameters and builds paginated responses with Link headers.
+package pagination
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gogo-framework/router"
+)
+
+const (
+	DefaultPerPage    = 20
+	DefaultMaxPerPage = 100
+)
+
+type Config struct {
+	// DefaultPerPage is used when the request doesn't have a per_page parameter, defaults to DefaultPerPage
+	DefaultPerPage int
+	// MaxPerPage is the largest allowed per_page value, defaults to DefaultMaxPerPage
+	MaxPerPage int
+	// RouteName is the named route used to build the links, by default the path of the request is used
+	RouteName string
+}
+
+// Error is returned by Parse for invalid parameters.
+type Error struct {
+	Param   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("pagination: %s %s", e.Param, e.Message)
+}
+
+// Params are the pagination parameters of a request. Either Page or Cursor is used, depending on the endpoint.
+type Params struct {
+	Page    int
+	PerPage int
+	Cursor  string
+
+	config Config
+}
+
+// Offset returns the amount of items to skip for page based pagination.
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Limit returns the amount of items per page.
+func (p Params) Limit() int {
+	return p.PerPage
+}
+
+// Parse reads the page, per_page and cursor query parameters of the request. Pages beyond the last page that can be
+// offset with MaxPerPage are clamped to it.
+func Parse(r *http.Request, config Config) (Params, error) {
+	if config.DefaultPerPage <= 0 {
+		config.DefaultPerPage = DefaultPerPage
+	}
+	if config.MaxPerPage <= 0 {
+		config.MaxPerPage = DefaultMaxPerPage
+	}
+	// Larger pages are clamped, so Offset can't overflow
+	maxPage := math.MaxInt / config.MaxPerPage
+
+	query := router.Query(r)
+	params := Params{Page: 1, PerPage: config.DefaultPerPage, Cursor: query.Get("cursor"), config: config}
+
+	if page := query.Get("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if errors.Is(err, strconv.ErrRange) && !strings.HasPrefix(page, "-") {
+			n, err = maxPage, nil
+		}
+		if err != nil || n < 1 {
+			return params, &Error{Param: "page", Message: "must be a positive integer"}
+		}
+		params.Page = min(n, maxPage)
+	}
+	if perPage := query.Get("per_page"); perPage != "" {
+		n, err := strconv.Atoi(perPage)
+		if err != nil || n < 1 || n > config.MaxPerPage {
+			return params, &Error{Param: "per_page", Message: fmt.Sprintf("must be between 1 and %d", config.MaxPerPage)}
+		}
+		params.PerPage = n
+	}
+	return params, nil
+}
+
+type Meta struct {
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page"`
+	Total      *int   `json:"total,omitempty"`
+	TotalPages *int   `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Envelope is the standard JSON body of a paginated response.
+type Envelope struct {
+	Data  any               `json:"data"`
+	Meta  Meta              `json:"meta"`
+	Links map[string]string `json:"links"`
+}
+
+// Page describes the position of a response within the paginated collection.
+type Page struct {
+	request *http.Request
+	params  Params
+	meta    Meta
+}
+
+// New creates a page based page, total is the total amount of items or -1 when unknown.
+func New(r *http.Request, params Params, total int) *Page {
+	meta := Meta{Page: params.Page, PerPage: params.PerPage}
+	if total >= 0 {
+		totalPages := (total + params.PerPage - 1) / params.PerPage
+		meta.Total = &total
+		meta.TotalPages = &totalPages
+	}
+	return &Page{request: r, params: params, meta: meta}
+}
+
+// NewCursor creates a cursor based page, an empty cursor means there is no next or previous page.
+func NewCursor(r *http.Request, params Params, nextCursor string, prevCursor string) *Page {
+	return &Page{
+		request: r,
+		params:  params,
+		meta:    Meta{PerPage: params.PerPage, NextCursor: nextCursor, PrevCursor: prevCursor},
+	}
+}
+
+// Links returns the first, prev, next and last links that apply to the page.
+func (p *Page) Links() map[string]string {
+	links := map[string]string{"self": p.url(nil)}
+
+	if p.meta.Page == 0 {
+		if p.meta.PrevCursor != "" {
+			links["prev"] = p.url(map[string]string{"cursor": p.meta.PrevCursor})
+		}
+		if p.meta.NextCursor != "" {
+			links["next"] = p.url(map[string]string{"cursor": p.meta.NextCursor})
+		}
+		return links
+	}
+
+	links["first"] = p.url(map[string]string{"page": "1"})
+	if p.meta.Page > 1 {
+		links["prev"] = p.url(map[string]string{"page": strconv.Itoa(p.meta.Page - 1)})
+	}
+	if p.meta.TotalPages == nil || p.meta.Page < *p.meta.TotalPages {
+		links["next"] = p.url(map[string]string{"page": strconv.Itoa(p.meta.Page + 1)})
+	}
+	if p.meta.TotalPages != nil && *p.meta.TotalPages > 0 {
+		links["last"] = p.url(map[string]string{"page": strconv.Itoa(*p.meta.TotalPages)})
+	}
+	return links
+}
+
+// SetLinkHeader sets the Link header as described in RFC 5988.
+func (p *Page) SetLinkHeader(w http.ResponseWriter) {
+	links := p.Links()
+	var values []string
+	for _, rel := range []string{"first", "prev", "next", "last"} {
+		if link, ok := links[rel]; ok {
+			values = append(values, fmt.Sprintf("<%s>; rel=%q", link, rel))
+		}
+	}
+	if len(values) > 0 {
+		w.Header().Set("Link", strings.Join(values, ", "))
+	}
+}
+
+// Write sets the Link header and writes the data wrapped in an Envelope as JSON.
+func (p *Page) Write(w http.ResponseWriter, data any) error {
+	p.SetLinkHeader(w)
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(Envelope{Data: data, Meta: p.meta, Links: p.Links()})
+}
+
+func (p *Page) url(overrides map[string]string) string {
+	path := p.request.URL.Path
+	if p.params.config.RouteName != "" {
+		if generated, err := router.URLFor(p.request, p.params.config.RouteName, pathValues(p.request)); err == nil {
+			path = generated
+		}
+	}
+
+	query := url.Values{}
+	for key, values := range p.request.URL.Query() {
+		query[key] = values
+	}
+	if p.params.PerPage != p.params.config.DefaultPerPage {
+		query.Set("per_page", strconv.Itoa(p.params.PerPage))
+	}
+	// Page and cursor based pagination don't mix, so the current position is replaced
+	if len(overrides) > 0 {
+		query.Del("page")
+		query.Del("cursor")
+	}
+	for key, value := range overrides {
+		query.Set(key, value)
+	}
+
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// pathValues collects the path parameters of the matched route, so links to the same named route can be generated.
+func pathValues(r *http.Request) map[string]string {
+	values := make(map[string]string)
+	if route := router.MatchedRoute(r); route != nil {
+		for _, name := range route.ParamNames() {
+			values[name] = r.PathValue(name)
+		}
+	}
+	return values
+}
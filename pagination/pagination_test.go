@@ -0,0 +1,148 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/pagination"
+)
+
+func TestPagination(t *testing.T) {
+	items := make([]int, 45)
+	for i := range items {
+		items[i] = i + 1
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/teams/{team}/members", func(w http.ResponseWriter, r *http.Request) {
+		params, err := pagination.Parse(r, pagination.Config{DefaultPerPage: 20, MaxPerPage: 50, RouteName: "members"})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		end := min(params.Offset()+params.Limit(), len(items))
+		start := min(params.Offset(), end)
+		pagination.New(r, params, len(items)).Write(w, items[start:end])
+	}).Name("members")
+
+	// Define test cases for different pages
+	tests := []struct {
+		path       string
+		statusCode int
+		count      int
+		links      map[string]string
+		linkHeader string
+	}{
+		{"/teams/core/members/", http.StatusOK, 20, map[string]string{
+			"self":  "/teams/core/members/",
+			"first": "/teams/core/members/?page=1",
+			"next":  "/teams/core/members/?page=2",
+			"last":  "/teams/core/members/?page=3",
+		}, `</teams/core/members/?page=1>; rel="first", </teams/core/members/?page=2>; rel="next", </teams/core/members/?page=3>; rel="last"`},
+		{"/teams/core/members/?page=3&sort=name", http.StatusOK, 5, map[string]string{
+			"self":  "/teams/core/members/?page=3&sort=name",
+			"first": "/teams/core/members/?page=1&sort=name",
+			"prev":  "/teams/core/members/?page=2&sort=name",
+			"last":  "/teams/core/members/?page=3&sort=name",
+		}, `</teams/core/members/?page=1&sort=name>; rel="first", </teams/core/members/?page=2&sort=name>; rel="prev", </teams/core/members/?page=3&sort=name>; rel="last"`},
+		{"/teams/core/members/?page=2&per_page=40", http.StatusOK, 5, map[string]string{
+			"self":  "/teams/core/members/?page=2&per_page=40",
+			"first": "/teams/core/members/?page=1&per_page=40",
+			"prev":  "/teams/core/members/?page=1&per_page=40",
+			"last":  "/teams/core/members/?page=2&per_page=40",
+		}, `</teams/core/members/?page=1&per_page=40>; rel="first", </teams/core/members/?page=1&per_page=40>; rel="prev", </teams/core/members/?page=2&per_page=40>; rel="last"`},
+		{"/teams/core/members/?per_page=500", http.StatusBadRequest, 0, nil, ""},
+		{"/teams/core/members/?page=0", http.StatusBadRequest, 0, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.statusCode)
+			}
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+
+			var envelope struct {
+				Data  []int             `json:"data"`
+				Meta  pagination.Meta   `json:"meta"`
+				Links map[string]string `json:"links"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&envelope); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(envelope.Data) != tt.count {
+				t.Errorf("unexpected amount of items: got %v want %v", len(envelope.Data), tt.count)
+			}
+			if !reflect.DeepEqual(envelope.Links, tt.links) {
+				t.Errorf("unexpected links:\ngot  %v\nwant %v", envelope.Links, tt.links)
+			}
+			if header := rr.Header().Get("Link"); header != tt.linkHeader {
+				t.Errorf("unexpected Link header:\ngot  %v\nwant %v", header, tt.linkHeader)
+			}
+		})
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/events?cursor=abc", nil)
+	params, err := pagination.Parse(req, pagination.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.Cursor != "abc" {
+		t.Errorf("unexpected cursor: %v", params.Cursor)
+	}
+
+	links := pagination.NewCursor(req, params, "def", "").Links()
+	expected := map[string]string{"self": "/events?cursor=abc", "next": "/events?cursor=def"}
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("unexpected links: got %v want %v", links, expected)
+	}
+}
+
+func TestParseLargePage(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		page    string
+		valid   bool
+		clamped bool
+	}{
+		{strconv.Itoa(math.MaxInt), true, true},
+		{"99999999999999999999", true, true},
+		{"1000", true, false},
+		{"-99999999999999999999", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/events?per_page=50&page="+tt.page, nil)
+			params, err := pagination.Parse(req, pagination.Config{MaxPerPage: 50})
+			if (err == nil) != tt.valid {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.valid {
+				return
+			}
+			if params.Offset() < 0 {
+				t.Errorf("expected a positive offset, got %d", params.Offset())
+			}
+			if clamped := params.Page == math.MaxInt/50; clamped != tt.clamped {
+				t.Errorf("unexpected page %d", params.Page)
+			}
+		})
+	}
+}
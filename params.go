@@ -0,0 +1,136 @@
+package router
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// ParamDecoder converts the value of a path or query parameter to a value of the type it's registered for.
+type ParamDecoder func(value string) (any, error)
+
+var (
+	paramDecodersMutex sync.RWMutex
+	paramDecoders      = make(map[reflect.Type]ParamDecoder)
+)
+
+// ParamError is returned by Param and QueryParam when a value can't be converted to the requested type.
+type ParamError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("router: invalid value %q for parameter %s: %v", e.Value, e.Name, e.Err)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// RegisterParamDecoder registers a decoder for a custom type, which is used by Param, QueryParam and the bind package.
+// This allows domain types like IDs to be read from paths and queries directly. The decoder has to return a value of
+// type t, it panics when a decoder for the type is registered twice.
+//
+//	router.RegisterParamDecoder(reflect.TypeOf(UserID{}), func(value string) (any, error) {
+//		return ParseUserID(value)
+//	})
+func RegisterParamDecoder(t reflect.Type, decoder ParamDecoder) {
+	paramDecodersMutex.Lock()
+	defer paramDecodersMutex.Unlock()
+	if _, ok := paramDecoders[t]; ok {
+		panic(fmt.Sprintf("router: param decoder for %s is registered twice", t))
+	}
+	paramDecoders[t] = decoder
+}
+
+// ParamDecoderFor returns the decoder registered for the type.
+func ParamDecoderFor(t reflect.Type) (ParamDecoder, bool) {
+	paramDecodersMutex.RLock()
+	defer paramDecodersMutex.RUnlock()
+	decoder, ok := paramDecoders[t]
+	return decoder, ok
+}
+
+// Param returns the path parameter converted to T, using the decoder registered for T, encoding.TextUnmarshaler or
+// strconv for strings, booleans and numbers.
+//
+//	id, err := router.Param[UserID](r, "id")
+func Param[T any](r *http.Request, name string) (T, error) {
+	return decodeParam[T](name, r.PathValue(name))
+}
+
+// QueryParam returns the first value of the query parameter converted to T, the same way as Param. A missing
+// parameter returns the zero value of T.
+func QueryParam[T any](r *http.Request, name string) (T, error) {
+	var zero T
+	value := Query(r).Get(name)
+	if value == "" {
+		return zero, nil
+	}
+	return decodeParam[T](name, value)
+}
+
+func decodeParam[T any](name string, value string) (T, error) {
+	var result T
+	if err := DecodeParam(reflect.ValueOf(&result).Elem(), value); err != nil {
+		return result, &ParamError{Name: name, Value: value, Err: err}
+	}
+	return result, nil
+}
+
+// DecodeParam sets v to the decoded value, like Param does. v has to be settable.
+func DecodeParam(v reflect.Value, value string) error {
+	t := v.Type()
+	if decoder, ok := ParamDecoderFor(t); ok {
+		decoded, err := decoder(value)
+		if err != nil {
+			return err
+		}
+		result := reflect.ValueOf(decoded)
+		if !result.IsValid() || !result.Type().AssignableTo(t) {
+			return fmt.Errorf("decoder for %s returned %T", t, decoded)
+		}
+		v.Set(result)
+		return nil
+	}
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package router_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+// UserID is a domain type that is decoded from strings like "usr_42"
+type UserID struct {
+	N int
+}
+
+func init() {
+	router.RegisterParamDecoder(reflect.TypeOf(UserID{}), func(value string) (any, error) {
+		var id UserID
+		if _, err := fmt.Sscanf(value, "usr_%d", &id.N); err != nil {
+			return nil, errors.New("expected an ID like usr_42")
+		}
+		return id, nil
+	})
+}
+
+func TestParam(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := router.Param[UserID](r, "id")
+		if err != nil {
+			router.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		limit, err := router.QueryParam[int](r, "limit")
+		if err != nil {
+			router.Error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		fmt.Fprintf(w, "%d %d", id.N, limit)
+	})
+
+	// Define test cases
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		body       string
+	}{
+		{"registered decoder", "/users/usr_42/?limit=10", http.StatusOK, "42 10"},
+		{"missing query parameter", "/users/usr_7/", http.StatusOK, "7 0"},
+		{"invalid path parameter", "/users/42/", http.StatusBadRequest, ""},
+		{"invalid query parameter", "/users/usr_7/?limit=ten", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status %d, got %d", tt.statusCode, w.Code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestParamError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?id=usr_x", nil)
+	_, err := router.QueryParam[UserID](req, "id")
+	var paramError *router.ParamError
+	if !errors.As(err, &paramError) || paramError.Name != "id" || !strings.Contains(err.Error(), "usr_42") {
+		t.Errorf("Expected a ParamError for id, got %v", err)
+	}
+
+	if _, err := router.QueryParam[[]string](httptest.NewRequest(http.MethodGet, "/?tags=a", nil), "tags"); err == nil {
+		t.Error("Expected an error for an unsupported type")
+	}
+}
@@ -0,0 +1,73 @@
+package router
+
+import "net/http"
+
+// PathRoutes registers the routes for the methods of a single path, see Router.Route.
+type PathRoutes struct {
+	pattern     string
+	register    func(method string, pattern string, handler http.HandlerFunc) *Route
+	routes      []*Route
+	middlewares []Middleware
+}
+
+// Route returns a registrar for all methods of one path, so they're declared together and share middlewares:
+//
+//	r.Route("/users/{id}").GET(show).PUT(update).DELETE(destroy).Use(auth)
+func (r *Router) Route(pattern string) *PathRoutes {
+	return &PathRoutes{pattern: pattern, register: r.RegisterRoute}
+}
+
+// Route returns a registrar for all methods of one path below the prefix of the group, like Router.Route.
+func (rg *RouteGroup) Route(pattern string) *PathRoutes {
+	return &PathRoutes{pattern: pattern, register: rg.RegisterRoute}
+}
+
+// Handle registers the handler for the method on the path.
+func (p *PathRoutes) Handle(method string, handler http.HandlerFunc) *PathRoutes {
+	route := p.register(method, p.pattern, handler)
+	route.Use(p.middlewares...)
+	p.routes = append(p.routes, route)
+	return p
+}
+
+// Use adds middlewares to the routes of the path, including the ones registered afterwards.
+func (p *PathRoutes) Use(middleware ...Middleware) *PathRoutes {
+	p.middlewares = append(p.middlewares, middleware...)
+	for _, route := range p.routes {
+		route.Use(middleware...)
+	}
+	return p
+}
+
+// Routes returns the routes registered for the path, e.g. to set metadata on them.
+func (p *PathRoutes) Routes() []*Route {
+	return p.routes
+}
+
+func (p *PathRoutes) GET(handler http.HandlerFunc) *PathRoutes {
+	return p.Handle(http.MethodGet, handler)
+}
+
+func (p *PathRoutes) POST(handler http.HandlerFunc) *PathRoutes {
+	return p.Handle(http.MethodPost, handler)
+}
+
+func (p *PathRoutes) PUT(handler http.HandlerFunc) *PathRoutes {
+	return p.Handle(http.MethodPut, handler)
+}
+
+func (p *PathRoutes) DELETE(handler http.HandlerFunc) *PathRoutes {
+	return p.Handle(http.MethodDelete, handler)
+}
+
+func (p *PathRoutes) PATCH(handler http.HandlerFunc) *PathRoutes {
+	return p.Handle(http.MethodPatch, handler)
+}
+
+func (p *PathRoutes) OPTIONS(handler http.HandlerFunc) *PathRoutes {
+	return p.Handle(http.MethodOptions, handler)
+}
+
+func (p *PathRoutes) HEAD(handler http.HandlerFunc) *PathRoutes {
+	return p.Handle(http.MethodHead, handler)
+}
@@ -0,0 +1,72 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestPathRoutes(t *testing.T) {
+	handler := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body + " " + r.PathValue("id")))
+		}
+	}
+	header := func(name string) router.Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", name)
+				next(w, r)
+			}
+		}
+	}
+
+	// Create a new router instance with all methods of a path declared together
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	users := r.Route("/users/{id}").GET(handler("show")).PUT(handler("update")).Use(header("auth"))
+	users.DELETE(handler("destroy"))
+	api := r.Group("/api")
+	api.Route("/posts/{id}").GET(handler("post")).PATCH(handler("patch")).Use(header("api"))
+
+	// Define test cases
+	tests := []struct {
+		method      string
+		path        string
+		status      int
+		body        string
+		middlewares string
+	}{
+		{http.MethodGet, "/users/1/", http.StatusOK, "show 1", "auth"},
+		{http.MethodPut, "/users/1/", http.StatusOK, "update 1", "auth"},
+		{http.MethodDelete, "/users/1/", http.StatusOK, "destroy 1", "auth"},
+		{http.MethodPost, "/users/1/", http.StatusMethodNotAllowed, "", ""},
+		{http.MethodGet, "/api/posts/2/", http.StatusOK, "post 2", "api"},
+		{http.MethodPatch, "/api/posts/2/", http.StatusOK, "patch 2", "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if rr.Body.String() != tt.body {
+				t.Errorf("Expected body %s, got %s", tt.body, rr.Body.String())
+			}
+			if got := strings.Join(rr.Header().Values("X-Middleware"), ","); got != tt.middlewares {
+				t.Errorf("Expected middlewares %s, got %s", tt.middlewares, got)
+			}
+		})
+	}
+
+	if len(users.Routes()) != 3 {
+		t.Errorf("Expected 3 routes for the path, got %d", len(users.Routes()))
+	}
+}
@@ -0,0 +1,139 @@
+// Package problem writes errors as Problem Details for HTTP APIs (RFC 7807), using the application/problem+json
+// content type.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is a problem details object. Extensions are added as top level members of the JSON object.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// New returns a problem for the status code, with the status text as title.
+func New(status int) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+}
+
+// With sets an extension member, it returns the problem so calls can be chained.
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// Error makes a problem usable as an error, so handlers can pass it to router.Error.
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	if p.Type != "" {
+		members["type"] = p.Type
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// Write writes the problem as the response, the instance defaults to the request path.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.Path
+	}
+	status := p.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// ErrorHandler is a router.ErrorHandlerFunc that writes problem details. A *Problem in the error chain is written
+// as is, other errors are used as the detail of client errors. Server errors don't include the error message.
+func ErrorHandler(w http.ResponseWriter, r *http.Request, status int, err error) {
+	var p *Problem
+	if errors.As(err, &p) {
+		copied := *p
+		if copied.Status == 0 {
+			copied.Status = status
+		}
+		Write(w, r, &copied)
+		return
+	}
+	p = New(status)
+	if err != nil && status < http.StatusInternalServerError {
+		p.Detail = err.Error()
+	}
+	Write(w, r, p)
+}
+
+// NotFound is a handler for router.NotFound.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, New(http.StatusNotFound))
+}
+
+// MethodNotAllowed is a handler for router.MethodNotAllowed.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, New(http.StatusMethodNotAllowed))
+}
+
+// ValidationError describes why a value of a request or response is invalid, e.g. as found by the openapi package.
+type ValidationError struct {
+	// In is where the value came from, e.g. "path", "query", "header" or "body"
+	In      string `json:"in"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", e.In, e.Message)
+	}
+	return fmt.Sprintf("%s %s: %s", e.In, e.Field, e.Message)
+}
+
+// ValidationErrorHandler can be used as the ErrorHandler of openapi.ValidatorConfig, the validation errors are added
+// as the "errors" member.
+func ValidationErrorHandler(w http.ResponseWriter, r *http.Request, errs []ValidationError) {
+	p := New(http.StatusBadRequest)
+	p.Detail = "The request is invalid"
+	p.With("errors", errs)
+	Write(w, r, p)
+}
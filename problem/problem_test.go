@@ -0,0 +1,105 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/problem"
+)
+
+func TestErrorHandler(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.SetErrorHandler(problem.ErrorHandler)
+
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		router.Error(w, r, http.StatusBadRequest, errors.New("missing name"))
+	})
+	r.GET("/crash", func(w http.ResponseWriter, r *http.Request) {
+		router.Error(w, r, http.StatusInternalServerError, errors.New("connection refused"))
+	})
+	r.GET("/orders", func(w http.ResponseWriter, r *http.Request) {
+		p := problem.New(http.StatusConflict).With("order", "42")
+		p.Type = "https://example.com/problems/out-of-stock"
+		p.Detail = "Item is out of stock"
+		router.Error(w, r, http.StatusConflict, p)
+	})
+
+	// Define test cases
+	tests := []struct {
+		method     string
+		path       string
+		statusCode int
+		expected   map[string]any
+	}{
+		{http.MethodGet, "/users/", http.StatusBadRequest, map[string]any{
+			"type": "about:blank", "title": "Bad Request", "status": float64(400), "detail": "missing name", "instance": "/users/",
+		}},
+		{http.MethodGet, "/crash/", http.StatusInternalServerError, map[string]any{
+			"type": "about:blank", "title": "Internal Server Error", "status": float64(500), "instance": "/crash/",
+		}},
+		{http.MethodGet, "/orders/", http.StatusConflict, map[string]any{
+			"type": "https://example.com/problems/out-of-stock", "title": "Conflict", "status": float64(409),
+			"detail": "Item is out of stock", "instance": "/orders/", "order": "42",
+		}},
+		{http.MethodGet, "/missing/", http.StatusNotFound, map[string]any{
+			"type": "about:blank", "title": "Not Found", "status": float64(404), "instance": "/missing/",
+		}},
+		{http.MethodPost, "/users/", http.StatusMethodNotAllowed, map[string]any{
+			"type": "about:blank", "title": "Method Not Allowed", "status": float64(405), "instance": "/users/",
+		}},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tc.statusCode {
+			t.Errorf("%s %s: expected status code %d, got %d", tc.method, tc.path, tc.statusCode, rr.Code)
+		}
+		if contentType := rr.Header().Get("Content-Type"); contentType != problem.ContentType {
+			t.Errorf("%s %s: expected content type %q, got %q", tc.method, tc.path, problem.ContentType, contentType)
+		}
+
+		var body map[string]any
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s %s: invalid JSON: %v", tc.method, tc.path, err)
+		}
+		if len(body) != len(tc.expected) {
+			t.Errorf("%s %s: expected %v, got %v", tc.method, tc.path, tc.expected, body)
+		}
+		for key, value := range tc.expected {
+			if body[key] != value {
+				t.Errorf("%s %s: expected %s to be %v, got %v", tc.method, tc.path, key, value, body[key])
+			}
+		}
+	}
+}
+
+func TestValidationErrorHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/", nil)
+	problem.ValidationErrorHandler(rr, req, []problem.ValidationError{
+		{In: "body", Field: "name", Message: "is required"},
+	})
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	var body struct {
+		Status int                       `json:"status"`
+		Errors []problem.ValidationError `json:"errors"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != http.StatusBadRequest || len(body.Errors) != 1 || body.Errors[0].Field != "name" {
+		t.Errorf("Unexpected body %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,96 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// MiddlewareTiming is the time spent in a middleware of a route, excluding the time spent in the middlewares and
+// handler it calls.
+type MiddlewareTiming struct {
+	Name    string        `json:"name"`
+	Calls   int64         `json:"calls"`
+	Total   time.Duration `json:"total"`
+	Average time.Duration `json:"average"`
+}
+
+// layerStats is the time spent in a middleware or the handler of a route
+type layerStats struct {
+	name  string
+	calls atomic.Int64
+	total atomic.Int64
+}
+
+// layerTimes is the time spent per layer during a request, it's stored in the request context
+type layerTimes struct {
+	children []time.Duration
+}
+
+type layerTimesContextKey struct{}
+
+// MiddlewareProfile returns the time spent per middleware and in the handler, in the order they run. It's only
+// recorded when RouterConfig.ProfileMiddlewares is enabled.
+func (r *Route) MiddlewareProfile() []MiddlewareTiming {
+	var timings []MiddlewareTiming
+	for _, layer := range r.profile {
+		timing := MiddlewareTiming{Name: layer.name, Calls: layer.calls.Load(), Total: time.Duration(layer.total.Load())}
+		if timing.Calls > 0 {
+			timing.Average = timing.Total / time.Duration(timing.Calls)
+		}
+		timings = append(timings, timing)
+	}
+	return timings
+}
+
+// profiledMiddlewares applies the middlewares like applyMiddlewares, while recording the time spent in every layer
+func profiledMiddlewares(route *Route, handler http.HandlerFunc, middlewares []Middleware, names []string) http.HandlerFunc {
+	route.profile = make([]*layerStats, len(middlewares)+1)
+	for i := range middlewares {
+		route.profile[i] = &layerStats{name: names[i]}
+	}
+	route.profile[len(middlewares)] = &layerStats{name: "handler"}
+
+	handler = profiledLayer(route.profile[len(middlewares)], len(middlewares), handler)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		next := handler
+		// The time spent in next is subtracted from the time of the middleware
+		timedNext := func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			next(w, req)
+			if times, ok := req.Context().Value(layerTimesContextKey{}).(*layerTimes); ok {
+				times.children[i] += time.Since(start)
+			}
+		}
+		handler = profiledLayer(route.profile[i], i, middlewares[i](timedNext))
+	}
+
+	layers := len(middlewares) + 1
+	return func(w http.ResponseWriter, req *http.Request) {
+		times := &layerTimes{children: make([]time.Duration, layers)}
+		handler(w, req.WithContext(context.WithValue(req.Context(), layerTimesContextKey{}, times)))
+	}
+}
+
+// middlewareNames returns the names of the middlewares, they're taken before the middlewares are wrapped for tracing
+func middlewareNames(middlewares []Middleware) []string {
+	names := make([]string, len(middlewares))
+	for i, middleware := range middlewares {
+		names[i] = funcName(middleware)
+	}
+	return names
+}
+
+func profiledLayer(stats *layerStats, index int, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		handler(w, req)
+		elapsed := time.Since(start)
+		if times, ok := req.Context().Value(layerTimesContextKey{}).(*layerTimes); ok {
+			elapsed -= times.children[index]
+		}
+		stats.calls.Add(1)
+		stats.total.Add(int64(elapsed))
+	}
+}
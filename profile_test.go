@@ -0,0 +1,78 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func slowMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		next(w, r)
+	}
+}
+
+func fastMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r)
+	}
+}
+
+func TestMiddlewareProfile(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithConfig(router.RouterConfig{ProfileMiddlewares: true}))
+	r.EnableAdmin("/__router", router.AdminConfig{Token: "secret"})
+	r.Use(fastMiddleware)
+	route := r.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+	}).Use(slowMiddleware)
+
+	for i := 0; i < 3; i++ {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+	}
+
+	profile := route.MiddlewareProfile()
+	if len(profile) != 3 {
+		t.Fatalf("Expected 2 middlewares and the handler, got %+v", profile)
+	}
+
+	// Define test cases, the slow middleware doesn't include the time of the handler
+	tests := []struct {
+		name string
+		min  time.Duration
+		max  time.Duration
+	}{
+		{"router_test.fastMiddleware", 0, 5 * time.Millisecond},
+		{"router_test.slowMiddleware", 20 * time.Millisecond, 28 * time.Millisecond},
+		{"handler", 10 * time.Millisecond, 18 * time.Millisecond},
+	}
+
+	for i, tc := range tests {
+		timing := profile[i]
+		if timing.Name != tc.name || timing.Calls != 3 {
+			t.Errorf("Expected %s with 3 calls, got %+v", tc.name, timing)
+		}
+		if timing.Average < tc.min || timing.Average > tc.max {
+			t.Errorf("Expected the average of %s to be between %s and %s, got %s", tc.name, tc.min, tc.max, timing.Average)
+		}
+	}
+
+	// The profile is available through the admin API
+	req := httptest.NewRequest(http.MethodGet, "/__router/profile/", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var profiles map[string][]router.MiddlewareTiming
+	if err := json.Unmarshal(rr.Body.Bytes(), &profiles); err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles["GET /users/{$}"]) != 3 {
+		t.Errorf("Expected the profile of the route, got %s", rr.Body.String())
+	}
+}
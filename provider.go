@@ -0,0 +1,78 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"sync"
+)
+
+// RouteProvider registers the routes of a package, e.g. a module of a modular monolith.
+type RouteProvider interface {
+	Routes(r *Router)
+}
+
+// RouteProviderFunc allows a func(r *Router) to be used as a RouteProvider, e.g. the Routes function of a package.
+type RouteProviderFunc func(r *Router)
+
+func (f RouteProviderFunc) Routes(r *Router) {
+	f(r)
+}
+
+var (
+	providersMutex sync.RWMutex
+	providers      = make(map[string]RouteProvider)
+)
+
+// RegisterProvider makes a provider available to AutoRegister under the name, packages usually call it from init.
+// It panics when the name is already registered.
+//
+//	func init() {
+//		router.RegisterProvider("users", router.RouteProviderFunc(Routes))
+//	}
+func RegisterProvider(name string, provider RouteProvider) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	if _, ok := providers[name]; ok {
+		panic(fmt.Sprintf("router: provider %q is registered twice", name))
+	}
+	providers[name] = provider
+}
+
+// AutoRegister mounts the providers of the directories in fsys that match the pattern, e.g. "modules/*". Every
+// directory needs a provider registered with its name, which gets a group with the name as prefix. As Go can't load
+// packages at runtime the packages still have to be imported, AutoRegister returns an error for directories without
+// a provider so a missing import is noticed.
+func (r *Router) AutoRegister(fsys fs.FS, pattern string) error {
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return fmt.Errorf("router: invalid pattern %q: %w", pattern, err)
+	}
+
+	// The providers are copied, so they can register providers themselves without a deadlock
+	providersMutex.RLock()
+	registered := maps.Clone(providers)
+	providersMutex.RUnlock()
+
+	var errs []error
+	for _, match := range matches {
+		info, err := fs.Stat(fsys, match)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !info.IsDir() {
+			continue
+		}
+		name := path.Base(match)
+		provider, ok := registered[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("router: no provider registered for %s, is the package imported?", match))
+			continue
+		}
+		r.Group("/"+name, provider.Routes).Set("provider", name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func init() {
+	router.RegisterProvider("orders", router.RouteProviderFunc(func(r *router.Router) {
+		r.GET("/", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("orders")) })
+	}))
+	router.RegisterProvider("invoices", router.RouteProviderFunc(func(r *router.Router) {
+		r.GET("/{id}", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("invoice " + r.PathValue("id"))) })
+	}))
+}
+
+func TestAutoRegister(t *testing.T) {
+	fsys := fstest.MapFS{
+		"modules/orders/routes.go":   {},
+		"modules/invoices/routes.go": {},
+		"modules/README.md":          {},
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	if err := r.AutoRegister(fsys, "modules/*"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Define test cases
+	tests := []struct {
+		path         string
+		expectedBody string
+	}{
+		{"/orders/", "orders"},
+		{"/invoices/5/", "invoice 5"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Body.String() != tt.expectedBody {
+			t.Errorf("%s: expected body %q, got %q", tt.path, tt.expectedBody, w.Body.String())
+		}
+	}
+}
+
+func TestAutoRegisterMissingProvider(t *testing.T) {
+	fsys := fstest.MapFS{
+		"modules/orders/routes.go":   {},
+		"modules/shipping/routes.go": {},
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	err := r.AutoRegister(fsys, "modules/*")
+	if err == nil || !strings.Contains(err.Error(), "modules/shipping") {
+		t.Errorf("Expected an error for modules/shipping, got %v", err)
+	}
+	if len(r.Routes()) != 1 {
+		t.Errorf("Expected the orders routes to be registered, got %d routes", len(r.Routes()))
+	}
+}
+
+func TestAutoRegisterNestedProvider(t *testing.T) {
+	// A provider that registers another provider, e.g. of a submodule, while its routes are added
+	router.RegisterProvider("catalog", router.RouteProviderFunc(func(r *router.Router) {
+		router.RegisterProvider("catalog-items", router.RouteProviderFunc(func(r *router.Router) {}))
+		r.GET("/", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("catalog")) })
+	}))
+	fsys := fstest.MapFS{"modules/catalog/routes.go": {}}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	done := make(chan error, 1)
+	go func() {
+		done <- r.AutoRegister(fsys, "modules/*")
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected AutoRegister to return, it is deadlocked")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/catalog/", nil))
+	if w.Body.String() != "catalog" {
+		t.Errorf("Expected body %q, got %q", "catalog", w.Body.String())
+	}
+}
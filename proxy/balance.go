@@ -0,0 +1,195 @@
+package proxy
+
+import (
+	"cmp"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Balancing is how requests are distributed over the upstreams.
+type Balancing int
+
+const (
+	// RoundRobin sends requests to the upstreams in turn
+	RoundRobin Balancing = iota
+	// Weighted sends requests to the upstreams in turn, in proportion to their weights
+	Weighted
+	// ConsistentHash sends requests with the same key to the same upstream, the key is the value of the HashHeader
+	// or HashCookie, or the IP of the client. When an upstream is added or removed, only the keys of that upstream
+	// move.
+	ConsistentHash
+)
+
+// Upstream is one of the services that requests are balanced over.
+type Upstream struct {
+	URL string
+	// Name identifies the upstream in the stats, defaults to the URL
+	Name string
+	// Weight is the share of the requests the upstream gets with Weighted and ConsistentHash balancing, defaults to 1
+	Weight int
+}
+
+// virtualNodes is the number of points per weight an upstream has on the hash ring, more points spread the keys
+// more evenly
+const virtualNodes = 100
+
+type ringNode struct {
+	hash   uint32
+	target *target
+}
+
+// balancer picks the upstream for a request
+type balancer struct {
+	targets     []*target
+	balancing   Balancing
+	hashHeader  string
+	hashCookie  string
+	maxFails    int64
+	failTimeout time.Duration
+
+	next  atomic.Uint64
+	mutex sync.Mutex
+	ring  []ringNode
+}
+
+func newBalancer(targets []*target, config Config) *balancer {
+	b := &balancer{
+		targets:     targets,
+		balancing:   config.Balancing,
+		hashHeader:  config.HashHeader,
+		hashCookie:  config.HashCookie,
+		maxFails:    int64(config.MaxFails),
+		failTimeout: config.FailTimeout,
+	}
+	if b.maxFails <= 0 {
+		b.maxFails = 3
+	}
+	if b.failTimeout <= 0 {
+		b.failTimeout = 30 * time.Second
+	}
+	if b.balancing == ConsistentHash {
+		for _, t := range targets {
+			for i := range t.weight * virtualNodes {
+				b.ring = append(b.ring, ringNode{hash: hashKey(t.name + "#" + strconv.Itoa(i)), target: t})
+			}
+		}
+		slices.SortFunc(b.ring, func(a, b ringNode) int {
+			return cmp.Compare(a.hash, b.hash)
+		})
+	}
+	return b
+}
+
+// pick returns the upstream for the request. Unhealthy upstreams and the excluded ones are skipped, unless there are
+// no others.
+func (b *balancer) pick(r *http.Request, exclude ...*target) *target {
+	if len(b.targets) == 1 {
+		return b.targets[0]
+	}
+	now := time.Now()
+	available := func(t *target) bool {
+		return t.healthy(now) && !slices.Contains(exclude, t)
+	}
+	if !slices.ContainsFunc(b.targets, available) {
+		available = func(t *target) bool { return !slices.Contains(exclude, t) }
+	}
+	if !slices.ContainsFunc(b.targets, available) {
+		available = func(t *target) bool { return true }
+	}
+
+	switch b.balancing {
+	case Weighted:
+		return b.pickWeighted(available)
+	case ConsistentHash:
+		return b.pickHashed(r, available)
+	}
+	for range b.targets {
+		t := b.targets[(b.next.Add(1)-1)%uint64(len(b.targets))]
+		if available(t) {
+			return t
+		}
+	}
+	return b.targets[0]
+}
+
+// pickWeighted is a smooth weighted round robin, which spreads the requests of an upstream with a high weight
+// instead of sending them in a burst
+func (b *balancer) pickWeighted(available func(*target) bool) *target {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var best *target
+	total := 0
+	for _, t := range b.targets {
+		if !available(t) {
+			continue
+		}
+		t.currentWeight += t.weight
+		total += t.weight
+		if best == nil || t.currentWeight > best.currentWeight {
+			best = t
+		}
+	}
+	best.currentWeight -= total
+	return best
+}
+
+func (b *balancer) pickHashed(r *http.Request, available func(*target) bool) *target {
+	hash := hashKey(b.hashKey(r))
+	start, _ := slices.BinarySearchFunc(b.ring, hash, func(node ringNode, hash uint32) int {
+		return cmp.Compare(node.hash, hash)
+	})
+	// The next available upstream on the ring takes over the keys of unavailable ones
+	for i := range b.ring {
+		if node := b.ring[(start+i)%len(b.ring)]; available(node.target) {
+			return node.target
+		}
+	}
+	return b.ring[start%len(b.ring)].target
+}
+
+func (b *balancer) hashKey(r *http.Request) string {
+	if b.hashHeader != "" {
+		if value := r.Header.Get(b.hashHeader); value != "" {
+			return value
+		}
+	}
+	if b.hashCookie != "" {
+		if cookie, err := r.Cookie(b.hashCookie); err == nil {
+			return cookie.Value
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// observe records the result of a request for the passive health check, upstreams are skipped for the fail timeout
+// after MaxFails consecutive failures
+func (b *balancer) observe(t *target, failed bool) {
+	if !failed {
+		t.fails.Store(0)
+		return
+	}
+	if t.fails.Add(1) >= b.maxFails {
+		t.unhealthyUntil.Store(time.Now().Add(b.failTimeout).UnixNano())
+		t.fails.Store(0)
+	}
+}
+
+func (t *target) healthy(now time.Time) bool {
+	return now.UnixNano() >= t.unhealthyUntil.Load()
+}
@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// HeaderRules change the headers of proxied requests or responses, without a custom Director. They're applied in the
+// order of the fields.
+type HeaderRules struct {
+	// Remove deletes headers, names ending with "*" delete all headers with the prefix, e.g. "X-Internal-*"
+	Remove []string
+	// Rename moves the values of headers to another name, e.g. "X-User" to "X-Forwarded-User". All renames read the
+	// headers as they were before renaming, so {"A": "B", "B": "C"} moves A to B and the original B to C
+	Rename map[string]string
+	// Set replaces the values of headers
+	Set map[string]string
+	// Add adds a value to headers, keeping the existing values
+	Add map[string]string
+}
+
+// SecretProvider returns a secret for a request header, e.g. the token of the upstream from a secret manager. It's
+// called for every request, so it should cache the secret.
+type SecretProvider func(ctx context.Context) (string, error)
+
+func (rules HeaderRules) apply(header http.Header) {
+	for _, name := range rules.Remove {
+		prefix, wildcard := strings.CutSuffix(name, "*")
+		if !wildcard {
+			header.Del(name)
+			continue
+		}
+		for key := range header {
+			if len(key) >= len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+				delete(header, key)
+			}
+		}
+	}
+	// The maps are applied in sorted order, so rules that overlap give the same result for every request
+	renamed := make(map[string][]string, len(rules.Rename))
+	for _, from := range slices.Sorted(maps.Keys(rules.Rename)) {
+		if values := header.Values(from); len(values) > 0 {
+			renamed[http.CanonicalHeaderKey(rules.Rename[from])] = values
+			header.Del(from)
+		}
+	}
+	for _, to := range slices.Sorted(maps.Keys(renamed)) {
+		header[to] = renamed[to]
+	}
+	for _, name := range slices.Sorted(maps.Keys(rules.Set)) {
+		header.Set(name, rules.Set[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(rules.Add)) {
+		header.Add(name, rules.Add[name])
+	}
+}
+
+// secrets returns the values of the secret headers
+func secrets(ctx context.Context, providers map[string]SecretProvider) (http.Header, error) {
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	header := make(http.Header, len(providers))
+	for name, provider := range providers {
+		value, err := provider(ctx)
+		if err != nil {
+			return nil, err
+		}
+		header.Set(name, value)
+	}
+	return header, nil
+}
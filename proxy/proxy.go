@@ -0,0 +1,281 @@
+// Package proxy forwards requests to upstream services, balanced over several upstreams, with canary rules to move
+// traffic between targets.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+const (
+	// StableTarget is the name of Config.Target in the stats
+	StableTarget = "stable"
+	// CanaryTarget is the name of Canary.Target in the stats
+	CanaryTarget = "canary"
+)
+
+type Config struct {
+	// Target is the URL of the upstream service, the path of requests is appended to its path
+	Target string
+	// Upstreams replace the Target with several upstreams, the requests are distributed over them by the Balancing
+	Upstreams []Upstream
+	Balancing Balancing
+	// HashHeader and HashCookie are the key of ConsistentHash balancing, requests without them use the client IP
+	HashHeader string
+	HashCookie string
+	// MaxFails is the number of consecutive failures after which an upstream is skipped for the FailTimeout, defaults
+	// to 3 failures and 30 seconds. Failures are requests that can't reach the upstream or get a 5xx response
+	MaxFails    int
+	FailTimeout time.Duration
+	// Canary sends part of the traffic to another target
+	Canary *Canary
+	// Transport is used to send the requests, defaults to http.DefaultTransport
+	Transport http.RoundTripper
+	// Retry retries idempotent requests that failed, without it requests are sent once
+	Retry *RetryConfig
+	// RequestHeaders and ResponseHeaders change the headers of requests before they're sent to the upstream, and of
+	// responses before they're sent to the client, e.g. to strip internal headers
+	RequestHeaders  HeaderRules
+	ResponseHeaders HeaderRules
+	// SecretHeaders set request headers to secrets after the RequestHeaders, e.g. an Authorization header for the
+	// upstream. Requests get a 500 when a secret can't be read
+	SecretHeaders map[string]SecretProvider
+	// StreamIdleTimeout closes upgraded connections, e.g. WebSockets, and event streams when no data flows for the
+	// duration, defaults to 5 minutes. Streams aren't limited by the read and write timeouts of the server
+	StreamIdleTimeout time.Duration
+	// FlushInterval flushes buffered responses periodically, event streams and responses without a length are flushed
+	// after every write
+	FlushInterval time.Duration
+}
+
+// Canary sends requests with the header, or a percentage of all requests, to the canary target.
+type Canary struct {
+	Target string
+	// Header forces the target of a request, "true" selects the canary and "false" the stable target.
+	// Defaults to "X-Canary"
+	Header string
+	// Percent of the requests without the header that go to the canary, between 0 and 100
+	Percent float64
+}
+
+// Stats are the counters of a target.
+type Stats struct {
+	Requests int64
+	// Errors counts requests that failed to reach the target or got a 5xx response
+	Errors   int64
+	Duration time.Duration
+	// Retries counts the retries that were sent to the target
+	Retries int64
+	// Healthy is false while the passive health check skips the target
+	Healthy bool
+}
+
+type target struct {
+	name     string
+	url      *url.URL
+	weight   int
+	balancer *balancer
+	requests atomic.Int64
+	errors   atomic.Int64
+	duration atomic.Int64
+	retries  atomic.Int64
+	// fails and unhealthyUntil are the state of the passive health check
+	fails          atomic.Int64
+	unhealthyUntil atomic.Int64
+	// currentWeight is the state of Weighted balancing, guarded by the mutex of the balancer
+	currentWeight int
+}
+
+type Proxy struct {
+	stable        *balancer
+	canary        *balancer
+	canaryHeader  string
+	canaryPercent atomic.Uint64
+	reverseProxy  *httputil.ReverseProxy
+	secrets       map[string]SecretProvider
+}
+
+type targetContextKey struct{}
+
+func New(config Config) (*Proxy, error) {
+	upstreams := config.Upstreams
+	switch {
+	case config.Target != "" && len(upstreams) > 0:
+		return nil, errors.New("proxy: either the Target or the Upstreams can be set")
+	case len(upstreams) == 0:
+		upstreams = []Upstream{{URL: config.Target, Name: StableTarget}}
+	}
+	var targets []*target
+	for _, upstream := range upstreams {
+		name := upstream.Name
+		if name == "" {
+			name = upstream.URL
+		}
+		t, err := newTarget(name, upstream.URL)
+		if err != nil {
+			return nil, err
+		}
+		t.weight = max(upstream.Weight, 1)
+		targets = append(targets, t)
+	}
+	p := &Proxy{stable: newBalancer(targets, config), secrets: config.SecretHeaders}
+
+	if config.Canary != nil {
+		canary, err := newTarget(CanaryTarget, config.Canary.Target)
+		if err != nil {
+			return nil, err
+		}
+		p.canary = newBalancer([]*target{canary}, config)
+		p.canaryHeader = config.Canary.Header
+		if p.canaryHeader == "" {
+			p.canaryHeader = "X-Canary"
+		}
+		p.SetCanaryPercent(config.Canary.Percent)
+	}
+
+	if config.StreamIdleTimeout <= 0 {
+		config.StreamIdleTimeout = 5 * time.Minute
+	}
+	transport := config.Transport
+	if config.Retry != nil {
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		transport = newRetryTransport(transport, *config.Retry)
+	}
+	p.reverseProxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			state := pr.In.Context().Value(targetContextKey{}).(*proxyRequest)
+			pr.SetURL(state.target.url)
+			pr.SetXForwarded()
+			config.RequestHeaders.apply(pr.Out.Header)
+			for name, values := range state.secrets {
+				pr.Out.Header[name] = values
+			}
+		},
+		Transport:     transport,
+		FlushInterval: config.FlushInterval,
+		ModifyResponse: func(resp *http.Response) error {
+			config.ResponseHeaders.apply(resp.Header)
+			state := resp.Request.Context().Value(targetContextKey{}).(*proxyRequest)
+			if streaming(resp) {
+				state.stream(resp, config.StreamIdleTimeout)
+			}
+			t := state.target
+			failed := resp.StatusCode >= http.StatusInternalServerError
+			if failed {
+				t.errors.Add(1)
+			}
+			t.balancer.observe(t, failed)
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			t := r.Context().Value(targetContextKey{}).(*proxyRequest).target
+			t.errors.Add(1)
+			t.balancer.observe(t, true)
+			router.Error(w, r, http.StatusBadGateway, err)
+		},
+	}
+	for _, b := range []*balancer{p.stable, p.canary} {
+		if b != nil {
+			for _, t := range b.targets {
+				t.balancer = b
+			}
+		}
+	}
+	return p, nil
+}
+
+func newTarget(name string, rawURL string) (*target, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: invalid %s target: %w", name, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("proxy: invalid %s target %q: the scheme and host are required", name, rawURL)
+	}
+	return &target{name: name, url: u}, nil
+}
+
+// SetCanaryPercent changes the share of the traffic that goes to the canary, so a rollout can be advanced or rolled
+// back without restarting. It does nothing when there is no canary.
+func (p *Proxy) SetCanaryPercent(percent float64) {
+	percent = min(max(percent, 0), 100)
+	p.canaryPercent.Store(uint64(percent * 100))
+}
+
+// Mount registers the proxy for every path below the prefix, which is stripped before forwarding.
+func (p *Proxy) Mount(r *router.Router, prefix string) *router.Route {
+	return r.Mount(prefix, p).Set("proxy", true)
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secrets, err := secrets(r.Context(), p.secrets)
+	if err != nil {
+		router.Error(w, r, http.StatusInternalServerError, fmt.Errorf("proxy: reading a secret header: %w", err))
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	state := &proxyRequest{target: p.targetFor(r), secrets: secrets, w: w, cancel: cancel}
+	start := time.Now()
+	p.reverseProxy.ServeHTTP(w, r.WithContext(context.WithValue(ctx, targetContextKey{}, state)))
+	// Retries can end on another target, which gets the request
+	state.target.requests.Add(1)
+	state.target.duration.Add(int64(time.Since(start)))
+}
+
+func (p *Proxy) targetFor(r *http.Request) *target {
+	return p.balancerFor(r).pick(r)
+}
+
+func (p *Proxy) balancerFor(r *http.Request) *balancer {
+	if p.canary == nil {
+		return p.stable
+	}
+	switch r.Header.Get(p.canaryHeader) {
+	case "true":
+		return p.canary
+	case "false":
+		return p.stable
+	}
+	if rand.Float64()*100 < float64(p.canaryPercent.Load())/100 {
+		return p.canary
+	}
+	return p.stable
+}
+
+// Stats returns the counters per target name, so they can be exported as metrics labeled by target. The target is
+// "stable" or the names of the upstreams, and "canary".
+func (p *Proxy) Stats() map[string]Stats {
+	stats := map[string]Stats{}
+	now := time.Now()
+	for _, b := range []*balancer{p.stable, p.canary} {
+		if b == nil {
+			continue
+		}
+		for _, t := range b.targets {
+			stats[t.name] = t.stats(now)
+		}
+	}
+	return stats
+}
+
+func (t *target) stats(now time.Time) Stats {
+	return Stats{
+		Requests: t.requests.Load(),
+		Errors:   t.errors.Load(),
+		Duration: time.Duration(t.duration.Load()),
+		Retries:  t.retries.Load(),
+		Healthy:  t.healthy(now),
+	}
+}
@@ -0,0 +1,556 @@
+package proxy_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/proxy"
+)
+
+func upstream(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, name+" "+r.URL.Path)
+	}))
+}
+
+func TestProxy(t *testing.T) {
+	stable := upstream("stable")
+	defer stable.Close()
+
+	p, err := proxy.New(proxy.Config{Target: stable.URL + "/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	p.Mount(r, "/api")
+
+	// Define test cases
+	tests := []struct {
+		path       string
+		statusCode int
+		body       string
+	}{
+		{"/api/users", http.StatusOK, "stable /v1/users"},
+		{"/api/users/5", http.StatusOK, "stable /v1/users/5"},
+	}
+
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != tc.statusCode || rr.Body.String() != tc.body {
+			t.Errorf("%s: expected %d %q, got %d %q", tc.path, tc.statusCode, tc.body, rr.Code, rr.Body.String())
+		}
+	}
+
+	if _, err := proxy.New(proxy.Config{Target: "/relative"}); err == nil {
+		t.Error("Expected an error for a target without scheme and host")
+	}
+}
+
+func TestProxyCanary(t *testing.T) {
+	stable := upstream("stable")
+	defer stable.Close()
+	canary := upstream("canary")
+	defer canary.Close()
+
+	p, err := proxy.New(proxy.Config{
+		Target: stable.URL,
+		Canary: &proxy.Canary{Target: canary.URL, Percent: 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	p.Mount(r, "/")
+
+	do := func(path string, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set("X-Canary", header)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// The header forces the target
+	if body := do("/users", "true").Body.String(); body != "canary /users" {
+		t.Errorf("Expected the canary, got %q", body)
+	}
+	if body := do("/users", "false").Body.String(); body != "stable /users" {
+		t.Errorf("Expected the stable target, got %q", body)
+	}
+
+	// A percentage of the other requests goes to the canary
+	canaryRequests := 0
+	for i := 0; i < 500; i++ {
+		if do("/users", "").Body.String() == "canary /users" {
+			canaryRequests++
+		}
+	}
+	if canaryRequests < 50 || canaryRequests > 150 {
+		t.Errorf("Expected about 100 canary requests, got %d", canaryRequests)
+	}
+
+	// Rolling back sends all traffic to the stable target
+	p.SetCanaryPercent(0)
+	for i := 0; i < 50; i++ {
+		if body := do("/users", "").Body.String(); body != "stable /users" {
+			t.Fatalf("Expected the stable target after rolling back, got %q", body)
+		}
+	}
+
+	do("/fail", "true")
+	stats := p.Stats()
+	if stats[proxy.CanaryTarget].Requests != int64(canaryRequests+2) || stats[proxy.CanaryTarget].Errors != 1 {
+		t.Errorf("Unexpected canary stats %+v", stats[proxy.CanaryTarget])
+	}
+	if stats[proxy.StableTarget].Requests != int64(500-canaryRequests+51) || stats[proxy.StableTarget].Errors != 0 {
+		t.Errorf("Unexpected stable stats %+v", stats[proxy.StableTarget])
+	}
+}
+
+func TestProxyUnreachable(t *testing.T) {
+	closed := upstream("closed")
+	closed.Close()
+
+	p, err := proxy.New(proxy.Config{Target: closed.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	p.Mount(r, "/")
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if rr.Code != http.StatusBadGateway {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadGateway, rr.Code)
+	}
+	if p.Stats()[proxy.StableTarget].Errors != 1 {
+		t.Errorf("Expected the error to be counted")
+	}
+}
+
+func TestProxyBalancing(t *testing.T) {
+	a := upstream("a")
+	defer a.Close()
+	b := upstream("b")
+	defer b.Close()
+	c := upstream("c")
+	defer c.Close()
+	upstreams := []proxy.Upstream{{URL: a.URL, Name: "a"}, {URL: b.URL, Name: "b", Weight: 3}, {URL: c.URL, Name: "c"}}
+
+	// counts sends the requests and counts the responses per upstream
+	counts := func(p *proxy.Proxy, n int, prepare func(i int, req *http.Request)) map[string]int {
+		// Create a new router instance
+		r := router.NewRouter(router.WithMux(http.NewServeMux()))
+		p.Mount(r, "/")
+		counts := map[string]int{}
+		for i := range n {
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if prepare != nil {
+				prepare(i, req)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			name, _, _ := strings.Cut(rr.Body.String(), " ")
+			counts[name]++
+		}
+		return counts
+	}
+
+	// Define test cases
+	tests := []struct {
+		name      string
+		balancing proxy.Balancing
+		expected  map[string]int
+	}{
+		{"round robin", proxy.RoundRobin, map[string]int{"a": 4, "b": 4, "c": 4}},
+		{"weighted", proxy.Weighted, map[string]int{"a": 2, "b": 6, "c": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := proxy.New(proxy.Config{Upstreams: upstreams, Balancing: tt.balancing})
+			if err != nil {
+				t.Fatal(err)
+			}
+			total := 0
+			for _, n := range tt.expected {
+				total += n
+			}
+			if got := counts(p, total, nil); !maps.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("consistent hash", func(t *testing.T) {
+		p, err := proxy.New(proxy.Config{Upstreams: upstreams, Balancing: proxy.ConsistentHash, HashHeader: "X-User-ID"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The same key always goes to the same upstream
+		for _, user := range []string{"1", "2", "3", "4"} {
+			got := counts(p, 5, func(i int, req *http.Request) { req.Header.Set("X-User-ID", user) })
+			if len(got) != 1 {
+				t.Errorf("expected user %s to stick to one upstream, got %v", user, got)
+			}
+		}
+		// The keys are spread by weight
+		got := counts(p, 1000, func(i int, req *http.Request) { req.Header.Set("X-User-ID", strconv.Itoa(i)) })
+		if got["b"] < 450 || got["a"] < 100 || got["c"] < 100 {
+			t.Errorf("expected about 600 keys on b and 200 on a and c, got %v", got)
+		}
+	})
+
+	t.Run("passive health check", func(t *testing.T) {
+		closed := upstream("closed")
+		closed.Close()
+		p, err := proxy.New(proxy.Config{
+			Upstreams: []proxy.Upstream{{URL: a.URL, Name: "a"}, {URL: closed.URL, Name: "closed"}},
+			MaxFails:  2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := counts(p, 20, nil)
+		if got["a"] != 18 {
+			t.Errorf("expected the closed upstream to be skipped after 2 failures, got %v", got)
+		}
+		stats := p.Stats()
+		if stats["closed"].Healthy || stats["closed"].Errors != 2 || !stats["a"].Healthy || stats["a"].Requests != 18 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	})
+
+	if _, err := proxy.New(proxy.Config{Target: a.URL, Upstreams: upstreams}); err == nil {
+		t.Error("Expected an error for both a target and upstreams")
+	}
+}
+
+func TestProxyRetry(t *testing.T) {
+	// flaky fails the first requests with 503, and echoes the method and body afterwards
+	flaky := func(failures int32, delay time.Duration) *httptest.Server {
+		var requests atomic.Int32
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			if requests.Add(1) <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			io.WriteString(w, r.Method+" "+r.URL.Path+" "+string(body))
+		}))
+	}
+
+	// Define test cases
+	tests := []struct {
+		name       string
+		failures   int32
+		delay      time.Duration
+		method     string
+		body       string
+		config     proxy.RetryConfig
+		statusCode int
+		response   string
+		retries    int64
+	}{
+		{"retried", 2, 0, http.MethodGet, "", proxy.RetryConfig{Backoff: time.Millisecond}, http.StatusOK, "GET /v1/users ", 2},
+		{"body replayed", 1, 0, http.MethodPut, "name=alice", proxy.RetryConfig{Backoff: time.Millisecond}, http.StatusOK, "PUT /v1/users name=alice", 1},
+		{"attempts exhausted", 5, 0, http.MethodGet, "", proxy.RetryConfig{Attempts: 2, Backoff: time.Millisecond}, http.StatusServiceUnavailable, "", 1},
+		{"not idempotent", 1, 0, http.MethodPost, "name=alice", proxy.RetryConfig{Backoff: time.Millisecond}, http.StatusServiceUnavailable, "", 0},
+		{"not retried status", 1, 0, http.MethodGet, "", proxy.RetryConfig{RetryOn: []int{http.StatusBadGateway}}, http.StatusServiceUnavailable, "", 0},
+		{"try timeout", 0, 50 * time.Millisecond, http.MethodGet, "", proxy.RetryConfig{Attempts: 2, TryTimeout: 10 * time.Millisecond, Backoff: time.Millisecond}, http.StatusBadGateway, "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := flaky(tt.failures, tt.delay)
+			defer upstream.Close()
+			p, err := proxy.New(proxy.Config{Target: upstream.URL + "/v1", Retry: &tt.config})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Create a new router instance
+			r := router.NewRouter(router.WithMux(http.NewServeMux()))
+			p.Mount(r, "/")
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(tt.method, "/users", strings.NewReader(tt.body)))
+			if rr.Code != tt.statusCode {
+				t.Errorf("expected status %d, got %d", tt.statusCode, rr.Code)
+			}
+			if tt.response != "" && rr.Body.String() != tt.response {
+				t.Errorf("expected %q, got %q", tt.response, rr.Body.String())
+			}
+			if stats := p.Stats()[proxy.StableTarget]; stats.Retries != tt.retries {
+				t.Errorf("expected %d retries, got %d", tt.retries, stats.Retries)
+			}
+		})
+	}
+
+	t.Run("other upstream", func(t *testing.T) {
+		down := flaky(100, 0)
+		defer down.Close()
+		up := flaky(0, 0)
+		defer up.Close()
+		p, err := proxy.New(proxy.Config{
+			Upstreams: []proxy.Upstream{{URL: down.URL, Name: "down"}, {URL: up.URL + "/v2", Name: "up"}},
+			Retry:     &proxy.RetryConfig{Attempts: 2, Backoff: time.Millisecond},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Create a new router instance
+		r := router.NewRouter(router.WithMux(http.NewServeMux()))
+		p.Mount(r, "/")
+		for range 4 {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users", nil))
+			if rr.Code != http.StatusOK || rr.Body.String() != "GET /v2/users " {
+				t.Errorf("expected the request to be retried on the other upstream, got %d %q", rr.Code, rr.Body.String())
+			}
+		}
+		// The upstream that is down is skipped after 3 failures
+		stats := p.Stats()
+		if stats["up"].Requests != 4 || stats["up"].Retries != 3 || stats["down"].Errors != 3 || stats["down"].Healthy {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		down := flaky(1000, 0)
+		defer down.Close()
+		p, err := proxy.New(proxy.Config{Target: down.URL, Retry: &proxy.RetryConfig{Attempts: 2, Backoff: time.Millisecond, Budget: 0.1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Create a new router instance
+		r := router.NewRouter(router.WithMux(http.NewServeMux()))
+		p.Mount(r, "/")
+		for range 50 {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+		}
+		// The reserve of 10 retries is used up, after that every 10th request can be retried
+		if retries := p.Stats()[proxy.StableTarget].Retries; retries < 10 || retries > 16 {
+			t.Errorf("expected about 14 retries, got %d", retries)
+		}
+	})
+}
+
+func TestProxyHeaders(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range []string{"X-Internal-User", "X-Internal-Role", "X-Forwarded-User", "X-Tenant", "X-Version", "Authorization"} {
+			w.Header()["Got-"+name] = r.Header.Values(name)
+		}
+		w.Header().Set("Server", "backend/1.2")
+		w.Header().Set("X-Debug-Host", "10.0.0.5")
+		w.Header().Set("X-Request-Cost", "3")
+		w.Header().Set("X-Cost", "1")
+	}))
+	defer backend.Close()
+
+	var failSecret atomic.Bool
+	p, err := proxy.New(proxy.Config{
+		Target: backend.URL,
+		RequestHeaders: proxy.HeaderRules{
+			Remove: []string{"X-Internal-*"},
+			Rename: map[string]string{"X-User": "X-Forwarded-User"},
+			Set:    map[string]string{"X-Tenant": "acme"},
+			Add:    map[string]string{"X-Version": "2"},
+		},
+		ResponseHeaders: proxy.HeaderRules{
+			Remove: []string{"Server", "x-debug-*"},
+			// Chained renames read the original headers
+			Rename: map[string]string{"X-Request-Cost": "X-Cost", "X-Cost": "X-Upstream-Cost"},
+		},
+		SecretHeaders: map[string]proxy.SecretProvider{
+			"Authorization": func(ctx context.Context) (string, error) {
+				if failSecret.Load() {
+					return "", errors.New("vault sealed")
+				}
+				return "Bearer upstream-token", nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	p.Mount(r, "/")
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("X-Internal-User", "1")
+	req.Header.Set("X-Internal-Role", "admin")
+	req.Header.Set("X-User", "ada")
+	req.Header.Set("X-Tenant", "other")
+	req.Header.Set("X-Version", "1")
+	req.Header.Set("Authorization", "Bearer client-token")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	// Define test cases
+	tests := []struct {
+		header string
+		values []string
+	}{
+		{"Got-X-Internal-User", nil},
+		{"Got-X-Internal-Role", nil},
+		{"Got-X-Forwarded-User", []string{"ada"}},
+		{"Got-X-Tenant", []string{"acme"}},
+		{"Got-X-Version", []string{"1", "2"}},
+		{"Got-Authorization", []string{"Bearer upstream-token"}},
+		{"Server", nil},
+		{"X-Debug-Host", nil},
+		{"X-Request-Cost", nil},
+		{"X-Cost", []string{"3"}},
+		{"X-Upstream-Cost", []string{"1"}},
+	}
+
+	for _, tc := range tests {
+		if values := rr.Header().Values(tc.header); strings.Join(values, ",") != strings.Join(tc.values, ",") {
+			t.Errorf("%s: expected %q, got %q", tc.header, tc.values, values)
+		}
+	}
+
+	failSecret.Store(true)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d without the secret, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	if p.Stats()[proxy.StableTarget].Requests != 1 {
+		t.Errorf("Expected the request without the secret not to be sent")
+	}
+}
+
+func TestProxyStreams(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ws":
+			conn, brw, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+			brw.Flush()
+			for {
+				line, err := brw.ReadString('\n')
+				if err != nil {
+					return
+				}
+				brw.WriteString("echo " + line)
+				brw.Flush()
+			}
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, event := range []string{"first", "second"} {
+				io.WriteString(w, "data: "+event+"\n\n")
+				http.NewResponseController(w).Flush()
+				time.Sleep(100 * time.Millisecond)
+			}
+			<-r.Context().Done()
+		}
+	}))
+	defer backend.Close()
+
+	p, err := proxy.New(proxy.Config{Target: backend.URL, StreamIdleTimeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	p.Mount(r, "/")
+	server := httptest.NewUnstartedServer(r)
+	// Streams outlive the write timeout of the server
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	t.Run("upgrade", func(t *testing.T) {
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: example.com\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("Expected status code %d, got %d", http.StatusSwitchingProtocols, resp.StatusCode)
+		}
+
+		for _, message := range []string{"hello", "world"} {
+			time.Sleep(100 * time.Millisecond)
+			io.WriteString(conn, message+"\n")
+			if line, err := reader.ReadString('\n'); err != nil || line != "echo "+message+"\n" {
+				t.Fatalf("Expected the echo of %q, got %q, %v", message, line, err)
+			}
+		}
+
+		// The idle timeout closes the connection
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, err := reader.ReadString('\n'); err != io.EOF {
+			t.Errorf("Expected the idle connection to be closed, got %v", err)
+		}
+	})
+
+	t.Run("event stream", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/events")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		// Events arrive while the stream is open, after the write timeout
+		reader := bufio.NewReader(resp.Body)
+		for _, event := range []string{"first", "second"} {
+			line, err := reader.ReadString('\n')
+			if err != nil || line != "data: "+event+"\n" {
+				t.Fatalf("Expected the %s event, got %q, %v", event, line, err)
+			}
+			reader.ReadString('\n')
+		}
+
+		start := time.Now()
+		io.Copy(io.Discard, resp.Body)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected the idle stream to be closed, took %s", elapsed)
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRetryBodySize is the largest request body that is buffered so it can be sent again, requests with larger bodies
+// or bodies of unknown length aren't retried
+const maxRetryBodySize = 1 << 20
+
+// budgetReserve is the number of retries the budget starts with and can save up, so services with little traffic
+// can still retry
+const budgetReserve = 10
+
+// RetryConfig retries idempotent requests that fail to reach an upstream, or get one of the RetryOn statuses. Retries
+// go to another upstream when there is one.
+type RetryConfig struct {
+	// Attempts is the number of tries including the first one, defaults to 3
+	Attempts int
+	// RetryOn are the statuses that are retried, defaults to 502, 503 and 504
+	RetryOn []int
+	// Backoff is the delay before the first retry, which doubles for every further retry up to MaxBackoff, with a
+	// random jitter. Defaults to 25 milliseconds and 1 second
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// TryTimeout limits the time of each try until the response headers arrive, 0 only applies the deadline of the
+	// request
+	TryTimeout time.Duration
+	// Budget is the share of requests that can be retried, defaults to 0.2. It stops retries from multiplying the
+	// load on upstreams that are down
+	Budget float64
+}
+
+var errTryTimeout = errors.New("proxy: try timed out")
+
+// proxyRequest is the state of a proxied request, the target changes when a retry goes to another upstream
+type proxyRequest struct {
+	target  *target
+	secrets http.Header
+	w       http.ResponseWriter
+	cancel  context.CancelFunc
+}
+
+type retryTransport struct {
+	next   http.RoundTripper
+	config RetryConfig
+
+	mutex  sync.Mutex
+	tokens float64
+}
+
+func newRetryTransport(next http.RoundTripper, config RetryConfig) *retryTransport {
+	if config.Attempts <= 0 {
+		config.Attempts = 3
+	}
+	if len(config.RetryOn) == 0 {
+		config.RetryOn = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = 25 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = time.Second
+	}
+	if config.Budget <= 0 {
+		config.Budget = 0.2
+	}
+	return &retryTransport{next: next, config: config, tokens: budgetReserve}
+}
+
+func (rt *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.deposit()
+	state := req.Context().Value(targetContextKey{}).(*proxyRequest)
+	if !retryable(req) {
+		return rt.try(req)
+	}
+
+	tried := []*target{state.target}
+	for attempt := 1; ; attempt++ {
+		resp, err := rt.try(req)
+		failed := err != nil || slices.Contains(rt.config.RetryOn, resp.StatusCode)
+		// Requests canceled by the client aren't retried
+		if !failed || attempt >= rt.config.Attempts || req.Context().Err() != nil || !rt.withdraw() {
+			return resp, err
+		}
+
+		t := state.target
+		t.errors.Add(1)
+		t.balancer.observe(t, true)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), rt.backoff(attempt)); err != nil {
+			return nil, err
+		}
+
+		next := t.balancer.pick(req, tried...)
+		tried = append(tried, next)
+		state.target = next
+		next.retries.Add(1)
+		req = retargeted(req, t, next)
+	}
+}
+
+// try sends the request once, with the try timeout
+func (rt *retryTransport) try(req *http.Request) (*http.Response, error) {
+	if req.GetBody != nil && req.Body != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	if rt.config.TryTimeout <= 0 {
+		return rt.next.RoundTrip(req)
+	}
+	// The timeout only applies until the response headers arrive, streaming the body can take longer
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timer := time.AfterFunc(rt.config.TryTimeout, func() { cancel(errTryTimeout) })
+	resp, err := rt.next.RoundTrip(req.WithContext(ctx))
+	timer.Stop()
+	if err != nil {
+		if errors.Is(context.Cause(ctx), errTryTimeout) {
+			err = errTryTimeout
+		}
+		cancel(nil)
+		return nil, err
+	}
+	// Upgraded connections need their writable body, they're closed with the request
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		return resp, nil
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: func() { cancel(nil) }}
+	return resp, nil
+}
+
+func (rt *retryTransport) backoff(attempt int) time.Duration {
+	backoff := min(rt.config.Backoff<<(attempt-1), rt.config.MaxBackoff)
+	// Full jitter between half and the whole backoff, so retries of many clients don't arrive at once
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+func (rt *retryTransport) deposit() {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.tokens = min(rt.tokens+rt.config.Budget, budgetReserve)
+}
+
+func (rt *retryTransport) withdraw() bool {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if rt.tokens < 1 {
+		return false
+	}
+	rt.tokens--
+	return true
+}
+
+// retryable reports whether the request is idempotent and its body can be sent again, the body is buffered when it's
+// small enough
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return true
+	}
+	if req.ContentLength <= 0 || req.ContentLength > maxRetryBodySize {
+		return false
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxRetryBodySize+1))
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return true
+}
+
+// retargeted returns the request for another upstream, with the path below the path of the new target
+func retargeted(req *http.Request, from *target, to *target) *http.Request {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = to.url.Scheme
+	req.URL.Host = to.url.Host
+	escaped := singleJoiningSlash(to.url.EscapedPath(), strings.TrimPrefix(req.URL.EscapedPath(), from.url.EscapedPath()))
+	if path, err := url.PathUnescape(escaped); err == nil {
+		req.URL.Path, req.URL.RawPath = path, escaped
+	}
+	req.Host = ""
+	return req
+}
+
+func singleJoiningSlash(a string, b string) string {
+	switch aslash, bslash := strings.HasSuffix(a, "/"), strings.HasPrefix(b, "/"); {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash && b != "":
+		return a + "/" + b
+	}
+	return a + b
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cancelBody cancels the context of the try once the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
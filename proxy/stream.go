@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// streaming reports whether the response is an upgraded connection, e.g. a WebSocket, or an event stream
+func streaming(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
+// stream prepares a streaming response: the read and write deadlines of the server are cleared, since streams outlive
+// them, and the request is canceled when no data flows for the idle timeout instead. Canceling the request also
+// closes upgraded connections.
+func (state *proxyRequest) stream(resp *http.Response, idleTimeout time.Duration) {
+	controller := http.NewResponseController(state.w)
+	controller.SetReadDeadline(time.Time{})
+	controller.SetWriteDeadline(time.Time{})
+
+	body := &idleBody{ReadCloser: resp.Body, timeout: idleTimeout}
+	body.timer = time.AfterFunc(idleTimeout, state.cancel)
+	if conn, ok := resp.Body.(io.ReadWriteCloser); ok {
+		resp.Body = &idleConn{idleBody: body, conn: conn}
+		return
+	}
+	resp.Body = body
+}
+
+// idleBody resets the idle timer whenever data is read from the upstream
+type idleBody struct {
+	io.ReadCloser
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.Stop()
+	return b.ReadCloser.Close()
+}
+
+// idleConn is the idleBody of an upgraded connection, which also resets the idle timer when data is written to the
+// upstream
+type idleConn struct {
+	*idleBody
+	conn io.ReadWriteCloser
+}
+
+func (c *idleConn) Write(p []byte) (int, error) {
+	n, err := c.conn.Write(p)
+	if n > 0 {
+		c.timer.Reset(c.timeout)
+	}
+	return n, err
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+)
+
+// PushAssets pushes the assets to the client using HTTP/2 server push when the connection supports it.
+// Otherwise preload Link headers are added to the response, which browsers and CDNs use to load the assets early.
+func (r *Route) PushAssets(paths ...string) *Route {
+	r.mustBeMutable()
+	r.pushAssets = append(r.pushAssets, paths...)
+	return r
+}
+
+func pushAssetsHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	if len(route.pushAssets) == 0 {
+		return handler
+	}
+
+	assets := route.pushAssets
+	return func(w http.ResponseWriter, req *http.Request) {
+		pusher, canPush := w.(http.Pusher)
+		for _, asset := range assets {
+			if canPush && pusher.Push(asset, nil) == nil {
+				continue
+			}
+			w.Header().Add("Link", preloadLink(asset))
+		}
+		handler(w, req)
+	}
+}
+
+func preloadLink(asset string) string {
+	as := ""
+	switch path.Ext(asset) {
+	case ".css":
+		as = "style"
+	case ".js", ".mjs":
+		as = "script"
+	case ".woff", ".woff2", ".ttf", ".otf":
+		as = "font"
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".avif", ".ico":
+		as = "image"
+	}
+	if as == "" {
+		return fmt.Sprintf("<%s>; rel=preload", asset)
+	}
+	if as == "font" {
+		// Fonts are always fetched in CORS mode, so the preload has to be too
+		return fmt.Sprintf("<%s>; rel=preload; as=font; crossorigin", asset)
+	}
+	return fmt.Sprintf("<%s>; rel=preload; as=%s", asset, as)
+}
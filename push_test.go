@@ -0,0 +1,50 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (p *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	p.pushed = append(p.pushed, target)
+	return nil
+}
+
+func TestPushAssets(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html></html>"))
+	}).PushAssets("/static/app.css", "/static/app.js")
+
+	t.Run("with push support", func(t *testing.T) {
+		rr := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if !reflect.DeepEqual(rr.pushed, []string{"/static/app.css", "/static/app.js"}) {
+			t.Errorf("unexpected pushed assets: %v", rr.pushed)
+		}
+		if links := rr.Header().Values("Link"); len(links) != 0 {
+			t.Errorf("expected no Link headers when pushing, got %v", links)
+		}
+	})
+
+	t.Run("without push support", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		expected := []string{"</static/app.css>; rel=preload; as=style", "</static/app.js>; rel=preload; as=script"}
+		if links := rr.Header().Values("Link"); !reflect.DeepEqual(links, expected) {
+			t.Errorf("unexpected Link headers: got %v want %v", links, expected)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Query returns the parsed query of the request. It's parsed once per request for matched routes, so middlewares
+// and handlers reading the query share the result. The values must not be changed, copy them first.
+func Query(r *http.Request) url.Values {
+	c, _ := r.Context().Value(requestContextKey).(*requestContext)
+	if c == nil {
+		return r.URL.Query()
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// A middleware may have changed the query
+	if c.query == nil || c.rawQuery != r.URL.RawQuery {
+		c.query = r.URL.Query()
+		c.rawQuery = r.URL.RawQuery
+	}
+	return c.query
+}
+
+// QueryInt returns the query parameter as int, 0 when it's missing. Invalid values return a *ParamError.
+func QueryInt(r *http.Request, name string) (int, error) {
+	return QueryParam[int](r, name)
+}
+
+// QueryBool returns the query parameter as bool, false when it's missing. Invalid values return a *ParamError.
+func QueryBool(r *http.Request, name string) (bool, error) {
+	return QueryParam[bool](r, name)
+}
+
+// QuerySlice returns all values of the query parameter, comma separated values are split, so "?tag=a,b&tag=c"
+// returns a, b and c. Empty values are skipped.
+func QuerySlice(r *http.Request, name string) []string {
+	return splitCommas(Query(r)[name])
+}
@@ -0,0 +1,61 @@
+package router_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestQuery(t *testing.T) {
+	// Create a new router instance, with a middleware that reads the query before the handler
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			router.Query(req)
+			next(w, req)
+		}
+	})
+
+	var first, second map[string][]string
+	var page int
+	var pageErr error
+	var archived bool
+	var tags []string
+	r.GET("/posts", func(w http.ResponseWriter, req *http.Request) {
+		first, second = router.Query(req), router.Query(req)
+		page, pageErr = router.QueryInt(req, "page")
+		archived, _ = router.QueryBool(req, "archived")
+		tags = router.QuerySlice(req, "tag")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/?page=2&archived=true&tag=go,http&tag=&tag=router", nil))
+	if reflect.ValueOf(first).Pointer() != reflect.ValueOf(second).Pointer() {
+		t.Error("Expected the query to be parsed once per request")
+	}
+	if page != 2 || pageErr != nil || !archived {
+		t.Errorf("Expected page 2 and archived, got %d, %v and %v", page, pageErr, archived)
+	}
+	if want := []string{"go", "http", "router"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, tags)
+	}
+
+	// Invalid values return a *ParamError
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/?page=two", nil))
+	var paramErr *router.ParamError
+	if !errors.As(pageErr, &paramErr) || paramErr.Name != "page" {
+		t.Errorf("Expected a *ParamError for page, got %v", pageErr)
+	}
+
+	// Outside of a route the query is parsed every time
+	req := httptest.NewRequest(http.MethodGet, "/?page=3", nil)
+	if n, err := router.QueryInt(req, "page"); n != 3 || err != nil {
+		t.Errorf("Expected page 3, got %d and %v", n, err)
+	}
+	if n, err := router.QueryInt(req, "missing"); n != 0 || err != nil {
+		t.Errorf("Expected 0 for a missing parameter, got %d and %v", n, err)
+	}
+}
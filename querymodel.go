@@ -0,0 +1,78 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// QueryModel binds the query of every request into a new value of the struct type of model before the handler is
+// called, which reads it with QueryModelOf. It's bound like bind.Query, see BindValues: fields are matched by their
+// query tag, their form tag or their name, and slices take repeated and comma separated values. When the struct has
+// a Validate() error method it's called after binding. Invalid queries are rejected with 400.
+//
+// The model is stored as "query" metadata, so generators can document the query parameters of the route.
+//
+//	type ListUsersParams struct {
+//		Sort   string   `query:"sort"`
+//		Status []string `query:"status"`
+//		Limit  int      `query:"limit"`
+//	}
+//
+//	r.GET("/users", listUsers).QueryModel(ListUsersParams{})
+func (r *Route) QueryModel(model any) *Route {
+	t := reflect.TypeOf(model)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("router: the query model of %s must be a struct, got %T", r.Pattern, model))
+	}
+	return r.Set("query", reflect.New(t).Elem().Interface())
+}
+
+// QueryModelOf returns the query bound by Route.QueryModel, false when the route has no query model of type T.
+//
+//	params, _ := router.QueryModelOf[ListUsersParams](r)
+func QueryModelOf[T any](r *http.Request) (T, bool) {
+	c, _ := r.Context().Value(requestContextKey).(*requestContext)
+	if c != nil {
+		if model, ok := c.queryModel.(*T); ok {
+			return *model, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// queryModelHandler binds the query model of the route before calling the handler
+func (r *Router) queryModelHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	value, ok := route.Get("query")
+	if !ok {
+		return handler
+	}
+	t := reflect.TypeOf(value)
+	return func(w http.ResponseWriter, req *http.Request) {
+		model := reflect.New(t)
+		if err := BindValues(Query(req), model.Interface(), QueryBindConfig(req)); err != nil {
+			Error(w, req, http.StatusBadRequest, err)
+			return
+		}
+		if validator, ok := model.Interface().(interface{ Validate() error }); ok {
+			if err := validator.Validate(); err != nil {
+				Error(w, req, http.StatusBadRequest, err)
+				return
+			}
+		}
+		if c, _ := req.Context().Value(requestContextKey).(*requestContext); c != nil {
+			c.queryModel = model.Interface()
+		}
+		handler(w, req)
+	}
+}
+
+// QueryBindConfig is the BindConfig for query strings, used by QueryModel and bind.Query. Fields are named by their
+// query or form tag, slices are split at commas and times are parsed with the layouts of the router.
+func QueryBindConfig(r *http.Request) BindConfig {
+	return BindConfig{Tags: []string{"query", "form"}, SplitCommas: true, TimeLayouts: TimeLayouts(r)}
+}
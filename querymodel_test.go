@@ -0,0 +1,78 @@
+package router_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+type listUsersParams struct {
+	Sort    string   `query:"sort"`
+	Status  []string `query:"status"`
+	Limit   int      `query:"limit"`
+	Deleted *bool    `query:"deleted"`
+	Page    int
+	ignored string
+}
+
+func (p *listUsersParams) Validate() error {
+	if p.Limit > 100 {
+		return errors.New("limit must be at most 100")
+	}
+	return nil
+}
+
+func TestQueryModel(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	route := r.GET("/users", func(w http.ResponseWriter, r *http.Request) {
+		params, ok := router.QueryModelOf[listUsersParams](r)
+		if !ok {
+			t.Error("Expected the query model")
+		}
+		fmt.Fprintf(w, "%s %v %d %v %d", params.Sort, params.Status, params.Limit, params.Deleted != nil && *params.Deleted, params.Page)
+	}).QueryModel(listUsersParams{})
+	r.GET("/posts", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := router.QueryModelOf[listUsersParams](r); ok {
+			t.Error("Expected no query model for a route without one")
+		}
+	})
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		body   string
+	}{
+		{"empty", "", http.StatusOK, " [] 0 false 0"},
+		{"values", "?sort=name&status=active,invited&status=blocked&limit=10&deleted=true&Page=2", http.StatusOK, "name [active invited blocked] 10 true 2"},
+		{"invalid value", "?limit=ten", http.StatusBadRequest, "limit"},
+		{"validation", "?limit=1000", http.StatusBadRequest, "at most 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/"+tt.query, nil))
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.body) {
+				t.Errorf("Expected body %q, got %q", tt.body, rr.Body.String())
+			}
+		})
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/", nil))
+
+	// The model is stored as metadata for generators
+	if model, ok := route.Get("query"); !ok || fmt.Sprintf("%T", model) != "router_test.listUsersParams" {
+		t.Errorf("Expected the model as metadata, got %T", model)
+	}
+}
@@ -0,0 +1,165 @@
+// Package quota counts requests per API key per route and enforces a quota for every window.
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+// Store keeps track of usage per API key and route for a window.
+type Store interface {
+	// Increment adds one request for the API key and route in the window starting at windowStart and returns the new
+	// count. When the limit is above 0 and the request would exceed it, it isn't counted and the count plus one is
+	// returned, so only admitted requests use up the quota.
+	Increment(ctx context.Context, apiKey string, route string, windowStart time.Time, window time.Duration, limit int64) (int64, error)
+	// Usage returns the amount of requests per route for the API key in the window starting at windowStart.
+	Usage(ctx context.Context, apiKey string, windowStart time.Time) (map[string]int64, error)
+}
+
+// ErrQuotaExceeded is the error passed to the router's error handler when a quota is used up.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+type Config struct {
+	// Store is where usage is kept, defaults to a new MemoryStore
+	Store Store
+	// Limit is the amount of requests per route allowed in a window, 0 means usage is only counted
+	Limit int64
+	// Window is the duration of a quota window, defaults to 24 hours
+	Window time.Duration
+	// LimitKey is the route metadata key that can override the limit for a route, defaults to "quota"
+	LimitKey string
+	// KeyFunc returns the API key for the request, defaults to the X-API-Key header. Requests without a key are not counted
+	KeyFunc func(r *http.Request) string
+}
+
+type Quota struct {
+	config Config
+	// limits remembers the limit per route pattern, so the report can include route specific limits
+	limits sync.Map
+}
+
+func New(config Config) *Quota {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.Window <= 0 {
+		config.Window = 24 * time.Hour
+	}
+	if config.LimitKey == "" {
+		config.LimitKey = "quota"
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(r *http.Request) string {
+			return r.Header.Get("X-API-Key")
+		}
+	}
+	return &Quota{config: config}
+}
+
+// Middleware counts the request for the matched route and rejects it with 429 when the quota is exhausted. Rejected
+// requests aren't counted.
+func (q *Quota) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiKey := q.config.KeyFunc(r)
+		route := router.MatchedRoute(r)
+		if apiKey == "" || route == nil {
+			next(w, r)
+			return
+		}
+
+		windowStart := q.windowStart()
+		limit := q.limitFor(route)
+		q.limits.Store(route.FullPattern(), limit)
+		used, err := q.config.Store.Increment(r.Context(), apiKey, route.FullPattern(), windowStart, q.config.Window, limit)
+		if err != nil {
+			router.Error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if limit > 0 {
+			reset := windowStart.Add(q.config.Window)
+			w.Header().Set("X-Quota-Limit", strconv.FormatInt(limit, 10))
+			w.Header().Set("X-Quota-Remaining", strconv.FormatInt(max(limit-used, 0), 10))
+			w.Header().Set("X-Quota-Reset", strconv.FormatInt(reset.Unix(), 10))
+			if used > limit {
+				w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(reset).Seconds())+1))
+				router.Error(w, r, http.StatusTooManyRequests, ErrQuotaExceeded)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+type RouteUsage struct {
+	Used      int64  `json:"used"`
+	Limit     *int64 `json:"limit,omitempty"`
+	Remaining *int64 `json:"remaining,omitempty"`
+}
+
+type Report struct {
+	WindowStart time.Time             `json:"window_start"`
+	WindowEnd   time.Time             `json:"window_end"`
+	Routes      map[string]RouteUsage `json:"routes"`
+}
+
+// Report is a handler responding with the usage of the API key making the request in the current window.
+func (q *Quota) Report(w http.ResponseWriter, r *http.Request) {
+	apiKey := q.config.KeyFunc(r)
+	if apiKey == "" {
+		router.Error(w, r, http.StatusUnauthorized, nil)
+		return
+	}
+
+	windowStart := q.windowStart()
+	usage, err := q.config.Store.Usage(r.Context(), apiKey, windowStart)
+	if err != nil {
+		router.Error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	report := Report{
+		WindowStart: windowStart,
+		WindowEnd:   windowStart.Add(q.config.Window),
+		Routes:      make(map[string]RouteUsage, len(usage)),
+	}
+	for route, used := range usage {
+		routeUsage := RouteUsage{Used: used}
+		limit := q.config.Limit
+		if routeLimit, ok := q.limits.Load(route); ok {
+			limit = routeLimit.(int64)
+		}
+		if limit > 0 {
+			remaining := max(limit-used, 0)
+			routeUsage.Limit = &limit
+			routeUsage.Remaining = &remaining
+		}
+		report.Routes[route] = routeUsage
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (q *Quota) windowStart() time.Time {
+	return time.Now().Truncate(q.config.Window)
+}
+
+func (q *Quota) limitFor(route *router.Route) int64 {
+	if value, ok := route.Get(q.config.LimitKey); ok {
+		switch limit := value.(type) {
+		case int:
+			return int64(limit)
+		case int64:
+			return limit
+		}
+	}
+	return q.config.Limit
+}
@@ -0,0 +1,155 @@
+package quota_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/quota"
+)
+
+func TestQuota(t *testing.T) {
+	q := quota.New(quota.Config{Limit: 2})
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(q.Middleware)
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}
+	r.GET("/search", ok)
+	r.GET("/export", ok).Set("quota", 1)
+	r.GET("/quota", q.Report)
+
+	do := func(path string, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Define test cases, requests are executed in order
+	tests := []struct {
+		path       string
+		apiKey     string
+		statusCode int
+		remaining  string
+	}{
+		{"/search/", "key-a", http.StatusOK, "1"},
+		{"/search/", "key-a", http.StatusOK, "0"},
+		{"/search/", "key-a", http.StatusTooManyRequests, "0"},
+		{"/search/", "key-b", http.StatusOK, "1"},
+		{"/export/", "key-a", http.StatusOK, "0"},
+		{"/export/", "key-a", http.StatusTooManyRequests, "0"},
+		{"/search/", "", http.StatusOK, ""},
+	}
+
+	for i, tt := range tests {
+		rr := do(tt.path, tt.apiKey)
+		if rr.Code != tt.statusCode {
+			t.Errorf("request %d returned wrong status code: got %v want %v", i, rr.Code, tt.statusCode)
+		}
+		if remaining := rr.Header().Get("X-Quota-Remaining"); remaining != tt.remaining {
+			t.Errorf("request %d returned wrong remaining quota: got %v want %v", i, remaining, tt.remaining)
+		}
+	}
+
+	// The report should contain the usage of the API key per route, without the rejected requests
+	rr := do("/quota/", "key-a")
+	var report quota.Report
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	search := report.Routes["GET /search/{$}"]
+	if search.Used != 2 || search.Limit == nil || *search.Limit != 2 {
+		t.Errorf("unexpected usage for search: %+v", search)
+	}
+	export := report.Routes["GET /export/{$}"]
+	if export.Used != 1 || export.Limit == nil || *export.Limit != 1 {
+		t.Errorf("unexpected usage for export: %+v", export)
+	}
+}
+
+// fakeRedis is a RedisClient keeping hashes in memory
+type fakeRedis struct {
+	mutex   sync.Mutex
+	hashes  map[string]map[string]int64
+	expires map[string]time.Duration
+}
+
+func (f *fakeRedis) HIncrBy(ctx context.Context, key string, field string, increment int64) (int64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.hashes[key] == nil {
+		f.hashes[key] = make(map[string]int64)
+	}
+	f.hashes[key][field] += increment
+	return f.hashes[key][field], nil
+}
+
+func (f *fakeRedis) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	values := make(map[string]string)
+	for field, value := range f.hashes[key] {
+		values[field] = strconv.FormatInt(value, 10)
+	}
+	return values, nil
+}
+
+func (f *fakeRedis) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.expires[key] = ttl
+	return nil
+}
+
+func TestRedisStore(t *testing.T) {
+	client := &fakeRedis{hashes: make(map[string]map[string]int64), expires: make(map[string]time.Duration)}
+	store := &quota.RedisStore{Client: client, Prefix: "test:"}
+	ctx := context.Background()
+	windowStart := time.Now().Truncate(time.Hour)
+
+	// Define test cases, requests are executed in order
+	tests := []struct {
+		apiKey string
+		limit  int64
+		used   int64
+	}{
+		{"key:a", 2, 1},
+		{"key:a", 2, 2},
+		{"key:a", 2, 3},
+		{"key:a", 2, 3},
+		{"key:b", 0, 1},
+	}
+
+	for i, tt := range tests {
+		used, err := store.Increment(ctx, tt.apiKey, "GET /search/{$}", windowStart, time.Hour, tt.limit)
+		if err != nil || used != tt.used {
+			t.Errorf("request %d: expected %d, got %d, %v", i, tt.used, used, err)
+		}
+	}
+
+	// Rejected requests aren't counted
+	usage, err := store.Usage(ctx, "key:a", windowStart)
+	if err != nil || usage["GET /search/{$}"] != 2 {
+		t.Errorf("expected 2 admitted requests, got %v, %v", usage, err)
+	}
+	key := "test:key:a:" + strconv.FormatInt(windowStart.Unix(), 10)
+	if ttl, until := client.expires[key], time.Until(windowStart.Add(time.Hour)); ttl < until+59*time.Second || ttl > until+61*time.Second {
+		t.Errorf("expected %s to expire a minute after the window, got %s", key, ttl)
+	}
+	if usage, err := store.Usage(ctx, "key:c", windowStart); err != nil || len(usage) != 0 {
+		t.Errorf("expected no usage for an unknown key, got %v, %v", usage, err)
+	}
+}
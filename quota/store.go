@@ -0,0 +1,134 @@
+package quota
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type memoryWindow struct {
+	expiresAt time.Time
+	routes    map[string]int64
+}
+
+// memoryKey is the key of a window in a MemoryStore, a struct so API keys can't collide with the window of another key
+type memoryKey struct {
+	apiKey      string
+	windowStart int64
+}
+
+// MemoryStore is an in-memory Store, it is only suitable for a single instance.
+type MemoryStore struct {
+	mutex   sync.Mutex
+	windows map[memoryKey]*memoryWindow
+	swept   time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{windows: make(map[memoryKey]*memoryWindow)}
+}
+
+func (s *MemoryStore) Increment(ctx context.Context, apiKey string, route string, windowStart time.Time, window time.Duration, limit int64) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	// Expired windows are removed once per window, not on every request
+	if now.Sub(s.swept) > window {
+		for key, w := range s.windows {
+			if now.After(w.expiresAt) {
+				delete(s.windows, key)
+			}
+		}
+		s.swept = now
+	}
+
+	key := memoryKey{apiKey: apiKey, windowStart: windowStart.Unix()}
+	w, ok := s.windows[key]
+	if !ok {
+		w = &memoryWindow{expiresAt: windowStart.Add(window), routes: make(map[string]int64)}
+		s.windows[key] = w
+	}
+	if limit > 0 && w.routes[route] >= limit {
+		return w.routes[route] + 1, nil
+	}
+	w.routes[route]++
+	return w.routes[route], nil
+}
+
+func (s *MemoryStore) Usage(ctx context.Context, apiKey string, windowStart time.Time) (map[string]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	usage := make(map[string]int64)
+	if w, ok := s.windows[memoryKey{apiKey: apiKey, windowStart: windowStart.Unix()}]; ok {
+		for route, used := range w.routes {
+			usage[route] = used
+		}
+	}
+	return usage, nil
+}
+
+// RedisClient is the subset of a Redis client used by RedisStore. It's an interface so this package doesn't depend on
+// a specific Redis library, wrapping go-redis or redigo only takes a few lines.
+type RedisClient interface {
+	HIncrBy(ctx context.Context, key string, field string, increment int64) (int64, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// RedisStore keeps usage in a Redis hash per API key and window, so it can be shared between instances. Requests
+// beyond the limit are counted and taken back right away, so concurrent requests can be rejected while the count is
+// briefly above the limit, but the quota is only used up by admitted requests.
+type RedisStore struct {
+	Client RedisClient
+	// Prefix is prepended to every key, defaults to "quota:"
+	Prefix string
+}
+
+func (s *RedisStore) Increment(ctx context.Context, apiKey string, route string, windowStart time.Time, window time.Duration, limit int64) (int64, error) {
+	key := s.key(apiKey, windowStart)
+	used, err := s.Client.HIncrBy(ctx, key, route, 1)
+	if err != nil {
+		return 0, err
+	}
+	if limit > 0 && used > limit {
+		if _, err := s.Client.HIncrBy(ctx, key, route, -1); err != nil {
+			return 0, err
+		}
+		return used, nil
+	}
+	if used == 1 {
+		// Keep the key a little longer than the window, so the report for the window is still available at the end
+		if err := s.Client.Expire(ctx, key, time.Until(windowStart.Add(window))+time.Minute); err != nil {
+			return 0, err
+		}
+	}
+	return used, nil
+}
+
+func (s *RedisStore) Usage(ctx context.Context, apiKey string, windowStart time.Time) (map[string]int64, error) {
+	values, err := s.Client.HGetAll(ctx, s.key(apiKey, windowStart))
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]int64, len(values))
+	for route, value := range values {
+		used, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		usage[route] = used
+	}
+	return usage, nil
+}
+
+func (s *RedisStore) key(apiKey string, windowStart time.Time) string {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = "quota:"
+	}
+	// The window is the last part and only has digits, so API keys with colons can't collide
+	return prefix + apiKey + ":" + strconv.FormatInt(windowStart.Unix(), 10)
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// Reloader serves a router that is built again whenever one of the watched files changes, e.g. the route manifests
+// and templates of config driven routing. The new router replaces the old one atomically, requests in flight finish
+// on the router they started on. It's meant for development, to shorten the edit-refresh loop.
+type Reloader struct {
+	build       func(ctx context.Context) (*Router, error)
+	paths       []string
+	current     atomic.Pointer[Router]
+	fingerprint string
+}
+
+// NewReloader builds the first router, an error is returned when that fails. The paths are files or directories,
+// which are watched including their subdirectories.
+//
+//	reloader, err := router.NewReloader(ctx, buildRoutes, "routes.json", "templates")
+//	go reloader.Watch(ctx, time.Second)
+//	http.ListenAndServe(":8000", reloader)
+func NewReloader(ctx context.Context, build func(ctx context.Context) (*Router, error), paths ...string) (*Reloader, error) {
+	rl := &Reloader{build: build, paths: paths}
+	rl.fingerprint = rl.scan()
+	if err := rl.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return rl, nil
+}
+
+func (rl *Reloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	rl.current.Load().ServeHTTP(w, req)
+}
+
+// Router returns the router that currently serves requests.
+func (rl *Reloader) Router() *Router {
+	return rl.current.Load()
+}
+
+// Reload builds a new router and swaps it in. When building or setting up the routes fails the error is returned and
+// the current router keeps serving.
+func (rl *Reloader) Reload(ctx context.Context) (err error) {
+	r, err := rl.build(ctx)
+	if err != nil {
+		return err
+	}
+	// Setting up the routes panics for invalid patterns, which shouldn't stop the development server
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("router: reload failed: %v", recovered)
+		}
+	}()
+	r.setup()
+	rl.current.Store(r)
+	return nil
+}
+
+// Watch checks the watched files for changes every interval and reloads the router when they changed, until the
+// context is canceled. Failed reloads are logged to the logger of the current router.
+func (rl *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		fingerprint := rl.scan()
+		if fingerprint == rl.fingerprint {
+			continue
+		}
+		rl.fingerprint = fingerprint
+		if err := rl.Reload(ctx); err != nil {
+			rl.Router().Logger().Error("router: reloading the routes failed", "error", err)
+			continue
+		}
+		rl.Router().Logger().Info("router: reloaded the routes")
+	}
+}
+
+// scan returns the names, sizes and modification times of the watched files, polling them works without
+// platform specific file system notifications
+func (rl *Reloader) scan() string {
+	var fingerprint []byte
+	for _, path := range rl.paths {
+		filepath.WalkDir(path, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				// Removed files change the fingerprint as well
+				return nil
+			}
+			info, err := os.Stat(name)
+			if err != nil {
+				return nil
+			}
+			fingerprint = fmt.Appendf(fingerprint, "%s|%d|%d\n", name, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+	}
+	return string(fingerprint)
+}
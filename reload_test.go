@@ -0,0 +1,95 @@
+package router_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+// buildFromFile registers a route for every "path text" line of the file, like a minimal config driven router
+func buildFromFile(file string) func(ctx context.Context) (*router.Router, error) {
+	return func(ctx context.Context) (*router.Router, error) {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		r := router.NewRouter()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			path, text, _ := strings.Cut(scanner.Text(), " ")
+			r.GET(path, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(text))
+			})
+		}
+		return r, scanner.Err()
+	}
+}
+
+func TestReloader(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "routes.txt")
+	write := func(content string, age time.Duration) {
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Make sure the modification time changes, even on file systems with a coarse resolution
+		modTime := time.Now().Add(age)
+		os.Chtimes(file, modTime, modTime)
+	}
+	get := func(reloader *router.Reloader, path string) string {
+		w := httptest.NewRecorder()
+		reloader.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Body.String()
+	}
+
+	write("/hello Hello", -time.Hour)
+	reloader, err := router.NewReloader(context.Background(), buildFromFile(file), file)
+	if err != nil {
+		t.Fatalf("Failed to create the reloader: %v", err)
+	}
+	if body := get(reloader, "/hello/"); body != "Hello" {
+		t.Fatalf("Expected the initial route, got %q", body)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watching := make(chan struct{})
+	go func() {
+		reloader.Watch(ctx, 10*time.Millisecond)
+		close(watching)
+	}()
+
+	// Changing the file reloads the routes
+	write("/hello Hi\n/bye Bye", -time.Minute)
+	deadline := time.Now().Add(5 * time.Second)
+	for get(reloader, "/bye/") != "Bye" {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the routes to be reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if body := get(reloader, "/hello/"); body != "Hi" {
+		t.Errorf("Expected the changed route, got %q", body)
+	}
+
+	// Invalid routes keep the current router serving, the watcher is stopped so it can't reload in between
+	cancel()
+	<-watching
+	previous := reloader.Router()
+	write("/files/{path...}/raw Broken", 0)
+	if err := reloader.Reload(context.Background()); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	if reloader.Router() != previous || get(reloader, "/bye/") != "Bye" {
+		t.Error("Expected the previous router to keep serving")
+	}
+}
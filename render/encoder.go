@@ -0,0 +1,155 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gogo-framework/router"
+)
+
+// Encoder encodes values for a content type, encoders are used by Negotiate to serve the format a client asks for.
+type Encoder interface {
+	ContentType() string
+	Encode(w io.Writer, v any) error
+}
+
+// ConditionalEncoder can be implemented by encoders that only support some values, e.g. protobuf messages.
+// Negotiate skips the encoder for values it can't encode.
+type ConditionalEncoder interface {
+	Encoder
+	CanEncode(v any) bool
+}
+
+var (
+	encodersMutex sync.RWMutex
+	encoders      = []Encoder{jsonEncoder{}, msgpackEncoder{}, protoEncoder{}}
+)
+
+// RegisterEncoder adds an encoder used by Negotiate, an encoder for an already registered content type replaces it.
+func RegisterEncoder(encoder Encoder) {
+	encodersMutex.Lock()
+	defer encodersMutex.Unlock()
+
+	for i, e := range encoders {
+		if e.ContentType() == encoder.ContentType() {
+			encoders[i] = encoder
+			return
+		}
+	}
+	encoders = append(encoders, encoder)
+}
+
+// JSON writes the value as JSON with the given status code.
+func JSON(w http.ResponseWriter, code int, v any) error {
+	return Encode(w, code, jsonEncoder{}, v)
+}
+
+// Encode writes the value using the encoder with the given status code. The value is encoded into a pooled buffer
+// first, so nothing is written when encoding fails and the handler can still respond with an error.
+func Encode(w http.ResponseWriter, code int, encoder Encoder, v any) error {
+	return encode(w, nil, code, encoder, v)
+}
+
+// Negotiate writes the value in the format that best matches the Accept header of the request.
+// JSON is used when the client doesn't send an Accept header or none of the registered encoders match it.
+func Negotiate(w http.ResponseWriter, r *http.Request, code int, v any) error {
+	router.Vary(w, "Accept")
+	return encode(w, r, code, NegotiateEncoder(r, v), v)
+}
+
+func encode(w http.ResponseWriter, r *http.Request, code int, encoder Encoder, v any) error {
+	// Protobuf messages have a fixed schema, so they can't be wrapped or renamed
+	if _, ok := encoder.(protoEncoder); !ok {
+		var err error
+		if v, err = router.SerializeResponse(w, code, v); err != nil {
+			return err
+		}
+	}
+	scratch := router.AcquireScratch(r)
+	defer scratch.Release()
+	if err := encoder.Encode(scratch, v); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", encoder.ContentType())
+	w.WriteHeader(code)
+	_, err := w.Write(scratch.Bytes())
+	return err
+}
+
+// NegotiateEncoder returns the registered encoder that best matches the Accept header of the request and can encode v.
+func NegotiateEncoder(r *http.Request, v any) Encoder {
+	encodersMutex.RLock()
+	defer encodersMutex.RUnlock()
+
+	for _, accepted := range parseAccept(r.Header.Get("Accept")) {
+		for _, encoder := range encoders {
+			if !matchMediaType(accepted, encoder.ContentType()) {
+				continue
+			}
+			if conditional, ok := encoder.(ConditionalEncoder); ok && !conditional.CanEncode(v) {
+				continue
+			}
+			return encoder
+		}
+	}
+	return encoders[0]
+}
+
+type acceptedType struct {
+	mediaType string
+	quality   float64
+}
+
+func parseAccept(header string) []string {
+	var accepted []acceptedType
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			accepted = append(accepted, acceptedType{mediaType: mediaType, quality: quality})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+
+	mediaTypes := make([]string, len(accepted))
+	for i, a := range accepted {
+		mediaTypes[i] = a.mediaType
+	}
+	return mediaTypes
+}
+
+func matchMediaType(accepted string, contentType string) bool {
+	if accepted == "*/*" || accepted == contentType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(accepted, "/*"); ok {
+		return strings.HasPrefix(contentType, prefix+"/")
+	}
+	return false
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string {
+	return "application/json"
+}
+
+func (jsonEncoder) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,113 @@
+package render_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router/render"
+)
+
+type user struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Password string `json:"-"`
+}
+
+type protoUser struct {
+	user
+}
+
+func (u protoUser) Marshal() ([]byte, error) {
+	return []byte{0x08, byte(u.ID)}, nil
+}
+
+func TestMarshalMsgpack(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"bool", true, []byte{0xc3}},
+		{"positive fixint", 5, []byte{0x05}},
+		{"negative fixint", -3, []byte{0xfd}},
+		{"uint16", 1000, []byte{0xcd, 0x03, 0xe8}},
+		{"int8", -100, []byte{0xd0, 0x9c}},
+		{"float64", 1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"fixstr", "hi", []byte{0xa2, 'h', 'i'}},
+		{"bytes", []byte{1, 2}, []byte{0xc4, 0x02, 1, 2}},
+		{"array", []int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{"map", map[string]int{"a": 1}, []byte{0x81, 0xa1, 'a', 0x01}},
+		{"struct", user{ID: 1, Name: "J", Password: "secret"}, []byte{0x82, 0xa2, 'i', 'd', 0x01, 0xa4, 'n', 'a', 'm', 'e', 0xa1, 'J'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := render.MarshalMsgpack(tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(b, tt.expected) {
+				t.Errorf("unexpected encoding: got %x want %x", b, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		value       any
+		contentType string
+	}{
+		{"no accept header", "", user{ID: 1}, "application/json"},
+		{"json", "application/json", user{ID: 1}, "application/json"},
+		{"msgpack", "application/msgpack", user{ID: 1}, "application/msgpack"},
+		{"quality", "application/json;q=0.5, application/msgpack", user{ID: 1}, "application/msgpack"},
+		{"proto message", "application/x-protobuf", protoUser{user{ID: 1}}, "application/x-protobuf"},
+		{"proto unsupported value", "application/x-protobuf, application/json;q=0.1", user{ID: 1}, "application/json"},
+		{"unknown", "text/csv", user{ID: 1}, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+
+			if err := render.Negotiate(rr, req, http.StatusOK, tt.value); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("wrong content type: got %v want %v", ct, tt.contentType)
+			}
+			if rr.Header().Get("Vary") != "Accept" {
+				t.Errorf("expected Vary: Accept header")
+			}
+		})
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	// Channels can't be encoded as JSON, so nothing should be written
+	if err := render.JSON(rr, http.StatusOK, map[string]any{"updates": make(chan int)}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if rr.Header().Get("Content-Type") != "" || rr.Body.Len() != 0 {
+		t.Errorf("expected nothing to be written, got %q with %v", rr.Body.String(), rr.Header())
+	}
+
+	if err := render.JSON(rr, http.StatusCreated, user{ID: 1, Name: "Ann"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rr.Code != http.StatusCreated || rr.Body.String() != "{\"id\":1,\"name\":\"Ann\"}\n" {
+		t.Errorf("unexpected response %d %q", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,23 @@
+package render
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// EarlyHints sends a 103 Early Hints response with the given Link header values, so the client can start loading
+// assets while the handler is still working on the final response. The links are kept for the final response too.
+func EarlyHints(w http.ResponseWriter, links ...string) {
+	for _, link := range links {
+		w.Header().Add("Link", link)
+	}
+	w.WriteHeader(http.StatusEarlyHints)
+}
+
+// Preload formats a Link header value preloading the path, as is the destination, e.g. "style" or "script".
+func Preload(path string, as string) string {
+	if as == "" {
+		return fmt.Sprintf("<%s>; rel=preload", path)
+	}
+	return fmt.Sprintf("<%s>; rel=preload; as=%s", path, as)
+}
@@ -0,0 +1,24 @@
+package render_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gogo-framework/router/render"
+)
+
+func TestEarlyHints(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	render.EarlyHints(rr, render.Preload("/app.css", "style"), render.Preload("/data.json", ""))
+
+	expected := []string{"</app.css>; rel=preload; as=style", "</data.json>; rel=preload"}
+	if links := rr.Header().Values("Link"); !reflect.DeepEqual(links, expected) {
+		t.Errorf("unexpected Link headers: got %v want %v", links, expected)
+	}
+	if rr.Code != http.StatusEarlyHints {
+		t.Errorf("expected 103 status, got %v", rr.Code)
+	}
+}
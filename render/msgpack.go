@@ -0,0 +1,308 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MsgpackMarshaler can be implemented by types that encode themselves as MessagePack.
+type MsgpackMarshaler interface {
+	MarshalMsgpack() ([]byte, error)
+}
+
+// Msgpack writes the value as MessagePack with the given status code. Structs are encoded as maps using the
+// "msgpack" struct tag, falling back to the "json" tag and the field name.
+func Msgpack(w http.ResponseWriter, code int, v any) error {
+	return Encode(w, code, msgpackEncoder{}, v)
+}
+
+// MarshalMsgpack returns the MessagePack encoding of v.
+func MarshalMsgpack(v any) ([]byte, error) {
+	var b bytes.Buffer
+	if err := (msgpackEncoder{}).Encode(&b, v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) ContentType() string {
+	return "application/msgpack"
+}
+
+func (msgpackEncoder) Encode(w io.Writer, v any) error {
+	bw := bufio.NewWriter(w)
+	if err := encodeMsgpack(bw, reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+var (
+	msgpackMarshalerType = reflect.TypeOf((*MsgpackMarshaler)(nil)).Elem()
+	textMarshalerType    = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType             = reflect.TypeOf(time.Time{})
+)
+
+func encodeMsgpack(w *bufio.Writer, v reflect.Value) error {
+	if !v.IsValid() {
+		return w.WriteByte(0xc0)
+	}
+
+	if v.Type().Implements(msgpackMarshalerType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return w.WriteByte(0xc0)
+		}
+		b, err := v.Interface().(MsgpackMarshaler).MarshalMsgpack()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	if v.Type() == timeType {
+		return encodeMsgpackTime(w, v.Interface().(time.Time))
+	}
+	if v.Kind() != reflect.String && v.Type().Implements(textMarshalerType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return w.WriteByte(0xc0)
+		}
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		return encodeMsgpackString(w, string(text))
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return w.WriteByte(0xc0)
+		}
+		return encodeMsgpack(w, v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			return w.WriteByte(0xc3)
+		}
+		return w.WriteByte(0xc2)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encodeMsgpackInt(w, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return encodeMsgpackUint(w, v.Uint())
+	case reflect.Float32:
+		w.WriteByte(0xca)
+		return binary.Write(w, binary.BigEndian, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		w.WriteByte(0xcb)
+		return binary.Write(w, binary.BigEndian, math.Float64bits(v.Float()))
+	case reflect.String:
+		return encodeMsgpackString(w, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return w.WriteByte(0xc0)
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return encodeMsgpackBytes(w, v)
+		}
+		if err := writeMsgpackLength(w, v.Len(), 0x90, 0xdc, 0xdd, 15); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := encodeMsgpack(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.IsNil() {
+			return w.WriteByte(0xc0)
+		}
+		keys := v.MapKeys()
+		// Sort keys so the output is deterministic
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		if err := writeMsgpackLength(w, len(keys), 0x80, 0xde, 0xdf, 15); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := encodeMsgpack(w, key); err != nil {
+				return err
+			}
+			if err := encodeMsgpack(w, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		return encodeMsgpackStruct(w, v)
+	}
+	return fmt.Errorf("render: msgpack: unsupported type %s", v.Type())
+}
+
+type msgpackField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+func msgpackFields(t reflect.Type) []msgpackField {
+	var fields []msgpackField
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("msgpack")
+		if !ok {
+			tag = field.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, msgpackField{
+			name:      name,
+			index:     field.Index,
+			omitEmpty: strings.Contains(options, "omitempty"),
+		})
+	}
+	return fields
+}
+
+func encodeMsgpackStruct(w *bufio.Writer, v reflect.Value) error {
+	var values []reflect.Value
+	var names []string
+	for _, field := range msgpackFields(v.Type()) {
+		fv, err := v.FieldByIndexErr(field.index)
+		if err != nil {
+			// The field is in a nil embedded pointer
+			continue
+		}
+		if field.omitEmpty && fv.IsZero() {
+			continue
+		}
+		values = append(values, fv)
+		names = append(names, field.name)
+	}
+
+	if err := writeMsgpackLength(w, len(values), 0x80, 0xde, 0xdf, 15); err != nil {
+		return err
+	}
+	for i, fv := range values {
+		if err := encodeMsgpackString(w, names[i]); err != nil {
+			return err
+		}
+		if err := encodeMsgpack(w, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeMsgpackInt(w *bufio.Writer, n int64) error {
+	switch {
+	case n >= 0:
+		return encodeMsgpackUint(w, uint64(n))
+	case n >= -32:
+		return w.WriteByte(byte(n))
+	case n >= math.MinInt8:
+		w.WriteByte(0xd0)
+		return w.WriteByte(byte(n))
+	case n >= math.MinInt16:
+		w.WriteByte(0xd1)
+		return binary.Write(w, binary.BigEndian, int16(n))
+	case n >= math.MinInt32:
+		w.WriteByte(0xd2)
+		return binary.Write(w, binary.BigEndian, int32(n))
+	}
+	w.WriteByte(0xd3)
+	return binary.Write(w, binary.BigEndian, n)
+}
+
+func encodeMsgpackUint(w *bufio.Writer, n uint64) error {
+	switch {
+	case n <= 127:
+		return w.WriteByte(byte(n))
+	case n <= math.MaxUint8:
+		w.WriteByte(0xcc)
+		return w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(0xcd)
+		return binary.Write(w, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		w.WriteByte(0xce)
+		return binary.Write(w, binary.BigEndian, uint32(n))
+	}
+	w.WriteByte(0xcf)
+	return binary.Write(w, binary.BigEndian, n)
+}
+
+func encodeMsgpackString(w *bufio.Writer, s string) error {
+	switch n := len(s); {
+	case n <= 31:
+		w.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		w.WriteByte(0xd9)
+		w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(0xda)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(0xdb)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	}
+	_, err := w.WriteString(s)
+	return err
+}
+
+func encodeMsgpackBytes(w *bufio.Writer, v reflect.Value) error {
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	switch n := len(b); {
+	case n <= math.MaxUint8:
+		w.WriteByte(0xc4)
+		w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(0xc5)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(0xc6)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// encodeMsgpackTime uses the timestamp extension type (-1) in its 96-bit format.
+func encodeMsgpackTime(w *bufio.Writer, t time.Time) error {
+	w.Write([]byte{0xc7, 12, 0xff})
+	binary.Write(w, binary.BigEndian, uint32(t.Nanosecond()))
+	return binary.Write(w, binary.BigEndian, t.Unix())
+}
+
+// writeMsgpackLength writes the header for an array or map, using the fix format for small lengths.
+func writeMsgpackLength(w *bufio.Writer, n int, fix byte, code16 byte, code32 byte, fixMax int) error {
+	switch {
+	case n <= fixMax:
+		return w.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		w.WriteByte(code16)
+		return binary.Write(w, binary.BigEndian, uint16(n))
+	}
+	w.WriteByte(code32)
+	return binary.Write(w, binary.BigEndian, uint32(n))
+}
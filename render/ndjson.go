@@ -0,0 +1,104 @@
+package render
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// ndjsonFlushItems is the amount of values after which the response is flushed
+	ndjsonFlushItems = 100
+	// ndjsonFlushInterval is the longest time values are held back before the response is flushed
+	ndjsonFlushInterval = time.Second
+)
+
+// NDJSON streams the values of the sequence as newline delimited JSON. The response is flushed every 100 values, and
+// values are held back for at most a second, also while the sequence is slow to produce the next one. It stops at
+// the first encoding or write error.
+func NDJSON(w http.ResponseWriter, code int, seq iter.Seq[any]) error {
+	return NDJSONContext(context.Background(), w, code, seq)
+}
+
+// NDJSONContext is like NDJSON, but stops with the context error as soon as the context is done. Pass the request
+// context to stop producing values when the client disconnects.
+func NDJSONContext(ctx context.Context, w http.ResponseWriter, code int, seq iter.Seq[any]) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(code)
+
+	rc := http.NewResponseController(w)
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+
+	// The ticker flushes the values that are held back while the sequence blocks, the mutex serializes it with the
+	// writes of the loop
+	var mutex sync.Mutex
+	var flushErr error
+	pending := 0
+	flush := func() {
+		if flushErr == nil {
+			flushErr = bw.Flush()
+		}
+		if err := rc.Flush(); flushErr == nil && err != nil && !errors.Is(err, http.ErrNotSupported) {
+			flushErr = err
+		}
+		pending = 0
+	}
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ndjsonFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				mutex.Lock()
+				if pending > 0 {
+					flush()
+				}
+				mutex.Unlock()
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-stopped
+	}()
+
+	for value := range seq {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		mutex.Lock()
+		err := flushErr
+		if err == nil {
+			err = encoder.Encode(value)
+		}
+		if err == nil {
+			pending++
+			if pending >= ndjsonFlushItems {
+				flush()
+				err = flushErr
+			}
+		}
+		mutex.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	flush()
+	return flushErr
+}
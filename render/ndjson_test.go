@@ -0,0 +1,125 @@
+package render_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router/render"
+)
+
+func TestNDJSON(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	err := render.NDJSON(rr, http.StatusOK, func(yield func(any) bool) {
+		for i := 1; i <= 3; i++ {
+			if !yield(map[string]int{"id": i}) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"
+	if body := rr.Body.String(); body != expected {
+		t.Errorf("unexpected body: got %q want %q", body, expected)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("unexpected content type: %v", ct)
+	}
+	if !rr.Flushed {
+		t.Errorf("expected response to be flushed")
+	}
+}
+
+func TestNDJSONContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rr := httptest.NewRecorder()
+	produced := 0
+
+	err := render.NDJSONContext(ctx, rr, http.StatusOK, func(yield func(any) bool) {
+		for i := 0; i < 1000; i++ {
+			produced++
+			if i == 5 {
+				cancel()
+			}
+			if !yield(i) {
+				return
+			}
+		}
+	})
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if produced != 6 {
+		t.Errorf("expected iteration to stop after cancellation, produced %d values", produced)
+	}
+}
+
+// flushWriter keeps what was flushed, it can be read while the response is written
+type flushWriter struct {
+	mutex   sync.Mutex
+	header  http.Header
+	body    bytes.Buffer
+	flushed string
+}
+
+func (w *flushWriter) Header() http.Header { return w.header }
+
+func (w *flushWriter) WriteHeader(statusCode int) {}
+
+func (w *flushWriter) Write(b []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.body.Write(b)
+}
+
+func (w *flushWriter) Flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.flushed = w.body.String()
+}
+
+func (w *flushWriter) Flushed() string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.flushed
+}
+
+func TestNDJSONSlowProducer(t *testing.T) {
+	w := &flushWriter{header: make(http.Header)}
+	release := make(chan struct{})
+
+	done := make(chan error)
+	go func() {
+		done <- render.NDJSON(w, http.StatusOK, func(yield func(any) bool) {
+			if !yield(1) {
+				return
+			}
+			// The value is flushed while the next one is produced
+			<-release
+			yield(2)
+		})
+	}()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for w.Flushed() != "1\n" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := w.Flushed(); got != "1\n" {
+		t.Errorf("expected the first value to be flushed while the producer blocks, got %q", got)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := w.Flushed(); got != "1\n2\n" {
+		t.Errorf("expected all values to be flushed, got %q", got)
+	}
+}
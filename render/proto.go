@@ -0,0 +1,52 @@
+package render
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrProtoUnsupported is returned when a value can't be encoded as protobuf.
+var ErrProtoUnsupported = errors.New("render: value does not implement ProtoMarshaler and ProtoMarshal is not set")
+
+// ProtoMarshaler is implemented by messages generated by gogo/protobuf and similar generators.
+type ProtoMarshaler interface {
+	Marshal() ([]byte, error)
+}
+
+// ProtoMarshal is used for values that don't implement ProtoMarshaler. This package doesn't depend on a protobuf
+// library, so set it to a function calling proto.Marshal when using google.golang.org/protobuf messages.
+var ProtoMarshal func(v any) ([]byte, error)
+
+// Proto writes the message as protobuf with the given status code.
+func Proto(w http.ResponseWriter, code int, msg any) error {
+	return Encode(w, code, protoEncoder{}, msg)
+}
+
+type protoEncoder struct{}
+
+func (protoEncoder) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (protoEncoder) CanEncode(v any) bool {
+	_, ok := v.(ProtoMarshaler)
+	return ok || ProtoMarshal != nil
+}
+
+func (protoEncoder) Encode(w io.Writer, v any) error {
+	var b []byte
+	var err error
+	if marshaler, ok := v.(ProtoMarshaler); ok {
+		b, err = marshaler.Marshal()
+	} else if ProtoMarshal != nil {
+		b, err = ProtoMarshal(v)
+	} else {
+		return ErrProtoUnsupported
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
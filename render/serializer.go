@@ -0,0 +1,136 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gogo-framework/router"
+)
+
+// Envelope is a router.ResponseSerializer that wraps successful responses in {"data": ..., "meta": ...}. Meta is
+// called for every response and may be nil, responses with a status of 400 or higher are written as they are.
+type Envelope struct {
+	Meta func(r *http.Request) any
+}
+
+type envelope struct {
+	Data any `json:"data"`
+	Meta any `json:"meta,omitempty"`
+}
+
+func (e Envelope) Serialize(r *http.Request, status int, v any) (any, error) {
+	if status >= http.StatusBadRequest {
+		return v, nil
+	}
+	result := envelope{Data: v}
+	if e.Meta != nil {
+		result.Meta = e.Meta(r)
+	}
+	return result, nil
+}
+
+// KeyCase is a router.ResponseSerializer that converts the keys of JSON objects, so all responses use the same case
+// no matter how the structs are tagged.
+type KeyCase int
+
+const (
+	// SnakeCaseKeys converts keys like "createdAt" and "CreatedAt" to "created_at"
+	SnakeCaseKeys KeyCase = iota
+	// CamelCaseKeys converts keys like "created_at" and "CreatedAt" to "createdAt"
+	CamelCaseKeys
+)
+
+func (c KeyCase) Serialize(r *http.Request, status int, v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	// Numbers are kept as they are, large integers would lose precision as float64
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return c.convert(value), nil
+}
+
+func (c KeyCase) convert(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		converted := make(map[string]any, len(value))
+		for key, v := range value {
+			if c == SnakeCaseKeys {
+				key = snakeCase(key)
+			} else {
+				key = camelCase(key)
+			}
+			converted[key] = c.convert(v)
+		}
+		return converted
+	case []any:
+		for i, v := range value {
+			value[i] = c.convert(v)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// snakeCase converts "createdAt", "CreatedAt" and "userID" to "created_at", "created_at" and "user_id"
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, c := range runes {
+		if unicode.IsUpper(c) {
+			// A new word starts at an upper case letter after a lower case one, or before one in an acronym
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// camelCase converts "created_at" and "CreatedAt" to "createdAt"
+func camelCase(s string) string {
+	var b strings.Builder
+	upper := false
+	for i, c := range s {
+		switch {
+		case c == '_' || c == '-':
+			upper = b.Len() > 0
+			continue
+		case i == 0:
+			c = unicode.ToLower(c)
+		case upper:
+			c = unicode.ToUpper(c)
+		}
+		upper = false
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// Serializers applies the serializers in order, e.g. converting the keys before wrapping them in an envelope.
+func Serializers(serializers ...router.ResponseSerializer) router.ResponseSerializer {
+	return serializerChain(serializers)
+}
+
+type serializerChain []router.ResponseSerializer
+
+func (c serializerChain) Serialize(r *http.Request, status int, v any) (any, error) {
+	var err error
+	for _, serializer := range c {
+		if v, err = serializer.Serialize(r, status, v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,61 @@
+package render_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/render"
+)
+
+type account struct {
+	UserID    int    `json:"userID"`
+	CreatedAt string `json:"created_at"`
+	Profile   struct {
+		DisplayName string
+	} `json:"profile"`
+}
+
+func TestSerializers(t *testing.T) {
+	value := account{UserID: 1, CreatedAt: "today"}
+	value.Profile.DisplayName = "Alice"
+	meta := func(r *http.Request) any { return map[string]string{"version": r.URL.Query().Get("v")} }
+
+	// Define test cases
+	tests := []struct {
+		name       string
+		serializer router.ResponseSerializer
+		status     int
+		value      any
+		expected   string
+	}{
+		{"snake case", render.SnakeCaseKeys, http.StatusOK, value, `{"created_at":"today","profile":{"display_name":"Alice"},"user_id":1}`},
+		{"camel case", render.CamelCaseKeys, http.StatusOK, value, `{"createdAt":"today","profile":{"displayName":"Alice"},"userID":1}`},
+		{"slices", render.SnakeCaseKeys, http.StatusOK, []map[string]int{{"itemCount": 12345678901234}}, `[{"item_count":12345678901234}]`},
+		{"envelope", render.Envelope{Meta: meta}, http.StatusOK, []int{1, 2}, `{"data":[1,2],"meta":{"version":"2"}}`},
+		{"envelope without meta", render.Envelope{}, http.StatusCreated, 1, `{"data":1}`},
+		{"envelope skips errors", render.Envelope{Meta: meta}, http.StatusNotFound, map[string]string{"error": "not found"}, `{"error":"not found"}`},
+		{"chain", render.Serializers(render.SnakeCaseKeys, render.Envelope{}), http.StatusOK, map[string]int{"pageSize": 10}, `{"data":{"page_size":10}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new router instance
+			r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithResponseSerializer(tt.serializer))
+			r.GET("/", func(w http.ResponseWriter, req *http.Request) {
+				render.JSON(w, tt.status, tt.value)
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?v=2", nil))
+			if body := strings.TrimSpace(rec.Body.String()); body != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, body)
+			}
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Package render contains helpers for writing responses from handlers registered on the router.
+package render
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// DefaultChunkSize is the amount of bytes written per chunk by Stream and StreamContext.
+const DefaultChunkSize = 32 * 1024
+
+// ServeContentFrom serves the content of the given ReadSeeker, handling Range, If-Match, If-Modified-Since etc.
+// the same way http.ServeContent does. Reading stops as soon as the request context is canceled.
+func ServeContentFrom(w http.ResponseWriter, r *http.Request, name string, modtime time.Time, content io.ReadSeeker) {
+	http.ServeContent(w, r, name, modtime, &contextReadSeeker{ctx: r.Context(), ReadSeeker: content})
+}
+
+// Stream copies the reader to the response in chunks of chunkSize bytes, flushing after every chunk. It stops when
+// the reader is exhausted or at the first read or write error, which is returned.
+func Stream(w http.ResponseWriter, contentType string, src io.Reader, chunkSize int) error {
+	return StreamContext(context.Background(), w, contentType, src, chunkSize)
+}
+
+// StreamContext is like Stream, but stops with the context error as soon as the context is done. Pass the request
+// context to stop reading when the client disconnects.
+func StreamContext(ctx context.Context, w http.ResponseWriter, contentType string, src io.Reader, chunkSize int) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if contentType != "" && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	rc := http.NewResponseController(w)
+	buf := make([]byte, chunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			// Not every ResponseWriter supports flushing, in which case we just keep writing
+			_ = rc.Flush()
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type contextReadSeeker struct {
+	ctx context.Context
+	io.ReadSeeker
+}
+
+func (c *contextReadSeeker) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.ReadSeeker.Read(p)
+}
+
+// Chunked writes every chunk produced by the sequence and flushes it right away, so the client receives it even when
+// middlewares wrap the ResponseWriter. Trailers can be set on the header before or during iteration using
+// http.TrailerPrefix. It stops at the first write error, which is returned.
+func Chunked(w http.ResponseWriter, chunks iter.Seq[[]byte]) error {
+	return ChunkedContext(context.Background(), w, chunks)
+}
+
+// ChunkedContext is like Chunked, but stops with the context error as soon as the context is done. Pass the request
+// context to stop producing chunks when the client disconnects.
+func ChunkedContext(ctx context.Context, w http.ResponseWriter, chunks iter.Seq[[]byte]) error {
+	rc := http.NewResponseController(w)
+	for chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package render_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router/render"
+)
+
+func TestServeContentFromRange(t *testing.T) {
+	content := "0123456789"
+
+	tests := []struct {
+		rangeHeader string
+		statusCode  int
+		response    string
+	}{
+		{"", http.StatusOK, "0123456789"},
+		{"bytes=0-3", http.StatusPartialContent, "0123"},
+		{"bytes=5-", http.StatusPartialContent, "56789"},
+		{"bytes=20-30", http.StatusRequestedRangeNotSatisfiable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rangeHeader, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/video.mp4", nil)
+			if tt.rangeHeader != "" {
+				req.Header.Set("Range", tt.rangeHeader)
+			}
+			rr := httptest.NewRecorder()
+
+			render.ServeContentFrom(rr, req, "video.mp4", time.Time{}, strings.NewReader(content))
+
+			if status := rr.Code; status != tt.statusCode {
+				t.Errorf("wrong status code: got %v want %v", status, tt.statusCode)
+			}
+			if tt.statusCode != http.StatusRequestedRangeNotSatisfiable {
+				if body := rr.Body.String(); body != tt.response {
+					t.Errorf("unexpected body: got %v want %v", body, tt.response)
+				}
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	err := render.Stream(rr, "text/plain", strings.NewReader("hello streaming world"), 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body := rr.Body.String(); body != "hello streaming world" {
+		t.Errorf("unexpected body: got %v", body)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "text/plain" {
+		t.Errorf("unexpected content type: got %v", ct)
+	}
+	if !rr.Flushed {
+		t.Errorf("expected response to be flushed")
+	}
+}
+
+func TestStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rr := httptest.NewRecorder()
+
+	err := render.StreamContext(ctx, rr, "text/plain", strings.NewReader("never written"), 4)
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if rr.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %v", rr.Body.String())
+	}
+}
+
+func TestChunkedContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rr := httptest.NewRecorder()
+
+	chunks := func(yield func([]byte) bool) {
+		for _, chunk := range []string{"first", "second"} {
+			if !yield([]byte(chunk)) {
+				return
+			}
+			// The client disconnects after the first chunk
+			cancel()
+		}
+	}
+	err := render.ChunkedContext(ctx, rr, chunks)
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if rr.Body.String() != "first" {
+		t.Errorf("expected only the first chunk, got %v", rr.Body.String())
+	}
+}
@@ -1,11 +1,14 @@
 package router
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 type Middleware func(http.HandlerFunc) http.HandlerFunc
@@ -22,24 +25,143 @@ type Route struct {
 	Pattern     string
 	HandlerFunc http.HandlerFunc
 	Middlewares []Middleware
+	Metadata    map[string]any
+
+	fullPattern string
+	mount       bool
+	exact       bool
+	pushAssets  []string
+	name        string
+	group       *RouteGroup
+	warmups     []func(ctx context.Context) error
+	mirror      *mirror
+	inFlight    atomic.Int64
+	profile     []*layerStats
+	constraints []paramConstraint
+	file        string
+	line        int
+	// handler is the compiled handler, for custom matchers
+	handler http.HandlerFunc
+	// built is set when the router of the route was built by a Builder
+	built bool
 }
 
 func (r *Route) Use(middleware ...Middleware) *Route {
+	r.mustBeMutable()
 	r.Middlewares = append(r.Middlewares, middleware...)
 	return r
 }
 
+// Set stores a metadata value on the route, middlewares can read it using MatchedRoute(r).Get(key)
+func (r *Route) Set(key string, value any) *Route {
+	r.mustBeMutable()
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]any)
+	}
+	r.Metadata[key] = value
+	return r
+}
+
+// Get returns a metadata value of the route, or of its group when the route doesn't have it.
+func (r *Route) Get(key string) (any, bool) {
+	value, ok := r.Metadata[key]
+	if !ok && r.group != nil {
+		value, ok = r.group.Metadata[key]
+	}
+	return value, ok
+}
+
+// Require sets the permissions needed for the route, it's enforced by the authz package. They are required in
+// addition to the permissions of the group.
+func (r *Route) Require(permissions ...string) *Route {
+	return r.Set("permissions", permissions)
+}
+
+// Permissions returns the permissions the route requires, those of its group followed by its own.
+func (r *Route) Permissions() []string {
+	var permissions []string
+	if r.group != nil {
+		permissions, _ = r.group.Metadata["permissions"].([]string)
+	}
+	own, _ := r.Metadata["permissions"].([]string)
+	for _, permission := range own {
+		if !slices.Contains(permissions, permission) {
+			permissions = append(slices.Clip(permissions), permission)
+		}
+	}
+	return permissions
+}
+
+// RequireClientCert only allows requests with a verified client certificate, it's enforced by middleware.ClientCert.
+func (r *Route) RequireClientCert() *Route {
+	return r.Set("client-cert", true)
+}
+
+// FullPattern returns the pattern the route was registered on the mux with, including the method and group prefix.
+// It's empty until the routes have been set up.
+func (r *Route) FullPattern() string {
+	return r.fullPattern
+}
+
+// Path returns the path of the route without the method, trailing slash and "{$}", e.g. "/users/{id}".
+// It's empty until the routes have been set up.
+func (r *Route) Path() string {
+	return pathFromPattern(r.Method, r.fullPattern)
+}
+
+func pathFromPattern(method string, pattern string) string {
+	path := pattern
+	if method != "" {
+		path = strings.TrimPrefix(path, method+" ")
+	}
+	path = strings.TrimSuffix(path, "{$}")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
 type RouteGroup struct {
 	Prefix      string
 	Middlewares []Middleware
 	Routes      []*Route
+	Metadata    map[string]any
+
+	router *Router
 }
 
 func (rg *RouteGroup) Use(middleware ...Middleware) *RouteGroup {
+	rg.router.mustBeMutable()
 	rg.Middlewares = append(rg.Middlewares, middleware...)
 	return rg
 }
 
+// Set stores a metadata value for all routes of the group, routes can override it with their own value.
+func (rg *RouteGroup) Set(key string, value any) *RouteGroup {
+	rg.router.mustBeMutable()
+	if rg.Metadata == nil {
+		rg.Metadata = make(map[string]any)
+	}
+	rg.Metadata[key] = value
+	return rg
+}
+
+// Require sets the permissions needed for all routes of the group, it's enforced by the authz package.
+func (rg *RouteGroup) Require(permissions ...string) *RouteGroup {
+	return rg.Set("permissions", permissions)
+}
+
+// ForTenants restricts the routes of the group to the tenants with the given IDs, it's enforced by middleware.Tenant.
+func (rg *RouteGroup) ForTenants(ids ...string) *RouteGroup {
+	return rg.Set("tenants", ids)
+}
+
+// ForPlans restricts the routes of the group to the tenants on one of the given plans, it's enforced by
+// middleware.Tenant. Groups restricted with both ForTenants and ForPlans allow the tenants matching either.
+func (rg *RouteGroup) ForPlans(plans ...string) *RouteGroup {
+	return rg.Set("plans", plans)
+}
+
 type RouterConfig struct {
 	// DisableAutoAddExactMatchWildcard will disable the automatic addition of a wildcard to the end of a route pattern
 	// The router adds this by default, to prevent unexpected behavior as Go's pattern matching is a bit strange
@@ -47,39 +169,130 @@ type RouterConfig struct {
 	// DisableAutoAddTrailingSlash will disable the automatic addition of a trailing slash to the end of a route pattern
 	// The router adds this by default, to prevent unexpected behavior as Go's pattern matching is a bit strange
 	DisableAutoAddTrailingSlash bool
+	// Env is the environment the router runs in, e.g. "development" or "production". Development features like
+	// dev error pages are disabled in "production"
+	Env string
+	// Recover recovers panics of handlers, the error handler gets a *PanicError with status 500. Panics after the
+	// response was started close the connection instead, see WithPanicReporter
+	Recover bool
+	// ProfileMiddlewares records the time spent in every middleware per route, see Route.MiddlewareProfile
+	ProfileMiddlewares bool
+	// ShardRoutes registers the routes on several ServeMuxes, which are set up in parallel, see Router.SetupRoutes.
+	// It speeds up setting up thousands of routes
+	ShardRoutes bool
+	// UnmatchedMiddlewares runs the global middlewares for requests without a route as well, i.e. 404 and 405
+	// responses, e.g. for CORS preflight requests and complete access logs. MatchedRoute returns nil for them
+	UnmatchedMiddlewares bool
+	// EncodedPathParams is how percent-encoded characters in path parameters are handled, e.g. encoded slashes. They're
+	// decoded by default, RawPathValue returns a parameter as it was sent
+	EncodedPathParams EncodedPathParams
+	// PostStartRegistration is what happens to routes registered after the router started serving, they're ignored
+	// with a warning by default
+	PostStartRegistration PostStartRegistration
+	// TimeLayouts are tried in order when times are bound from forms and query strings, defaults to
+	// DefaultTimeLayouts
+	TimeLayouts []string
+	// BaseURL is the scheme and host the site is served at, e.g. "https://example.com". Robots and Sitemap resolve
+	// paths against it. Without it they use the Host header of the request, which the client controls
+	BaseURL string
 }
 
 type Router struct {
-	mutex          sync.Mutex
-	mux            *http.ServeMux
+	mutex   sync.Mutex
+	mux     *http.ServeMux
+	matcher matcher
+	// routesMutex guards the routes and the routes of the groups, which late routes are added to while serving
+	routesMutex    sync.RWMutex
 	routes         []*Route
 	routeGroups    []*RouteGroup
 	middlewares    []Middleware
-	hasSetupRoutes bool
+	requestHooks   []func(*http.Request) *http.Request
+	responseHooks  []func(ResponseInfo)
+	mockEnabled    bool
+	mockExamples   map[string]any
+	hasSetupRoutes atomic.Bool
+
+	errorHandler            ErrorHandlerFunc
+	notFoundHandler         http.HandlerFunc
+	methodNotAllowedHandler http.HandlerFunc
+	admin                   adminState
+	devErrors               bool
+	logger                  *slog.Logger
+	built                   bool
+	tracer                  *tracer
+	signingKey              []byte
+	setupProgress           func(done int, total int)
+	scratch                 sync.Pool
+	unmatchedHandler        http.HandlerFunc
+	customMatcher           Matcher
+	responseSerializer      ResponseSerializer
+	panicReporter           func(*http.Request, *PanicError)
+	conns                   connTracker
+	swap                    swapState
+	async                   asyncWork
+	matchEvents             matchEvents
+	// scope is the group the routes are added to, for routers returned by With
+	scope *RouteGroup
 
 	config RouterConfig
 }
 
-func NewRouter() *Router {
-	return &Router{}
+// NewRouter creates a router, options are applied in order. Prefer options over the setters, so the configuration
+// is complete before any route is registered.
+func NewRouter(options ...Option) *Router {
+	r := &Router{}
+	for _, option := range options {
+		option(r)
+	}
+	return r
 }
 
+// SetMux sets the mux the routes are registered on, it panics once the routes were set up. Prefer WithMux.
 func (r *Router) SetMux(mux *http.ServeMux) {
+	r.mustNotBeSetUp()
 	r.mux = mux
 }
 
+// SetConfig replaces the configuration, it panics once the routes were set up. Prefer WithConfig.
 func (r *Router) SetConfig(config RouterConfig) {
+	r.mustNotBeSetUp()
 	r.config = config
 }
 
+func (r *Router) Config() RouterConfig {
+	return r.config
+}
+
 func (r *Router) RegisterRoute(method string, pattern string, handler http.HandlerFunc) *Route {
+	if r.scope != nil {
+		return r.scope.RegisterRoute(method, pattern, handler)
+	}
+	r.mustBeMutable()
+	route := newRoute(method, pattern, handler)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.hasSetupRoutes.Load() {
+		r.handleLateRoute(route, nil)
+	}
+	r.routesMutex.Lock()
+	r.routes = append(r.routes, route)
+	r.routesMutex.Unlock()
+	return route
+}
+
+// newRoute creates a route without the constraints in its pattern, which records where it was registered
+func newRoute(method string, pattern string, handler http.HandlerFunc) *Route {
+	pattern, constraints := parseConstraints(pattern)
 	route := &Route{
-		Method:      method,
-		Pattern:     pattern,
+		Method:      intern(method),
+		Pattern:     intern(pattern),
 		HandlerFunc: handler,
 		Middlewares: nil,
+		constraints: constraints,
 	}
-	r.routes = append(r.routes, route)
+	file, line := callSite()
+	route.file, route.line = intern(file), line
 	return route
 }
 
@@ -119,24 +332,75 @@ func (r *Router) TRACE(pattern string, handler http.HandlerFunc) *Route {
 	return r.RegisterRoute(http.MethodTrace, pattern, handler)
 }
 
-func (r *Router) Group(prefix string, group func(r *Router)) *RouteGroup {
-	tmpRouter := &Router{middlewares: make([]Middleware, len(r.middlewares))}
-	copy(tmpRouter.middlewares, r.middlewares)
-	group(tmpRouter)
+// Group registers the routes of the callbacks below the prefix. Routes and middlewares can be added to the returned
+// group as well, without a callback:
+//
+//	api := r.Group("/api")
+//	api.Use(auth)
+//	api.GET("/users", listUsers)
+//
+// The global middlewares of the router apply to the routes of the group before the middlewares of the group.
+func (r *Router) Group(prefix string, groups ...func(r *Router)) *RouteGroup {
+	if r.scope != nil {
+		// The middlewares of With apply before the middlewares of the group
+		rg := r.scope.router.Group(prefix, groups...)
+		rg.Middlewares = append(slices.Clone(r.scope.Middlewares), rg.Middlewares...)
+		return rg
+	}
+	r.mustBeMutable()
+	tmpRouter := &Router{}
+	for _, group := range groups {
+		group(tmpRouter)
+	}
 	rg := &RouteGroup{
-		Prefix:      prefix,
+		Prefix:      intern(prefix),
 		Routes:      tmpRouter.routes,
 		Middlewares: tmpRouter.middlewares,
+		router:      r,
+	}
+	for _, route := range rg.Routes {
+		route.group = rg
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.hasSetupRoutes.Load() {
+		for _, route := range rg.Routes {
+			r.handleLateRoute(route, rg)
+		}
 	}
+	r.routesMutex.Lock()
 	r.routeGroups = append(r.routeGroups, rg)
+	r.routesMutex.Unlock()
 	return rg
 }
 
 func (r *Router) Use(middleware ...Middleware) {
+	if r.scope != nil {
+		r.scope.Use(middleware...)
+		return
+	}
+	r.mustBeMutable()
 	r.middlewares = append(r.middlewares, middleware...)
 }
 
+// Routes returns all registered routes, the routes of groups come after the other routes.
+func (r *Router) Routes() []*Route {
+	r.routesMutex.RLock()
+	defer r.routesMutex.RUnlock()
+	routes := make([]*Route, 0, len(r.routes))
+	routes = append(routes, r.routes...)
+	for _, routeGroup := range r.routeGroups {
+		routes = append(routes, routeGroup.Routes...)
+	}
+	return routes
+}
+
 func (r *Router) SanitizePath(path string) string {
+	return r.SanitizePathWithConfig(path, r.config)
+}
+
+func (r *Router) SanitizePathWithConfig(path string, config RouterConfig) string {
 	for strings.Contains(path, "//") {
 		path = strings.Replace(path, "//", "/", -1)
 	}
@@ -145,11 +409,16 @@ func (r *Router) SanitizePath(path string) string {
 		path = "/" + path
 	}
 
-	if !r.config.DisableAutoAddTrailingSlash && path[len(path)-1] != '/' {
+	// A remainder wildcard like {path...} has to be at the end, so nothing can be added after it
+	if strings.HasSuffix(path, "...}") {
+		return path
+	}
+
+	if !config.DisableAutoAddTrailingSlash && path[len(path)-1] != '/' {
 		path = path + "/"
 	}
 
-	if !r.config.DisableAutoAddExactMatchWildcard {
+	if !config.DisableAutoAddExactMatchWildcard {
 		path = path + "{$}"
 	}
 
@@ -158,17 +427,54 @@ func (r *Router) SanitizePath(path string) string {
 
 func (r *Router) GetPathForRoute(route *Route) string {
 	path := fmt.Sprintf("/%s", route.Pattern)
-	return fmt.Sprintf("%s %s", route.Method, r.SanitizePath(path))
+	return r.patternForRoute(route, path)
 }
 
 func (r *Router) GetPathForRouteWithRouteGroup(route *Route, routeGroup *RouteGroup) string {
 	path := fmt.Sprintf("/%s/%s", routeGroup.Prefix, route.Pattern)
-	return fmt.Sprintf("%s %s", route.Method, r.SanitizePath(path))
+	return r.patternForRoute(route, path)
 }
 
+func (r *Router) patternForRoute(route *Route, path string) string {
+	if route.mount {
+		// Mounted handlers match everything below their prefix, so only a trailing slash is added
+		path = r.SanitizePathWithConfig(path, RouterConfig{DisableAutoAddExactMatchWildcard: true})
+	} else if route.exact {
+		// Files like /robots.txt only match their exact path
+		path = r.SanitizePathWithConfig(path, RouterConfig{DisableAutoAddTrailingSlash: true, DisableAutoAddExactMatchWildcard: true})
+	} else {
+		path = r.SanitizePath(path)
+	}
+	if route.Method == "" {
+		return path
+	}
+	return fmt.Sprintf("%s %s", route.Method, path)
+}
+
+// compileRoute wraps the handler of the route with the given middlewares and the router level features
+func (r *Router) compileRoute(route *Route, middlewares []Middleware) http.HandlerFunc {
+	handler := pushAssetsHandler(route, r.mockHandler(route))
+	names := middlewareNames(middlewares)
+	if r.tracingEnabled() {
+		handler, middlewares = tracedMiddlewares(handler, middlewares, names)
+	}
+	if r.config.ProfileMiddlewares {
+		handler = profiledMiddlewares(route, handler, middlewares, names)
+	} else {
+		handler = applyMiddlewares(handler, middlewares...)
+	}
+	handler = r.limitsHandler(route, r.queryModelHandler(route, deprecationHandler(route, headersHandler(route, r.mirrorHandler(route, r.serializerHandler(route, handler))))))
+	handler = r.encodedParamsHandler(route, r.constraintHandler(route, handler))
+	return r.withRoute(route, r.matchEventHandler(route, r.traceHandler(route, r.applyHooks(route, r.adminHandler(route, r.recoverHandler(handler))))))
+}
+
+// SetupRoutes registers the routes on the ServeMux, wrapped with their middlewares. With RouterConfig.ShardRoutes the
+// routes are divided between several muxes by the path segment with the most different literals, e.g. the tenant in
+// "/tenants/{tenant}/...", and the muxes are set up in parallel. Handlers registered on the ServeMux directly are only
+// matched for requests that don't belong to one of the shards then.
 func (r *Router) SetupRoutes() {
 	if r.mux == nil {
-		log.Println("Warning: ServeMux is nil, creating a default one")
+		r.Logger().Warn("router: ServeMux is nil, creating a default one")
 		r.mux = http.NewServeMux()
 	}
 
@@ -180,35 +486,80 @@ func (r *Router) SetupRoutes() {
 		return allMiddlewares
 	}
 
+	routes := r.Routes()
+	handlers := make([]http.HandlerFunc, 0, len(routes))
 	for _, route := range r.routes {
-		handler := applyMiddlewares(
-			route.HandlerFunc,
-			combineMiddlewares(route.Middlewares, r.middlewares)...,
-		)
-		r.mux.HandleFunc(r.GetPathForRoute(route), func(w http.ResponseWriter, req *http.Request) {
-			handler(w, req)
-		})
+		route.fullPattern = r.GetPathForRoute(route)
+		// Routes without their own middlewares share the global middlewares, instead of a copy per route
+		middlewares := r.middlewares
+		if len(route.Middlewares) > 0 {
+			middlewares = combineMiddlewares(route.Middlewares, r.middlewares)
+		}
+		handlers = append(handlers, r.compileRoute(route, middlewares))
 	}
 
 	for _, routeGroup := range r.routeGroups {
+		groupMiddlewares := combineMiddlewares(routeGroup.Middlewares, r.middlewares)
 		for _, route := range routeGroup.Routes {
-			handler := applyMiddlewares(
-				route.HandlerFunc,
-				combineMiddlewares(append(routeGroup.Middlewares, route.Middlewares...), r.middlewares)...,
-			)
-			r.mux.HandleFunc(r.GetPathForRouteWithRouteGroup(route, routeGroup), func(w http.ResponseWriter, req *http.Request) {
-				handler(w, req)
-			})
+			route.fullPattern = r.GetPathForRouteWithRouteGroup(route, routeGroup)
+			middlewares := groupMiddlewares
+			if len(route.Middlewares) > 0 {
+				middlewares = combineMiddlewares(append(routeGroup.Middlewares, route.Middlewares...), r.middlewares)
+			}
+			handlers = append(handlers, r.compileRoute(route, middlewares))
+		}
+	}
+
+	r.matcher = r.mux
+	r.unmatchedHandler = nil
+	if r.config.UnmatchedMiddlewares {
+		r.unmatchedHandler = r.compileUnmatched()
+	}
+	if r.customMatcher != nil {
+		r.matcher = &customMatcher{matcher: r.customMatcher, fallback: r.mux}
+		progress := r.setupProgressReporter(len(routes))
+		for i, route := range routes {
+			r.addRoute(route, handlers[i])
+			progress()
 		}
+		return
+	}
+	if r.config.ShardRoutes && r.handleShardedRoutes(routes, handlers) {
+		return
+	}
+	progress := r.setupProgressReporter(len(routes))
+	for i, route := range routes {
+		r.handleRoute(r.mux, route, handlers[i])
+		progress()
 	}
 }
 
-func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	if !r.hasSetupRoutes {
-		r.mutex.Lock()
+// setup sets up the routes once, either on the first request or when the router is built
+func (r *Router) setup() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.hasSetupRoutes.Load() {
 		r.SetupRoutes()
-		r.hasSetupRoutes = true
-		r.mutex.Unlock()
+		r.hasSetupRoutes.Store(true)
+	}
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.serveSwapped(w, req) {
+		return
+	}
+	if !r.hasSetupRoutes.Load() {
+		r.setup()
+	}
+	if r.unmatchedHandler != nil || r.hasUnmatchedHandlers() {
+		if handler, pattern := r.matcher.Handler(req); pattern == "" {
+			if r.unmatchedHandler != nil {
+				r.unmatchedHandler(w, req)
+				return
+			}
+			r.serveUnmatched(w, req, handler)
+			return
+		}
 	}
-	r.mux.ServeHTTP(w, req)
+	r.matcher.ServeHTTP(w, req)
 }
@@ -0,0 +1,77 @@
+// Package routertest contains helpers to test routers.
+package routertest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router/middleware"
+)
+
+// Load reads the recordings of a file written by middleware.Record.
+func Load(file string) ([]middleware.Recording, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var recordings []middleware.Recording
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var recording middleware.Recording
+		if err := json.Unmarshal(scanner.Bytes(), &recording); err != nil {
+			return nil, fmt.Errorf("routertest: %s:%d: %w", file, line, err)
+		}
+		recordings = append(recordings, recording)
+	}
+	return recordings, scanner.Err()
+}
+
+// Replay sends the recorded requests in the file to the handler, and reports an error for every response with a
+// different status code or body than recorded. Redacted headers are sent as recorded, so use a handler that doesn't
+// depend on them. Requests with a truncated body are skipped, truncated response bodies are compared by their
+// recorded start.
+func Replay(t testing.TB, handler http.Handler, file string) {
+	t.Helper()
+
+	recordings, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, recording := range recordings {
+		name := recording.Request.Method + " " + recording.Request.URL
+		if recording.Request.Truncated {
+			t.Logf("%s: skipped, the request body was truncated", name)
+			continue
+		}
+		req := httptest.NewRequest(recording.Request.Method, recording.Request.URL, strings.NewReader(recording.Request.Body))
+		req.Header = recording.Request.Header.Clone()
+		if req.Header == nil {
+			req.Header = make(http.Header)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != recording.Response.StatusCode {
+			t.Errorf("%s: expected status code %d, got %d", name, recording.Response.StatusCode, rr.Code)
+		}
+		body := rr.Body.String()
+		if recording.Response.Truncated && strings.HasPrefix(body, recording.Response.Body) {
+			continue
+		}
+		if body != recording.Response.Body {
+			t.Errorf("%s: expected body %q, got %q", name, recording.Response.Body, body)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// maxScratchSize is the largest buffer that is put back into the pool, so a few large requests don't keep their
+// buffers alive for the lifetime of the pool
+const maxScratchSize = 64 << 10
+
+// defaultScratchPool is used for requests that weren't matched by a router
+var defaultScratchPool sync.Pool
+
+// Scratch is a pooled buffer for per-request work, like encoding a response or reading a body. It has to be released
+// when it's no longer used, its bytes must not be kept after that.
+type Scratch struct {
+	bytes.Buffer
+	pool *sync.Pool
+}
+
+// AcquireScratch returns an empty buffer from the pool of the router that matched the request. Reusing buffers
+// reduces the garbage of high-throughput JSON endpoints, the render and bind packages use them as well. The request
+// may be nil, e.g. in helpers without one.
+//
+//	scratch := router.AcquireScratch(r)
+//	defer scratch.Release()
+func AcquireScratch(r *http.Request) *Scratch {
+	pool := &defaultScratchPool
+	if r != nil {
+		if router := routerFromRequest(r); router != nil {
+			pool = &router.scratch
+		}
+	}
+	if scratch, ok := pool.Get().(*Scratch); ok {
+		return scratch
+	}
+	return &Scratch{pool: pool}
+}
+
+// Release resets the buffer and puts it back into the pool.
+func (s *Scratch) Release() {
+	if s.Cap() > maxScratchSize {
+		return
+	}
+	s.Reset()
+	s.pool.Put(s)
+}
@@ -0,0 +1,41 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestAcquireScratch(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/users", func(w http.ResponseWriter, req *http.Request) {
+		scratch := router.AcquireScratch(req)
+		defer scratch.Release()
+
+		// A buffer from the pool is always empty, even when an earlier request used it
+		if scratch.Len() != 0 {
+			t.Errorf("Expected an empty buffer, got %q", scratch.String())
+		}
+		scratch.WriteString("users")
+		w.Write(scratch.Bytes())
+	})
+
+	for range 10 {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/", nil))
+		if rr.Body.String() != "users" {
+			t.Errorf("Expected body users, got %s", rr.Body.String())
+		}
+	}
+
+	// Outside of a router the default pool is used
+	scratch := router.AcquireScratch(nil)
+	scratch.WriteString("data")
+	scratch.Release()
+	if scratch := router.AcquireScratch(nil); scratch.Len() != 0 {
+		t.Errorf("Expected an empty buffer, got %q", scratch.String())
+	}
+}
@@ -0,0 +1,64 @@
+package router
+
+import "net/http"
+
+// ResponseSerializer transforms the values written by the render helpers before they're encoded, e.g. to wrap them in
+// a standard envelope or to convert the keys to snake_case. The render package has serializers for both.
+type ResponseSerializer interface {
+	Serialize(r *http.Request, status int, v any) (any, error)
+}
+
+// WithResponseSerializer sets the serializer for the responses of all routes, groups and routes can override it with
+// their Serializer method.
+func WithResponseSerializer(serializer ResponseSerializer) Option {
+	return func(r *Router) {
+		r.responseSerializer = serializer
+	}
+}
+
+// Serializer sets the serializer for the responses of the route. It's stored as "serializer" metadata.
+func (r *Route) Serializer(serializer ResponseSerializer) *Route {
+	return r.Set("serializer", serializer)
+}
+
+// Serializer sets the serializer for the responses of the routes of the group, e.g. for another version of the API.
+func (rg *RouteGroup) Serializer(serializer ResponseSerializer) *RouteGroup {
+	return rg.Set("serializer", serializer)
+}
+
+// SerializeResponse applies the serializer of the route the response belongs to, values are returned as they are
+// when there is none. It's used by the render helpers, ResponseWriters wrapped by middlewares need an Unwrap method.
+func SerializeResponse(w http.ResponseWriter, status int, v any) (any, error) {
+	for {
+		if sw, ok := w.(*serializingWriter); ok {
+			return sw.serializer.Serialize(sw.req, status, v)
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return v, nil
+		}
+		w = unwrapper.Unwrap()
+	}
+}
+
+// serializingWriter carries the serializer of the route to the render helpers, it supports flushing, hijacking and
+// pushing like responseWriter
+type serializingWriter struct {
+	*responseWriter
+	serializer ResponseSerializer
+	req        *http.Request
+}
+
+// serializerHandler passes the serializer of the route to the render helpers, routes without one aren't wrapped
+func (r *Router) serializerHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	serializer := r.responseSerializer
+	if value, ok := route.Get("serializer"); ok {
+		serializer, _ = value.(ResponseSerializer)
+	}
+	if serializer == nil {
+		return handler
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		handler(&serializingWriter{responseWriter: &responseWriter{ResponseWriter: w}, serializer: serializer, req: req}, req)
+	}
+}
@@ -0,0 +1,76 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+type prefixSerializer string
+
+func (s prefixSerializer) Serialize(r *http.Request, status int, v any) (any, error) {
+	return fmt.Sprintf("%s:%d:%v", s, status, v), nil
+}
+
+// wrappedWriter is a ResponseWriter wrapped by a middleware
+type wrappedWriter struct {
+	http.ResponseWriter
+}
+
+func (w wrappedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestResponseSerializer(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithResponseSerializer(prefixSerializer("v1")))
+
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		v, err := router.SerializeResponse(w, http.StatusOK, "value")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprint(w, v)
+	}
+	r.GET("/users", handler)
+	r.GET("/raw", handler).Serializer(nil)
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			next(wrappedWriter{w}, req)
+		}
+	})
+	r.Group("/v2", func(r *router.Router) {
+		r.GET("/users", handler)
+	}).Serializer(prefixSerializer("v2"))
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"router serializer", "/users/", "v1:200:value"},
+		{"group serializer", "/v2/users/", "v2:200:value"},
+		{"disabled for the route", "/raw/", "value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Body.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("without a router", func(t *testing.T) {
+		v, err := router.SerializeResponse(httptest.NewRecorder(), http.StatusOK, "value")
+		if err != nil || v != "value" {
+			t.Errorf("expected the value unchanged, got %v, %v", v, err)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package router
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"time"
+)
+
+// ServerOption configures the http.Server created by Server, Run and RunTLS.
+type ServerOption func(server *http.Server)
+
+// WithTLSConfig sets the TLS configuration of the server.
+func WithTLSConfig(config *tls.Config) ServerOption {
+	return func(server *http.Server) {
+		server.TLSConfig = config
+	}
+}
+
+// WithClientAuth requests client certificates, e.g. tls.RequireAndVerifyClientCert for service to service APIs
+// using mTLS. Certificates are verified against the clientCAs, middleware.ClientCert makes the identity of verified
+// certificates available to handlers.
+func WithClientAuth(clientCAs *x509.CertPool, clientAuth tls.ClientAuthType) ServerOption {
+	return func(server *http.Server) {
+		if server.TLSConfig == nil {
+			server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		server.TLSConfig.ClientCAs = clientCAs
+		server.TLSConfig.ClientAuth = clientAuth
+	}
+}
+
+// WithReadHeaderTimeout sets how long clients have to send the request headers, which protects against slowloris
+// attacks. It defaults to 10 seconds.
+func WithReadHeaderTimeout(timeout time.Duration) ServerOption {
+	return func(server *http.Server) {
+		server.ReadHeaderTimeout = timeout
+	}
+}
+
+// WithReadTimeout sets how long clients have to send the whole request, including the body. It defaults to 1 minute.
+func WithReadTimeout(timeout time.Duration) ServerOption {
+	return func(server *http.Server) {
+		server.ReadTimeout = timeout
+	}
+}
+
+// WithWriteTimeout sets how long the server has to write the response, starting when the request headers are read.
+// It's not set by default, as it would cut off streamed responses, server-sent events and long polling. Handlers can
+// extend it for their request with http.ResponseController.SetWriteDeadline.
+func WithWriteTimeout(timeout time.Duration) ServerOption {
+	return func(server *http.Server) {
+		server.WriteTimeout = timeout
+	}
+}
+
+// WithIdleTimeout sets how long keep-alive connections stay open between requests. It defaults to 2 minutes.
+func WithIdleTimeout(timeout time.Duration) ServerOption {
+	return func(server *http.Server) {
+		server.IdleTimeout = timeout
+	}
+}
+
+// WithMaxHeaderBytes sets the largest size of the request headers. It defaults to 64KB, instead of the 1MB of
+// net/http.
+func WithMaxHeaderBytes(size int) ServerOption {
+	return func(server *http.Server) {
+		server.MaxHeaderBytes = size
+	}
+}
+
+// WithStrictTimeouts sets short timeouts for APIs with small requests and responses, which don't stream: 5 seconds
+// for the headers, 10 seconds for the request, 30 seconds for the response, 1 minute for idle connections and at
+// most 16KB of headers.
+func WithStrictTimeouts() ServerOption {
+	return func(server *http.Server) {
+		server.ReadHeaderTimeout = 5 * time.Second
+		server.ReadTimeout = 10 * time.Second
+		server.WriteTimeout = 30 * time.Second
+		server.IdleTimeout = time.Minute
+		server.MaxHeaderBytes = 16 << 10
+	}
+}
+
+// Server returns a http.Server for the router. Unlike a bare http.Server it has timeouts for reading the request,
+// for idle connections and a smaller limit for the request headers, see the options for the defaults. There is no
+// write timeout by default, as it would cut off streamed responses. The connections of the server are counted in
+// ConnStats.
+func (r *Router) Server(addr string, options ...ServerOption) *http.Server {
+	return newServer(addr, r, &r.conns, options)
+}
+
+func newServer(addr string, handler http.Handler, conns *connTracker, options []ServerOption) *http.Server {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		IdleTimeout:       2 * time.Minute,
+		MaxHeaderBytes:    64 << 10,
+		ConnState:         conns.track,
+	}
+	for _, option := range options {
+		option(server)
+	}
+	return server
+}
+
+// Run serves the router on the address.
+func (r *Router) Run(addr string, options ...ServerOption) error {
+	return r.Server(addr, options...).ListenAndServe()
+}
+
+// RunTLS serves the router on the address using TLS.
+func (r *Router) RunTLS(addr string, certFile string, keyFile string, options ...ServerOption) error {
+	return r.Server(addr, options...).ListenAndServeTLS(certFile, keyFile)
+}
@@ -0,0 +1,81 @@
+package router_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestServer(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	pool := x509.NewCertPool()
+
+	server := r.Server(":8443", router.WithClientAuth(pool, tls.RequireAndVerifyClientCert))
+	if server.Addr != ":8443" || server.Handler != r {
+		t.Errorf("Expected the server to serve the router on :8443, got %s", server.Addr)
+	}
+	if server.ReadHeaderTimeout != 10*time.Second {
+		t.Errorf("Expected a read header timeout of 10s, got %s", server.ReadHeaderTimeout)
+	}
+	if server.TLSConfig == nil || server.TLSConfig.ClientAuth != tls.RequireAndVerifyClientCert || server.TLSConfig.ClientCAs != pool {
+		t.Errorf("Expected the server to require client certificates of the pool, got %+v", server.TLSConfig)
+	}
+
+	if server.ReadTimeout != time.Minute || server.IdleTimeout != 2*time.Minute || server.WriteTimeout != 0 || server.MaxHeaderBytes != 64<<10 {
+		t.Errorf("Expected the default timeouts and header limit, got %+v", server)
+	}
+
+	// The client auth is added to an existing TLS configuration
+	config := &tls.Config{MinVersion: tls.VersionTLS13}
+	server = r.Server(":8443", router.WithTLSConfig(config), router.WithClientAuth(pool, tls.VerifyClientCertIfGiven))
+	if server.TLSConfig != config || config.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Error("Expected the client auth to be set on the existing TLS configuration")
+	}
+}
+
+func TestServerTimeouts(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+
+	// Define test cases
+	tests := []struct {
+		name              string
+		options           []router.ServerOption
+		readHeaderTimeout time.Duration
+		readTimeout       time.Duration
+		writeTimeout      time.Duration
+		idleTimeout       time.Duration
+		maxHeaderBytes    int
+	}{
+		{"defaults", nil, 10 * time.Second, time.Minute, 0, 2 * time.Minute, 64 << 10},
+		{"strict", []router.ServerOption{router.WithStrictTimeouts()}, 5 * time.Second, 10 * time.Second, 30 * time.Second, time.Minute, 16 << 10},
+		{
+			"options",
+			[]router.ServerOption{
+				router.WithStrictTimeouts(),
+				router.WithReadHeaderTimeout(2 * time.Second),
+				router.WithReadTimeout(time.Hour),
+				router.WithWriteTimeout(0),
+				router.WithIdleTimeout(3 * time.Minute),
+				router.WithMaxHeaderBytes(8 << 10),
+			},
+			2 * time.Second, time.Hour, 0, 3 * time.Minute, 8 << 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := r.Server(":8080", tt.options...)
+			if server.ReadHeaderTimeout != tt.readHeaderTimeout || server.ReadTimeout != tt.readTimeout ||
+				server.WriteTimeout != tt.writeTimeout || server.IdleTimeout != tt.idleTimeout || server.MaxHeaderBytes != tt.maxHeaderBytes {
+				t.Errorf("Expected timeouts %s, %s, %s, %s and %d header bytes, got %s, %s, %s, %s and %d",
+					tt.readHeaderTimeout, tt.readTimeout, tt.writeTimeout, tt.idleTimeout, tt.maxHeaderBytes,
+					server.ReadHeaderTimeout, server.ReadTimeout, server.WriteTimeout, server.IdleTimeout, server.MaxHeaderBytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// matcher finds the handler of a request, it's the ServeMux of the router or a shardedMux
+type matcher interface {
+	Handler(req *http.Request) (http.Handler, string)
+	ServeHTTP(w http.ResponseWriter, req *http.Request)
+}
+
+// WithSetupProgress sets a function that is called after every route is set up, e.g. to report the progress of
+// setting up thousands of routes. It's called from one goroutine at a time.
+func WithSetupProgress(progress func(done int, total int)) Option {
+	return func(r *Router) {
+		r.setupProgress = progress
+	}
+}
+
+// setupProgressReporter returns a function to call after every route, which reports the progress
+func (r *Router) setupProgressReporter(total int) func() {
+	if r.setupProgress == nil {
+		return func() {}
+	}
+	var mutex sync.Mutex
+	done := 0
+	return func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		done++
+		r.setupProgress(done, total)
+	}
+}
+
+// shardedMux divides the routes between ServeMuxes by the path segment at a position, so the muxes only compare
+// the patterns of their own shard for conflicts. Every shard has the routes with its literal at the position, plus
+// the routes with a wildcard or without a segment there, so it matches the same routes as a single mux would.
+type shardedMux struct {
+	position int
+	shards   map[string]*http.ServeMux
+	// fallback is the mux of the router, with the routes that don't have a literal at the position
+	fallback *http.ServeMux
+}
+
+func (m *shardedMux) mux(req *http.Request) *http.ServeMux {
+	if segment, ok := pathSegment(cleanPath(req.URL.EscapedPath()), m.position); ok {
+		if shard, ok := m.shards[segment]; ok {
+			return shard
+		}
+	}
+	return m.fallback
+}
+
+func (m *shardedMux) Handler(req *http.Request) (http.Handler, string) {
+	return m.mux(req).Handler(req)
+}
+
+func (m *shardedMux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m.mux(req).ServeHTTP(w, req)
+}
+
+// handleShardedRoutes registers the routes on a shardedMux, setting up the shards in parallel. It returns false
+// when the routes have no position with different literals to shard by.
+func (r *Router) handleShardedRoutes(routes []*Route, handlers []http.HandlerFunc) bool {
+	position, ok := shardPosition(routes)
+	if !ok {
+		return false
+	}
+
+	keyed := make(map[string][]int)
+	var unkeyed []int
+	for i, route := range routes {
+		if literal, ok := patternLiteral(route.fullPattern, position); ok {
+			keyed[literal] = append(keyed[literal], i)
+		} else {
+			unkeyed = append(unkeyed, i)
+		}
+	}
+
+	m := &shardedMux{position: position, shards: make(map[string]*http.ServeMux, len(keyed)), fallback: r.mux}
+	type shard struct {
+		mux *http.ServeMux
+		// routes are the indexes of the routes that are reported to the progress function
+		routes []int
+		// shared are the routes every shard has, they're reported by the fallback
+		shared []int
+	}
+	shards := []shard{{mux: r.mux, routes: unkeyed}}
+	for literal, indexes := range keyed {
+		m.shards[literal] = http.NewServeMux()
+		shards = append(shards, shard{mux: m.shards[literal], routes: indexes, shared: unkeyed})
+	}
+
+	progress := r.setupProgressReporter(len(routes))
+	queue := make(chan shard)
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var recovered any
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range queue {
+				// A conflict panics in the worker, it's passed on to the goroutine setting up the routes
+				func() {
+					defer func() {
+						if p := recover(); p != nil {
+							panicOnce.Do(func() { recovered = p })
+						}
+					}()
+					for _, i := range mergeIndexes(s.routes, s.shared) {
+						r.handleRoute(s.mux, routes[i], handlers[i])
+					}
+					for range s.routes {
+						progress()
+					}
+				}()
+			}
+		}()
+	}
+	for _, s := range shards {
+		queue <- s
+	}
+	close(queue)
+	wg.Wait()
+	if recovered != nil {
+		panic(recovered)
+	}
+
+	r.matcher = m
+	return true
+}
+
+// shardPosition returns the position of the path segment with the most different literals
+func shardPosition(routes []*Route) (int, bool) {
+	var literals []map[string]bool
+	for _, route := range routes {
+		for i := range patternSegments(route.fullPattern) {
+			if len(literals) <= i {
+				literals = append(literals, make(map[string]bool))
+			}
+			if literal, ok := patternLiteral(route.fullPattern, i); ok {
+				literals[i][literal] = true
+			}
+		}
+	}
+
+	position, most := 0, 0
+	for i, values := range literals {
+		if len(values) > most {
+			position, most = i, len(values)
+		}
+	}
+	return position, most > 1
+}
+
+// patternSegments returns the path segments of a pattern, e.g. ["users", "{id}", "{$}"] for "GET /users/{id}/{$}"
+func patternSegments(pattern string) []string {
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		pattern = pattern[i+1:]
+	}
+	return strings.Split(pattern, "/")
+}
+
+// patternLiteral returns the literal segment of the pattern at the position, patterns with a wildcard or without a
+// segment at the position return false
+func patternLiteral(pattern string, position int) (string, bool) {
+	segments := patternSegments(pattern)
+	if position >= len(segments) || segments[position] == "" || strings.HasPrefix(segments[position], "{") {
+		return "", false
+	}
+	literal, err := url.PathUnescape(segments[position])
+	return literal, err == nil
+}
+
+// pathSegment returns the unescaped segment of the path at the position, the same way the mux compares it with the
+// literals of the patterns
+func pathSegment(escapedPath string, position int) (string, bool) {
+	segments := strings.Split(strings.TrimPrefix(escapedPath, "/"), "/")
+	if position >= len(segments) || segments[position] == "" {
+		return "", false
+	}
+	segment, err := url.PathUnescape(segments[position])
+	return segment, err == nil
+}
+
+// cleanPath returns the canonical path the mux matches, like the unexported cleanPath of net/http
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	cleaned := path.Clean(p)
+	if p[len(p)-1] == '/' && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// mergeIndexes merges two sorted lists of route indexes, so the routes are registered in their original order
+func mergeIndexes(a []int, b []int) []int {
+	merged := make([]int, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if a[0] < b[0] {
+			merged, a = append(merged, a[0]), a[1:]
+		} else {
+			merged, b = append(merged, b[0]), b[1:]
+		}
+	}
+	return append(append(merged, a...), b...)
+}
@@ -0,0 +1,114 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+// tenantRoutes registers the same routes on a router with and without sharding
+func tenantRoutes(config router.RouterConfig, options ...router.Option) *router.Router {
+	r := router.NewRouter(append([]router.Option{router.WithConfig(config)}, options...)...)
+	for i := range 50 {
+		tenant := fmt.Sprintf("t%d", i)
+		r.GET("/tenants/"+tenant+"/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s item %s", tenant, r.PathValue("id"))
+		})
+		r.POST("/tenants/"+tenant+"/items", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s create", tenant)
+		})
+	}
+	r.GET("/tenants/{tenant}/health", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "health of %s", r.PathValue("tenant"))
+	})
+	r.GET("/tenants/t1/items/special", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("special"))
+	})
+	r.GET("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("home"))
+	})
+	r.Mount("/static", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("static " + r.URL.Path))
+	}))
+	r.Group("/admin", func(r *router.Router) {
+		r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "admin user %s", r.PathValue("id"))
+		})
+	})
+	return r
+}
+
+func TestShardRoutes(t *testing.T) {
+	var progress []int
+	plain := tenantRoutes(router.RouterConfig{})
+	sharded := tenantRoutes(router.RouterConfig{ShardRoutes: true}, router.WithSetupProgress(func(done int, total int) {
+		progress = append(progress, done)
+		if total != 105 {
+			t.Errorf("Expected 105 routes in total, got %d", total)
+		}
+	}))
+
+	// Define the requests that both routers should handle the same way
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/tenants/t1/items/5/"},
+		{http.MethodGet, "/tenants/t49/items/7/"},
+		{http.MethodGet, "/tenants/t1/items/special/"},
+		{http.MethodGet, "/tenants/t2/items/5"},
+		{http.MethodPost, "/tenants/t3/items/"},
+		{http.MethodDelete, "/tenants/t3/items/"},
+		{http.MethodGet, "/tenants/t7/health/"},
+		{http.MethodGet, "/tenants/unknown/health/"},
+		{http.MethodGet, "/tenants/unknown/items/1/"},
+		{http.MethodGet, "/tenants/t%31/items/5/"},
+		{http.MethodGet, "/tenants/t1/../t2/items/5/"},
+		{http.MethodGet, "/"},
+		{http.MethodGet, "/static/css/app.css"},
+		{http.MethodGet, "/static"},
+		{http.MethodGet, "/admin/users/1/"},
+		{http.MethodGet, "/missing/"},
+	}
+
+	for _, tt := range requests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			want := httptest.NewRecorder()
+			plain.ServeHTTP(want, httptest.NewRequest(tt.method, tt.path, nil))
+			got := httptest.NewRecorder()
+			sharded.ServeHTTP(got, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if got.Code != want.Code || got.Body.String() != want.Body.String() ||
+				got.Header().Get("Location") != want.Header().Get("Location") ||
+				got.Header().Get("Allow") != want.Header().Get("Allow") {
+				t.Errorf("Expected %d %q, got %d %q", want.Code, want.Body.String(), got.Code, got.Body.String())
+			}
+		})
+	}
+
+	if len(progress) != 105 || progress[len(progress)-1] != 105 {
+		t.Errorf("Expected the progress of all routes, got %v", progress)
+	}
+	if explanation, _ := sharded.Explain(http.MethodGet, "", "/tenants/t1/items/5/"); explanation.Matched == nil ||
+		explanation.Matched.Pattern != "GET /tenants/t1/items/{id}/{$}" {
+		t.Errorf("Expected the explanation to find the route, got %+v", explanation.Matched)
+	}
+}
+
+func TestShardRoutesConflict(t *testing.T) {
+	// Create a new router instance
+	r := tenantRoutes(router.RouterConfig{ShardRoutes: true})
+	r.GET("/tenants/{tenant}/items/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	r.GET("/tenants/{name}/items/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	defer func() {
+		if message := fmt.Sprint(recover()); !strings.Contains(message, "conflicts with") {
+			t.Errorf("Expected the conflict to panic, got %s", message)
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
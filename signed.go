@@ -0,0 +1,88 @@
+package router
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signed URL was changed or signed with another key
+	ErrInvalidSignature = errors.New("router: invalid signature")
+	// ErrSignatureExpired is returned when a signed URL is used after it expired
+	ErrSignatureExpired = errors.New("router: signature expired")
+)
+
+// WithSigningKey sets the key used to sign URLs, it should be at least 32 random bytes.
+func WithSigningKey(key []byte) Option {
+	return func(r *Router) {
+		r.signingKey = key
+	}
+}
+
+// SignedURL generates the path for the named route like URL, with a signature that can be verified with
+// VerifySignedURL or middleware.VerifySignature, e.g. for temporary download links or email confirmations. The URL
+// expires after the expiry, or never when it's 0.
+func (r *Router) SignedURL(name string, params map[string]string, expiry time.Duration) (string, error) {
+	if len(r.signingKey) == 0 {
+		return "", errors.New("router: signing URLs needs a key, see WithSigningKey")
+	}
+	path, err := r.URL(name, params)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	if expiry != 0 {
+		query.Set("expires", strconv.FormatInt(time.Now().Add(expiry).Unix(), 10))
+	}
+	query.Set("signature", r.sign(path, query))
+	return path + "?" + query.Encode(), nil
+}
+
+// SignedURLFor generates a signed URL for the named route on the router that matched the request.
+func SignedURLFor(req *http.Request, name string, params map[string]string, expiry time.Duration) (string, error) {
+	r := routerFromRequest(req)
+	if r == nil {
+		return "", fmt.Errorf("%w: %q (request was not matched by a router)", ErrRouteNotFound, name)
+	}
+	return r.SignedURL(name, params, expiry)
+}
+
+// VerifySignedURL checks the signature of a URL generated by SignedURL, using the key of the router that matched the
+// request.
+func VerifySignedURL(req *http.Request) error {
+	r := routerFromRequest(req)
+	if r == nil || len(r.signingKey) == 0 {
+		return ErrInvalidSignature
+	}
+
+	query := req.URL.Query()
+	signature := query.Get("signature")
+	query.Del("signature")
+	if signature == "" || !hmac.Equal([]byte(signature), []byte(r.sign(req.URL.EscapedPath(), query))) {
+		return ErrInvalidSignature
+	}
+	if expires := query.Get("expires"); expires != "" {
+		unix, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		if time.Now().After(time.Unix(unix, 0)) {
+			return ErrSignatureExpired
+		}
+	}
+	return nil
+}
+
+// sign signs the path with the query, which is encoded sorted by key
+func (r *Router) sign(path string, query url.Values) string {
+	mac := hmac.New(sha256.New, r.signingKey)
+	mac.Write([]byte(path + "?" + query.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,65 @@
+package router_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestSignedURL(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithSigningKey([]byte(strings.Repeat("k", 32))))
+	var verifyErr error
+	r.GET("/downloads/{file}", func(w http.ResponseWriter, req *http.Request) {
+		verifyErr = router.VerifySignedURL(req)
+	}).Name("download")
+
+	signed, err := r.SignedURL("download", map[string]string{"file": "report 2024.pdf"}, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to sign the URL: %v", err)
+	}
+	if !strings.HasPrefix(signed, "/downloads/report%202024.pdf/?expires=") || !strings.Contains(signed, "&signature=") {
+		t.Fatalf("Unexpected signed URL %s", signed)
+	}
+	expired, _ := r.SignedURL("download", map[string]string{"file": "report 2024.pdf"}, -time.Hour)
+	forever, _ := r.SignedURL("download", map[string]string{"file": "report 2024.pdf"}, 0)
+
+	// Define test cases
+	tests := []struct {
+		name        string
+		url         string
+		expectedErr error
+	}{
+		{"valid", signed, nil},
+		{"never expires", forever, nil},
+		{"other file", strings.Replace(signed, "report", "secret", 1), router.ErrInvalidSignature},
+		{"extended expiry", strings.Replace(signed, "expires=1", "expires=9", 1), router.ErrInvalidSignature},
+		{"unsigned", "/downloads/report%202024.pdf/", router.ErrInvalidSignature},
+		{"expired", expired, router.ErrSignatureExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifyErr = nil
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if !errors.Is(verifyErr, tt.expectedErr) {
+				t.Errorf("Expected %v, got %v", tt.expectedErr, verifyErr)
+			}
+		})
+	}
+}
+
+func TestSignedURLWithoutKey(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/downloads/{file}", func(w http.ResponseWriter, req *http.Request) {}).Name("download")
+
+	if _, err := r.SignedURL("download", map[string]string{"file": "report.pdf"}, time.Hour); err == nil {
+		t.Error("Expected an error without a signing key")
+	}
+}
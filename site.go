@@ -0,0 +1,208 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RobotsRule is a group of rules in robots.txt. Paths in Allow and Disallow are prefixes, e.g. "/admin/".
+type RobotsRule struct {
+	// UserAgent is the crawler the rules are for, defaults to "*"
+	UserAgent  string
+	Allow      []string
+	Disallow   []string
+	CrawlDelay time.Duration
+}
+
+// SitemapURL is a page in sitemap.xml. Loc can be a path, it's resolved against RouterConfig.BaseURL.
+type SitemapURL struct {
+	Loc        string
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   float64
+}
+
+// SitemapGenerator returns the pages listed in sitemap.xml, e.g. from the database.
+type SitemapGenerator func(r *http.Request) ([]SitemapURL, error)
+
+// Robots serves /robots.txt with the rules. When the router has a sitemap, its URL is added as well.
+//
+//	r.Robots(router.RobotsRule{Disallow: []string{"/admin/", "/api/"}})
+func (r *Router) Robots(rules ...RobotsRule) *Route {
+	var b strings.Builder
+	for i, rule := range rules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		userAgent := rule.UserAgent
+		if userAgent == "" {
+			userAgent = "*"
+		}
+		fmt.Fprintf(&b, "User-agent: %s\n", userAgent)
+		for _, path := range rule.Allow {
+			fmt.Fprintf(&b, "Allow: %s\n", path)
+		}
+		for _, path := range rule.Disallow {
+			fmt.Fprintf(&b, "Disallow: %s\n", path)
+		}
+		if rule.CrawlDelay > 0 {
+			fmt.Fprintf(&b, "Crawl-delay: %s\n", strconv.FormatFloat(rule.CrawlDelay.Seconds(), 'f', -1, 64))
+		}
+	}
+	robots := b.String()
+
+	return r.exactRoute("/robots.txt", func(w http.ResponseWriter, req *http.Request) {
+		body := robots
+		if sitemap := r.sitemapRoute(); sitemap != nil {
+			body += fmt.Sprintf("\nSitemap: %s\n", r.siteURL(w, req, sitemap.Path()))
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Write([]byte(body))
+	})
+}
+
+// Sitemap serves /sitemap.xml with the pages of the generator. PublicRoutes lists the routes marked with Public.
+//
+//	r.Sitemap(router.PublicRoutes)
+func (r *Router) Sitemap(generator SitemapGenerator) *Route {
+	route := r.exactRoute("/sitemap.xml", func(w http.ResponseWriter, req *http.Request) {
+		urls, err := generator(req)
+		if err != nil {
+			Error(w, req, http.StatusInternalServerError, err)
+			return
+		}
+
+		type sitemapURL struct {
+			Loc        string `xml:"loc"`
+			LastMod    string `xml:"lastmod,omitempty"`
+			ChangeFreq string `xml:"changefreq,omitempty"`
+			Priority   string `xml:"priority,omitempty"`
+		}
+		sitemap := struct {
+			XMLName xml.Name     `xml:"urlset"`
+			Xmlns   string       `xml:"xmlns,attr"`
+			URLs    []sitemapURL `xml:"url"`
+		}{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+		for _, u := range urls {
+			entry := sitemapURL{Loc: r.siteURL(w, req, u.Loc), ChangeFreq: u.ChangeFreq}
+			if !u.LastMod.IsZero() {
+				entry.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+			}
+			if u.Priority > 0 {
+				entry.Priority = strconv.FormatFloat(u.Priority, 'f', 1, 64)
+			}
+			sitemap.URLs = append(sitemap.URLs, entry)
+		}
+
+		scratch := AcquireScratch(req)
+		defer scratch.Release()
+		scratch.WriteString(xml.Header)
+		if err := xml.NewEncoder(scratch).Encode(sitemap); err != nil {
+			Error(w, req, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.Write(scratch.Bytes())
+	})
+	return route.Set("sitemap", true)
+}
+
+// Public marks the route as a public page, PublicRoutes adds it to the sitemap. Only named GET routes without path
+// parameters are listed. It's stored as "public" metadata.
+func (r *Route) Public() *Route {
+	return r.Set("public", true)
+}
+
+// PublicRoutes is a SitemapGenerator that lists the named GET routes marked with Public, in the order they were
+// registered.
+func PublicRoutes(req *http.Request) ([]SitemapURL, error) {
+	r := routerFromRequest(req)
+	if r == nil {
+		return nil, nil
+	}
+	var urls []SitemapURL
+	for _, route := range r.Routes() {
+		value, _ := route.Get("public")
+		if public, _ := value.(bool); !public || route.Method != http.MethodGet || route.name == "" || len(route.ParamNames()) > 0 {
+			continue
+		}
+		path, err := r.URL(route.name, nil)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, SitemapURL{Loc: path})
+	}
+	return urls, nil
+}
+
+// Favicon serves /favicon.ico. The icon is either the bytes of the icon, or a fs.FS with a favicon.ico file, e.g. an
+// embed.FS. It panics when the icon can't be read.
+func (r *Router) Favicon(icon any) *Route {
+	var data []byte
+	switch icon := icon.(type) {
+	case []byte:
+		data = icon
+	case fs.FS:
+		var err error
+		if data, err = fs.ReadFile(icon, "favicon.ico"); err != nil {
+			panic(fmt.Sprintf("router: can't read favicon: %v", err))
+		}
+	default:
+		panic(fmt.Sprintf("router: Favicon needs []byte or fs.FS, got %T", icon))
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	contentType := http.DetectContentType(data)
+
+	return r.exactRoute("/favicon.ico", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "public, max-age=604800")
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, req, "favicon.ico", time.Time{}, bytes.NewReader(data))
+	})
+}
+
+// exactRoute registers a GET route for a file, which doesn't get a trailing slash
+func (r *Router) exactRoute(path string, handler http.HandlerFunc) *Route {
+	route := r.GET(path, handler)
+	route.exact = true
+	return route
+}
+
+// sitemapRoute returns the route registered with Sitemap
+func (r *Router) sitemapRoute() *Route {
+	for _, route := range r.Routes() {
+		if sitemap, _ := route.Metadata["sitemap"].(bool); sitemap {
+			return route
+		}
+	}
+	return nil
+}
+
+// siteURL resolves the path against the base URL of the router, absolute URLs are returned as they are. Without a
+// base URL it falls back to the scheme and host of the request, the response varies by host then so shared caches
+// don't serve it for other hosts.
+func (r *Router) siteURL(w http.ResponseWriter, req *http.Request, path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	if r.config.BaseURL != "" {
+		return strings.TrimSuffix(r.config.BaseURL, "/") + path
+	}
+	Vary(w, "Host")
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + req.Host + path
+}
@@ -0,0 +1,140 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestSiteFiles(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	r.GET("/", noop).Name("home").Public()
+	r.GET("/pricing", noop).Name("pricing").Public()
+	r.GET("/users/{id}", noop).Name("users.show").Public()
+	r.GET("/admin", noop).Name("admin")
+	r.POST("/contact", noop).Name("contact").Public()
+
+	r.Robots(
+		router.RobotsRule{Disallow: []string{"/admin/"}},
+		router.RobotsRule{UserAgent: "BadBot", Disallow: []string{"/"}, CrawlDelay: 1500 * time.Millisecond},
+	)
+	r.Sitemap(router.PublicRoutes)
+	r.Favicon(fstest.MapFS{"favicon.ico": {Data: []byte{0, 0, 1, 0, 1, 0}}})
+
+	// Define test cases
+	tests := []struct {
+		name        string
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			"robots", "/robots.txt", http.StatusOK, "text/plain; charset=utf-8",
+			"User-agent: *\nDisallow: /admin/\n\nUser-agent: BadBot\nDisallow: /\nCrawl-delay: 1.5\n\nSitemap: http://example.com/sitemap.xml\n",
+		},
+		{
+			"sitemap", "/sitemap.xml", http.StatusOK, "application/xml; charset=utf-8",
+			`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>http://example.com/</loc></url><url><loc>http://example.com/pricing/</loc></url></urlset>`,
+		},
+		{"favicon", "/favicon.ico", http.StatusOK, "image/x-icon", "\x00\x00\x01\x00\x01\x00"},
+		{"no trailing slash", "/robots.txt/", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("expected content type %q, got %q", tt.contentType, got)
+			}
+			if !strings.HasPrefix(rec.Header().Get("Cache-Control"), "public, max-age=") {
+				t.Errorf("expected a public Cache-Control header, got %q", rec.Header().Get("Cache-Control"))
+			}
+			if rec.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("favicon etag", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/favicon.ico", nil))
+		req := httptest.NewRequest(http.MethodGet, "/favicon.ico", nil)
+		req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected status %d, got %d", http.StatusNotModified, rec.Code)
+		}
+	})
+}
+
+func TestSiteBaseURL(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name    string
+		baseURL string
+		robots  string
+		vary    string
+	}{
+		{"base url", "https://www.example.com/", "Sitemap: https://www.example.com/sitemap.xml", ""},
+		{"request host", "", "Sitemap: http://evil.example/sitemap.xml", "Host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new router instance
+			r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithConfig(router.RouterConfig{BaseURL: tt.baseURL}))
+			r.Robots()
+			r.Sitemap(func(req *http.Request) ([]router.SitemapURL, error) {
+				return []router.SitemapURL{{Loc: "/pricing"}}, nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/robots.txt", nil)
+			req.Host = "evil.example"
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if !strings.Contains(rec.Body.String(), tt.robots) {
+				t.Errorf("expected robots.txt to contain %q, got %q", tt.robots, rec.Body.String())
+			}
+			if got := rec.Header().Get("Vary"); got != tt.vary {
+				t.Errorf("expected Vary %q, got %q", tt.vary, got)
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil)
+			req.Host = "evil.example"
+			rec = httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if tt.baseURL != "" && !strings.Contains(rec.Body.String(), "<loc>https://www.example.com/pricing</loc>") {
+				t.Errorf("expected the sitemap to use the base URL, got %q", rec.Body.String())
+			}
+			if got := rec.Header().Get("Vary"); got != tt.vary {
+				t.Errorf("expected Vary %q, got %q", tt.vary, got)
+			}
+		})
+	}
+}
+
+func TestFaviconInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unsupported icon")
+		}
+	}()
+	router.NewRouter(router.WithMux(http.NewServeMux())).Favicon("favicon.ico")
+}
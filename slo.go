@@ -0,0 +1,15 @@
+package router
+
+import "time"
+
+// SLO is the service level objective of a route. Availability is the percentage of requests that have to succeed
+// within the latency, e.g. 99.9.
+type SLO struct {
+	Availability float64
+	Latency      time.Duration
+}
+
+// SLO sets the service level objective of the route, it's stored as "slo" metadata for the slo package.
+func (r *Route) SLO(availability float64, latency time.Duration) *Route {
+	return r.Set("slo", SLO{Availability: availability, Latency: latency})
+}
@@ -0,0 +1,225 @@
+// Package slo tracks the error budget of routes with a service level objective, and alerts when it burns too fast.
+package slo
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+const (
+	bucketSize = time.Minute
+	// budgetBucketSize is the size of the buckets of the budget window, which is much longer than the burn rate windows
+	budgetBucketSize = time.Hour
+)
+
+type Config struct {
+	// ShortWindow and LongWindow are the windows the burn rate is computed over, an alert fires when the burn rate
+	// of both is above AlertBurnRate. Defaults to 5 minutes and 1 hour
+	ShortWindow time.Duration
+	LongWindow  time.Duration
+	// AlertBurnRate is the burn rate that fires an alert, defaults to 14.4 which uses 2% of a 30 day budget per hour
+	AlertBurnRate float64
+	// MinRequests is the number of requests the short window needs before an alert fires, so a single failure after
+	// an idle period doesn't fire it. Defaults to 10
+	MinRequests int64
+	// BudgetWindow is the period of the objectives, the error budget is computed over it. Defaults to 30 days
+	BudgetWindow time.Duration
+	// OnAlert is called when a route starts burning its budget too fast, and again when it recovers
+	OnAlert func(alert Alert)
+}
+
+// Alert is passed to OnAlert when the burn rate of a route crosses the threshold.
+type Alert struct {
+	Route    string
+	Team     string
+	SLO      router.SLO
+	BurnRate float64
+	// Firing is false when the burn rate dropped below the threshold again
+	Firing bool
+}
+
+// Status is the state of the error budget of a route.
+type Status struct {
+	Route         string        `json:"route"`
+	Team          string        `json:"team,omitempty"`
+	Availability  float64       `json:"availability"`
+	Latency       time.Duration `json:"latency"`
+	Requests      int64         `json:"requests"`
+	BadRequests   int64         `json:"bad_requests"`
+	ShortBurnRate float64       `json:"short_burn_rate"`
+	LongBurnRate  float64       `json:"long_burn_rate"`
+	// BudgetRemaining is the share of the error budget of the BudgetWindow that is left, it's negative when more
+	// requests failed than the objective allows
+	BudgetRemaining float64 `json:"budget_remaining"`
+	Firing          bool    `json:"firing"`
+}
+
+// Tracker counts good and bad requests per route. Requests are bad when they fail with a 5xx status or take longer
+// than the latency of the objective.
+type Tracker struct {
+	config Config
+	mutex  sync.Mutex
+	routes map[*router.Route]*routeTracker
+}
+
+type bucket struct {
+	start time.Time
+	total int64
+	bad   int64
+}
+
+type routeTracker struct {
+	slo     router.SLO
+	buckets []bucket
+	// budgetBuckets count the requests of the budget window
+	budgetBuckets []bucket
+	firing        bool
+}
+
+func New(config Config) *Tracker {
+	if config.ShortWindow <= 0 {
+		config.ShortWindow = 5 * time.Minute
+	}
+	if config.LongWindow <= 0 {
+		config.LongWindow = time.Hour
+	}
+	if config.AlertBurnRate <= 0 {
+		config.AlertBurnRate = 14.4
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.BudgetWindow <= 0 {
+		config.BudgetWindow = 30 * 24 * time.Hour
+	}
+	return &Tracker{config: config, routes: make(map[*router.Route]*routeTracker)}
+}
+
+// Observe records a response, register it with r.OnResponse(tracker.Observe).
+func (t *Tracker) Observe(info router.ResponseInfo) {
+	value, ok := info.Route.Get("slo")
+	if !ok {
+		return
+	}
+	objective, ok := value.(router.SLO)
+	if !ok {
+		return
+	}
+	bad := info.StatusCode >= http.StatusInternalServerError || (objective.Latency > 0 && info.Duration > objective.Latency)
+
+	t.mutex.Lock()
+	rt, ok := t.routes[info.Route]
+	if !ok {
+		rt = &routeTracker{slo: objective}
+		t.routes[info.Route] = rt
+	}
+	now := time.Now()
+	rt.buckets = record(rt.buckets, now, bucketSize, bad, t.config.LongWindow)
+	rt.budgetBuckets = record(rt.budgetBuckets, now, budgetBucketSize, bad, t.config.BudgetWindow)
+	status := t.status(info.Route, rt, now)
+	var alert *Alert
+	shortTotal, _ := counts(rt.buckets, now, t.config.ShortWindow)
+	firing := shortTotal >= t.config.MinRequests && status.ShortBurnRate >= t.config.AlertBurnRate &&
+		status.LongBurnRate >= t.config.AlertBurnRate
+	if firing != rt.firing {
+		rt.firing = firing
+		alert = &Alert{Route: status.Route, Team: status.Team, SLO: objective, BurnRate: status.ShortBurnRate, Firing: firing}
+	}
+	t.mutex.Unlock()
+
+	if alert != nil && t.config.OnAlert != nil {
+		t.config.OnAlert(*alert)
+	}
+}
+
+// record counts the request in the buckets of the given size, and removes the buckets older than the window
+func record(buckets []bucket, now time.Time, size time.Duration, bad bool, window time.Duration) []bucket {
+	start := now.Truncate(size)
+	if len(buckets) == 0 || buckets[len(buckets)-1].start != start {
+		buckets = append(buckets, bucket{start: start})
+	}
+	current := &buckets[len(buckets)-1]
+	current.total++
+	if bad {
+		current.bad++
+	}
+
+	// Buckets older than the window are no longer needed
+	for len(buckets) > 0 && now.Sub(buckets[0].start) > window+size {
+		buckets = buckets[1:]
+	}
+	return buckets
+}
+
+// counts returns the total and bad requests of the buckets in the window
+func counts(buckets []bucket, now time.Time, window time.Duration) (total int64, bad int64) {
+	for _, b := range buckets {
+		if now.Sub(b.start) < window {
+			total += b.total
+			bad += b.bad
+		}
+	}
+	return total, bad
+}
+
+// burnRate is how fast the budget is used, 1 means the budget is used up exactly at the end of the period
+func (rt *routeTracker) burnRate(now time.Time, window time.Duration) float64 {
+	total, bad := counts(rt.buckets, now, window)
+	budget := 1 - rt.slo.Availability/100
+	if total == 0 || budget <= 0 {
+		return 0
+	}
+	return float64(bad) / float64(total) / budget
+}
+
+// budgetRemaining is the share of the error budget of the window that wasn't used by bad requests
+func (rt *routeTracker) budgetRemaining(now time.Time, window time.Duration) float64 {
+	total, bad := counts(rt.budgetBuckets, now, window)
+	budget := 1 - rt.slo.Availability/100
+	if total == 0 || budget <= 0 {
+		return 1
+	}
+	return 1 - float64(bad)/float64(total)/budget
+}
+
+func (t *Tracker) status(route *router.Route, rt *routeTracker, now time.Time) Status {
+	total, bad := counts(rt.buckets, now, t.config.LongWindow)
+	return Status{
+		Route:           route.FullPattern(),
+		Team:            route.GetTeam(),
+		Availability:    rt.slo.Availability,
+		Latency:         rt.slo.Latency,
+		Requests:        total,
+		BadRequests:     bad,
+		ShortBurnRate:   rt.burnRate(now, t.config.ShortWindow),
+		LongBurnRate:    rt.burnRate(now, t.config.LongWindow),
+		BudgetRemaining: rt.budgetRemaining(now, t.config.BudgetWindow),
+		Firing:          rt.firing,
+	}
+}
+
+// Statuses returns the status of every route that has received requests, e.g. to export them as metrics.
+func (t *Tracker) Statuses() []Status {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	statuses := make([]Status, 0, len(t.routes))
+	for route, rt := range t.routes {
+		statuses = append(statuses, t.status(route, rt, now))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Route < statuses[j].Route })
+	return statuses
+}
+
+// Report writes the statuses as JSON, it can be added to the admin API as an endpoint.
+func (t *Tracker) Report(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(t.Statuses())
+}
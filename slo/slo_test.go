@@ -0,0 +1,115 @@
+package slo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/slo"
+)
+
+func TestTracker(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+
+	var alerts []slo.Alert
+	tracker := slo.New(slo.Config{OnAlert: func(alert slo.Alert) { alerts = append(alerts, alert) }})
+	r.OnResponse(tracker.Observe)
+
+	failing := true
+	r.GET("/users", func(w http.ResponseWriter, req *http.Request) {
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}).SLO(99, 200*time.Millisecond)
+	r.GET("/untracked", func(w http.ResponseWriter, req *http.Request) {})
+
+	r.EnableAdmin("/__router", router.AdminConfig{Token: "secret", Endpoints: map[string]http.HandlerFunc{"/slo": tracker.Report}})
+
+	for range 4 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+	}
+	failing = false
+	for range 6 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/untracked/", nil))
+
+	statuses := tracker.Statuses()
+	if len(statuses) != 1 {
+		t.Fatalf("Expected 1 tracked route, got %d", len(statuses))
+	}
+	status := statuses[0]
+	if status.Requests != 10 || status.BadRequests != 4 {
+		t.Errorf("Expected 10 requests of which 4 bad, got %d and %d", status.Requests, status.BadRequests)
+	}
+	// 40% bad requests with a 1% budget burns 40 times too fast
+	if status.LongBurnRate < 39.9 || status.LongBurnRate > 40.1 {
+		t.Errorf("Expected a burn rate of 40, got %f", status.LongBurnRate)
+	}
+	// 4 bad requests use up 40 times the 1% budget of 10 requests
+	if status.BudgetRemaining < -39.1 || status.BudgetRemaining > -38.9 {
+		t.Errorf("Expected a remaining budget of -39, got %f", status.BudgetRemaining)
+	}
+	if !status.Firing {
+		t.Error("Expected the alert to be firing")
+	}
+	if len(alerts) != 1 || alerts[0].Route != "GET /users/{$}" || !alerts[0].Firing {
+		t.Errorf("Expected one firing alert for GET /users/{$}, got %+v", alerts)
+	}
+
+	// The statuses are exposed in the admin API
+	req := httptest.NewRequest(http.MethodGet, "/__router/slo/", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var reported []slo.Status
+	if err := json.NewDecoder(w.Body).Decode(&reported); err != nil {
+		t.Fatalf("Failed to decode the report: %v", err)
+	}
+	if len(reported) != 1 || reported[0].Requests != 10 {
+		t.Errorf("Expected the report to contain the tracked route, got %+v", reported)
+	}
+}
+
+func TestTrackerMinRequests(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	var alerts []slo.Alert
+	tracker := slo.New(slo.Config{OnAlert: func(alert slo.Alert) { alerts = append(alerts, alert) }})
+	r.OnResponse(tracker.Observe)
+
+	r.GET("/users", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}).SLO(99.9, 0)
+
+	// A single failure after an idle period doesn't fire an alert
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alert for a single request, got %+v", alerts)
+	}
+	if status := tracker.Statuses()[0]; status.BudgetRemaining > -998 || status.BudgetRemaining < -1000 {
+		t.Errorf("Expected a remaining budget of -999, got %f", status.BudgetRemaining)
+	}
+}
+
+func TestTrackerLatency(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	tracker := slo.New(slo.Config{})
+	r.OnResponse(tracker.Observe)
+
+	r.GET("/slow", func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	}).SLO(99.9, time.Millisecond)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow/", nil))
+
+	statuses := tracker.Statuses()
+	if len(statuses) != 1 || statuses[0].BadRequests != 1 {
+		t.Errorf("Expected the slow request to be bad, got %+v", statuses)
+	}
+}
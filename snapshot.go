@@ -0,0 +1,301 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// snapshotVersion is the version of the format written by TableMatcher.Snapshot
+const snapshotVersion = 1
+
+// TableMatcher is a Matcher that matches the patterns of the routes like the ServeMux, with a tree of path segments
+// per method. Adding a route takes time linear in the length of its pattern, while the ServeMux compares every new
+// pattern with the patterns registered before it. The tree holds the patterns but no handlers, so it can be saved
+// with Snapshot and loaded at startup with LoadTableMatcher. Add then only binds the routes to the patterns of the
+// snapshot. Routes missing from the snapshot are added to the tree, patterns of the snapshot without a route don't
+// match, so an outdated snapshot is slower to load but still correct.
+//
+// Static segments take precedence over parameters, patterns ending in "{$}" over prefixes, and routes with a method
+// over routes without one. GET routes match HEAD requests as well. Hosts aren't matched.
+//
+//	matcher := router.NewTableMatcher()
+//	r := router.NewRouter(router.WithMatcher(matcher))
+type TableMatcher struct {
+	mutex sync.RWMutex
+	trees map[string]*tableNode
+	// routes are the registered routes by pattern, snapshot the patterns of the loaded snapshot
+	routes   map[string]*Route
+	snapshot map[string]bool
+}
+
+// tableNode is a path segment in the tree of a TableMatcher
+type tableNode struct {
+	Static map[string]*tableNode `json:"static,omitempty"`
+	Param  *tableNode            `json:"param,omitempty"`
+	// Exact matches when the path ends after this segment, Prefix when it continues
+	Exact  *tableLeaf `json:"exact,omitempty"`
+	Prefix *tableLeaf `json:"prefix,omitempty"`
+}
+
+// tableLeaf is the pattern of a route with its path parameters
+type tableLeaf struct {
+	Pattern string       `json:"pattern"`
+	Params  []tableParam `json:"params,omitempty"`
+}
+
+// tableParam is a path parameter at the index of its segment, a rest parameter like {path...} gets the remaining
+// segments
+type tableParam struct {
+	Name  string `json:"name"`
+	Index int    `json:"index"`
+	Rest  bool   `json:"rest,omitempty"`
+}
+
+// NewTableMatcher returns an empty TableMatcher.
+func NewTableMatcher() *TableMatcher {
+	return &TableMatcher{trees: make(map[string]*tableNode), routes: make(map[string]*Route)}
+}
+
+// LoadTableMatcher returns a TableMatcher with the route table saved by Snapshot.
+//
+//	file, err := os.Open("routes.snapshot")
+//	...
+//	matcher, err := router.LoadTableMatcher(file)
+func LoadTableMatcher(r io.Reader) (*TableMatcher, error) {
+	var snapshot struct {
+		Version int                   `json:"version"`
+		Trees   map[string]*tableNode `json:"trees"`
+	}
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("router: can't load the route table: %w", err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return nil, fmt.Errorf("router: can't load the route table of version %d, expected version %d", snapshot.Version, snapshotVersion)
+	}
+
+	m := NewTableMatcher()
+	m.snapshot = make(map[string]bool)
+	for method, tree := range snapshot.Trees {
+		if tree == nil {
+			continue
+		}
+		m.trees[method] = tree
+		tree.patterns(m.snapshot)
+	}
+	return m, nil
+}
+
+// Snapshot writes the route table, so it can be loaded with LoadTableMatcher at the next start. Call it after the
+// routes were set up, e.g. after Router.Build. Patterns of a loaded snapshot without a route are left out.
+func (m *TableMatcher) Snapshot(w io.Writer) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	trees := make(map[string]*tableNode, len(m.trees))
+	for method, tree := range m.trees {
+		if tree = tree.bound(m.routes); tree != nil {
+			trees[method] = tree
+		}
+	}
+	return json.NewEncoder(w).Encode(map[string]any{"version": snapshotVersion, "trees": trees})
+}
+
+func (m *TableMatcher) Add(route *Route) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	pattern := route.FullPattern()
+	if other, ok := m.routes[pattern]; ok {
+		return fmt.Errorf("pattern %s is already registered at %s", pattern, other.Source())
+	}
+	if !m.snapshot[pattern] {
+		if err := m.insert(route.Method, pattern); err != nil {
+			return err
+		}
+	}
+	m.routes[pattern] = route
+	return nil
+}
+
+func (m *TableMatcher) Match(method string, host string, path string) (*Route, map[string]string, bool) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, nil, false
+	}
+	segments := strings.Split(path[1:], "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, nil, false
+		}
+		segments[i] = unescaped
+	}
+
+	methods := []string{method, ""}
+	if method == "HEAD" {
+		methods = []string{method, "GET", ""}
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for _, method := range methods {
+		tree := m.trees[method]
+		if tree == nil {
+			continue
+		}
+		leaf := tree.match(segments, 0, m.routes)
+		if leaf == nil {
+			continue
+		}
+		params := make(map[string]string, len(leaf.Params))
+		for _, param := range leaf.Params {
+			if param.Rest {
+				params[param.Name] = strings.Join(segments[param.Index:], "/")
+			} else {
+				params[param.Name] = segments[param.Index]
+			}
+		}
+		return m.routes[leaf.Pattern], params, true
+	}
+	return nil, nil, false
+}
+
+// insert adds the pattern to the tree of the method. The mutex is held.
+func (m *TableMatcher) insert(method string, pattern string) error {
+	path := pattern
+	if method != "" {
+		path = strings.TrimPrefix(pattern, method+" ")
+	}
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("pattern %s doesn't start with a slash", pattern)
+	}
+	segments := strings.Split(path[1:], "/")
+
+	leaf := &tableLeaf{Pattern: pattern}
+	prefix := false
+	last := segments[len(segments)-1]
+	switch {
+	case last == "{$}":
+		// The path ends with a slash, which is an empty segment
+		segments[len(segments)-1] = ""
+	case last == "":
+		segments = segments[:len(segments)-1]
+		prefix = true
+	case strings.HasPrefix(last, "{") && strings.HasSuffix(last, "...}"):
+		segments = segments[:len(segments)-1]
+		prefix = true
+		leaf.Params = append(leaf.Params, tableParam{Name: strings.TrimSuffix(last[1:], "...}"), Index: len(segments), Rest: true})
+	}
+
+	if m.trees[method] == nil {
+		m.trees[method] = &tableNode{}
+	}
+	node := m.trees[method]
+	var params []tableParam
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, tableParam{Name: segment[1 : len(segment)-1], Index: i})
+			if node.Param == nil {
+				node.Param = &tableNode{}
+			}
+			node = node.Param
+			continue
+		}
+		if node.Static == nil {
+			node.Static = make(map[string]*tableNode)
+		}
+		child := node.Static[segment]
+		if child == nil {
+			child = &tableNode{}
+			node.Static[segment] = child
+		}
+		node = child
+	}
+	leaf.Params = append(params, leaf.Params...)
+
+	slot := &node.Exact
+	if prefix {
+		slot = &node.Prefix
+	}
+	// Patterns of a loaded snapshot without a route are replaced, e.g. when a parameter was renamed
+	if *slot != nil && m.routes[(*slot).Pattern] != nil {
+		return fmt.Errorf("pattern %s conflicts with %s registered at %s", pattern, (*slot).Pattern, m.routes[(*slot).Pattern].Source())
+	}
+	if *slot != nil {
+		delete(m.snapshot, (*slot).Pattern)
+	}
+	*slot = leaf
+	return nil
+}
+
+// match returns the leaf with a route that matches the segments from index i on, static segments are tried before
+// parameters and prefixes
+func (n *tableNode) match(segments []string, i int, routes map[string]*Route) *tableLeaf {
+	if i == len(segments) {
+		if n.Exact != nil && routes[n.Exact.Pattern] != nil {
+			return n.Exact
+		}
+		return nil
+	}
+	if child := n.Static[segments[i]]; child != nil {
+		if leaf := child.match(segments, i+1, routes); leaf != nil {
+			return leaf
+		}
+	}
+	if n.Param != nil && segments[i] != "" {
+		if leaf := n.Param.match(segments, i+1, routes); leaf != nil {
+			return leaf
+		}
+	}
+	if n.Prefix != nil && routes[n.Prefix.Pattern] != nil {
+		return n.Prefix
+	}
+	return nil
+}
+
+// patterns adds the patterns of the tree to the set
+func (n *tableNode) patterns(set map[string]bool) {
+	for _, leaf := range []*tableLeaf{n.Exact, n.Prefix} {
+		if leaf != nil {
+			set[leaf.Pattern] = true
+		}
+	}
+	for _, child := range n.Static {
+		if child != nil {
+			child.patterns(set)
+		}
+	}
+	if n.Param != nil {
+		n.Param.patterns(set)
+	}
+}
+
+// bound returns a copy of the tree with only the patterns that have a route, or nil if none of them has one
+func (n *tableNode) bound(routes map[string]*Route) *tableNode {
+	copied := &tableNode{}
+	if n.Exact != nil && routes[n.Exact.Pattern] != nil {
+		copied.Exact = n.Exact
+	}
+	if n.Prefix != nil && routes[n.Prefix.Pattern] != nil {
+		copied.Prefix = n.Prefix
+	}
+	for segment, child := range n.Static {
+		if child == nil {
+			continue
+		}
+		if child = child.bound(routes); child != nil {
+			if copied.Static == nil {
+				copied.Static = make(map[string]*tableNode)
+			}
+			copied.Static[segment] = child
+		}
+	}
+	if n.Param != nil {
+		copied.Param = n.Param.bound(routes)
+	}
+	if copied.Exact == nil && copied.Prefix == nil && copied.Static == nil && copied.Param == nil {
+		return nil
+	}
+	return copied
+}
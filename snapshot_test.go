@@ -0,0 +1,175 @@
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestTableMatcher(t *testing.T) {
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s %s", name, r.PathValue("id"))
+		}
+	}
+	routes := func(r *router.Router) {
+		r.GET("/users", handler("users"))
+		r.GET("/users/{id}", handler("user"))
+		r.GET("/users/me", handler("me"))
+		r.POST("/users/{id}", handler("update"))
+		r.Robots(router.RobotsRule{Disallow: []string{"/admin/"}})
+		r.Mount("/static", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "static %s", r.URL.Path)
+		}))
+	}
+
+	// Define test cases
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{"static", http.MethodGet, "/users/", http.StatusOK, "users "},
+		{"param", http.MethodGet, "/users/42/", http.StatusOK, "user 42"},
+		{"static before param", http.MethodGet, "/users/me/", http.StatusOK, "me "},
+		{"encoded param", http.MethodGet, "/users/a%2Fb/", http.StatusOK, "user a/b"},
+		{"method", http.MethodPost, "/users/42/", http.StatusOK, "update 42"},
+		{"head", http.MethodHead, "/users/42/", http.StatusOK, "user 42"},
+		{"exact", http.MethodGet, "/robots.txt", http.StatusOK, "User-agent: *\nDisallow: /admin/\n"},
+		{"prefix", http.MethodDelete, "/static/css/app.css", http.StatusOK, "static /css/app.css"},
+		{"no trailing slash", http.MethodGet, "/users/42", http.StatusNotFound, ""},
+		{"too long", http.MethodGet, "/users/42/posts/", http.StatusNotFound, ""},
+		{"unknown method", http.MethodPut, "/users/42/", http.StatusNotFound, ""},
+	}
+
+	run := func(t *testing.T, r *router.Router) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := httptest.NewRecorder()
+				r.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+				if rr.Code != tt.status {
+					t.Fatalf("Expected status %d, got %d", tt.status, rr.Code)
+				}
+				if tt.status == http.StatusOK && rr.Body.String() != tt.body {
+					t.Errorf("Expected body %q, got %q", tt.body, rr.Body.String())
+				}
+			})
+		}
+	}
+
+	// Create a new router instance with a table matcher and save its snapshot
+	matcher := router.NewTableMatcher()
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithMatcher(matcher))
+	routes(r)
+	run(t, r)
+	var snapshot bytes.Buffer
+	if err := matcher.Snapshot(&snapshot); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("snapshot", func(t *testing.T) {
+		loaded, err := router.LoadTableMatcher(bytes.NewReader(snapshot.Bytes()))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithMatcher(loaded))
+		routes(r)
+		run(t, r)
+	})
+
+	t.Run("outdated snapshot", func(t *testing.T) {
+		loaded, err := router.LoadTableMatcher(bytes.NewReader(snapshot.Bytes()))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// The route of /users/me was removed, a route was added and a parameter renamed
+		r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithMatcher(loaded))
+		r.GET("/users/{userID}", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "user %s", r.PathValue("userID"))
+		})
+		r.GET("/teams/{id}", handler("team"))
+
+		for path, body := range map[string]string{"/users/me/": "user me", "/teams/7/": "team 7"} {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+			if rr.Code != http.StatusOK || rr.Body.String() != body {
+				t.Errorf("Expected %q for %s, got %d %q", body, path, rr.Code, rr.Body.String())
+			}
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/robots.txt", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a pattern without a route, got %d", rr.Code)
+		}
+
+		// Saving it again leaves out the patterns without a route
+		var saved bytes.Buffer
+		if err := loaded.Snapshot(&saved); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if strings.Contains(saved.String(), "robots.txt") || !strings.Contains(saved.String(), "/teams/{id}/{$}") {
+			t.Errorf("Expected the snapshot to have only the registered patterns, got %s", saved.String())
+		}
+	})
+
+	t.Run("invalid snapshot", func(t *testing.T) {
+		for _, snapshot := range []string{"", `{"version": 2}`} {
+			if _, err := router.LoadTableMatcher(strings.NewReader(snapshot)); err == nil {
+				t.Errorf("Expected an error for %q", snapshot)
+			}
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		defer func() {
+			if recovered := fmt.Sprint(recover()); !strings.Contains(recovered, "conflicts with GET /users/{id}/{$}") {
+				t.Errorf("Expected a conflict, got %s", recovered)
+			}
+		}()
+		r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithMatcher(router.NewTableMatcher()))
+		r.GET("/users/{id}", handler("user"))
+		r.GET("/users/{name}", handler("user"))
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func BenchmarkTableMatcherSnapshot(b *testing.B) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	routes := func(r *router.Router) {
+		for i := range 10000 {
+			r.GET(fmt.Sprintf("/resources%d/{id}/items/{item}", i), handler)
+		}
+	}
+	matcher := router.NewTableMatcher()
+	r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithMatcher(matcher))
+	routes(r)
+	if err := r.Build(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	var snapshot bytes.Buffer
+	if err := matcher.Snapshot(&snapshot); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		loaded, err := router.LoadTableMatcher(bytes.NewReader(snapshot.Bytes()))
+		if err != nil {
+			b.Fatal(err)
+		}
+		r := router.NewRouter(router.WithMux(http.NewServeMux()), router.WithMatcher(loaded))
+		routes(r)
+		if err := r.Build(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/resources9999/1/items/2/", nil))
+	}
+}
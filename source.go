@@ -0,0 +1,60 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Source returns the file and line where the route was registered, e.g. "users/routes.go:12". It's empty for routes
+// that weren't registered by RegisterRoute or one of its shorthands.
+func (r *Route) Source() string {
+	if r.file == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", r.file, r.line)
+}
+
+// routerPackage is the prefix of the functions of this package in stack traces
+var routerPackage = reflect.TypeFor[Router]().PkgPath() + "."
+
+// callSite returns the file and line of the first caller outside of the router package, which registered the route
+func callSite() (string, int) {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, routerPackage) {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return "", 0
+		}
+	}
+}
+
+// handleRoute registers the route on the mux. The mux panics for invalid and conflicting patterns with its own call
+// site, so the panic is replaced by one pointing to where the routes were registered.
+func (r *Router) handleRoute(mux *http.ServeMux, route *Route, handler http.HandlerFunc) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		for _, other := range r.Routes() {
+			if other == route || other.fullPattern == "" {
+				continue
+			}
+			mux := http.NewServeMux()
+			if handlePattern(mux, other.fullPattern) == nil && handlePattern(mux, route.fullPattern) != nil {
+				panic(fmt.Sprintf("router: %s registered at %s conflicts with %s registered at %s",
+					route.fullPattern, route.Source(), other.fullPattern, other.Source()))
+			}
+		}
+		panic(fmt.Sprintf("router: %s registered at %s: %v", route.fullPattern, route.Source(), recovered))
+	}()
+	// The compiled handler is registered as it is, another closure around it would only add a call per request
+	mux.Handle(route.fullPattern, handler)
+}
@@ -0,0 +1,56 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestRouteSource(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	_, file, line, _ := runtime.Caller(0)
+	route := r.GET("/users/{id}", handler)
+	group := r.Group("/api", func(r *router.Router) {
+		r.GET("/posts", handler)
+	})
+
+	if want := fmt.Sprintf("%s:%d", file, line+1); route.Source() != want {
+		t.Errorf("Expected source %s, got %s", want, route.Source())
+	}
+	if want := fmt.Sprintf("%s:%d", file, line+3); group.Routes[0].Source() != want {
+		t.Errorf("Expected source %s for the group route, got %s", want, group.Routes[0].Source())
+	}
+
+	explanation, err := r.Explain(http.MethodGet, "", "/users/1/")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if explanation.Matched == nil || explanation.Matched.Source != route.Source() {
+		t.Errorf("Expected the explanation to include the source, got %+v", explanation.Matched)
+	}
+}
+
+func TestConflictSource(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	first := r.GET("/users/{id}", handler)
+	second := r.GET("/users/{name}", handler)
+
+	defer func() {
+		message := fmt.Sprint(recover())
+		if !strings.Contains(message, second.Source()+" conflicts with") || !strings.HasSuffix(message, first.Source()) {
+			t.Errorf("Expected the panic to point to both routes, got %s", message)
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1/", nil))
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/gogo-framework/router/cookies"
+)
+
+// Variant is a handler that gets the given share of the traffic of a split route.
+type Variant struct {
+	Name    string
+	Weight  float64
+	Handler http.HandlerFunc
+}
+
+type Variants []Variant
+
+type SplitConfig struct {
+	// Header identifies the user, e.g. "X-User-ID". When it's set, the variant is chosen by hashing its value,
+	// so the same user always gets the same variant without a cookie
+	Header string
+	// Cookie stores the variant of clients without the header, defaults to "split_" followed by a hash of the pattern
+	Cookie string
+	// CookieMaxAge is how long clients keep their variant, defaults to 30 days
+	CookieMaxAge time.Duration
+}
+
+type variantContextKey struct{}
+
+// SplitVariant returns the name of the variant that handles the request, e.g. to add it to analytics events.
+func SplitVariant(r *http.Request) string {
+	name, _ := r.Context().Value(variantContextKey{}).(string)
+	return name
+}
+
+// Split registers a route for all methods that divides the traffic between the variants by their weights.
+// Clients are assigned to a variant once, which is remembered using a cookie.
+func (r *Router) Split(pattern string, variants Variants) *Route {
+	return r.SplitWithConfig(pattern, variants, SplitConfig{})
+}
+
+func (r *Router) SplitWithConfig(pattern string, variants Variants, config SplitConfig) *Route {
+	if len(variants) == 0 {
+		panic("router: Split needs at least one variant")
+	}
+	if config.Cookie == "" {
+		h := fnv.New32a()
+		h.Write([]byte(pattern))
+		config.Cookie = fmt.Sprintf("split_%08x", h.Sum32())
+	}
+	if config.CookieMaxAge <= 0 {
+		config.CookieMaxAge = 30 * 24 * time.Hour
+	}
+
+	var total float64
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	// pick returns the variant for a number between 0 and 1
+	pick := func(n float64) Variant {
+		n *= total
+		for _, variant := range variants {
+			if n < variant.Weight {
+				return variant
+			}
+			n -= variant.Weight
+		}
+		return variants[len(variants)-1]
+	}
+	// assigned returns the variant stored in the cookie, variants without weight are no longer assigned
+	assigned := func(req *http.Request) (Variant, bool) {
+		cookie, err := req.Cookie(config.Cookie)
+		if err != nil {
+			return Variant{}, false
+		}
+		for _, variant := range variants {
+			if variant.Name == cookie.Value && variant.Weight > 0 {
+				return variant, true
+			}
+		}
+		return Variant{}, false
+	}
+
+	return r.RegisterRoute("", pattern, func(w http.ResponseWriter, req *http.Request) {
+		var id string
+		if config.Header != "" {
+			id = req.Header.Get(config.Header)
+			Vary(w, config.Header)
+		}
+
+		var variant Variant
+		if id != "" {
+			h := fnv.New64a()
+			h.Write([]byte(pattern + "|" + id))
+			variant = pick(float64(h.Sum64()) / math.MaxUint64)
+		} else if previous, ok := assigned(req); ok {
+			Vary(w, "Cookie")
+			variant = previous
+		} else {
+			variant = pick(rand.Float64())
+			Vary(w, "Cookie")
+			cookies.Set(w, req, &http.Cookie{
+				Name:   config.Cookie,
+				Value:  variant.Name,
+				MaxAge: int(config.CookieMaxAge.Seconds()),
+			})
+		}
+		variant.Handler(w, req.WithContext(context.WithValue(req.Context(), variantContextKey{}, variant.Name)))
+	})
+}
@@ -0,0 +1,84 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestSplit(t *testing.T) {
+	variantHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(router.SplitVariant(r)))
+	}
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Split("/checkout", router.Variants{
+		{"A", 0.9, variantHandler},
+		{"B", 0.1, variantHandler},
+	})
+	r.SplitWithConfig("/search", router.Variants{
+		{"old", 0.5, variantHandler},
+		{"new", 0.5, variantHandler},
+	}, router.SplitConfig{Header: "X-User-ID"})
+
+	do := func(path string, cookie *http.Cookie, userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		if userID != "" {
+			req.Header.Set("X-User-ID", userID)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// The traffic is divided by the weights of the variants
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		counts[do("/checkout/", nil, "").Body.String()]++
+	}
+	if counts["A"] < 800 || counts["B"] < 50 || counts["A"]+counts["B"] != 1000 {
+		t.Errorf("Expected about 900 A and 100 B, got %v", counts)
+	}
+
+	// The variant is remembered using a cookie
+	rr := do("/checkout/", nil, "")
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 1 || !strings.HasPrefix(cookies[0].Name, "split_") {
+		t.Fatalf("Expected a split cookie, got %v", cookies)
+	}
+	for i := 0; i < 20; i++ {
+		sticky := do("/checkout/", cookies[0], "")
+		if sticky.Body.String() != rr.Body.String() {
+			t.Fatalf("Expected variant %q to stick, got %q", rr.Body.String(), sticky.Body.String())
+		}
+		if len(sticky.Result().Cookies()) != 0 {
+			t.Fatal("Expected no new cookie for an assigned client")
+		}
+	}
+
+	// An unknown variant in the cookie is replaced
+	rr = do("/checkout/", &http.Cookie{Name: cookies[0].Name, Value: "C"}, "")
+	if body := rr.Body.String(); body != "A" && body != "B" {
+		t.Errorf("Expected a known variant, got %q", body)
+	}
+
+	// The variant of a user is based on the header, without cookies
+	for _, userID := range []string{"1", "2", "3", "4"} {
+		first := do("/search/", nil, userID)
+		if len(first.Result().Cookies()) != 0 {
+			t.Errorf("Expected no cookie when the header is set")
+		}
+		for i := 0; i < 5; i++ {
+			if again := do("/search/", nil, userID); again.Body.String() != first.Body.String() {
+				t.Errorf("Expected user %s to always get variant %q, got %q", userID, first.Body.String(), again.Body.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package router
+
+import (
+	"unique"
+	"unsafe"
+)
+
+// muxBytesPerRoute is about what http.ServeMux allocates for a pattern with a few segments, measured with Go 1.23
+const muxBytesPerRoute = 850
+
+// Stats describe the size of the route table, for capacity planning.
+type Stats struct {
+	Routes int
+	Groups int
+	// Memory is the approximate number of bytes used by the routes, their patterns and the ServeMux. Memory captured
+	// by handlers and middlewares isn't included.
+	Memory int64
+}
+
+// Stats returns the number of routes and groups and the approximate memory they use. The patterns registered on the
+// mux are only counted once the routes have been set up.
+func (r *Router) Stats() Stats {
+	stats := Stats{}
+	// Interned strings are shared by many routes, so they're counted once
+	seen := make(map[string]bool)
+	countString := func(s string) {
+		if !seen[s] {
+			seen[s] = true
+			stats.Memory += int64(len(s))
+		}
+	}
+
+	r.routesMutex.RLock()
+	stats.Groups = len(r.routeGroups)
+	for _, routeGroup := range r.routeGroups {
+		stats.Memory += int64(unsafe.Sizeof(RouteGroup{})) + int64(cap(routeGroup.Routes))*int64(unsafe.Sizeof(&Route{}))
+		countString(routeGroup.Prefix)
+	}
+	r.routesMutex.RUnlock()
+	for _, route := range r.Routes() {
+		stats.Routes++
+		stats.Memory += int64(unsafe.Sizeof(Route{})) + int64(cap(route.Middlewares))*int64(unsafe.Sizeof(Middleware(nil)))
+		countString(route.Method)
+		countString(route.Pattern)
+		countString(route.file)
+		if route.fullPattern != "" {
+			stats.Memory += int64(len(route.fullPattern)) + muxBytesPerRoute
+		}
+		for key := range route.Metadata {
+			// A map entry is about the size of the key and an interface value, plus the overhead of the map
+			countString(key)
+			stats.Memory += int64(unsafe.Sizeof("")) + int64(unsafe.Sizeof(any(nil))) + 16
+		}
+	}
+	return stats
+}
+
+// intern returns a copy of the string that is shared with equal strings, as the methods, patterns and group prefixes
+// of large route tables, e.g. loaded from a manifest, repeat a lot
+func intern(s string) string {
+	return unique.Make(s).Value()
+}
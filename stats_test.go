@@ -0,0 +1,47 @@
+package router_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"unsafe"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestStats(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	// Create a new router instance with a group per tenant
+	r := router.NewRouter()
+	r.GET("/health", handler)
+	var groups []*router.RouteGroup
+	for i := range 10 {
+		groups = append(groups, r.Group(fmt.Sprintf("/tenants/t%d", i), func(r *router.Router) {
+			r.GET(fmt.Sprint("/users/", "{id}"), handler)
+			r.POST("/users", handler)
+		}))
+	}
+
+	before := r.Stats()
+	if before.Routes != 21 || before.Groups != 10 {
+		t.Errorf("Expected 21 routes in 10 groups, got %+v", before)
+	}
+	if before.Memory <= 0 {
+		t.Errorf("Expected the memory of the routes, got %d", before.Memory)
+	}
+
+	if err := r.Build(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if after := r.Stats(); after.Memory <= before.Memory {
+		t.Errorf("Expected the mux to add to the memory, got %d before and %d after setting up", before.Memory, after.Memory)
+	}
+
+	// The patterns are built at runtime, but every group shares the same string
+	first, last := groups[0].Routes[0].Pattern, groups[9].Routes[0].Pattern
+	if unsafe.StringData(first) != unsafe.StringData(last) {
+		t.Errorf("Expected the patterns %q and %q to be interned", first, last)
+	}
+}
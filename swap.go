@@ -0,0 +1,90 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// swapState holds the route table that replaced the one of the router
+type swapState struct {
+	mutex sync.Mutex
+	// active serves the requests of the router when it's set
+	active   atomic.Pointer[Router]
+	previous *Router
+}
+
+// Swap replaces the routes the router serves with the routes of next, e.g. for a gateway that applies a new routing
+// config without a restart. Next is built first, including its warmups, and only swapped in when that succeeds, so a
+// broken config never serves requests. Requests in flight finish on the routes they started on. The servers of the
+// router keep running, and Rollback swaps the previous routes back in. Swapping in the router itself serves its own
+// routes again.
+//
+//	next, err := buildGateway(config)
+//	if err := r.Swap(ctx, next); err != nil {
+//		log.Printf("keeping the current routes: %v", err)
+//	}
+func (r *Router) Swap(ctx context.Context, next *Router) (err error) {
+	r.swap.mutex.Lock()
+	defer r.swap.mutex.Unlock()
+
+	if next != r {
+		// Setting up the routes panics for invalid patterns, which shouldn't take down the routes being served
+		err = func() (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					err = fmt.Errorf("router: swap failed: %v", recovered)
+				}
+			}()
+			return next.Build(ctx)
+		}()
+		if err != nil {
+			return err
+		}
+	}
+	r.swap.previous = r.Active()
+	r.activate(next)
+	return nil
+}
+
+// Rollback swaps the routes that were served before the last Swap back in. It returns false when there was no swap
+// to roll back.
+func (r *Router) Rollback() bool {
+	r.swap.mutex.Lock()
+	defer r.swap.mutex.Unlock()
+
+	if r.swap.previous == nil {
+		return false
+	}
+	previous := r.Active()
+	r.activate(r.swap.previous)
+	r.swap.previous = previous
+	return true
+}
+
+// Active returns the router whose routes are served, which is the router itself until another one is swapped in.
+func (r *Router) Active() *Router {
+	if active := r.swap.active.Load(); active != nil {
+		return active
+	}
+	return r
+}
+
+func (r *Router) activate(next *Router) {
+	if next == r {
+		next = nil
+	}
+	r.swap.active.Store(next)
+}
+
+// serveSwapped serves the request with the swapped in routes, it reports false when the router serves its own routes
+func (r *Router) serveSwapped(w http.ResponseWriter, req *http.Request) bool {
+	active := r.swap.active.Load()
+	if active == nil {
+		return false
+	}
+	active.ServeHTTP(w, req)
+	return true
+}
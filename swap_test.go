@@ -0,0 +1,82 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestSwap(t *testing.T) {
+	// newTable creates a router that responds with its version
+	newTable := func(version string) *router.Router {
+		r := router.NewRouter(router.WithMux(http.NewServeMux()))
+		r.GET("/version", func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, version)
+		})
+		return r
+	}
+
+	// Create a new router instance
+	r := newTable("v1")
+	get := func() string {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version/", nil))
+		return rec.Body.String()
+	}
+
+	if r.Rollback() {
+		t.Error("expected no rollback before a swap")
+	}
+
+	v2 := newTable("v2")
+	if err := r.Swap(context.Background(), v2); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(); got != "v2" || r.Active() != v2 {
+		t.Errorf("expected v2 to be served, got %q", got)
+	}
+
+	// Routes that fail to build are never served
+	invalid := newTable("invalid")
+	invalid.GET("/{", func(w http.ResponseWriter, req *http.Request) {})
+	if err := r.Swap(context.Background(), invalid); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+	failing := newTable("failing")
+	failing.GET("/cache", func(w http.ResponseWriter, req *http.Request) {}).Warmup(func(ctx context.Context) error {
+		return errors.New("cache unavailable")
+	})
+	if err := r.Swap(context.Background(), failing); err == nil {
+		t.Error("expected an error for a failing warmup")
+	}
+	if got := get(); got != "v2" {
+		t.Errorf("expected v2 to keep serving, got %q", got)
+	}
+
+	if err := r.Swap(context.Background(), newTable("v3")); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Rollback() {
+		t.Fatal("expected a rollback")
+	}
+	if got := get(); got != "v2" {
+		t.Errorf("expected the rollback to serve v2, got %q", got)
+	}
+
+	// Swapping the router itself in serves its own routes again
+	if err := r.Swap(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(); got != "v1" || r.Active() != r {
+		t.Errorf("expected v1 to be served, got %q", got)
+	}
+	r.Rollback()
+	if got := get(); got != "v2" {
+		t.Errorf("expected the rollback to serve v2, got %q", got)
+	}
+}
@@ -0,0 +1,20 @@
+package router
+
+// Team tags the route with the team or cost center that owns it, e.g. "payments". It's stored as "team" metadata, so
+// Set("team", "payments") works the same. The access log, traces, the admin API and the slo package include the
+// team, so usage and errors can be attributed per team.
+func (r *Route) Team(team string) *Route {
+	return r.Set("team", team)
+}
+
+// Team tags all routes of the group with the team that owns them, like Route.Team.
+func (rg *RouteGroup) Team(team string) *RouteGroup {
+	return rg.Set("team", team)
+}
+
+// GetTeam returns the team of the route or its group, or an empty string if it has none.
+func (r *Route) GetTeam() string {
+	value, _ := r.Get("team")
+	team, _ := value.(string)
+	return team
+}
@@ -0,0 +1,61 @@
+package router_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestTeam(t *testing.T) {
+	var logs bytes.Buffer
+
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.OnResponse(router.AccessLog(slog.New(slog.NewJSONHandler(&logs, nil))))
+	var route *router.Route
+	r.OnResponse(func(info router.ResponseInfo) { route = info.Route })
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	r.GET("/health", noop)
+	r.GET("/search", noop).Set("team", "discovery")
+	r.Group("/payments", func(r *router.Router) {
+		r.GET("/invoices", noop)
+		r.GET("/fraud", noop).Team("risk")
+	}).Team("payments")
+
+	// Define test cases
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"no team", "/health/", ""},
+		{"metadata", "/search/", "discovery"},
+		{"group team", "/payments/invoices/", "payments"},
+		{"route overrides group", "/payments/fraud/", "risk"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if team := route.GetTeam(); team != tt.expected {
+				t.Errorf("expected team %q, got %q", tt.expected, team)
+			}
+			var entry map[string]any
+			if err := json.NewDecoder(strings.NewReader(logs.String())).Decode(&entry); err != nil {
+				t.Fatal(err)
+			}
+			if team, _ := entry["team"].(string); team != tt.expected {
+				t.Errorf("expected the access log to have team %q, got %q", tt.expected, team)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultTimeLayouts are used to parse times when RouterConfig.TimeLayouts is empty. Besides RFC 3339 they accept the
+// values of HTML date and datetime-local inputs.
+var DefaultTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+// TimeLayouts returns the time layouts of the router that matched the request, or DefaultTimeLayouts.
+func TimeLayouts(r *http.Request) []string {
+	if router := routerFromRequest(r); router != nil && len(router.config.TimeLayouts) > 0 {
+		return router.config.TimeLayouts
+	}
+	return DefaultTimeLayouts
+}
@@ -0,0 +1,284 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxTraces = 100
+
+// Trace is the timing of a request, with a span for every middleware and the handler of the route.
+type Trace struct {
+	ID       string        `json:"id"`
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Route    string        `json:"route"`
+	Team     string        `json:"team,omitempty"`
+	Status   int           `json:"status"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Spans    []Span        `json:"spans"`
+}
+
+// Span is a middleware or handler in a trace. Start is relative to the start of the request and Depth is the
+// position of the layer in the chain, so the spans can be drawn as a waterfall.
+type Span struct {
+	Name     string        `json:"name"`
+	Depth    int           `json:"depth"`
+	Start    time.Duration `json:"start"`
+	Duration time.Duration `json:"duration"`
+}
+
+// tracer keeps the most recent traces
+type tracer struct {
+	mutex  sync.Mutex
+	traces map[string]*Trace
+	order  []string
+}
+
+func newTracer() *tracer {
+	return &tracer{traces: make(map[string]*Trace)}
+}
+
+func (t *tracer) add(trace *Trace) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.order) == maxTraces {
+		delete(t.traces, t.order[0])
+		t.order = t.order[1:]
+	}
+	t.traces[trace.ID] = trace
+	t.order = append(t.order, trace.ID)
+}
+
+func (t *tracer) get(id string) *Trace {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.traces[id]
+}
+
+// recent returns the traces, newest first
+func (t *tracer) recent() []*Trace {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	traces := make([]*Trace, 0, len(t.order))
+	for i := len(t.order) - 1; i >= 0; i-- {
+		traces = append(traces, t.traces[t.order[i]])
+	}
+	return traces
+}
+
+// activeTrace is the trace of the current request, it's stored in the request context
+type activeTrace struct {
+	mutex sync.Mutex
+	trace *Trace
+}
+
+type traceContextKey struct{}
+
+// EnableTracing records the time spent in every middleware and the handler of each request, and shows the recent
+// requests as a waterfall at <prefix>/trace/{id}. Responses get an X-Trace-Id header with the ID of their trace.
+// Like dev errors, tracing is a development tool and is disabled when RouterConfig.Env is "production".
+func (r *Router) EnableTracing(prefix string) *RouteGroup {
+	r.mustBeMutable()
+	r.tracer = newTracer()
+	return r.Group(prefix, func(tracing *Router) {
+		tracing.GET("/trace", adminEndpoint((*Router).traceList))
+		tracing.GET("/trace/{id}", adminEndpoint((*Router).traceWaterfall))
+	}).Set("devtools", true)
+}
+
+func (r *Router) tracingEnabled() bool {
+	return r.tracer != nil && r.config.Env != "production"
+}
+
+// tracedMiddlewares wraps the middlewares and the handler, so every layer adds a span to the trace of the request
+func tracedMiddlewares(handler http.HandlerFunc, middlewares []Middleware, names []string) (http.HandlerFunc, []Middleware) {
+	traced := make([]Middleware, len(middlewares))
+	for i, middleware := range middlewares {
+		traced[i] = func(next http.HandlerFunc) http.HandlerFunc {
+			return tracedLayer(names[i], i, middleware(next))
+		}
+	}
+	return tracedLayer("handler", len(middlewares), handler), traced
+}
+
+func tracedLayer(name string, depth int, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		active, ok := req.Context().Value(traceContextKey{}).(*activeTrace)
+		if !ok {
+			handler(w, req)
+			return
+		}
+		start := time.Now()
+		defer func() {
+			active.mutex.Lock()
+			defer active.mutex.Unlock()
+			active.trace.Spans = append(active.trace.Spans, Span{
+				Name:     name,
+				Depth:    depth,
+				Start:    start.Sub(active.trace.Start),
+				Duration: time.Since(start),
+			})
+		}()
+		handler(w, req)
+	}
+}
+
+// traceHandler starts a trace for every request of the route and stores it when the response has been written
+func (r *Router) traceHandler(route *Route, handler http.HandlerFunc) http.HandlerFunc {
+	if !r.tracingEnabled() {
+		return handler
+	}
+	if _, ok := route.Get("devtools"); ok {
+		return handler
+	}
+	tracer := r.tracer
+	return func(w http.ResponseWriter, req *http.Request) {
+		trace := &Trace{
+			ID:     newTraceID(),
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Route:  route.FullPattern(),
+			Team:   route.GetTeam(),
+			Start:  time.Now(),
+		}
+		active := &activeTrace{trace: trace}
+		rw := &responseWriter{ResponseWriter: w}
+		rw.Header().Set("X-Trace-Id", trace.ID)
+		defer func() {
+			active.mutex.Lock()
+			trace.Status = rw.Status()
+			trace.Duration = time.Since(trace.Start)
+			// Spans are added when a layer returns, so inner layers come first
+			spans := trace.Spans
+			for i, j := 0, len(spans)-1; i < j; i, j = i+1, j-1 {
+				spans[i], spans[j] = spans[j], spans[i]
+			}
+			active.mutex.Unlock()
+			tracer.add(trace)
+		}()
+		handler(rw, req.WithContext(context.WithValue(req.Context(), traceContextKey{}, active)))
+	}
+}
+
+func newTraceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (r *Router) traceList(w http.ResponseWriter, req *http.Request) {
+	if !r.tracingEnabled() {
+		r.writeError(w, req, http.StatusNotFound, nil)
+		return
+	}
+	traces := r.tracer.recent()
+	Vary(w, "Accept")
+	if !strings.Contains(req.Header.Get("Accept"), "text/html") {
+		writeAdminJSON(w, traces)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	traceListTemplate.Execute(w, struct {
+		Base   string
+		Traces []*Trace
+	}{strings.TrimSuffix(req.URL.Path, "/"), traces})
+}
+
+func (r *Router) traceWaterfall(w http.ResponseWriter, req *http.Request) {
+	if !r.tracingEnabled() {
+		r.writeError(w, req, http.StatusNotFound, nil)
+		return
+	}
+	trace := r.tracer.get(req.PathValue("id"))
+	if trace == nil {
+		r.writeError(w, req, http.StatusNotFound, nil)
+		return
+	}
+	Vary(w, "Accept")
+	if !strings.Contains(req.Header.Get("Accept"), "text/html") {
+		writeAdminJSON(w, trace)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	traceWaterfallTemplate.Execute(w, trace)
+}
+
+var traceTemplateFuncs = template.FuncMap{
+	// percent returns the position of a span in the waterfall as a percentage of the request
+	"percent": func(d time.Duration, total time.Duration) float64 {
+		if total <= 0 {
+			return 0
+		}
+		return float64(d) / float64(total) * 100
+	},
+	"indent": func(depth int) int {
+		return depth * 16
+	},
+}
+
+const traceStyle = `<style>
+body { margin: 0; font-family: system-ui, sans-serif; background: #1e1e2e; color: #cdd6f4; }
+header { padding: 24px 32px; background: #89b4fa; color: #1e1e2e; }
+header h1 { margin: 0; font-size: 20px; }
+header p { margin: 8px 0 0; font-family: monospace; }
+section { padding: 16px 32px; }
+a { color: #89b4fa; }
+table { width: 100%; border-collapse: collapse; font-family: monospace; font-size: 13px; }
+td, th { padding: 6px 8px; text-align: left; border-bottom: 1px solid #313244; }
+.name { width: 30%; white-space: nowrap; }
+.duration { width: 10%; text-align: right; color: #a6adc8; }
+.bar { position: relative; height: 14px; }
+.bar span { position: absolute; height: 14px; min-width: 1px; background: #a6e3a1; border-radius: 2px; }
+</style>`
+
+var traceListTemplate = template.Must(template.New("traces").Funcs(traceTemplateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Traces</title>
+` + traceStyle + `
+</head>
+<body>
+<header><h1>Recent requests</h1></header>
+<section>
+<table>
+<tr><th>Request</th><th>Route</th><th>Status</th><th class="duration">Duration</th></tr>
+{{$base := .Base}}{{range .Traces}}<tr><td><a href="{{$base}}/{{.ID}}">{{.Method}} {{.Path}}</a></td><td>{{.Route}}</td><td>{{.Status}}</td><td class="duration">{{.Duration}}</td></tr>
+{{end}}</table>
+</section>
+</body>
+</html>
+`))
+
+var traceWaterfallTemplate = template.Must(template.New("trace").Funcs(traceTemplateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Method}} {{.Path}}</title>
+` + traceStyle + `
+</head>
+<body>
+<header>
+<h1>{{.Method}} {{.Path}}</h1>
+<p>{{.Route}} · {{.Status}} · {{.Duration}}</p>
+</header>
+<section>
+<table>
+{{$total := .Duration}}{{range .Spans}}<tr>
+<td class="name" style="padding-left: {{indent .Depth}}px">{{.Name}}</td>
+<td class="duration">{{.Duration}}</td>
+<td><div class="bar"><span style="left: {{percent .Start $total}}%; width: {{percent .Duration $total}}%"></span></div></td>
+</tr>
+{{end}}</table>
+</section>
+</body>
+</html>
+`))
@@ -0,0 +1,90 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestTracing(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.Use(slowMiddleware)
+	r.EnableTracing("/__router")
+	r.GET("/users", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/", nil))
+	id := w.Header().Get("X-Trace-Id")
+	if id == "" {
+		t.Fatal("Expected an X-Trace-Id header")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__router/trace/"+id+"/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var trace router.Trace
+	if err := json.NewDecoder(w.Body).Decode(&trace); err != nil {
+		t.Fatalf("Failed to decode the trace: %v", err)
+	}
+	if trace.Route != "GET /users/{$}" || trace.Status != http.StatusCreated {
+		t.Errorf("Expected a trace of GET /users/{$} with status 201, got %s with %d", trace.Route, trace.Status)
+	}
+	if len(trace.Spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %+v", trace.Spans)
+	}
+	if trace.Spans[0].Name != "router_test.slowMiddleware" || trace.Spans[0].Depth != 0 {
+		t.Errorf("Expected the middleware as first span, got %+v", trace.Spans[0])
+	}
+	if trace.Spans[1].Name != "handler" || trace.Spans[1].Start < 20*time.Millisecond {
+		t.Errorf("Expected the handler to start after the middleware slept, got %+v", trace.Spans[1])
+	}
+
+	// Browsers get the waterfall page
+	req := httptest.NewRequest(http.MethodGet, "/__router/trace/"+id+"/", nil)
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "router_test.slowMiddleware") {
+		t.Error("Expected the waterfall to contain the middleware")
+	}
+
+	// The trace endpoints aren't traced themselves
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__router/trace/", nil))
+	var traces []router.Trace
+	if err := json.NewDecoder(w.Body).Decode(&traces); err != nil {
+		t.Fatalf("Failed to decode the traces: %v", err)
+	}
+	if len(traces) != 1 || traces[0].ID != id {
+		t.Errorf("Expected only the trace of the users request, got %+v", traces)
+	}
+}
+
+func TestTracingDisabledInProduction(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithConfig(router.RouterConfig{Env: router.EnvProduction}))
+	r.EnableTracing("/__router")
+	r.GET("/users", func(w http.ResponseWriter, req *http.Request) {})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/", nil))
+	if w.Header().Get("X-Trace-Id") != "" {
+		t.Error("Expected no X-Trace-Id header in production")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/__router/trace/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,14 @@
+package router
+
+// RouteTypes are the types of the request and response body of a route, e.g. to generate typed clients with the gen
+// package. A nil type means the route has no body.
+type RouteTypes struct {
+	Request  any
+	Response any
+}
+
+// Types sets the types of the request and response body of the route, pass a value of each type, e.g.
+// Types(CreateUser{}, User{}). They're stored as "types" metadata.
+func (r *Route) Types(request any, response any) *Route {
+	return r.Set("types", RouteTypes{Request: request, Response: response})
+}
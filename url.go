@@ -0,0 +1,136 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrRouteNotFound is returned when generating a URL for a name that no route has.
+var ErrRouteNotFound = errors.New("router: no route with this name")
+
+// Name gives the route a name, which can be used to generate URLs for it.
+func (r *Route) Name(name string) *Route {
+	r.mustBeMutable()
+	r.name = name
+	return r
+}
+
+// GetName returns the name of the route, or an empty string if it has none.
+func (r *Route) GetName() string {
+	return r.name
+}
+
+// RouteByName returns the route with the given name, or nil if there is none.
+func (r *Router) RouteByName(name string) *Route {
+	for _, route := range r.Routes() {
+		if route.name == name {
+			return route
+		}
+	}
+	return nil
+}
+
+// URL generates the path for the named route, filling in the path parameters. The path ends the same way as the
+// registered pattern, so with the default config it has a trailing slash.
+func (r *Router) URL(name string, params map[string]string) (string, error) {
+	route := r.RouteByName(name)
+	if route == nil {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotFound, name)
+	}
+
+	pattern := r.routePattern(route)
+	if route.Method != "" {
+		pattern = strings.TrimPrefix(pattern, route.Method+" ")
+	}
+	pattern = strings.TrimSuffix(pattern, "{$}")
+
+	var b strings.Builder
+	for {
+		start := strings.Index(pattern, "{")
+		if start < 0 {
+			b.WriteString(pattern)
+			break
+		}
+		end := strings.Index(pattern[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("router: invalid pattern %q", pattern)
+		}
+		end += start
+
+		b.WriteString(pattern[:start])
+		param, remainder := strings.CutSuffix(pattern[start+1:end], "...")
+		value, ok := params[param]
+		if !ok {
+			return "", fmt.Errorf("router: missing parameter %q for route %q", param, name)
+		}
+		if remainder {
+			// A remainder wildcard can span multiple segments, so only the segments are escaped
+			segments := strings.Split(value, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+		pattern = pattern[end+1:]
+	}
+	return b.String(), nil
+}
+
+// URLFor generates the path for the named route on the router that matched the request.
+func URLFor(req *http.Request, name string, params map[string]string) (string, error) {
+	r := routerFromRequest(req)
+	if r == nil {
+		return "", fmt.Errorf("%w: %q (request was not matched by a router)", ErrRouteNotFound, name)
+	}
+	return r.URL(name, params)
+}
+
+// RouteFor returns the named route of the router that matched the request, or nil if there is none.
+func RouteFor(req *http.Request, name string) *Route {
+	r := routerFromRequest(req)
+	if r == nil {
+		return nil
+	}
+	return r.RouteByName(name)
+}
+
+// ParamNames returns the names of the path parameters in the pattern of the route, e.g. ["id"] for "/users/{id}".
+func (r *Route) ParamNames() []string {
+	var names []string
+	pattern := r.fullPattern
+	if pattern == "" {
+		pattern = r.Pattern
+		if r.group != nil {
+			pattern = r.group.Prefix + "/" + pattern
+		}
+	}
+	for {
+		start := strings.Index(pattern, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(pattern[start:], "}")
+		if end < 0 {
+			break
+		}
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name != "$" {
+			names = append(names, name)
+		}
+		pattern = pattern[start+end+1:]
+	}
+	return names
+}
+
+// routePattern returns the pattern of the route as it is registered on the mux
+func (r *Router) routePattern(route *Route) string {
+	if route.group != nil {
+		return r.GetPathForRouteWithRouteGroup(route, route.group)
+	}
+	return r.GetPathForRoute(route)
+}
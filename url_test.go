@@ -0,0 +1,71 @@
+package router_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestURL(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	r.GET("/", handler).Name("home")
+	r.GET("/users/{id}", handler).Name("users.show")
+	r.GET("/files/{path...}", handler).Name("files")
+	r.Group("/teams/{team}", func(rg *router.Router) {
+		rg.GET("/members/{id}/edit", handler).Name("teams.members.edit")
+	})
+
+	// Define test cases for each named route
+	tests := []struct {
+		name     string
+		params   map[string]string
+		expected string
+	}{
+		{"home", nil, "/"},
+		{"users.show", map[string]string{"id": "5"}, "/users/5/"},
+		{"users.show", map[string]string{"id": "a b/c"}, "/users/a%20b%2Fc/"},
+		{"files", map[string]string{"path": "docs/read me.txt"}, "/files/docs/read%20me.txt"},
+		{"teams.members.edit", map[string]string{"team": "core", "id": "7"}, "/teams/core/members/7/edit/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, err := r.URL(tt.name, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if url != tt.expected {
+				t.Errorf("unexpected url: got %v want %v", url, tt.expected)
+			}
+		})
+	}
+
+	if _, err := r.URL("unknown", nil); !errors.Is(err, router.ErrRouteNotFound) {
+		t.Errorf("expected ErrRouteNotFound, got %v", err)
+	}
+	if _, err := r.URL("users.show", nil); err == nil {
+		t.Errorf("expected an error for a missing parameter")
+	}
+}
+
+func TestRemainderWildcard(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.PathValue("path")))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/files/docs/readme.txt", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if body := rr.Body.String(); body != "docs/readme.txt" {
+		t.Errorf("handler returned unexpected body: got %v want %v", body, "docs/readme.txt")
+	}
+}
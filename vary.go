@@ -0,0 +1,69 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Vary adds the request headers to the Vary header of the response, so caches store a response per value of them.
+// Headers that are already listed aren't added again, no matter how they were added, and "*" replaces all of them.
+// Middlewares that choose the response based on a request header, like compression, locales or A/B tests, should
+// call it before writing the response.
+//
+//	router.Vary(w, "Accept-Language")
+func Vary(w http.ResponseWriter, headers ...string) {
+	header := w.Header()
+	existing := varyHeaders(header)
+	if len(existing) == 1 && existing[0] == "*" {
+		return
+	}
+	changed := false
+	for _, name := range headers {
+		name = strings.TrimSpace(name)
+		if name == "" || containsFold(existing, name) {
+			continue
+		}
+		if name == "*" {
+			header.Set("Vary", "*")
+			return
+		}
+		existing = append(existing, http.CanonicalHeaderKey(name))
+		changed = true
+	}
+	if changed {
+		header.Set("Vary", strings.Join(existing, ", "))
+	}
+}
+
+// VaryBy returns a middleware that adds the headers to the Vary header of the responses, for routes whose handlers
+// choose the response based on them.
+func VaryBy(headers ...string) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			Vary(w, headers...)
+			next(w, req)
+		}
+	}
+}
+
+// varyHeaders returns the headers listed in all Vary headers of the response
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" && !containsFold(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestVary(t *testing.T) {
+	// Define test cases
+	tests := []struct {
+		name     string
+		existing []string
+		headers  []string
+		expected []string
+	}{
+		{"empty", nil, []string{"accept-encoding"}, []string{"Accept-Encoding"}},
+		{"appends", []string{"Accept"}, []string{"Accept-Language"}, []string{"Accept, Accept-Language"}},
+		{"no duplicates", []string{"Accept, accept-encoding"}, []string{"Accept-Encoding", "accept"}, []string{"Accept, accept-encoding"}},
+		{"merges headers", []string{"Accept", "Cookie"}, []string{"X-Variant"}, []string{"Accept, Cookie, X-Variant"}},
+		{"star replaces all", []string{"Accept"}, []string{"*"}, []string{"*"}},
+		{"star absorbs", []string{"*"}, []string{"Accept"}, []string{"*"}},
+		{"nothing to add", nil, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			for _, value := range tt.existing {
+				rec.Header().Add("Vary", value)
+			}
+			router.Vary(rec, tt.headers...)
+			got := rec.Header().Values("Vary")
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %q, got %q", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected %q, got %q", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVaryBy(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(router.VaryBy("Accept-Encoding"))
+	r.GET("/greeting", func(w http.ResponseWriter, req *http.Request) {
+		router.Vary(w, "Accept-Language", "accept-encoding")
+	}).Use(router.VaryBy("Accept-Language"))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/greeting/", nil))
+	if got := rec.Header().Get("Vary"); got != "Accept-Encoding, Accept-Language" {
+		t.Errorf("expected %q, got %q", "Accept-Encoding, Accept-Language", got)
+	}
+}
@@ -0,0 +1,148 @@
+package router
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// VirtualHosts serves a router per host, so one process can serve several apps. Hosts are matched by the Host header
+// without the port, "*.example.com" matches all subdomains of example.com. Requests for other hosts are served by the
+// default router, or get a 404 when there is none.
+type VirtualHosts struct {
+	hosts        map[string]*Router
+	defaultHost  *Router
+	middlewares  []Middleware
+	certificates map[string]*tls.Certificate
+	conns        connTracker
+
+	once    sync.Once
+	handler http.HandlerFunc
+}
+
+// VHost returns a dispatcher that serves the routers by their host.
+//
+//	hosts := router.VHost(map[string]*router.Router{
+//		"example.com":     site,
+//		"api.example.com": api,
+//		"*.example.com":   tenants,
+//	})
+//	hosts.RunTLS(":443")
+func VHost(hosts map[string]*Router) *VirtualHosts {
+	v := &VirtualHosts{hosts: make(map[string]*Router, len(hosts)), certificates: make(map[string]*tls.Certificate)}
+	for host, r := range hosts {
+		v.hosts[strings.ToLower(host)] = r
+	}
+	return v
+}
+
+// Default sets the router for requests to hosts without a router of their own.
+func (v *VirtualHosts) Default(r *Router) *VirtualHosts {
+	v.defaultHost = r
+	return v
+}
+
+// Use adds middlewares that run for the requests of all hosts, before the router of the host. They run before
+// routing, so MatchedRoute returns nil in them.
+func (v *VirtualHosts) Use(middlewares ...Middleware) *VirtualHosts {
+	v.middlewares = append(v.middlewares, middlewares...)
+	return v
+}
+
+// Certificate loads the certificate for the host, it's picked by the server name the client sends in the TLS
+// handshake (SNI). Wildcard hosts like "*.example.com" are matched the same way as the routers.
+func (v *VirtualHosts) Certificate(host string, certFile string, keyFile string) error {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("router: loading the certificate for %s: %w", host, err)
+	}
+	v.certificates[strings.ToLower(host)] = &certificate
+	return nil
+}
+
+// Router returns the router that serves the host, or the default router.
+func (v *VirtualHosts) Router(host string) *Router {
+	if r := matchHost(v.hosts, host); r != nil {
+		return r
+	}
+	return v.defaultHost
+}
+
+func (v *VirtualHosts) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	v.once.Do(func() {
+		v.handler = v.dispatch
+		for i := len(v.middlewares) - 1; i >= 0; i-- {
+			v.handler = v.middlewares[i](v.handler)
+		}
+	})
+	v.handler(w, req)
+}
+
+func (v *VirtualHosts) dispatch(w http.ResponseWriter, req *http.Request) {
+	// Clients can reuse a connection for other hosts with the same certificate, but not send a request for a host
+	// the certificate of the connection wasn't chosen for
+	if req.TLS != nil && req.TLS.ServerName != "" && len(v.certificates) > 0 &&
+		matchHost(v.certificates, req.TLS.ServerName) != matchHost(v.certificates, req.Host) {
+		Error(w, req, http.StatusMisdirectedRequest, nil)
+		return
+	}
+	r := v.Router(req.Host)
+	if r == nil {
+		Error(w, req, http.StatusNotFound, nil)
+		return
+	}
+	r.ServeHTTP(w, req)
+}
+
+// Server returns a http.Server for the hosts, with the same timeouts as Router.Server. With certificates, the TLS
+// config picks the certificate of the host the client asks for.
+func (v *VirtualHosts) Server(addr string, options ...ServerOption) *http.Server {
+	if len(v.certificates) > 0 {
+		options = append([]ServerOption{WithTLSConfig(&tls.Config{
+			MinVersion: tls.VersionTLS12,
+			GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+				if certificate := matchHost(v.certificates, hello.ServerName); certificate != nil {
+					return certificate, nil
+				}
+				return nil, fmt.Errorf("router: no certificate for %q", hello.ServerName)
+			},
+		})}, options...)
+	}
+	return newServer(addr, v, &v.conns, options)
+}
+
+// Run serves the hosts on the address.
+func (v *VirtualHosts) Run(addr string, options ...ServerOption) error {
+	return v.Server(addr, options...).ListenAndServe()
+}
+
+// RunTLS serves the hosts on the address using TLS, with the certificates added with Certificate.
+func (v *VirtualHosts) RunTLS(addr string, options ...ServerOption) error {
+	return v.Server(addr, options...).ListenAndServeTLS("", "")
+}
+
+// ConnStats returns the connection counts of the servers created by Server, Run and RunTLS.
+func (v *VirtualHosts) ConnStats() ConnStats {
+	return v.conns.stats()
+}
+
+// matchHost returns the value for the host, exact hosts are preferred over the longest matching wildcard
+func matchHost[T any](hosts map[string]*T, host string) *T {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if value, ok := hosts[host]; ok {
+		return value
+	}
+	for i := strings.IndexByte(host, '.'); i >= 0; i = strings.IndexByte(host, '.') {
+		host = host[i+1:]
+		if value, ok := hosts["*."+host]; ok {
+			return value
+		}
+	}
+	return nil
+}
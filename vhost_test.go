@@ -0,0 +1,157 @@
+package router_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogo-framework/router"
+)
+
+// writeCertificate writes a self-signed certificate for the host, and returns the paths of the certificate and key
+func writeCertificate(t *testing.T, host string) (string, string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestVirtualHosts(t *testing.T) {
+	// newApp creates a router that responds with its name
+	newApp := func(name string) *router.Router {
+		r := router.NewRouter(router.WithMux(http.NewServeMux()))
+		r.GET("/", func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, name)
+		})
+		return r
+	}
+
+	hosts := router.VHost(map[string]*router.Router{
+		"example.com":      newApp("site"),
+		"API.example.com":  newApp("api"),
+		"*.example.com":    newApp("tenants"),
+		"*.eu.example.com": newApp("eu"),
+	})
+	hosts.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Served-By", "gateway")
+			next(w, req)
+		}
+	})
+
+	// Define test cases
+	tests := []struct {
+		name       string
+		host       string
+		statusCode int
+		body       string
+	}{
+		{"exact host", "example.com", http.StatusOK, "site"},
+		{"case insensitive", "api.EXAMPLE.com", http.StatusOK, "api"},
+		{"with port", "api.example.com:8080", http.StatusOK, "api"},
+		{"wildcard", "acme.example.com", http.StatusOK, "tenants"},
+		{"longest wildcard", "acme.eu.example.com", http.StatusOK, "eu"},
+		{"unknown host", "example.org", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			hosts.ServeHTTP(rec, req)
+
+			if rec.Code != tt.statusCode {
+				t.Errorf("expected status %d, got %d", tt.statusCode, rec.Code)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, rec.Body.String())
+			}
+			if rec.Header().Get("X-Served-By") != "gateway" {
+				t.Error("expected the shared middleware to run")
+			}
+		})
+	}
+
+	t.Run("default", func(t *testing.T) {
+		hosts.Default(newApp("default"))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = "example.org"
+		rec := httptest.NewRecorder()
+		hosts.ServeHTTP(rec, req)
+		if rec.Body.String() != "default" {
+			t.Errorf("expected the default router, got %q", rec.Body.String())
+		}
+	})
+}
+
+func TestVirtualHostsTLS(t *testing.T) {
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.GET("/", func(w http.ResponseWriter, req *http.Request) {})
+
+	hosts := router.VHost(map[string]*router.Router{"example.com": r, "api.example.com": r})
+	for _, host := range []string{"example.com", "api.example.com"} {
+		certFile, keyFile := writeCertificate(t, host)
+		if err := hosts.Certificate(host, certFile, keyFile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hosts.Certificate("other.com", "missing.pem", "missing.pem"); err == nil {
+		t.Error("expected an error for a missing certificate")
+	}
+
+	// The certificate is picked by the server name of the handshake
+	server := hosts.Server(":443")
+	for _, host := range []string{"example.com", "api.example.com"} {
+		certificate, err := server.TLSConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: host})
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, _ := x509.ParseCertificate(certificate.Certificate[0])
+		if leaf.Subject.CommonName != host {
+			t.Errorf("expected the certificate of %s, got %s", host, leaf.Subject.CommonName)
+		}
+	}
+	if _, err := server.TLSConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.com"}); err == nil {
+		t.Error("expected an error for a host without a certificate")
+	}
+
+	// Requests for another host than the certificate of the connection was chosen for are misdirected
+	for host, statusCode := range map[string]int{"api.example.com": http.StatusOK, "example.com": http.StatusMisdirectedRequest} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		req.TLS = &tls.ConnectionState{ServerName: "api.example.com"}
+		rec := httptest.NewRecorder()
+		hosts.ServeHTTP(rec, req)
+		if rec.Code != statusCode {
+			t.Errorf("expected status %d for %s, got %d", statusCode, host, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Warmup adds a function that is run when the router is built, e.g. to fill caches, compile templates or connect
+// to upstream services, so the route is ready before the server accepts traffic.
+func (r *Route) Warmup(fn func(ctx context.Context) error) *Route {
+	r.mustBeMutable()
+	r.warmups = append(r.warmups, fn)
+	return r
+}
+
+// Build sets up the routes and runs the warmup functions of all routes concurrently. The errors of all failed
+// warmups are joined, so they can be reported at once. Without Build, routes are set up on the first request and
+// warmups don't run.
+func (r *Router) Build(ctx context.Context) error {
+	r.setup()
+
+	type warmup struct {
+		route *Route
+		fn    func(ctx context.Context) error
+	}
+	var warmups []warmup
+	for _, route := range r.Routes() {
+		for _, fn := range route.warmups {
+			warmups = append(warmups, warmup{route: route, fn: fn})
+		}
+	}
+
+	// Errors are stored by index, so they are reported in the order the routes were registered
+	errs := make([]error, len(warmups))
+	var wg sync.WaitGroup
+	for i, w := range warmups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.fn(ctx); err != nil {
+				errs[i] = fmt.Errorf("warmup of %s: %w", w.route.FullPattern(), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,74 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestWarmup(t *testing.T) {
+	var warmed atomic.Int32
+	errCache := errors.New("cache unavailable")
+	errUpstream := errors.New("upstream unreachable")
+
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/users", func(w http.ResponseWriter, r *http.Request) {}).
+		Warmup(func(ctx context.Context) error {
+			warmed.Add(1)
+			return nil
+		})
+	r.GET("/reports", func(w http.ResponseWriter, r *http.Request) {}).
+		Warmup(func(ctx context.Context) error { return errCache })
+	r.Group("/proxy", func(r *router.Router) {
+		r.GET("/orders", func(w http.ResponseWriter, r *http.Request) {}).
+			Warmup(func(ctx context.Context) error { return errUpstream })
+	})
+
+	err := r.Build(context.Background())
+	if warmed.Load() != 1 {
+		t.Errorf("Expected the warmup to run once, ran %d times", warmed.Load())
+	}
+	if !errors.Is(err, errCache) || !errors.Is(err, errUpstream) {
+		t.Fatalf("Expected both warmup errors, got %v", err)
+	}
+
+	// The errors are reported in the order the routes were registered
+	expected := "warmup of GET /reports/{$}: cache unavailable\nwarmup of GET /proxy/orders/{$}: upstream unreachable"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+
+	// The router serves requests after it's built
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+	if warmed.Load() != 1 {
+		t.Errorf("Expected warmups not to run on requests")
+	}
+}
+
+func TestWarmupContext(t *testing.T) {
+	// Create a new router instance
+	r := router.NewRouter()
+	r.GET("/slow", func(w http.ResponseWriter, r *http.Request) {}).
+		Warmup(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := r.Build(ctx)
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "/slow/") {
+		t.Errorf("Expected a canceled warmup of /slow/, got %v", err)
+	}
+}
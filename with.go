@@ -0,0 +1,19 @@
+package router
+
+import "slices"
+
+// With returns a router that registers routes with extra middlewares, without a path prefix, so a few routes can
+// share e.g. authentication without an artificial URL segment:
+//
+//	authed := r.With(requireLogin)
+//	authed.GET("/account", showAccount)
+//	authed.POST("/account", updateAccount)
+//
+// The routes are added to r as a group without a prefix, after the global middlewares of r. The returned router is
+// only meant for registering routes and middlewares, requests are served by r.
+func (r *Router) With(middlewares ...Middleware) *Router {
+	if r.scope != nil {
+		return r.scope.router.With(append(slices.Clone(r.scope.Middlewares), middlewares...)...)
+	}
+	return &Router{scope: r.Group("").Use(middlewares...)}
+}
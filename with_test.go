@@ -0,0 +1,73 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo-framework/router"
+)
+
+func TestWith(t *testing.T) {
+	handler := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}
+	}
+	header := func(name string) router.Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", name)
+				next(w, r)
+			}
+		}
+	}
+
+	// Create a new router instance with a few routes that share middlewares without a prefix
+	r := router.NewRouter(router.WithMux(http.NewServeMux()))
+	r.Use(header("global"))
+	r.GET("/", handler("home"))
+	authed := r.With(header("auth"))
+	authed.GET("/account", handler("account"))
+	authed.POST("/account", handler("updated")).Use(header("route"))
+	authed.With(header("admin")).GET("/admin", handler("admin"))
+	authed.Group("/settings", func(r *router.Router) {
+		r.Use(header("settings"))
+		r.GET("/profile", handler("profile"))
+	})
+
+	// Define test cases
+	tests := []struct {
+		method      string
+		path        string
+		body        string
+		middlewares string
+	}{
+		{http.MethodGet, "/", "home", "global"},
+		{http.MethodGet, "/account/", "account", "global,auth"},
+		{http.MethodPost, "/account/", "updated", "global,auth,route"},
+		{http.MethodGet, "/admin/", "admin", "global,auth,admin"},
+		{http.MethodGet, "/settings/profile/", "profile", "global,auth,settings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			if rr.Body.String() != tt.body {
+				t.Errorf("Expected body %s, got %s", tt.body, rr.Body.String())
+			}
+			if got := strings.Join(rr.Header().Values("X-Middleware"), ","); got != tt.middlewares {
+				t.Errorf("Expected middlewares %s, got %s", tt.middlewares, got)
+			}
+		})
+	}
+
+	// The routes are registered without a prefix
+	for _, route := range r.Routes() {
+		if route.Pattern == "/account" && route.FullPattern() != "GET /account/{$}" && route.FullPattern() != "POST /account/{$}" {
+			t.Errorf("Expected the route to be registered without a prefix, got %s", route.FullPattern())
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package router
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// responseWriter wraps a http.ResponseWriter to keep track of the status code and the amount of bytes written.
+// It supports flushing, hijacking and trailers, so streaming handlers keep working when it's in the chain.
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode   int
+	bytesWritten int64
+}
+
+func (rw *responseWriter) WriteHeader(statusCode int) {
+	// Informational responses like 103 Early Hints can be followed by the final status code
+	if rw.statusCode == 0 && statusCode >= 200 {
+		rw.statusCode = statusCode
+	}
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if rw.statusCode == 0 {
+		rw.statusCode = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytesWritten += int64(n)
+	return n, err
+}
+
+// Unwrap allows http.ResponseController to reach the underlying ResponseWriter.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *responseWriter) Status() int {
+	if rw.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rw.statusCode
+}
+
+// Flush implements http.Flusher, so streaming responses work when the underlying ResponseWriter supports it.
+func (rw *responseWriter) Flush() {
+	rw.FlushError()
+}
+
+// Push implements http.Pusher, it returns http.ErrNotSupported when the underlying ResponseWriter can't push.
+func (rw *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := rw.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// FlushError flushes the underlying ResponseWriter and returns its error, it's used by http.ResponseController.
+func (rw *responseWriter) FlushError() error {
+	if rw.statusCode == 0 {
+		rw.statusCode = http.StatusOK
+	}
+	return http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker, so connection upgrades like WebSockets work through the middleware chain.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(rw.ResponseWriter).Hijack()
+}
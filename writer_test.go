@@ -0,0 +1,64 @@
+package router_test
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo-framework/router"
+	"github.com/gogo-framework/router/middleware"
+	"github.com/gogo-framework/router/render"
+)
+
+func TestStreamingThroughMiddleware(t *testing.T) {
+	next := make(chan struct{})
+
+	// Create a new router instance with a response hook and a middleware that wrap the ResponseWriter
+	r := router.NewRouter()
+	r.OnResponse(func(info router.ResponseInfo) {})
+	r.Use(middleware.Idempotency(middleware.IdempotencyConfig{}))
+
+	r.POST("/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		render.Chunked(w, func(yield func([]byte) bool) {
+			for _, line := range []string{"first\n", "second\n"} {
+				if !yield([]byte(line)) {
+					return
+				}
+				// Wait for the client to receive the line, which only happens if it was flushed
+				<-next
+			}
+		})
+		w.Header().Set("X-Checksum", "abc123")
+	})
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/export/", nil)
+	req.Header.Set("Idempotency-Key", "export-1")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	reader := bufio.NewReader(res.Body)
+	for _, expected := range []string{"first\n", "second\n"} {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read line: %v", err)
+		}
+		if line != expected {
+			t.Errorf("unexpected line: got %q want %q", line, expected)
+		}
+		next <- struct{}{}
+	}
+	io.ReadAll(reader)
+
+	if checksum := res.Trailer.Get("X-Checksum"); checksum != "abc123" {
+		t.Errorf("unexpected trailer: got %q want %q", checksum, "abc123")
+	}
+}